@@ -1,6 +1,7 @@
 package bridge
 
 import (
+	"crypto/tls"
 	"errors"
 	"flag"
 	"fmt"
@@ -19,8 +20,14 @@ import (
 	"github.com/stellar/gateway/db"
 	"github.com/stellar/gateway/db/drivers/mysql"
 	"github.com/stellar/gateway/db/drivers/postgres"
+	"github.com/stellar/gateway/events"
+	"github.com/stellar/gateway/external"
 	"github.com/stellar/gateway/horizon"
 	"github.com/stellar/gateway/listener"
+	"github.com/stellar/gateway/metrics"
+	"github.com/stellar/gateway/net"
+	"github.com/stellar/gateway/protocols"
+	"github.com/stellar/gateway/ratelimit"
 	"github.com/stellar/gateway/server"
 	"github.com/stellar/gateway/submitter"
 	"github.com/stellar/go/clients/federation"
@@ -40,6 +47,8 @@ type App struct {
 func NewApp(config config.Config, migrateFlag bool, versionFlag bool, version string) (app *App, err error) {
 	var g inject.Graph
 
+	log.WithFields(config.LogFields()).Info("Effective configuration")
+
 	var driver db.Driver
 	switch config.Database.Type {
 	case "mysql":
@@ -53,7 +62,7 @@ func NewApp(config config.Config, migrateFlag bool, versionFlag bool, version st
 		return nil, fmt.Errorf("%s database has no driver", config.Database.Type)
 	}
 
-	var entityManager db.EntityManagerInterface
+	var entityManager db.EntityManager
 	var repository db.Repository
 
 	if driver != nil {
@@ -91,6 +100,35 @@ func NewApp(config config.Config, migrateFlag bool, versionFlag bool, version st
 	}
 
 	h := horizon.New(config.Horizon)
+	h.MaxRetryWait = time.Duration(config.HorizonMaxRetryWait) * time.Second
+	// Waiting for Horizon must leave time for the rest of the request
+	if config.RequestTimeout > 0 && config.HorizonMaxRetryWait >= config.RequestTimeout {
+		h.MaxRetryWait = time.Duration(config.RequestTimeout) * time.Second / 2
+	}
+
+	tlsConfig, err := net.NewTLSConfig(
+		config.HorizonTLS.CABundle,
+		config.HorizonTLS.CertFingerprint,
+		config.HorizonTLS.InsecureSkipVerify,
+	)
+	if err != nil {
+		return
+	}
+
+	if tlsConfig != nil {
+		if config.HorizonTLS.InsecureSkipVerify {
+			log.Warning("horizon_tls.insecure_skip_verify is enabled. Horizon certificate is NOT verified, never use it in production!")
+		}
+		h.Transport = &http.Transport{
+			Proxy:           http.ProxyFromEnvironment,
+			TLSClientConfig: tlsConfig,
+		}
+	}
+
+	err = checkNetworkPassphrase(&h, &config)
+	if err != nil {
+		return
+	}
 
 	log.Print("Creating and initializing TransactionSubmitter")
 	ts := submitter.NewTransactionSubmitter(&h, entityManager, config.NetworkPassphrase, time.Now)
@@ -98,6 +136,17 @@ func NewApp(config config.Config, migrateFlag bool, versionFlag bool, version st
 		return
 	}
 
+	ts.TxTimeout = time.Duration(config.Timebounds.Timeout) * time.Second
+	ts.ClockSkew = time.Duration(config.Timebounds.ClockSkew) * time.Second
+	ts.MaxBaseFee = uint64(config.Submission.MaxBaseFee)
+
+	if config.Submission.RelayURL != "" {
+		log.Print("Submitting transactions via relay: ", config.Submission.RelayURL)
+		ts.SubmissionService = submitter.NewRelaySubmissionService(config.Submission.RelayURL)
+	} else {
+		ts.AsyncSubmissionService = submitter.SubmissionServiceFunc(h.SubmitTransactionAsync)
+	}
+
 	log.Print("Initializing Authorizing account")
 
 	if config.Accounts.AuthorizingSeed == "" {
@@ -147,7 +196,65 @@ func NewApp(config config.Config, migrateFlag bool, versionFlag bool, version st
 		return
 	}
 
+	rateLimiter := ratelimit.NewAssetRateLimiter(time.Now)
+	for _, limit := range config.RateLimits {
+		rateLimiter.SetLimit(
+			ratelimit.Asset{Code: limit.AssetCode, Issuer: limit.AssetIssuer},
+			ratelimit.Limit{Rate: limit.Rate, Burst: limit.Burst},
+		)
+	}
+
+	memoRequiredCache := horizon.NewMemoRequiredCache(&h, time.Duration(config.MemoRequiredCacheTTL)*time.Second, time.Now)
+	feeStatsCache := horizon.NewFeeStatsCache(&h, time.Duration(config.FeeStats.CacheTTL)*time.Second, time.Now)
+
+	var metricsBackend metrics.Metrics
+	switch config.Metrics.Backend {
+	case "prometheus":
+		metricsBackend = metrics.NewPrometheus(config.Metrics.Prefix)
+	case "statsd", "dogstatsd":
+		log.Print("Sending metrics to ", config.Metrics.StatsDAddress)
+		metricsBackend, err = metrics.NewStatsD(config.Metrics.StatsDAddress, config.Metrics.Prefix, config.Metrics.Backend == "dogstatsd")
+		if err != nil {
+			return
+		}
+	default:
+		metricsBackend = &metrics.Noop{}
+	}
+
+	var eventsPublisher events.Publisher
+	switch config.Events.Broker {
+	case "nats":
+		var natsTLS *tls.Config
+		natsTLS, err = net.NewTLSConfig(config.Events.NATSCABundle, config.Events.NATSCertFingerprint, false)
+		if err != nil {
+			return
+		}
+		var broker *events.NATS
+		broker, err = events.NewNATS(config.Events.NATSURL, natsTLS)
+		if err != nil {
+			return
+		}
+		log.Print("Publishing payment events to NATS subject ", config.Events.Subject)
+		publisher := events.NewAsyncPublisher(broker, config.Events.Subject, config.Events.BufferSize, config.Events.Overflow == "block")
+		go publisher.Run()
+		eventsPublisher = publisher
+
+		// Follow-up events of transactions accepted by async submission
+		ts.AsyncWatcher = submitter.NewAsyncWatcher(
+			&h,
+			&repository,
+			&entityManager,
+			eventsPublisher,
+			time.Duration(config.Submission.ConfirmationPollInterval)*time.Second,
+			time.Duration(config.Submission.ConfirmationTimeout)*time.Second,
+			time.Now,
+		)
+	default:
+		eventsPublisher = &events.Noop{}
+	}
+
 	requestHandler := handlers.RequestHandler{}
+	maintenance := &server.Maintenance{}
 
 	httpClientWithTimeout := http.Client{
 		Timeout: 10 * time.Second,
@@ -157,9 +264,34 @@ func NewApp(config config.Config, migrateFlag bool, versionFlag bool, version st
 		HTTP: &httpClientWithTimeout,
 	}
 
+	stellarTomlChecker := external.NewStellarTomlChecker(&httpClientWithTimeout, time.Duration(config.StellarTomlCacheTTL)*time.Second, time.Now)
+
+	// Federation servers are resolved using a separate client so a slow server (or its DNS)
+	// fails fast in the phase it hangs instead of using the whole request timeout.
+	federationHTTPClient := http.Client{
+		Timeout: time.Duration(config.Federation.Timeout) * time.Second,
+		Transport: net.NewLimitTransport(
+			net.NewTimeoutTransport(
+				time.Duration(config.Federation.DialTimeout)*time.Second,
+				time.Duration(config.Federation.TLSHandshakeTimeout)*time.Second,
+				time.Duration(config.Federation.ResponseHeaderTimeout)*time.Second,
+				log.WithField("service", "federation"),
+			),
+			int64(config.Federation.MaxResponseSize),
+		),
+	}
+
 	federationClient := federation.Client{
-		HTTP:        &httpClientWithTimeout,
-		StellarTOML: &stellartomlClient,
+		HTTP: &federationHTTPClient,
+		StellarTOML: external.NewStellarTomlDiscovery(
+			&stellartoml.Client{HTTP: &federationHTTPClient},
+			config.Federation.TomlRetries,
+			time.Duration(config.Federation.TomlRetryWait)*time.Second,
+			time.Duration(config.Federation.TomlCacheTTL)*time.Second,
+			time.Now,
+		),
+		// Used for reverse federation lookups (compliance_sender_policy = home_domain)
+		Horizon: &h,
 	}
 
 	err = g.Provide(
@@ -169,9 +301,17 @@ func NewApp(config config.Config, migrateFlag bool, versionFlag bool, version st
 		&inject.Object{Value: &federationClient},
 		&inject.Object{Value: &h},
 		&inject.Object{Value: &repository},
+		&inject.Object{Value: &entityManager},
 		&inject.Object{Value: driver},
 		&inject.Object{Value: &ts},
 		&inject.Object{Value: &paymentListener},
+		&inject.Object{Value: rateLimiter},
+		&inject.Object{Value: memoRequiredCache},
+		&inject.Object{Value: feeStatsCache},
+		&inject.Object{Value: stellarTomlChecker},
+		&inject.Object{Value: metricsBackend},
+		&inject.Object{Value: eventsPublisher},
+		&inject.Object{Value: maintenance},
 		&inject.Object{Value: &httpClientWithTimeout},
 	)
 
@@ -183,6 +323,19 @@ func NewApp(config config.Config, migrateFlag bool, versionFlag bool, version st
 		log.Fatal("Injector: ", err)
 	}
 
+	if config.ComplianceQueue.Enabled {
+		log.Print("Starting compliance queue worker")
+		go func() {
+			for range time.Tick(time.Duration(config.ComplianceQueue.RetryInterval) * time.Second) {
+				// Queued payments are not sent during maintenance
+				if maintenance.Paused() {
+					continue
+				}
+				requestHandler.ProcessComplianceQueue()
+			}
+		}()
+	}
+
 	app = &App{
 		config:         config,
 		requestHandler: requestHandler,
@@ -190,6 +343,55 @@ func NewApp(config config.Config, migrateFlag bool, versionFlag bool, version st
 	return
 }
 
+// handler returns a handler calling fn with the request handler or, for requests with debug logging
+// enabled or Horizon URL overridden, with its copy (see RequestHandler.WithDebugLog and
+// RequestHandler.WithHorizonURL)
+func (a *App) handler(fn func(*handlers.RequestHandler, http.ResponseWriter, *http.Request)) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		rh, errorResponse := a.requestHandlerFor(r)
+		if errorResponse != nil {
+			server.Write(w, errorResponse)
+			return
+		}
+		fn(rh, w, r)
+	}
+}
+
+// handlerC works like handler for handlers reading URL params from web.C
+func (a *App) handlerC(fn func(*handlers.RequestHandler, web.C, http.ResponseWriter, *http.Request)) web.HandlerFunc {
+	return func(c web.C, w http.ResponseWriter, r *http.Request) {
+		rh, errorResponse := a.requestHandlerFor(r)
+		if errorResponse != nil {
+			server.Write(w, errorResponse)
+			return
+		}
+		fn(rh, c, w, r)
+	}
+}
+
+func (a *App) requestHandlerFor(r *http.Request) (*handlers.RequestHandler, *protocols.ErrorResponse) {
+	rh := &a.requestHandler
+	// Overridden first so requests to the overriding Horizon are debug logged too
+	if serverURL := handlers.HorizonURLRequested(r); serverURL != "" {
+		var errorResponse *protocols.ErrorResponse
+		rh, errorResponse = rh.WithHorizonURL(serverURL)
+		if errorResponse != nil {
+			log.WithFields(log.Fields{"request_id": server.RequestID(r), "horizon": serverURL}).Warn("Horizon URL override rejected")
+			return nil, errorResponse
+		}
+		log.WithFields(log.Fields{"request_id": server.RequestID(r), "horizon": serverURL}).Info("Horizon URL overridden")
+	}
+	if debugLog := server.DebugLog(r); debugLog != nil {
+		rh = rh.WithDebugLog(debugLog)
+	}
+	if handlers.RawErrorsRequested(r) {
+		rh = rh.WithRawErrors()
+	}
+	// Wrapped last so debug logged and overriding clients are cancelled with the request too
+	rh = rh.WithContext(r.Context())
+	return rh, nil
+}
+
 // Serve starts the server
 func (a *App) Serve() {
 	portString := fmt.Sprintf(":%d", *a.config.Port)
@@ -199,26 +401,92 @@ func (a *App) Serve() {
 
 	bridge.Abandon(middleware.Logger)
 	bridge.Use(server.StripTrailingSlashMiddleware())
+	bridge.Use(server.RequestIDMiddleware())
 	bridge.Use(server.HeadersMiddleware())
+	if a.config.Compression.Enabled {
+		bridge.Use(server.CompressionMiddleware(a.config.Compression.MinSize))
+	}
+	// Registered after compression so keys are converted before the body is gzipped
+	if a.config.JSONKeyCase == "camelCase" {
+		bridge.Use(server.CamelCaseMiddleware())
+	}
+	// Registered after key case conversion so `api_version` field is converted too
+	bridge.Use(server.APIVersionMiddleware(a.config.APIVersion, protocols.NewUnsupportedAPIVersionError(server.SupportedAPIVersions())))
+	// Registered before api key check so unauthenticated callers are throttled too
+	if a.config.IPRateLimit.Rate > 0 {
+		// Validated in config
+		proxies, _ := server.ParseTrustedProxies(a.config.IPRateLimit.TrustedProxies)
+		limiter := ratelimit.NewClientRateLimiter(ratelimit.Limit{Rate: float64(a.config.IPRateLimit.Rate), Burst: a.config.IPRateLimit.Burst}, time.Now)
+		bridge.Use(server.ClientRateLimitMiddleware(limiter, a.config.IPRateLimit.ClientIPHeader, proxies, func(retryAfter int) server.Response {
+			return protocols.NewClientRateLimitedError(retryAfter)
+		}))
+	}
 	if a.config.APIKey != "" {
 		bridge.Use(server.APIKeyMiddleware(a.config.APIKey))
 	}
+	// Registered after response rewriting middlewares so the logged response is not compressed nor converted
+	if a.config.DebugSecret != "" {
+		bridge.Use(server.DebugMiddleware(a.config.DebugSecret, log.StandardLogger()))
+	}
+	// Registered after response rewriting middlewares so timeout response is compressed and converted like any other
+	if a.config.RequestTimeout > 0 {
+		bridge.Use(server.TimeoutMiddleware(time.Duration(a.config.RequestTimeout)*time.Second, protocols.RequestTimeoutError))
+	}
+	// Registered after timeout so retries are not started after the request deadline
+	if a.config.RetryBudget > 0 {
+		bridge.Use(server.RetryBudgetMiddleware(a.config.RetryBudget))
+	}
+	// Registered after timeout so panics in handlers run in a separate goroutine by it are recovered too
+	bridge.Use(server.RecoveryMiddleware(protocols.InternalServerError, log.WithField("service", "bridge")))
+
+	// Endpoints changing state return ServiceMaintenanceError while the server is paused (see /admin/maintenance)
+	pausable := a.requestHandler.Maintenance.Middleware(protocols.ServiceMaintenanceError)
 
 	if a.config.Accounts.AuthorizingSeed != "" {
-		bridge.Post("/authorize", a.requestHandler.Authorize)
+		bridge.Post("/authorize", pausable(a.handler((*handlers.RequestHandler).Authorize)))
 	} else {
 		log.Warning("accounts.authorizing_seed not provided. /authorize endpoint will not be available.")
 	}
 
-	bridge.Post("/create-keypair", a.requestHandler.CreateKeypair)
-	bridge.Post("/builder", a.requestHandler.Builder)
-	bridge.Post("/payment", a.requestHandler.Payment)
-	bridge.Get("/payment", a.requestHandler.Payment)
-	bridge.Post("/reprocess", a.requestHandler.Reprocess)
+	bridge.Post("/create-keypair", a.handler((*handlers.RequestHandler).CreateKeypair))
+	bridge.Post("/builder", a.handler((*handlers.RequestHandler).Builder))
+	bridge.Post("/payment", pausable(a.handler((*handlers.RequestHandler).Payment)))
+	bridge.Get("/payment", pausable(a.handler((*handlers.RequestHandler).Payment)))
+	bridge.Post("/batch-payment", pausable(a.handler((*handlers.RequestHandler).BatchPayment)))
+	bridge.Post("/change-trust", pausable(a.handler((*handlers.RequestHandler).ChangeTrust)))
+	bridge.Post("/preauthorize", pausable(a.handler((*handlers.RequestHandler).Preauthorize)))
+	bridge.Post("/reserve-sequence", pausable(a.handler((*handlers.RequestHandler).ReserveSequence)))
+	bridge.Post("/submit", pausable(a.handler((*handlers.RequestHandler).Submit)))
+	bridge.Post("/sign", pausable(a.handler((*handlers.RequestHandler).Sign)))
+	bridge.Post("/transaction-hash", a.handler((*handlers.RequestHandler).TransactionHash))
+	bridge.Post("/reprocess", pausable(a.handler((*handlers.RequestHandler).Reprocess)))
+	bridge.Get("/effects", a.handler((*handlers.RequestHandler).Effects))
+	bridge.Get("/federation", a.handler((*handlers.RequestHandler).Federation))
+	bridge.Get("/asset", a.handler((*handlers.RequestHandler).Asset))
+	bridge.Get("/fee-stats", a.handler((*handlers.RequestHandler).FeeStats))
+	bridge.Get("/stellar-toml", a.handler((*handlers.RequestHandler).StellarToml))
+	bridge.Get("/account/:address/spendable", a.handlerC((*handlers.RequestHandler).AccountSpendable))
+	bridge.Get("/health", a.handler((*handlers.RequestHandler).Health))
+
+	bridge.Get("/admin/received-payments", a.handler((*handlers.RequestHandler).AdminReceivedPayments))
+	bridge.Get("/admin/received-payments/:id", a.handlerC((*handlers.RequestHandler).AdminReceivedPayment))
+	bridge.Get("/admin/sent-transactions", a.handler((*handlers.RequestHandler).AdminSentTransactions))
+	bridge.Get("/admin/compliance-queue", a.handler((*handlers.RequestHandler).AdminComplianceQueue))
+	bridge.Get("/admin/rate-limits", a.handler((*handlers.RequestHandler).AdminRateLimits))
+	bridge.Get("/admin/maintenance", a.handler((*handlers.RequestHandler).AdminMaintenance))
+
+	// Backends scraped by the monitoring system (Prometheus) are served at /metrics
+	if handler, ok := a.requestHandler.Metrics.(http.Handler); ok {
+		bridge.Get("/metrics", handler)
+	}
 
-	bridge.Get("/admin/received-payments", a.requestHandler.AdminReceivedPayments)
-	bridge.Get("/admin/received-payments/:id", a.requestHandler.AdminReceivedPayment)
-	bridge.Get("/admin/sent-transactions", a.requestHandler.AdminSentTransactions)
+	if a.config.APIKey != "" {
+		bridge.Post("/keypair", a.handler((*handlers.RequestHandler).Keypair))
+		bridge.Post("/admin/probe", pausable(a.handler((*handlers.RequestHandler).AdminProbe)))
+		bridge.Post("/admin/maintenance", a.handler((*handlers.RequestHandler).AdminMaintenance))
+	} else {
+		log.Warning("api_key not provided. /keypair, /admin/probe and POST /admin/maintenance endpoints will not be available.")
+	}
 
 	if a.config.Develop {
 		// Create a proxy server to localhost:3000 where GUI development server lives.
@@ -0,0 +1,234 @@
+package config
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/mitchellh/mapstructure"
+	"github.com/spf13/pflag"
+	"github.com/spf13/viper"
+)
+
+// EnvPrefix is prepended to names of environment variables overriding config params
+const EnvPrefix = "BRIDGE_"
+
+// Defaults contains values of config params that are not set in any other source
+var Defaults = map[string]interface{}{
+	"timebounds.clock_skew":                 5,
+	"batch.federation_concurrency":          10,
+	"batch.max_operations":                  100,
+	"batch.source_concurrency":              5,
+	"compression.min_size":                  1024,
+	"compliance_queue.retry_interval":       30,
+	"json_key_case":                         "snake_case",
+	"compliance_check":                      "basic",
+	"compliance_max_response_size":          64 * 1024,
+	"network_passphrase_check":              "strict",
+	"unknown_params":                        "log",
+	"horizon_max_retry_wait":                5,
+	"api_version":                           "1",
+	"memo_required_cache_ttl":               300,
+	"stellar_toml_cache_ttl":                60,
+	"metrics.prefix":                        "bridge",
+	"submission.confirmation_timeout":       30,
+	"submission.confirmation_poll_interval": 1,
+	"submission.sequence_reservation_ttl":   60,
+	"federation.timeout":                    10,
+	"federation.dial_timeout":               5,
+	"federation.tls_handshake_timeout":      5,
+	"federation.response_header_timeout":    5,
+	"federation.max_response_size":          64 * 1024,
+	"federation.toml_retries":               2,
+	"federation.toml_retry_wait":            1,
+	"federation.toml_cache_ttl":             3600,
+	"spendable.fee_buffer":                  "0.01",
+	"events.subject":                        "bridge.payments",
+	"events.buffer_size":                    1000,
+	"events.overflow":                       "drop",
+	"fee_stats.confidence":                  "p90",
+	"fee_stats.cache_ttl":                   5,
+}
+
+// Param is a config param that can be overridden by environment variable and command line flag
+type Param struct {
+	// Key of the param in config file, ex. `accounts.base_seed`
+	Key string
+	// Name of environment variable, ex. `BRIDGE_ACCOUNTS_BASE_SEED`
+	Env string
+	// Name of command line flag, ex. `accounts-base-seed`
+	Flag string
+	kind reflect.Kind
+}
+
+// Params returns all config params that can be overridden by environment variables and flags.
+// Arrays of tables (`assets`, `auth_tokens`, `balance_floors`, `batch.max_amounts`, `compliance_rules`,
+// `memo_rules`, `rate_limits`) can be set in config file only.
+func Params() (params []Param) {
+	return appendParams(params, "", reflect.TypeOf(Config{}))
+}
+
+func appendParams(params []Param, prefix string, t reflect.Type) []Param {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		key := field.Tag.Get("mapstructure")
+		if key == "" {
+			key = strings.ToLower(field.Name)
+		}
+		key = prefix + key
+
+		fieldType := field.Type
+		if fieldType.Kind() == reflect.Ptr {
+			fieldType = fieldType.Elem()
+		}
+
+		switch fieldType.Kind() {
+		case reflect.Struct:
+			params = appendParams(params, key+".", fieldType)
+			continue
+		case reflect.Slice:
+			if fieldType.Elem().Kind() != reflect.String {
+				continue
+			}
+		case reflect.String, reflect.Int, reflect.Bool:
+		default:
+			continue
+		}
+
+		params = append(params, Param{
+			Key:  key,
+			Env:  EnvPrefix + strings.ToUpper(strings.Replace(key, ".", "_", -1)),
+			Flag: strings.Replace(strings.Replace(key, ".", "-", -1), "_", "-", -1),
+			kind: fieldType.Kind(),
+		})
+	}
+	return params
+}
+
+// RegisterFlags adds a command line flag for every param to flags
+func RegisterFlags(flags *pflag.FlagSet) {
+	for _, param := range Params() {
+		usage := fmt.Sprintf("overrides %s config param", param.Key)
+		switch param.kind {
+		case reflect.Int:
+			flags.Int(param.Flag, 0, usage)
+		case reflect.Bool:
+			flags.Bool(param.Flag, false, usage)
+		case reflect.Slice:
+			flags.String(param.Flag, "", usage+" (comma separated)")
+		default:
+			flags.String(param.Flag, "", usage)
+		}
+	}
+}
+
+// Load builds config from command line flags, environment variables, config file read by v
+// and Defaults, in this order of precedence. Flags are used only when set explicitly and
+// environment variables only when not empty. Merged config is validated.
+func Load(v *viper.Viper, flags *pflag.FlagSet, getenv func(string) string) (config Config, err error) {
+	settings := v.AllSettings()
+
+	for _, param := range Params() {
+		var value interface{}
+
+		flag := flags.Lookup(param.Flag)
+		if flag != nil && flag.Changed {
+			value, err = param.parse(flag.Value.String())
+			if err != nil {
+				err = fmt.Errorf("Invalid value of --%s flag: %s", param.Flag, err)
+				return
+			}
+		} else if env := getenv(param.Env); env != "" {
+			value, err = param.parse(env)
+			if err != nil {
+				err = fmt.Errorf("Invalid value of %s environment variable: %s", param.Env, err)
+				return
+			}
+		} else if _, exists := lookupSetting(settings, param.Key); exists {
+			continue
+		} else if value, exists = Defaults[param.Key]; !exists {
+			continue
+		}
+
+		setSetting(settings, param.Key, value)
+	}
+
+	err = mapstructure.WeakDecode(settings, &config)
+	if err != nil {
+		return
+	}
+
+	err = config.DeriveSeeds()
+	if err != nil {
+		return
+	}
+
+	err = config.Validate()
+	return
+}
+
+func (p Param) parse(value string) (interface{}, error) {
+	switch p.kind {
+	case reflect.Int:
+		return strconv.Atoi(value)
+	case reflect.Bool:
+		return strconv.ParseBool(value)
+	case reflect.Slice:
+		values := []string{}
+		for _, item := range strings.Split(value, ",") {
+			item = strings.TrimSpace(item)
+			if item != "" {
+				values = append(values, item)
+			}
+		}
+		return values, nil
+	default:
+		return value, nil
+	}
+}
+
+// lookupSetting returns value of a dotted key from nested settings maps
+func lookupSetting(settings map[string]interface{}, key string) (interface{}, bool) {
+	path := strings.Split(key, ".")
+	for _, name := range path[:len(path)-1] {
+		group, ok := toStringMap(settings[name])
+		if !ok {
+			return nil, false
+		}
+		settings = group
+	}
+
+	value, exists := settings[path[len(path)-1]]
+	return value, exists && value != nil
+}
+
+// setSetting sets value of a dotted key in nested settings maps, creating groups when needed
+func setSetting(settings map[string]interface{}, key string, value interface{}) {
+	path := strings.Split(key, ".")
+	for _, name := range path[:len(path)-1] {
+		group, ok := toStringMap(settings[name])
+		if !ok {
+			group = map[string]interface{}{}
+		}
+		settings[name] = group
+		settings = group
+	}
+
+	settings[path[len(path)-1]] = value
+}
+
+func toStringMap(value interface{}) (map[string]interface{}, bool) {
+	switch value := value.(type) {
+	case map[string]interface{}:
+		return value, true
+	case map[interface{}]interface{}:
+		converted := make(map[string]interface{}, len(value))
+		for k, v := range value {
+			converted[fmt.Sprint(k)] = v
+		}
+		return converted, true
+	default:
+		return nil, false
+	}
+}
@@ -0,0 +1,202 @@
+package config_test
+
+import (
+	"strings"
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+	"github.com/spf13/pflag"
+	"github.com/spf13/viper"
+	"github.com/stellar/gateway/bridge/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testConfigFile = `
+port = 8001
+horizon = "https://horizon-testnet.stellar.org"
+network_passphrase = "Test SDF Network ; September 2015"
+allowed_memo_types = ["id"]
+
+[accounts]
+issuing_account_id = "GD4I7AFSLZGTDL34TQLWJOM2NHLIIOEKD5RHHZUW54HERBLSIRKUOXRR"
+
+[federation]
+dial_timeout = 3
+
+[[assets]]
+code = "USD"
+issuer = "GD4I7AFSLZGTDL34TQLWJOM2NHLIIOEKD5RHHZUW54HERBLSIRKUOXRR"
+`
+
+func TestLoad(t *testing.T) {
+	Convey("Load", t, func() {
+		v := viper.New()
+		v.SetConfigType("toml")
+		require.NoError(t, v.ReadConfig(strings.NewReader(testConfigFile)))
+
+		flags := pflag.NewFlagSet("bridge", pflag.ContinueOnError)
+		config.RegisterFlags(flags)
+
+		env := map[string]string{}
+		getenv := func(name string) string { return env[name] }
+
+		Convey("reads config file and defaults", func() {
+			c, err := config.Load(v, flags, getenv)
+			require.NoError(t, err)
+			assert.Equal(t, 8001, *c.Port)
+			assert.Equal(t, []string{"id"}, c.AllowedMemoTypes)
+			assert.Equal(t, "GD4I7AFSLZGTDL34TQLWJOM2NHLIIOEKD5RHHZUW54HERBLSIRKUOXRR", c.Accounts.IssuingAccountID)
+			assert.Equal(t, 3, c.Federation.DialTimeout)
+			assert.Equal(t, 10, c.Federation.Timeout)
+			assert.Equal(t, 1, c.Submission.ConfirmationPollInterval)
+			assert.Equal(t, 60, c.Submission.SequenceReservationTTL)
+			assert.Equal(t, 60, c.StellarTomlCacheTTL)
+			assert.Equal(t, "log", c.UnknownParams)
+			assert.Equal(t, "snake_case", c.JSONKeyCase)
+			require.Len(t, c.Assets, 1)
+			assert.Equal(t, "USD", c.Assets[0].Code)
+		})
+
+		Convey("environment variables override config file", func() {
+			env["BRIDGE_PORT"] = "9000"
+			env["BRIDGE_FEDERATION_DIAL_TIMEOUT"] = "7"
+			env["BRIDGE_ACCOUNTS_BASE_SEED"] = "SDWLS4G3XCNIYPKXJWWGGJT6UDY63WV6PEFTWP7JZMQB4RE7EUJQN5XM"
+			env["BRIDGE_ALLOWED_MEMO_TYPES"] = "id, text"
+			env["BRIDGE_DEVELOP"] = "true"
+
+			c, err := config.Load(v, flags, getenv)
+			require.NoError(t, err)
+			assert.Equal(t, 9000, *c.Port)
+			assert.Equal(t, 7, c.Federation.DialTimeout)
+			assert.Equal(t, "SDWLS4G3XCNIYPKXJWWGGJT6UDY63WV6PEFTWP7JZMQB4RE7EUJQN5XM", c.Accounts.BaseSeed)
+			assert.Equal(t, "GD4I7AFSLZGTDL34TQLWJOM2NHLIIOEKD5RHHZUW54HERBLSIRKUOXRR", c.Accounts.IssuingAccountID)
+			assert.Equal(t, []string{"id", "text"}, c.AllowedMemoTypes)
+			assert.True(t, c.Develop)
+		})
+
+		Convey("flags override environment variables", func() {
+			env["BRIDGE_PORT"] = "9000"
+			env["BRIDGE_HORIZON"] = "https://horizon.stellar.org"
+			require.NoError(t, flags.Parse([]string{"--port", "9001", "--federation-timeout", "20"}))
+
+			c, err := config.Load(v, flags, getenv)
+			require.NoError(t, err)
+			assert.Equal(t, 9001, *c.Port)
+			assert.Equal(t, 20, c.Federation.Timeout)
+			assert.Equal(t, "https://horizon.stellar.org", c.Horizon)
+		})
+
+		Convey("config file is not required", func() {
+			env["BRIDGE_PORT"] = "9000"
+			env["BRIDGE_HORIZON"] = "https://horizon.stellar.org"
+			env["BRIDGE_NETWORK_PASSPHRASE"] = "Public Global Stellar Network ; September 2015"
+
+			c, err := config.Load(viper.New(), flags, getenv)
+			require.NoError(t, err)
+			assert.Equal(t, 9000, *c.Port)
+			assert.Equal(t, 10, c.Federation.Timeout)
+		})
+
+		Convey("seeds given as derivation paths are derived from mnemonic", func() {
+			env["BRIDGE_ACCOUNTS_MNEMONIC"] = "illness spike retreat truth genius clock brain pass fit cave bargain toe"
+			env["BRIDGE_ACCOUNTS_BASE_SEED"] = "m/44'/148'/0'"
+			env["BRIDGE_ACCOUNTS_AUTHORIZING_SEED"] = "m/44'/148'/1'"
+
+			c, err := config.Load(v, flags, getenv)
+			require.NoError(t, err)
+			assert.Equal(t, "SBGWSG6BTNCKCOB3DIFBGCVMUPQFYPA2G4O34RMTB343OYPXU5DJDVMN", c.Accounts.BaseSeed)
+			assert.Equal(t, "SCEPFFWGAG5P2VX5DHIYK3XEMZYLTYWIPWYEKXFHSK25RVMIUNJ7CTIS", c.Accounts.AuthorizingSeed)
+			assert.Equal(t, "REDACTED", c.LogFields()["accounts.mnemonic"])
+		})
+
+		Convey("derivation path requires mnemonic", func() {
+			env["BRIDGE_ACCOUNTS_BASE_SEED"] = "m/44'/148'/0'"
+			_, err := config.Load(v, flags, getenv)
+			assert.EqualError(t, err, "accounts.base_seed is a derivation path but accounts.mnemonic param is not set")
+		})
+
+		Convey("invalid derivation path is rejected", func() {
+			env["BRIDGE_ACCOUNTS_MNEMONIC"] = "illness spike retreat truth genius clock brain pass fit cave bargain toe"
+			env["BRIDGE_ACCOUNTS_BASE_SEED"] = "m/44/148/0"
+			_, err := config.Load(v, flags, getenv)
+			assert.EqualError(t, err, "accounts.base_seed is an invalid derivation path")
+		})
+
+		Convey("invalid mnemonic is rejected", func() {
+			env["BRIDGE_ACCOUNTS_MNEMONIC"] = "illness spike retreat truth genius clock brain pass fit cave bargain bargain"
+			_, err := config.Load(v, flags, getenv)
+			assert.EqualError(t, err, "accounts.mnemonic is invalid")
+		})
+
+		Convey("client IP header requires trusted proxies", func() {
+			env["BRIDGE_IP_RATE_LIMIT_RATE"] = "5"
+			env["BRIDGE_IP_RATE_LIMIT_BURST"] = "10"
+			env["BRIDGE_IP_RATE_LIMIT_CLIENT_IP_HEADER"] = "X-Forwarded-For"
+			_, err := config.Load(v, flags, getenv)
+			assert.EqualError(t, err, "ip_rate_limit.client_ip_header param requires ip_rate_limit.trusted_proxies param")
+
+			env["BRIDGE_IP_RATE_LIMIT_TRUSTED_PROXIES"] = "10.0.0.0/8,192.168.1.1"
+			c, err := config.Load(v, flags, getenv)
+			require.NoError(t, err)
+			assert.Equal(t, []string{"10.0.0.0/8", "192.168.1.1"}, c.IPRateLimit.TrustedProxies)
+		})
+
+		Convey("signing timestamps requires MAC key", func() {
+			env["BRIDGE_SIGN_TIMESTAMPS"] = "true"
+			_, err := config.Load(v, flags, getenv)
+			assert.EqualError(t, err, "sign_timestamps param requires mac_key param")
+
+			env["BRIDGE_MAC_KEY"] = "SABLR5HOI2IUOYB27TR4TO7HWDJIGSRJTT4UUTXXZOFVVPGQKJ5ME43J"
+			c, err := config.Load(v, flags, getenv)
+			require.NoError(t, err)
+			assert.True(t, c.SignTimestamps)
+		})
+
+		Convey("NATS broker requires valid URL", func() {
+			env["BRIDGE_EVENTS_BROKER"] = "nats"
+			_, err := config.Load(v, flags, getenv)
+			assert.EqualError(t, err, "events.nats_url param is required when events.broker is nats")
+
+			env["BRIDGE_EVENTS_NATS_URL"] = "localhost:4222"
+			_, err = config.Load(v, flags, getenv)
+			assert.EqualError(t, err, "events.nats_url param is invalid: NATS server URL must be in nats://host:port or tls://host:port format")
+
+			env["BRIDGE_EVENTS_NATS_URL"] = "nats://localhost:4222"
+			c, err := config.Load(v, flags, getenv)
+			require.NoError(t, err)
+			assert.Equal(t, "bridge.payments", c.Events.Subject)
+			assert.Equal(t, 1000, c.Events.BufferSize)
+			assert.Equal(t, "drop", c.Events.Overflow)
+
+			env["BRIDGE_EVENTS_NATS_URL"] = "tls://localhost:4222"
+			env["BRIDGE_EVENTS_NATS_CERT_FINGERPRINT"] = "abc"
+			_, err = config.Load(v, flags, getenv)
+			assert.EqualError(t, err, "events.nats_cert_fingerprint param is invalid")
+		})
+
+		Convey("fee stats confidence must be a known percentile", func() {
+			c, err := config.Load(v, flags, getenv)
+			require.NoError(t, err)
+			assert.Equal(t, "p90", c.FeeStats.Confidence)
+			assert.Equal(t, 5, c.FeeStats.CacheTTL)
+
+			env["BRIDGE_FEE_STATS_CONFIDENCE"] = "p75"
+			_, err = config.Load(v, flags, getenv)
+			assert.EqualError(t, err, "fee_stats.confidence param must be `p10`, `p50`, `p90` or `p99`")
+		})
+
+		Convey("invalid values are rejected", func() {
+			env["BRIDGE_PORT"] = "abc"
+			_, err := config.Load(v, flags, getenv)
+			assert.EqualError(t, err, `Invalid value of BRIDGE_PORT environment variable: strconv.Atoi: parsing "abc": invalid syntax`)
+		})
+
+		Convey("merged config is validated", func() {
+			env["BRIDGE_NETWORK_PASSPHRASE"] = ""
+			require.NoError(t, flags.Parse([]string{"--horizon", ""}))
+			_, err := config.Load(viper.New(), flags, getenv)
+			assert.EqualError(t, err, "port param is required")
+		})
+	})
+}
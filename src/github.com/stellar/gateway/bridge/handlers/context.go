@@ -0,0 +1,66 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/stellar/gateway/external"
+	"github.com/stellar/gateway/horizon"
+	"github.com/stellar/gateway/net"
+	"github.com/stellar/gateway/submitter"
+	"github.com/stellar/go/clients/federation"
+	"github.com/stellar/go/clients/stellartoml"
+)
+
+// WithContext returns a copy of the request handler sending requests to Horizon, federation and
+// compliance servers and stellar.toml files with ctx, the context of the client request, so they are
+// cancelled when it times out (see `request_timeout` config param) or the client disconnects.
+// Transactions are not submitted once ctx is done (see TransactionSubmitter.WithContext). Caches
+// (ex. memo required cache) still use the shared clients.
+func (rh *RequestHandler) WithContext(ctx context.Context) *RequestHandler {
+	handler := *rh
+
+	if h, ok := rh.Horizon.(*horizon.Horizon); ok {
+		handler.Horizon = h.WithContext(ctx)
+	}
+
+	if ts, ok := rh.TransactionSubmitter.(*submitter.TransactionSubmitter); ok {
+		handler.TransactionSubmitter = ts.WithContext(ctx)
+	}
+
+	if client, ok := rh.Client.(*http.Client); ok {
+		handler.Client = net.ContextClient(client, ctx)
+	}
+
+	if resolver, ok := rh.StellarTomlResolver.(*stellartoml.Client); ok {
+		handler.StellarTomlResolver = contextStellarTomlClient(resolver, ctx)
+	}
+
+	if resolver, ok := rh.FederationResolver.(*federation.Client); ok {
+		contextResolver := *resolver
+		if client, ok := resolver.HTTP.(*http.Client); ok {
+			contextResolver.HTTP = net.ContextClient(client, ctx)
+		}
+		switch tomlResolver := resolver.StellarTOML.(type) {
+		case *stellartoml.Client:
+			contextResolver.StellarTOML = contextStellarTomlClient(tomlResolver, ctx)
+		case *external.StellarTomlDiscovery:
+			contextResolver.StellarTOML = tomlResolver.WithContext(ctx)
+		}
+		if h, ok := resolver.Horizon.(*horizon.Horizon); ok {
+			contextResolver.Horizon = h.WithContext(ctx)
+		}
+		handler.FederationResolver = &contextResolver
+	}
+
+	return &handler
+}
+
+// contextStellarTomlClient returns a copy of stellar.toml client sending requests with ctx
+func contextStellarTomlClient(client *stellartoml.Client, ctx context.Context) *stellartoml.Client {
+	contextClient := *client
+	if httpClient, ok := client.HTTP.(*http.Client); ok {
+		contextClient.HTTP = net.ContextClient(httpClient, ctx)
+	}
+	return &contextClient
+}
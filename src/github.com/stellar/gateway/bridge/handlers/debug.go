@@ -0,0 +1,62 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stellar/gateway/horizon"
+	"github.com/stellar/gateway/net"
+	"github.com/stellar/go/clients/federation"
+	"github.com/stellar/go/clients/stellartoml"
+)
+
+// WithDebugLog returns a copy of the request handler logging requests to Horizon, federation and
+// compliance servers and stellar.toml files, with full bodies, to log. It's used for requests with
+// debug logging enabled (see server.DebugMiddleware). Transactions are still submitted by the shared
+// TransactionSubmitter which logs submitted envelopes itself.
+func (rh *RequestHandler) WithDebugLog(log *logrus.Entry) *RequestHandler {
+	debug := *rh
+
+	if h, ok := rh.Horizon.(*horizon.Horizon); ok {
+		debugHorizon := *h
+		debugHorizon.Transport = net.NewDebugTransport(h.Transport, log.WithField("service", "horizon"))
+		debug.Horizon = &debugHorizon
+	}
+
+	if client, ok := rh.Client.(*http.Client); ok {
+		debug.Client = debugHTTPClient(client, log)
+	}
+
+	if resolver, ok := rh.StellarTomlResolver.(*stellartoml.Client); ok {
+		debug.StellarTomlResolver = debugStellarTomlClient(resolver, log)
+	}
+
+	if resolver, ok := rh.FederationResolver.(*federation.Client); ok {
+		debugResolver := *resolver
+		if client, ok := resolver.HTTP.(*http.Client); ok {
+			debugResolver.HTTP = debugHTTPClient(client, log.WithField("service", "federation"))
+		}
+		if tomlResolver, ok := resolver.StellarTOML.(*stellartoml.Client); ok {
+			debugResolver.StellarTOML = debugStellarTomlClient(tomlResolver, log)
+		}
+		debug.FederationResolver = &debugResolver
+	}
+
+	return &debug
+}
+
+// debugHTTPClient returns a copy of client logging requests to log
+func debugHTTPClient(client *http.Client, log *logrus.Entry) *http.Client {
+	debugClient := *client
+	debugClient.Transport = net.NewDebugTransport(client.Transport, log)
+	return &debugClient
+}
+
+// debugStellarTomlClient returns a copy of stellar.toml client logging requests to log
+func debugStellarTomlClient(client *stellartoml.Client, log *logrus.Entry) *stellartoml.Client {
+	debugClient := *client
+	if httpClient, ok := client.HTTP.(*http.Client); ok {
+		debugClient.HTTP = debugHTTPClient(httpClient, log.WithField("service", "stellar_toml"))
+	}
+	return &debugClient
+}
@@ -0,0 +1,68 @@
+package handlers
+
+import (
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/stellar/gateway/horizon"
+	"github.com/stellar/gateway/protocols"
+	"github.com/stellar/gateway/submitter"
+)
+
+// HorizonURLHeader overrides Horizon server URL of a single request (see WithHorizonURL)
+const HorizonURLHeader = "X-Horizon-URL"
+
+// HorizonURLRequested returns Horizon server URL requested using `X-Horizon-URL` header or an
+// empty string when it's not sent
+func HorizonURLRequested(r *http.Request) string {
+	return r.Header.Get(HorizonURLHeader)
+}
+
+// WithHorizonURL returns a copy of the request handler sending requests to Horizon server at
+// serverURL, including submitted transactions. It's used by operators to test a specific Horizon
+// instance without redeploying. To prevent the bridge from sending requests to arbitrary hosts
+// serverURL must be one of `horizon_override_urls`, which can be set only together with `api_key`.
+// Transactions are submitted by a separate TransactionSubmitter loading sequence numbers from
+// serverURL. Caches (ex. memo required cache) still use the configured Horizon.
+func (rh *RequestHandler) WithHorizonURL(serverURL string) (*RequestHandler, *protocols.ErrorResponse) {
+	if !rh.isHorizonOverrideAllowed(serverURL) {
+		return nil, protocols.NewInvalidParameterError(HorizonURLHeader, serverURL, "Horizon URL is not one of horizon_override_urls.")
+	}
+
+	h, ok := rh.Horizon.(*horizon.Horizon)
+	if !ok {
+		return nil, protocols.NewInternalServerError("Horizon cannot be overridden", nil)
+	}
+
+	override := *rh
+	overrideHorizon := *h
+	overrideHorizon.ServerURL = strings.TrimRight(serverURL, "/")
+	override.Horizon = &overrideHorizon
+
+	if ts, ok := rh.TransactionSubmitter.(*submitter.TransactionSubmitter); ok {
+		override.TransactionSubmitter = ts.WithHorizon(&overrideHorizon)
+	}
+
+	return &override, nil
+}
+
+// isHorizonOverrideAllowed checks if serverURL is a valid http(s) URL listed in `horizon_override_urls`.
+// Trailing slashes are ignored.
+func (rh *RequestHandler) isHorizonOverrideAllowed(serverURL string) bool {
+	if rh.Config.APIKey == "" {
+		return false
+	}
+
+	u, err := url.Parse(serverURL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return false
+	}
+
+	for _, allowed := range rh.Config.HorizonOverrideURLs {
+		if strings.TrimRight(allowed, "/") == strings.TrimRight(serverURL, "/") {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,67 @@
+package handlers
+
+import (
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+	"github.com/stellar/gateway/bridge/config"
+	"github.com/stellar/gateway/horizon"
+	"github.com/stellar/gateway/submitter"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRequestHandlerWithHorizonURL(t *testing.T) {
+	c := &config.Config{
+		APIKey:              "api-key-for-tests",
+		HorizonOverrideURLs: []string{"https://horizon-canary.example.com/"},
+	}
+	h := horizon.New("https://horizon.example.com")
+	ts := submitter.NewTransactionSubmitter(&h, nil, "Test SDF Network ; September 2015", nil)
+	requestHandler := RequestHandler{Config: c, Horizon: &h, TransactionSubmitter: &ts}
+
+	Convey("WithHorizonURL", t, func() {
+		Reset(func() {
+			c.APIKey = "api-key-for-tests"
+		})
+
+		Convey("returns a copy using the allowed Horizon", func() {
+			rh, errorResponse := requestHandler.WithHorizonURL("https://horizon-canary.example.com")
+			require.Nil(t, errorResponse)
+
+			overrideHorizon := rh.Horizon.(*horizon.Horizon)
+			assert.Equal(t, "https://horizon-canary.example.com", overrideHorizon.ServerURL)
+			overrideSubmitter := rh.TransactionSubmitter.(*submitter.TransactionSubmitter)
+			assert.Equal(t, overrideHorizon, overrideSubmitter.Horizon)
+			assert.Equal(t, overrideHorizon, overrideSubmitter.SubmissionService)
+			assert.Equal(t, ts.Network, overrideSubmitter.Network)
+
+			assert.Equal(t, "https://horizon.example.com", h.ServerURL)
+			assert.Equal(t, &h, requestHandler.Horizon)
+			assert.Equal(t, &ts, requestHandler.TransactionSubmitter)
+		})
+
+		Convey("rejects Horizon that is not allowed", func() {
+			for _, serverURL := range []string{
+				"https://horizon-canary.example.com.evil.com",
+				"http://169.254.169.254/latest/meta-data",
+				"ftp://horizon-canary.example.com",
+				"horizon-canary.example.com",
+			} {
+				rh, errorResponse := requestHandler.WithHorizonURL(serverURL)
+				assert.Nil(t, rh)
+				require.NotNil(t, errorResponse, serverURL)
+				assert.Equal(t, "invalid_parameter", errorResponse.Code)
+				assert.Equal(t, "X-Horizon-URL", errorResponse.Data["name"])
+			}
+		})
+
+		Convey("rejects any Horizon when api_key is not set", func() {
+			c.APIKey = ""
+			rh, errorResponse := requestHandler.WithHorizonURL("https://horizon-canary.example.com")
+			assert.Nil(t, rh)
+			require.NotNil(t, errorResponse)
+			assert.Equal(t, "invalid_parameter", errorResponse.Code)
+		})
+	})
+}
@@ -1,14 +1,34 @@
 package handlers
 
 import (
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	log "github.com/sirupsen/logrus"
 	"github.com/stellar/gateway/bridge/config"
 	"github.com/stellar/gateway/db"
+	"github.com/stellar/gateway/events"
 	"github.com/stellar/gateway/external"
 	"github.com/stellar/gateway/horizon"
 	"github.com/stellar/gateway/listener"
+	"github.com/stellar/gateway/metrics"
 	"github.com/stellar/gateway/net"
+	"github.com/stellar/gateway/protocols"
+	"github.com/stellar/gateway/protocols/bridge"
+	"github.com/stellar/gateway/ratelimit"
+	"github.com/stellar/gateway/server"
 	"github.com/stellar/gateway/submitter"
+	"github.com/stellar/go/address"
+	"github.com/stellar/go/amount"
 	"github.com/stellar/go/clients/federation"
+	"github.com/stellar/go/keypair"
+	"github.com/stellar/go/xdr"
 )
 
 // RequestHandler implements bridge server request handlers
@@ -18,10 +38,36 @@ type RequestHandler struct {
 	Horizon              horizon.HorizonInterface                `inject:""`
 	Driver               db.Driver                               `inject:""`
 	Repository           db.RepositoryInterface                  `inject:""`
+	EntityManager        db.EntityManagerInterface               `inject:""`
 	StellarTomlResolver  external.StellarTomlClientInterface     `inject:""`
+	StellarTomlChecker   *external.StellarTomlChecker            `inject:""`
 	FederationResolver   federation.ClientInterface              `inject:""`
 	TransactionSubmitter submitter.TransactionSubmitterInterface `inject:""`
 	PaymentListener      *listener.PaymentListener               `inject:""`
+	RateLimiter          *ratelimit.AssetRateLimiter             `inject:""`
+	MemoRequiredCache    *horizon.MemoRequiredCache              `inject:""`
+	FeeStatsCache        *horizon.FeeStatsCache                  `inject:""`
+	Metrics              metrics.Metrics                         `inject:""`
+	Events               events.Publisher                        `inject:""`
+	Maintenance          *server.Maintenance                     `inject:""`
+	// When true raw Horizon error documents are included in error responses (see WithRawErrors)
+	includeRawErrors bool
+}
+
+// RawErrorsRequested checks if raw Horizon error documents were requested by the client
+// using `include_raw_error=true` param
+func RawErrorsRequested(r *http.Request) bool {
+	return r.FormValue("include_raw_error") == "true"
+}
+
+// WithRawErrors returns a copy of the request handler including raw Horizon error documents
+// (problem+json) in `horizon_error` field of error responses of failed requests to Horizon.
+// It's used for requests sent with `include_raw_error=true` param only as the documents can
+// contain internal details.
+func (rh *RequestHandler) WithRawErrors() *RequestHandler {
+	handler := *rh
+	handler.includeRawErrors = true
+	return &handler
 }
 
 func (rh *RequestHandler) isAssetAllowed(code string, issuer string) bool {
@@ -32,3 +78,575 @@ func (rh *RequestHandler) isAssetAllowed(code string, issuer string) bool {
 	}
 	return false
 }
+
+// checkRateLimit takes tokens of `rate_limits` buckets for the given number of payments of each asset.
+// It returns PaymentRateLimited error when any of the assets exceeded its limit.
+func (rh *RequestHandler) checkRateLimit(payments map[ratelimit.Asset]int) *protocols.ErrorResponse {
+	if rh.RateLimiter == nil {
+		return nil
+	}
+
+	asset := rh.RateLimiter.Allow(payments)
+	if asset == nil {
+		return nil
+	}
+
+	return bridge.NewPaymentRateLimitedError(asset.Code, asset.Issuer)
+}
+
+// checkDestinationAuthorization checks if the destination trusts the asset and, when the issuer has
+// `auth_required` flag set, if the trustline is authorized (see `check_authorization` config param).
+// Nothing is checked when accounts cannot be loaded, submission will fail with the right error then.
+func (rh *RequestHandler) checkDestinationAuthorization(destination, code, issuer string) *protocols.ErrorResponse {
+	if !rh.Config.CheckAuthorization || code == "" || destination == issuer {
+		return nil
+	}
+
+	issuerAccount, err := rh.Horizon.LoadAccount(issuer)
+	if err != nil {
+		log.WithFields(log.Fields{"issuer": issuer, "err": err}).Print("Cannot load issuer account, skipping authorization check")
+		return nil
+	}
+
+	if !issuerAccount.Flags.AuthRequired {
+		return nil
+	}
+
+	destinationAccount, err := rh.Horizon.LoadAccount(destination)
+	if err != nil {
+		log.WithFields(log.Fields{"destination": destination, "err": err}).Print("Cannot load destination account, skipping authorization check")
+		return nil
+	}
+
+	balance := destinationAccount.Balance(code, issuer)
+	if balance == nil {
+		return bridge.PaymentNoTrust
+	}
+
+	if balance.IsAuthorized != nil && !*balance.IsAuthorized {
+		return bridge.PaymentDestinationNotAuthorized
+	}
+	return nil
+}
+
+// checkSigner checks if the signer's weight is enough to send payments from the source account.
+// Nothing is checked when the source account cannot be loaded, submission will fail with the right error then.
+func (rh *RequestHandler) checkSigner(source, signer string) *protocols.ErrorResponse {
+	sourceAccount, err := rh.Horizon.LoadAccount(source)
+	if err != nil {
+		log.WithFields(log.Fields{"source": source, "err": err}).Print("Cannot load source account, skipping signer check")
+		return nil
+	}
+
+	kp := keypair.MustParse(signer)
+	weight := sourceAccount.SignerWeight(kp.Address())
+	if weight == 0 || weight < sourceAccount.Thresholds.Medium {
+		// Never log or return the secret
+		return protocols.NewInvalidParameterError("signer", "", "Signer weight is not enough to send payments from the source account.", map[string]interface{}{"signer": kp.Address()})
+	}
+	return nil
+}
+
+// writeHorizonError writes HorizonRateLimitedError, passing `Retry-After` from Horizon to the client,
+// when err is caused by Horizon rate limiting the bridge and InternalServerError otherwise. The raw
+// Horizon error document is added to the latter when requested (see WithRawErrors).
+func (rh *RequestHandler) writeHorizonError(w http.ResponseWriter, err error) {
+	var rateLimited *horizon.RateLimitedError
+	if errors.As(err, &rateLimited) {
+		retryAfter := int(math.Ceil(rateLimited.RetryAfter.Seconds()))
+		w.Header().Set("Retry-After", strconv.Itoa(retryAfter))
+		server.Write(w, protocols.NewHorizonRateLimitedError(retryAfter))
+		return
+	}
+
+	var horizonError *horizon.Error
+	if rh.includeRawErrors && errors.As(err, &horizonError) {
+		server.Write(w, protocols.InternalServerError.WithHorizonError(horizonError.Problem))
+		return
+	}
+
+	server.Write(w, protocols.InternalServerError)
+}
+
+// errorFromHorizonResponse works like bridge.ErrorFromHorizonResponse but adds the current base fee
+// of the network to TransactionInsufficientFee error so clients can retry with a higher fee
+func (rh *RequestHandler) errorFromHorizonResponse(response horizon.SubmitTransactionResponse) *protocols.ErrorResponse {
+	errorResponse := bridge.ErrorFromHorizonResponse(response)
+	if errorResponse == bridge.TransactionInsufficientFee {
+		ledger, err := rh.Horizon.LoadLatestLedger()
+		if err != nil {
+			log.WithFields(log.Fields{"err": err}).Print("Cannot load base fee of the latest ledger")
+		} else {
+			errorResponse = bridge.NewTransactionInsufficientFeeError(int64(ledger.BaseFeeInStroops))
+		}
+	}
+
+	if errorResponse != nil && rh.includeRawErrors {
+		errorResponse = errorResponse.WithHorizonError(response.RawError)
+	}
+	return errorResponse
+}
+
+// confirmTransaction polls Horizon for a transaction with unknown result (ex. when Horizon timed out
+// waiting for the ledger) until it's found in a ledger or `submission.confirmation_timeout` elapses.
+// Responses of transactions already in a ledger or failed are returned unchanged.
+func (rh *RequestHandler) confirmTransaction(response horizon.SubmitTransactionResponse) (horizon.SubmitTransactionResponse, *protocols.ErrorResponse) {
+	if response.Ledger != nil || response.Extras != nil {
+		return response, nil
+	}
+
+	interval := time.Duration(rh.Config.Submission.ConfirmationPollInterval) * time.Second
+	deadline := time.Now().Add(time.Duration(rh.Config.Submission.ConfirmationTimeout) * time.Second)
+
+	for {
+		transaction, err := rh.Horizon.LoadTransaction(response.Hash)
+		if err != nil {
+			log.WithFields(log.Fields{"hash": response.Hash, "err": err}).Warn("Error loading transaction")
+		} else if transaction != nil {
+			ledger := transaction.Ledger
+			return horizon.SubmitTransactionResponse{
+				Hash:           transaction.Hash,
+				Ledger:         &ledger,
+				ResultXdr:      &transaction.ResultXdr,
+				ResultMetaXdr:  &transaction.ResultMetaXdr,
+				SourceSequence: response.SourceSequence,
+			}, nil
+		}
+
+		if time.Now().Add(interval).After(deadline) {
+			return response, bridge.NewTransactionNotConfirmedError(response.Hash)
+		}
+		time.Sleep(interval)
+	}
+}
+
+// asyncSubmission checks if transactions of a request with the given `submission_mode` param are
+// submitted to Horizon async submission endpoint. `submission.mode` config param is used when empty.
+func (rh *RequestHandler) asyncSubmission(mode string) bool {
+	if mode == "" {
+		mode = rh.Config.Submission.Mode
+	}
+	return mode == bridge.SubmissionModeAsync
+}
+
+// observePayment records status and duration of a /payment request in the metrics backend
+func (rh *RequestHandler) observePayment(status int, duration time.Duration) {
+	if rh.Metrics == nil {
+		return
+	}
+
+	tags := metrics.Tags{"status": strconv.Itoa(status)}
+	rh.Metrics.IncCounter("payments", tags)
+	rh.Metrics.ObserveDuration("payment_duration", duration, tags)
+}
+
+// publishPaymentEvent publishes the event of a /payment request: confirmed when the transaction is in
+// a ledger, submitted when it's not in a ledger yet and failed for error responses. Successful responses
+// without a transaction hash (ex. queued compliance payments) are not published. Transactions accepted
+// by async submission are published as submitted even when the request waited for confirmation, their
+// follow-up event is published by the submitter (see submitter.AsyncWatcher). Params of the payment are
+// taken from the parsed request (ex. read from `uri`) and destination is its account ID once resolved.
+func (rh *RequestHandler) publishPaymentEvent(r *http.Request, recorder *statusRecorder) {
+	if rh.Events == nil {
+		return
+	}
+
+	event := events.PaymentEvent{
+		RequestID:   server.RequestID(r),
+		PaymentID:   r.PostFormValue("id"),
+		Destination: r.PostFormValue("destination"),
+		Amount:      r.PostFormValue("amount"),
+		AssetCode:   r.PostFormValue("asset_code"),
+		AssetIssuer: r.PostFormValue("asset_issuer"),
+		Hash:        recorder.hash,
+		Time:        time.Now().UTC(),
+	}
+
+	if request := recorder.request; request != nil {
+		event.PaymentID = request.ID
+		event.Destination = request.Destination
+		if request.DestinationAccountID != "" {
+			event.Destination = request.DestinationAccountID
+		}
+		event.Amount = request.Amount
+		event.AssetCode = request.AssetCode
+		event.AssetIssuer = request.AssetIssuer
+	}
+
+	switch {
+	case recorder.status >= http.StatusBadRequest:
+		event.Type = events.PaymentFailed
+		event.Error = recorder.code
+	case recorder.hash != "" && recorder.ledger != nil && !recorder.submittedAsync:
+		event.Type = events.PaymentConfirmed
+		event.Ledger = recorder.ledger
+	case recorder.hash != "":
+		event.Type = events.PaymentSubmitted
+	default:
+		return
+	}
+
+	rh.Events.PublishPayment(event)
+}
+
+// memoErrors are codes of errors returned when the memo of a payment is rejected. Invalid and missing
+// parameter errors are memo errors when returned for `memo` or `memo_type` param.
+var memoErrors = map[string]bool{
+	bridge.PaymentCannotUseMemo.Code:      true,
+	bridge.PaymentMemoNotAllowed.Code:     true,
+	bridge.PaymentMemoRequired.Code:       true,
+	bridge.PaymentMemoTypeNotAllowed.Code: true,
+	bridge.PaymentMemoTypeForbidden.Code:  true,
+}
+
+// observePaymentMemo counts /payment requests by memo type (`none` when empty) and result (`success`
+// or `rejected`) and, when the memo has been rejected, memo errors by memo type and error code. memoType
+// is the type of the memo attached to the transaction or `memo_type` param when it's not known yet.
+func (rh *RequestHandler) observePaymentMemo(memoType string, recorder *statusRecorder) {
+	if rh.Metrics == nil {
+		return
+	}
+
+	switch memoType {
+	case "":
+		memoType = "none"
+	case "id", "text", "hash", "return":
+	default:
+		// Keeps the number of series bounded
+		memoType = "invalid"
+	}
+
+	result := "success"
+	if recorder.status >= http.StatusBadRequest {
+		result = "rejected"
+	}
+	rh.Metrics.IncCounter("payment_memos", metrics.Tags{"memo_type": memoType, "result": result})
+
+	memoParam := recorder.param == "memo" || recorder.param == "memo_type"
+	switch {
+	case memoErrors[recorder.code]:
+	case memoParam && (recorder.code == protocols.InvalidParameterError.Code || recorder.code == protocols.MissingParameterError.Code):
+	default:
+		return
+	}
+	rh.Metrics.IncCounter("payment_memo_errors", metrics.Tags{"memo_type": memoType, "code": recorder.code})
+}
+
+// statusRecorder remembers status of the response written by a handler, the hash and the ledger of
+// the transaction and, for error responses, the error code and the name of the param the error is about
+type statusRecorder struct {
+	http.ResponseWriter
+	status int
+	code   string
+	param  string
+	hash   string
+	ledger *uint64
+	parsed bool
+	// Set when the transaction has been accepted by async submission
+	submittedAsync bool
+	// Type of the memo attached to the transaction (empty for none), nil until it's known
+	memoType *string
+	// Parsed /payment request, nil when params are invalid
+	request *bridge.PaymentRequest
+}
+
+// recordMemoType saves the type of the memo attached to the payment once it's known (ex. returned by
+// federation), see observePaymentMemo
+func recordMemoType(w http.ResponseWriter, memoType string) {
+	if recorder, ok := w.(*statusRecorder); ok {
+		recorder.memoType = &memoType
+	}
+}
+
+func (r *statusRecorder) WriteHeader(status int) {
+	r.status = status
+	r.ResponseWriter.WriteHeader(status)
+}
+
+func (r *statusRecorder) Write(data []byte) (int, error) {
+	if !r.parsed {
+		r.parsed = true
+		var response struct {
+			Code   string  `json:"code"`
+			Hash   string  `json:"hash"`
+			Ledger *uint64 `json:"ledger"`
+			// Memo of successful payments, ex. attached by compliance server
+			MemoType *string `json:"memo_type"`
+			Data     struct {
+				Name string `json:"name"`
+				// Hash of transaction_not_confirmed errors
+				Hash string `json:"hash"`
+			} `json:"data"`
+		}
+		if json.Unmarshal(data, &response) == nil {
+			r.code = response.Code
+			r.param = response.Data.Name
+			r.hash = response.Hash
+			if r.hash == "" {
+				r.hash = response.Data.Hash
+			}
+			r.ledger = response.Ledger
+			if response.MemoType != nil {
+				r.memoType = response.MemoType
+			}
+		}
+	}
+	return r.ResponseWriter.Write(data)
+}
+
+// complianceRequired checks if payment of a given asset and amount matches any of `compliance_rules`
+func (rh *RequestHandler) complianceRequired(code, issuer, paymentAmount string) bool {
+	value, err := amount.Parse(paymentAmount)
+	if err != nil {
+		return false
+	}
+
+	for _, rule := range rh.Config.ComplianceRules {
+		if rule.AssetCode != code || rule.AssetIssuer != issuer {
+			continue
+		}
+
+		minAmount, err := amount.Parse(rule.MinAmount)
+		if err == nil && value >= minAmount {
+			return true
+		}
+	}
+	return false
+}
+
+// assetSeed returns a seed configured for the given asset or an empty string if there is none
+func (rh *RequestHandler) assetSeed(code string, issuer string) string {
+	for _, asset := range rh.Config.Assets {
+		if asset.Code == code && asset.Issuer == issuer {
+			return asset.Seed
+		}
+	}
+	return ""
+}
+
+// checkUnknownParams logs or rejects, depending on `unknown_params` config param, a payment request
+// with params that are not recognized (ex. misspelled `destnation`), so integration mistakes don't
+// pass unnoticed
+func (rh *RequestHandler) checkUnknownParams(unknown []string) *protocols.ErrorResponse {
+	if len(unknown) == 0 || rh.Config.UnknownParams == "ignore" {
+		return nil
+	}
+
+	log.WithFields(log.Fields{"params": unknown}).Warn("Request contains unknown params")
+	if rh.Config.UnknownParams == "reject" {
+		return protocols.NewInvalidParameterError(unknown[0], "", "Unknown parameter. Check spelling of the parameter name.")
+	}
+	return nil
+}
+
+// checkAssetsAllowed returns PaymentAssetNotAllowed error for the first of assets not allowed by
+// `asset_allow_list` config group
+func (rh *RequestHandler) checkAssetsAllowed(assets ...protocols.Asset) *protocols.ErrorResponse {
+	for _, asset := range assets {
+		if !rh.Config.AssetAllowList.Allows(asset.Code, asset.Issuer) {
+			return bridge.NewPaymentAssetNotAllowedError(asset.Code, asset.Issuer)
+		}
+	}
+	return nil
+}
+
+// checkIssuance returns PaymentNotIssuance error when `issuance_only` config param is set and the
+// credit asset sent by source (seed or account ID) is not issued by it. `name` is the param of the
+// asset issuer returned in the error.
+func (rh *RequestHandler) checkIssuance(name, source string, asset protocols.Asset) *protocols.ErrorResponse {
+	if !rh.Config.IssuanceOnly || asset.Issuer == "" {
+		return nil
+	}
+
+	kp, err := keypair.Parse(source)
+	if err != nil {
+		// Invalid sources are rejected later
+		return nil
+	}
+
+	if asset.Issuer != kp.Address() {
+		return bridge.NewPaymentNotIssuanceError(name, asset.Code, asset.Issuer, kp.Address())
+	}
+	return nil
+}
+
+// checkAssetDecimals returns InvalidParameterError when value has more decimals than allowed for
+// the asset by its `display_decimals` config param. Empty code matches XLM asset configured with
+// `XLM` code. value must be validated before.
+func (rh *RequestHandler) checkAssetDecimals(name, code, issuer, value string) *protocols.ErrorResponse {
+	decimals := rh.assetDisplayDecimals(code, issuer)
+	if decimals == nil {
+		return nil
+	}
+
+	parsed, err := amount.Parse(value)
+	if err != nil {
+		return nil
+	}
+
+	// Amounts are integers of stroops (7 decimals)
+	unit := int64(math.Pow10(7 - *decimals))
+	if int64(parsed)%unit != 0 {
+		return protocols.NewInvalidParameterError(name, value, "Amount has more than "+strconv.Itoa(*decimals)+" decimals allowed for the asset.")
+	}
+	return nil
+}
+
+// assetDisplayDecimals returns `display_decimals` of the asset or nil when it's not set. Empty code
+// matches XLM asset configured with `XLM` code.
+func (rh *RequestHandler) assetDisplayDecimals(code, issuer string) *int {
+	if code == "" {
+		code = "XLM"
+	}
+
+	for _, asset := range rh.Config.Assets {
+		if asset.Code == code && asset.Issuer == issuer {
+			return asset.DisplayDecimals
+		}
+	}
+	return nil
+}
+
+// formatAssetAmount formats value in stroops as a decimal string with `display_decimals` of the asset.
+// Only trailing zeros are removed so the value is never rounded (ex. fee of `100` stroops is formatted
+// as `0.00001` even for XLM with 2 decimals). All 7 decimals are kept when `display_decimals` is not set.
+func (rh *RequestHandler) formatAssetAmount(code, issuer string, value xdr.Int64) string {
+	formatted := amount.String(value)
+
+	decimals := rh.assetDisplayDecimals(code, issuer)
+	if decimals == nil {
+		return formatted
+	}
+
+	// amount.String always returns 7 decimals
+	for trimmed := 0; trimmed < 7-*decimals && strings.HasSuffix(formatted, "0"); trimmed++ {
+		formatted = formatted[:len(formatted)-1]
+	}
+	return strings.TrimSuffix(formatted, ".")
+}
+
+// seedFromAuthorization returns a seed assigned to the bearer token sent in `Authorization` header
+func (rh *RequestHandler) seedFromAuthorization(r *http.Request) (string, bool) {
+	header := r.Header.Get("Authorization")
+	if !strings.HasPrefix(header, "Bearer ") {
+		return "", false
+	}
+
+	token := []byte(strings.TrimPrefix(header, "Bearer "))
+	for _, authToken := range rh.Config.AuthTokens {
+		if subtle.ConstantTimeCompare(token, []byte(authToken.Token)) == 1 {
+			return authToken.Seed, true
+		}
+	}
+	return "", false
+}
+
+// requestExpired checks if deadline of the request (see `request_timeout` config param) has passed.
+// No transactions are submitted then as the client will not get their results.
+func requestExpired(r *http.Request) bool {
+	return r != nil && r.Context().Err() != nil
+}
+
+// takeRetry checks if a failed call made while handling the request can be retried: its deadline has
+// not passed and its retry budget (see `retry_budget` config param) is not used up. It uses one retry
+// of the budget then.
+func takeRetry(r *http.Request) bool {
+	return r == nil || net.TakeRetry(r.Context(), 0)
+}
+
+// configuredSeed returns a seed of the given account found in the config (`accounts.base_seed`,
+// `assets` or `auth_tokens`) or an empty string if there is none
+func (rh *RequestHandler) configuredSeed(accountID string) string {
+	seeds := []string{rh.Config.Accounts.BaseSeed}
+	for _, asset := range rh.Config.Assets {
+		seeds = append(seeds, asset.Seed)
+	}
+	for _, authToken := range rh.Config.AuthTokens {
+		seeds = append(seeds, authToken.Seed)
+	}
+
+	for _, seed := range seeds {
+		if seed == "" {
+			continue
+		}
+		kp, err := keypair.Parse(seed)
+		if err == nil && kp.Address() == accountID {
+			return seed
+		}
+	}
+	return ""
+}
+
+// destinationDomain returns domain of a federated address or an empty string for account IDs
+func destinationDomain(destination string) string {
+	_, domain, err := address.Split(destination)
+	if err != nil {
+		return ""
+	}
+	return domain
+}
+
+// allowedMemoType returns true if payments with a given memo type can be sent (see `allowed_memo_types`)
+func (rh *RequestHandler) allowedMemoType(memoType string) bool {
+	if memoType == "" || len(rh.Config.AllowedMemoTypes) == 0 {
+		return true
+	}
+
+	for _, t := range rh.Config.AllowedMemoTypes {
+		if t == memoType {
+			return true
+		}
+	}
+	return false
+}
+
+// checkMemoType checks memo type of a payment against the first of `memo_rules` matching destination
+// account ID or federation domain. memoType is empty for payments without a memo.
+func (rh *RequestHandler) checkMemoType(destination, domain, accountID, memoType string) *protocols.ErrorResponse {
+	for _, rule := range rh.Config.MemoRules {
+		if (rule.AccountID == "" || rule.AccountID != accountID) && (rule.Domain == "" || rule.Domain != domain) {
+			continue
+		}
+
+		allowedType := memoType
+		if allowedType == "" {
+			allowedType = "none"
+		}
+
+		for _, t := range rule.MemoTypes {
+			if t == allowedType {
+				return nil
+			}
+		}
+
+		if memoType == "" {
+			return bridge.NewPaymentMemoRequiredError(destination, rule.MemoTypes)
+		}
+		return bridge.NewPaymentMemoTypeNotAllowedError(destination, rule.MemoTypes)
+	}
+	return nil
+}
+
+// checkMemoRequired rejects payments without a memo to accounts requiring one (see `check_memo_required`
+// config param). Nothing is checked when the account cannot be loaded.
+func (rh *RequestHandler) checkMemoRequired(destination, accountID, memoType string) *protocols.ErrorResponse {
+	if !rh.Config.CheckMemoRequired || memoType != "" || rh.MemoRequiredCache == nil {
+		return nil
+	}
+
+	required, err := rh.MemoRequiredCache.MemoRequired(accountID)
+	if err != nil {
+		log.WithFields(log.Fields{"account_id": accountID, "err": err}).Warn("Cannot check if account requires memo")
+		return nil
+	}
+
+	if !required {
+		return nil
+	}
+
+	memoTypes := rh.Config.AllowedMemoTypes
+	if len(memoTypes) == 0 {
+		memoTypes = []string{"id", "text", "hash"}
+	}
+	return bridge.NewPaymentMemoRequiredError(destination, memoTypes)
+}
@@ -12,6 +12,7 @@ import (
 	"github.com/stellar/gateway/horizon"
 	"github.com/stellar/gateway/protocols"
 	callback "github.com/stellar/gateway/protocols/compliance"
+	"github.com/stellar/gateway/ratelimit"
 	"github.com/stellar/gateway/server"
 	"github.com/stellar/go/protocols/compliance"
 	"github.com/stellar/go/support/errors"
@@ -154,3 +155,40 @@ func (rh *RequestHandler) AdminSentTransactions(w http.ResponseWriter, r *http.R
 		return
 	}
 }
+
+// AdminComplianceQueue implements /admin/compliance-queue endpoint
+func (rh *RequestHandler) AdminComplianceQueue(w http.ResponseWriter, r *http.Request) {
+	page, _ := strconv.Atoi(r.URL.Query().Get("page"))
+	limit := 10
+
+	payments, err := rh.Repository.GetQueuedPayments(page, limit)
+	if err != nil {
+		log.WithFields(log.Fields{"err": err}).Error("Error loading QueuedPayments")
+		server.Write(w, protocols.InternalServerError)
+		return
+	}
+
+	encoder := json.NewEncoder(w)
+	err = encoder.Encode(payments)
+	if err != nil {
+		log.WithFields(log.Fields{"err": err, "payments": payments}).Error("Error encoding QueuedPayments")
+		server.Write(w, protocols.InternalServerError)
+		return
+	}
+}
+
+// AdminRateLimits implements /admin/rate-limits endpoint
+func (rh *RequestHandler) AdminRateLimits(w http.ResponseWriter, r *http.Request) {
+	stats := []ratelimit.Stats{}
+	if rh.RateLimiter != nil {
+		stats = rh.RateLimiter.Stats()
+	}
+
+	encoder := json.NewEncoder(w)
+	err := encoder.Encode(stats)
+	if err != nil {
+		log.WithFields(log.Fields{"err": err}).Error("Error encoding rate limits stats")
+		server.Write(w, protocols.InternalServerError)
+		return
+	}
+}
@@ -0,0 +1,44 @@
+package handlers
+
+import (
+	"net/http"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/stellar/gateway/protocols"
+	"github.com/stellar/gateway/protocols/bridge"
+	"github.com/stellar/gateway/server"
+)
+
+// Asset implements /asset endpoint. It returns the ID of the Stellar Asset Contract of the asset
+// on the configured network so clients bridging to Soroban don't need to derive it.
+func (rh *RequestHandler) Asset(w http.ResponseWriter, r *http.Request) {
+	request := &bridge.AssetRequest{}
+	err := request.FromRequest(r)
+	if err != nil {
+		log.Error(err.Error())
+		server.Write(w, protocols.InvalidParameterError)
+		return
+	}
+
+	err = request.Validate()
+	if err != nil {
+		errorResponse := err.(*protocols.ErrorResponse)
+		log.WithFields(errorResponse.LogData).Error(errorResponse.Error())
+		server.Write(w, errorResponse)
+		return
+	}
+
+	asset := protocols.Asset{Code: request.AssetCode, Issuer: request.AssetIssuer}
+	contractID, err := asset.ContractID(rh.Config.NetworkPassphrase)
+	if err != nil {
+		log.WithFields(log.Fields{"asset": asset.String(), "err": err}).Error("Error deriving asset contract ID")
+		server.Write(w, protocols.InternalServerError)
+		return
+	}
+
+	server.Write(w, bridge.AssetResponse{
+		AssetCode:   request.AssetCode,
+		AssetIssuer: request.AssetIssuer,
+		ContractID:  contractID,
+	})
+}
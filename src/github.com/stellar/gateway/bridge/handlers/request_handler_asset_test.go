@@ -0,0 +1,76 @@
+package handlers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+	"github.com/stellar/gateway/bridge/config"
+	"github.com/stellar/gateway/net"
+	"github.com/stellar/gateway/test"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRequestHandlerAsset(t *testing.T) {
+	c := &config.Config{NetworkPassphrase: "Public Global Stellar Network ; September 2015"}
+	requestHandler := RequestHandler{Config: c}
+
+	testServer := httptest.NewServer(http.HandlerFunc(requestHandler.Asset))
+	defer testServer.Close()
+
+	Convey("Given asset request", t, func() {
+		Convey("When asset is invalid", func() {
+			params := url.Values{"asset_code": {"USDC"}}
+
+			Convey("it should return error", func() {
+				statusCode, response := net.GetResponse(testServer, params)
+				responseString := strings.TrimSpace(string(response))
+				assert.Equal(t, 400, statusCode)
+				expected := test.StringToJSONMap(`{
+  "code": "invalid_parameter",
+  "error_code": 101,
+  "message": "Invalid parameter.",
+  "more_info": "Invalid asset.",
+  "data": {
+    "name": "asset"
+  }
+}`)
+				assert.Equal(t, expected, test.StringToJSONMap(responseString))
+			})
+		})
+
+		Convey("When asset is XLM", func() {
+			Convey("it should return contract ID", func() {
+				statusCode, response := net.GetResponse(testServer, url.Values{})
+				responseString := strings.TrimSpace(string(response))
+				assert.Equal(t, 200, statusCode)
+				expected := test.StringToJSONMap(`{
+  "contract_id": "CAS3J7GYLGXMF6TDJBBYYSE3HQ6BBSMLNUQ34T6TZMYMW2EVH34XOWMA"
+}`)
+				assert.Equal(t, expected, test.StringToJSONMap(responseString))
+			})
+		})
+
+		Convey("When asset is a credit asset", func() {
+			params := url.Values{
+				"asset_code":   {"USDC"},
+				"asset_issuer": {"GA5ZSEJYB37JRC5AVCIA5MOP4RHTM335X2KGX3IHOJAPP5RE34K4KZVN"},
+			}
+
+			Convey("it should return contract ID", func() {
+				statusCode, response := net.GetResponse(testServer, params)
+				responseString := strings.TrimSpace(string(response))
+				assert.Equal(t, 200, statusCode)
+				expected := test.StringToJSONMap(`{
+  "asset_code": "USDC",
+  "asset_issuer": "GA5ZSEJYB37JRC5AVCIA5MOP4RHTM335X2KGX3IHOJAPP5RE34K4KZVN",
+  "contract_id": "CCW67TSZV3SSS2HXMBQ5JFGCKJNXKZM7UQUWUZPUTHXSTZLEO7SJMI75"
+}`)
+				assert.Equal(t, expected, test.StringToJSONMap(responseString))
+			})
+		})
+	})
+}
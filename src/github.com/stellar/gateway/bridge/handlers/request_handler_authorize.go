@@ -34,20 +34,25 @@ func (rh *RequestHandler) Authorize(w http.ResponseWriter, r *http.Request) {
 		b.AllowTrustAsset{request.AssetCode},
 	)
 
+	seed := rh.assetSeed(request.AssetCode, rh.Config.Accounts.IssuingAccountID)
+	if seed == "" {
+		seed = rh.Config.Accounts.AuthorizingSeed
+	}
+
 	submitResponse, err := rh.TransactionSubmitter.SubmitTransaction(
 		nil,
-		rh.Config.Accounts.AuthorizingSeed,
+		seed,
 		operationMutator,
 		nil,
 	)
 
 	if err != nil {
 		log.WithFields(log.Fields{"err": err}).Error("Error submitting transaction")
-		server.Write(w, protocols.InternalServerError)
+		rh.writeHorizonError(w, err)
 		return
 	}
 
-	errorResponse := bridge.ErrorFromHorizonResponse(submitResponse)
+	errorResponse := rh.errorFromHorizonResponse(submitResponse)
 	if errorResponse != nil {
 		log.WithFields(errorResponse.LogData).Error(errorResponse.Error())
 		server.Write(w, errorResponse)
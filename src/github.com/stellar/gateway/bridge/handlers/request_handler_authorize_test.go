@@ -25,7 +25,12 @@ func TestRequestHandlerAuthorize(t *testing.T) {
 	config := config.Config{
 		Assets: []config.Asset{
 			{Code: "USD", Issuer: "GD4I7AFSLZGTDL34TQLWJOM2NHLIIOEKD5RHHZUW54HERBLSIRKUOXRR"},
-			{Code: "EUR", Issuer: "GD4I7AFSLZGTDL34TQLWJOM2NHLIIOEKD5RHHZUW54HERBLSIRKUOXRR"},
+			{
+				Code:   "EUR",
+				Issuer: "GD4I7AFSLZGTDL34TQLWJOM2NHLIIOEKD5RHHZUW54HERBLSIRKUOXRR",
+				// GCF3WVYTHF75PEG6622G5G6KU26GOSDQPDHSCJ3DQD7VONH4EYVDOGKJ
+				Seed: "SDWLS4G3XCNIYPKXJWWGGJT6UDY63WV6PEFTWP7JZMQB4RE7EUJQN5XM",
+			},
 		},
 		Accounts: config.Accounts{
 			IssuingAccountID: "GD4I7AFSLZGTDL34TQLWJOM2NHLIIOEKD5RHHZUW54HERBLSIRKUOXRR",
@@ -49,6 +54,7 @@ func TestRequestHandlerAuthorize(t *testing.T) {
 				assert.Equal(t, 400, statusCode)
 				expected := test.StringToJSONMap(`{
 				  "code": "invalid_parameter",
+				  "error_code": 101,
 				  "message": "Invalid parameter.",
 				  "data": {
 				    "name": "account_id"
@@ -68,6 +74,7 @@ func TestRequestHandlerAuthorize(t *testing.T) {
 				assert.Equal(t, 400, statusCode)
 				expected := test.StringToJSONMap(`{
 				  "code": "invalid_parameter",
+				  "error_code": 101,
 				  "message": "Invalid parameter.",
 				  "data": {
 				    "name": "asset_code"
@@ -105,6 +112,7 @@ func TestRequestHandlerAuthorize(t *testing.T) {
 					assert.Equal(t, 500, statusCode)
 					expected := test.StringToJSONMap(`{
 					  "code": "internal_server_error",
+					  "error_code": 100,
 					  "message": "Internal Server Error, please try again."
 					}`)
 					assert.Equal(t, expected, test.StringToJSONMap(responseString))
@@ -138,5 +146,36 @@ func TestRequestHandlerAuthorize(t *testing.T) {
 				})
 			})
 		})
+
+		Convey("When asset has its own seed", func() {
+			accountID := "GDSIKW43UA6JTOA47WVEBCZ4MYC74M3GNKNXTVDXFHXYYTNO5GGVN632"
+			assetCode := "EUR"
+
+			operation := b.AllowTrust(
+				b.Trustor{accountID},
+				b.Authorize{true},
+				b.AllowTrustAsset{assetCode},
+			)
+
+			var ledger uint64
+			ledger = 100
+			expectedSubmitResponse := horizon.SubmitTransactionResponse{
+				Ledger: &ledger,
+			}
+
+			mockTransactionSubmitter.On(
+				"SubmitTransaction",
+				(*string)(nil),
+				"SDWLS4G3XCNIYPKXJWWGGJT6UDY63WV6PEFTWP7JZMQB4RE7EUJQN5XM",
+				operation,
+				nil,
+			).Return(expectedSubmitResponse, nil).Once()
+
+			Convey("it should sign using asset seed", func() {
+				statusCode, _ := net.GetResponse(testServer, url.Values{"account_id": {accountID}, "asset_code": {assetCode}})
+				assert.Equal(t, 200, statusCode)
+				mockTransactionSubmitter.AssertExpectations(t)
+			})
+		})
 	})
 }
@@ -0,0 +1,1417 @@
+package handlers
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io/ioutil"
+	"math"
+	"net/http"
+	"net/url"
+	"strconv"
+	"sync"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/stellar/gateway/db/entities"
+	"github.com/stellar/gateway/events"
+	"github.com/stellar/gateway/horizon"
+	"github.com/stellar/gateway/protocols"
+	"github.com/stellar/gateway/protocols/bridge"
+	"github.com/stellar/gateway/ratelimit"
+	"github.com/stellar/gateway/server"
+	"github.com/stellar/go/address"
+	"github.com/stellar/go/amount"
+	b "github.com/stellar/go/build"
+	"github.com/stellar/go/keypair"
+	"github.com/stellar/go/xdr"
+)
+
+// BatchPayment implements /batch-payment endpoint
+func (rh *RequestHandler) BatchPayment(w http.ResponseWriter, r *http.Request) {
+	var request bridge.BatchPaymentRequest
+
+	body, err := ioutil.ReadAll(r.Body)
+	if err == nil {
+		err = json.Unmarshal(body, &request)
+	}
+	if err != nil {
+		log.WithFields(log.Fields{"err": err}).Error("Error decoding request")
+		server.Write(w, protocols.NewInvalidParameterError("", "", "Request body is not a valid JSON"))
+		return
+	}
+
+	// Body has been decoded so it's a valid JSON
+	unknown, _ := protocols.UnknownJSONFields(body, &request)
+	errorResponse := rh.checkUnknownParams(unknown)
+	if errorResponse != nil {
+		server.Write(w, errorResponse)
+		return
+	}
+
+	err = request.Validate()
+	if err != nil {
+		errorResponse := err.(*protocols.ErrorResponse)
+		log.WithFields(errorResponse.LogData).Error(errorResponse.Error())
+		server.Write(w, errorResponse)
+		return
+	}
+
+	for i, payment := range request.Payments {
+		if rh.checkAmount(payment.Amount, payment.Operation == "create_account") != nil {
+			log.WithFields(log.Fields{"payment": i, "amount": payment.Amount}).Print("Invalid batch payment amount")
+			server.Write(w, batchPaymentInvalidAmount(i))
+			return
+		}
+
+		errorResponse := rh.checkAssetDecimals("payments["+strconv.Itoa(i)+"][amount]", payment.AssetCode, payment.AssetIssuer, payment.Amount)
+		if errorResponse != nil {
+			log.WithFields(errorResponse.LogData).Print(errorResponse.Error())
+			server.Write(w, errorResponse)
+			return
+		}
+
+		errorResponse = rh.checkAssetsAllowed(protocols.Asset{Code: payment.AssetCode, Issuer: payment.AssetIssuer})
+		if errorResponse != nil {
+			log.WithFields(log.Fields{"payment": i}).WithFields(errorResponse.Data).Print(errorResponse.Error())
+			server.Write(w, errorResponse)
+			return
+		}
+	}
+
+	// When bearer tokens are configured source account is determined by the token only
+	if len(rh.Config.AuthTokens) > 0 {
+		if request.Source != "" {
+			log.Print("source param sent when bearer token authentication is enabled")
+			server.Write(w, protocols.NewInvalidParameterError("source", "", "Source param is not accepted. Use `Authorization: Bearer` header instead."))
+			return
+		}
+
+		seed, ok := rh.seedFromAuthorization(r)
+		if !ok {
+			log.Print("Missing or invalid bearer token")
+			server.Write(w, protocols.UnauthorizedError)
+			return
+		}
+		request.Source = seed
+
+		for i, payment := range request.Payments {
+			if payment.Source != "" {
+				log.Print("payment source param sent when bearer token authentication is enabled")
+				server.Write(w, protocols.NewInvalidParameterError("payments["+strconv.Itoa(i)+"][source]", "", "Source param is not accepted. Use `Authorization: Bearer` header instead."))
+				return
+			}
+		}
+	}
+
+	// From now on every payment has its transaction source set: `source` of the batch, the seed of the
+	// sent asset or `base_seed`, like /payment
+	for i := range request.Payments {
+		payment := &request.Payments[i]
+		if payment.Source == "" {
+			payment.Source = request.Source
+		}
+		if payment.Source == "" {
+			payment.Source = rh.assetSeed(payment.AssetCode, payment.AssetIssuer)
+		}
+		if payment.Source == "" {
+			payment.Source = rh.Config.Accounts.BaseSeed
+		}
+	}
+
+	requestHash := batchRequestHash(&request)
+
+	if request.ID != "" {
+		errorResponse = rh.checkBatchID(request.ID, requestHash)
+		if errorResponse != nil {
+			log.WithFields(log.Fields{"id": request.ID}).Print(errorResponse.Error())
+			server.Write(w, errorResponse)
+			return
+		}
+	}
+
+	// Batches are never sent using compliance protocol
+	for i, payment := range request.Payments {
+		if rh.complianceRequired(payment.AssetCode, payment.AssetIssuer, payment.Amount) {
+			log.WithFields(log.Fields{"payment": i}).Print("Batch payment requires compliance protocol")
+			errorResponse := *bridge.PaymentComplianceRequired
+			errorResponse.Data = map[string]interface{}{"name": "payments[" + strconv.Itoa(i) + "]"}
+			server.Write(w, &errorResponse)
+			return
+		}
+	}
+
+	for i, payment := range request.Payments {
+		// Operations sent from another account send its assets
+		source := payment.Source
+		if payment.OperationSource != "" {
+			source = payment.OperationSource
+		}
+
+		errorResponse := rh.checkIssuance("payments["+strconv.Itoa(i)+"][asset_issuer]", source, protocols.Asset{Code: payment.AssetCode, Issuer: payment.AssetIssuer})
+		if errorResponse != nil {
+			log.WithFields(errorResponse.Data).Print(errorResponse.Error())
+			server.Write(w, errorResponse)
+			return
+		}
+	}
+
+	// Payments sent with keys by previous requests are not sent again
+	var items *batchItems
+	if request.HasKeys() {
+		items, errorResponse = rh.loadBatchItems(&request)
+		if errorResponse != nil {
+			log.WithFields(errorResponse.Data).Print(errorResponse.Error())
+			server.Write(w, errorResponse)
+			return
+		}
+
+		if len(request.Payments) == 0 {
+			log.Print("All payments of the batch have already been sent")
+			server.Write(w, &bridge.BatchPaymentResponse{Items: items.skipped})
+			return
+		}
+	}
+
+	var paymentID *string
+	if request.ID != "" {
+		paymentID = &request.ID
+	}
+
+	destinations, failed := rh.resolveDestinations(request.Payments)
+	if len(failed) > 0 {
+		errorResponse := bridge.NewBatchPaymentCannotResolveDestinationsError(failed)
+		log.WithFields(errorResponse.LogData).Print(errorResponse.Error())
+		server.Write(w, errorResponse)
+		return
+	}
+
+	duplicates := duplicatePayments(request.Payments, destinations)
+	if len(duplicates) > 0 {
+		switch rh.Config.Batch.Duplicates {
+		case "reject":
+			errorResponse := bridge.NewBatchPaymentDuplicateError(duplicates[0][0], duplicates[0][1])
+			log.WithFields(errorResponse.Data).Print(errorResponse.Error())
+			server.Write(w, errorResponse)
+			return
+		case "collapse":
+			log.WithFields(log.Fields{"duplicates": len(duplicates)}).Print("Collapsing identical payments of the batch")
+			request.Payments = withoutDuplicates(request.Payments, duplicates)
+		}
+	}
+
+	errorResponse = rh.checkBatchLimits(request.Payments)
+	if errorResponse != nil {
+		log.WithFields(errorResponse.Data).Print(errorResponse.Error())
+		server.Write(w, errorResponse)
+		return
+	}
+
+	errorResponse = rh.checkBalanceFloors(batchSpending(request.Payments, request.PerOpFee))
+	if errorResponse != nil {
+		log.WithFields(errorResponse.Data).Print(errorResponse.Error())
+		server.Write(w, errorResponse)
+		return
+	}
+
+	// Seeds of operation sources, empty for operations sent from the transaction source
+	signers, errorResponse := rh.operationSigners(request.Payments)
+	if errorResponse != nil {
+		log.WithFields(errorResponse.LogData).Print(errorResponse.Error())
+		server.Write(w, errorResponse)
+		return
+	}
+
+	var operations bridge.Operations
+
+	for i, payment := range request.Payments {
+		accountID := destinations[payment.Destination]
+
+		mutators := []interface{}{
+			b.Destination{accountID},
+		}
+
+		if payment.OperationSource != "" {
+			mutators = append(mutators, b.SourceAccount{payment.OperationSource})
+		}
+
+		if payment.AssetCode != "" {
+			mutators = append(mutators, b.CreditAmount{payment.AssetCode, payment.AssetIssuer, payment.Amount})
+			operations = append(operations, b.Payment(mutators...))
+			continue
+		}
+
+		mutators = append(mutators, b.NativeAmount{payment.Amount})
+
+		switch payment.Operation {
+		case "payment":
+			operations = append(operations, b.Payment(mutators...))
+		case "create_account":
+			operations = append(operations, b.CreateAccount(mutators...))
+		default:
+			// Check if destination account exist
+			_, err = rh.Horizon.LoadAccount(accountID)
+			if err == horizon.ErrAccountNotFound {
+				if rh.checkAmount(payment.Amount, true) != nil {
+					log.WithFields(log.Fields{"payment": i, "destination": accountID}).Print("Cannot create account with zero starting balance")
+					server.Write(w, batchPaymentInvalidAmount(i))
+					return
+				}
+				operations = append(operations, b.CreateAccount(mutators...))
+			} else if err != nil {
+				log.WithFields(log.Fields{"error": err}).Error("Error loading account")
+				rh.writeHorizonError(w, err)
+				return
+			} else {
+				operations = append(operations, b.Payment(mutators...))
+			}
+		}
+	}
+
+	if rh.Config.Batch.Ordering != "" {
+		payment, dependency := orderingProblem(request.Payments, destinations, operations)
+		if payment >= 0 {
+			errorResponse := bridge.NewBatchPaymentOrderingError(payment, dependency)
+			if rh.Config.Batch.Ordering == "reject" {
+				log.WithFields(errorResponse.Data).Print(errorResponse.Error())
+				server.Write(w, errorResponse)
+				return
+			}
+			log.WithFields(errorResponse.Data).Warn("Batch payment depends on an operation applied after it")
+		}
+	}
+
+	if rh.Config.ForbidMemo && request.MemoType != "" {
+		log.WithFields(log.Fields{"memo_type": request.MemoType, "memo": request.Memo}).Print("Memo is not allowed")
+		server.Write(w, bridge.PaymentMemoNotAllowed)
+		return
+	}
+
+	if !rh.allowedMemoType(request.MemoType) {
+		log.WithFields(log.Fields{"memo_type": request.MemoType}).Print("Memo type is not allowed")
+		server.Write(w, bridge.NewPaymentMemoTypeForbiddenError(rh.Config.AllowedMemoTypes))
+		return
+	}
+
+	for _, payment := range request.Payments {
+		errorResponse := rh.checkMemoType(payment.Destination, destinationDomain(payment.Destination), destinations[payment.Destination], request.MemoType)
+		if errorResponse != nil {
+			log.WithFields(log.Fields{"destination": payment.Destination, "memo_type": request.MemoType}).Print(errorResponse.Error())
+			server.Write(w, errorResponse)
+			return
+		}
+
+		errorResponse = rh.checkMemoRequired(payment.Destination, destinations[payment.Destination], request.MemoType)
+		if errorResponse != nil {
+			log.WithFields(log.Fields{"destination": payment.Destination}).Print(errorResponse.Error())
+			server.Write(w, errorResponse)
+			return
+		}
+	}
+
+	var memoMutator interface{}
+	switch request.MemoType {
+	case "":
+		break
+	case "id":
+		id, err := strconv.ParseUint(request.Memo, 10, 64)
+		if err != nil {
+			log.WithFields(log.Fields{"memo": request.Memo}).Print("Cannot convert memo_id value to uint64")
+			server.Write(w, protocols.NewInvalidParameterError("memo", request.Memo, "Memo.id must be a number"))
+			return
+		}
+		memoMutator = b.MemoID{id}
+	case "text":
+		memoMutator = b.MemoText{request.Memo}
+	case "hash":
+		memoBytes, err := hex.DecodeString(request.Memo)
+		if err != nil || len(memoBytes) != 32 {
+			log.WithFields(log.Fields{"memo": request.Memo}).Print("Cannot decode hash memo value")
+			server.Write(w, protocols.NewInvalidParameterError("memo", request.Memo, "Memo.hash must be 32 bytes and hex encoded."))
+			return
+		}
+		var b32 [32]byte
+		copy(b32[:], memoBytes[0:32])
+		memoMutator = b.MemoHash{xdr.Hash(b32)}
+	default:
+		log.Print("Not supported memo type: ", request.MemoType)
+		server.Write(w, protocols.NewInvalidParameterError("memo", request.Memo, "Memo type not supported"))
+		return
+	}
+
+	payments := make(map[ratelimit.Asset]int)
+	for _, payment := range request.Payments {
+		payments[ratelimit.Asset{Code: payment.AssetCode, Issuer: payment.AssetIssuer}]++
+	}
+
+	errorResponse = rh.checkRateLimit(payments)
+	if errorResponse != nil {
+		log.WithFields(errorResponse.Data).Print(errorResponse.Error())
+		server.Write(w, errorResponse)
+		return
+	}
+
+	maxOperations := rh.Config.Batch.MaxOperations
+	if maxOperations == 0 {
+		maxOperations = bridge.MaxOperationsPerTransaction
+	}
+
+	sources, groups := batchSources(request.Payments)
+	if len(sources) > 1 {
+		rh.multiSourceBatchPayment(w, r, request, requestHash, sources, groups, operations, signers, memoMutator, maxOperations, items)
+		return
+	}
+	request.Source = sources[0]
+
+	errorResponse = rh.checkBatchFee(request.PerOpFee, len(operations), maxOperations)
+	if errorResponse != nil {
+		log.WithFields(errorResponse.Data).Print(errorResponse.Error())
+		server.Write(w, errorResponse)
+		return
+	}
+
+	if requestExpired(r) {
+		log.Print("Request deadline exceeded, transaction not submitted")
+		server.Write(w, protocols.RequestTimeoutError)
+		return
+	}
+
+	if len(operations) <= maxOperations {
+		if items != nil {
+			paymentID, errorResponse = rh.claimBatchItems(items, request.Payments)
+			if errorResponse != nil {
+				log.WithFields(errorResponse.Data).Print(errorResponse.Error())
+				server.Write(w, errorResponse)
+				return
+			}
+		}
+
+		submitResponse, err := rh.submitBatchTransaction(paymentID, requestHash, request.Source, withBaseFee(operations, request.PerOpFee), memoMutator, distinctSigners(signers)...)
+		if err != nil {
+			log.WithFields(log.Fields{"error": err}).Error("Error submitting transaction")
+			rh.saveBatchItems(items, request.Payments, paymentID, nil, protocols.InternalServerError)
+			rh.publishBatchPaymentEvents(r, &request, request.Payments, nil, protocols.InternalServerError)
+			rh.writeHorizonError(w, err)
+			return
+		}
+
+		response := rh.submitterResponse(submitResponse, request.IncludeMeta)
+		errorResponse, _ := response.(*protocols.ErrorResponse)
+		rh.publishBatchPaymentEvents(r, &request, request.Payments, &submitResponse, errorResponse)
+		if items == nil {
+			server.Write(w, response)
+			return
+		}
+
+		results := items.withSkipped(rh.saveBatchItems(items, request.Payments, paymentID, &submitResponse, errorResponse))
+		if errorResponse != nil {
+			server.Write(w, withBatchItems(errorResponse, results))
+			return
+		}
+
+		server.Write(w, &bridge.BatchPaymentResponse{
+			Transactions: []horizon.SubmitTransactionResponse{*response.(*horizon.SubmitTransactionResponse)},
+			Items:        results,
+		})
+		return
+	}
+
+	if !rh.Config.Batch.SplitTransactions {
+		log.WithFields(log.Fields{"operations": len(operations), "max_operations": maxOperations}).Print("Batch exceeds maximum number of operations")
+		server.Write(w, bridge.NewBatchPaymentTooManyOperationsError(maxOperations))
+		return
+	}
+
+	rh.splitBatchPayment(w, r, request, requestHash, operations, signers, memoMutator, maxOperations, items)
+}
+
+// checkBatchLimits returns BatchPaymentTooManyPayments error when the batch has more payments than
+// `batch.max_payments` and BatchPaymentAmountTooHigh error when the total amount of an asset sent by
+// all payments exceeds its `batch.max_amounts` limit. Limits of single payments are checked separately.
+func (rh *RequestHandler) checkBatchLimits(payments []bridge.BatchPaymentItem) *protocols.ErrorResponse {
+	maxPayments := rh.Config.Batch.MaxPayments
+	if maxPayments > 0 && len(payments) > maxPayments {
+		return bridge.NewBatchPaymentTooManyPaymentsError(len(payments), maxPayments)
+	}
+
+	if len(rh.Config.Batch.MaxAmounts) == 0 {
+		return nil
+	}
+
+	totals := make(map[protocols.Asset]xdr.Int64)
+	for _, payment := range payments {
+		// Amounts have been validated already
+		value, _ := amount.Parse(payment.Amount)
+		asset := protocols.Asset{Code: payment.AssetCode, Issuer: payment.AssetIssuer}
+
+		// Total cannot exceed the maximum amount so it's capped instead of overflowing
+		if totals[asset] > math.MaxInt64-value {
+			totals[asset] = math.MaxInt64
+		} else {
+			totals[asset] += value
+		}
+	}
+
+	for _, limit := range rh.Config.Batch.MaxAmounts {
+		asset := protocols.Asset{Code: limit.AssetCode, Issuer: limit.AssetIssuer}
+		// Limits have been validated in config
+		maxAmount, _ := amount.Parse(limit.Amount)
+		if totals[asset] > maxAmount {
+			return bridge.NewBatchPaymentAmountTooHighError(asset, amount.String(totals[asset]), amount.String(maxAmount))
+		}
+	}
+
+	return nil
+}
+
+// batchSpending returns amounts of assets sent by accounts sending payments of the batch (operation
+// sources or transaction sources) and fees paid by transaction sources, `perOpFee` per operation or
+// the default base fee when it's zero
+func batchSpending(payments []bridge.BatchPaymentItem, perOpFee uint64) map[accountAsset]xdr.Int64 {
+	fee := xdr.Int64(b.DefaultBaseFee)
+	if perOpFee != 0 {
+		fee = xdr.Int64(perOpFee)
+	}
+
+	spent := make(map[accountAsset]xdr.Int64)
+	for _, payment := range payments {
+		source, err := keypair.Parse(payment.Source)
+		if err != nil {
+			continue
+		}
+
+		sender := source.Address()
+		if payment.OperationSource != "" {
+			sender = payment.OperationSource
+		}
+
+		// Amounts have been validated already
+		value, _ := amount.Parse(payment.Amount)
+		spent[accountAsset{sender, protocols.Asset{Code: payment.AssetCode, Issuer: payment.AssetIssuer}}] += value
+		spent[accountAsset{AccountID: source.Address()}] += fee
+	}
+	return spent
+}
+
+// checkBatchFee returns BatchPaymentFeeTooHigh error when the fee of the largest transaction built
+// from operations (split into transactions of at most maxOperations) paying perOpFee per operation
+// exceeds `batch.max_transaction_fee` or the maximum fee a transaction can have.
+func (rh *RequestHandler) checkBatchFee(perOpFee uint64, operations, maxOperations int) *protocols.ErrorResponse {
+	if perOpFee == 0 {
+		return nil
+	}
+
+	if operations > maxOperations {
+		operations = maxOperations
+	}
+
+	maxFee := uint64(math.MaxUint32)
+	if rh.Config.Batch.MaxTransactionFee > 0 && uint64(rh.Config.Batch.MaxTransactionFee) < maxFee {
+		maxFee = uint64(rh.Config.Batch.MaxTransactionFee)
+	}
+
+	if perOpFee > maxFee/uint64(operations) {
+		return bridge.NewBatchPaymentFeeTooHighError(perOpFee*uint64(operations), maxFee)
+	}
+	return nil
+}
+
+// withBaseFee sets the fee per operation of the transaction built from operations.
+// Default base fee is used when baseFee is zero.
+func withBaseFee(operations bridge.Operations, baseFee uint64) bridge.Operations {
+	if baseFee == 0 {
+		return operations
+	}
+	return append(bridge.Operations{b.BaseFee{Amount: baseFee}}, operations...)
+}
+
+// splitBatchPayment submits operations in consecutive transactions of at most maxOperations
+// operations each. Submission stops at the first failed transaction; the error returned then
+// contains hashes of transactions that have already been submitted successfully and results of
+// keyed payments (items is nil when payments have no keys).
+func (rh *RequestHandler) splitBatchPayment(w http.ResponseWriter, r *http.Request, request bridge.BatchPaymentRequest, requestHash string, operations bridge.Operations, signers []string, memo interface{}, maxOperations int, items *batchItems) {
+	response := bridge.BatchPaymentResponse{}
+	var submitted []string
+	var results []bridge.BatchPaymentItemResult
+
+	for i := 0; i*maxOperations < len(operations); i++ {
+		end := (i + 1) * maxOperations
+		if end > len(operations) {
+			end = len(operations)
+		}
+
+		// payment_id must be unique so every transaction gets its own
+		var paymentID *string
+		if request.ID != "" {
+			transactionID := request.ID + "-" + strconv.Itoa(i)
+			paymentID = &transactionID
+		}
+
+		if requestExpired(r) {
+			log.WithFields(log.Fields{"submitted": submitted}).Print("Request deadline exceeded, remaining transactions not submitted")
+			server.Write(w, withBatchItems(withSubmittedTransactions(protocols.RequestTimeoutError, submitted), items.withSkipped(results)))
+			return
+		}
+
+		payments := request.Payments[i*maxOperations : end]
+		if items != nil {
+			var errorResponse *protocols.ErrorResponse
+			paymentID, errorResponse = rh.claimBatchItems(items, payments)
+			if errorResponse != nil {
+				log.WithFields(errorResponse.Data).WithFields(log.Fields{"submitted": submitted}).Print(errorResponse.Error())
+				server.Write(w, withBatchItems(withSubmittedTransactions(errorResponse, submitted), items.withSkipped(results)))
+				return
+			}
+		}
+
+		submitResponse, err := rh.submitBatchTransaction(paymentID, requestHash, request.Source, withBaseFee(operations[i*maxOperations:end], request.PerOpFee), memo, distinctSigners(signers[i*maxOperations:end])...)
+		if err != nil {
+			log.WithFields(log.Fields{"error": err, "submitted": submitted}).Error("Error submitting transaction")
+			results = append(results, rh.saveBatchItems(items, payments, paymentID, nil, protocols.InternalServerError)...)
+			rh.publishBatchPaymentEvents(r, &request, payments, nil, protocols.InternalServerError)
+			server.Write(w, withBatchItems(withSubmittedTransactions(protocols.InternalServerError, submitted), items.withSkipped(results)))
+			return
+		}
+
+		errorResponse := rh.errorFromHorizonResponse(submitResponse)
+		results = append(results, rh.saveBatchItems(items, payments, paymentID, &submitResponse, errorResponse)...)
+		rh.publishBatchPaymentEvents(r, &request, payments, &submitResponse, errorResponse)
+		if errorResponse != nil {
+			log.WithFields(errorResponse.LogData).WithFields(log.Fields{"submitted": submitted}).Error(errorResponse.Error())
+			server.Write(w, withBatchItems(withSubmittedTransactions(errorResponse, submitted), items.withSkipped(results)))
+			return
+		}
+
+		if !request.IncludeMeta {
+			submitResponse.ResultMetaXdr = nil
+		}
+
+		submitted = append(submitted, submitResponse.Hash)
+		response.Transactions = append(response.Transactions, submitResponse)
+	}
+
+	response.Items = items.withSkipped(results)
+	server.Write(w, &response)
+}
+
+// multiSourceBatchPayment submits payments of every source account of the batch in a separate
+// transaction, at most `batch.source_concurrency` transactions at a time. `groups` contains indexes
+// of payments (and their operations and signers) of each of `sources`. Transactions are independent:
+// a failure of one of them doesn't stop the others. Results of all source accounts (and of keyed
+// payments, items is nil when payments have no keys) are returned, in BatchPaymentSourceFailed
+// error when any of the transactions failed.
+func (rh *RequestHandler) multiSourceBatchPayment(w http.ResponseWriter, r *http.Request, request bridge.BatchPaymentRequest, requestHash string, sources []string, groups [][]int, operations bridge.Operations, signers []string, memo interface{}, maxOperations int, items *batchItems) {
+	largest := 0
+	for _, group := range groups {
+		if len(group) > largest {
+			largest = len(group)
+		}
+	}
+
+	// Transactions of a source account are never split
+	if largest > maxOperations {
+		log.WithFields(log.Fields{"operations": largest, "max_operations": maxOperations}).Print("Batch of a source account exceeds maximum number of operations")
+		server.Write(w, bridge.NewBatchPaymentTooManyOperationsError(maxOperations))
+		return
+	}
+
+	errorResponse := rh.checkBatchFee(request.PerOpFee, largest, maxOperations)
+	if errorResponse != nil {
+		log.WithFields(errorResponse.Data).Print(errorResponse.Error())
+		server.Write(w, errorResponse)
+		return
+	}
+
+	if requestExpired(r) {
+		log.Print("Request deadline exceeded, transactions not submitted")
+		server.Write(w, protocols.RequestTimeoutError)
+		return
+	}
+
+	concurrency := rh.Config.Batch.SourceConcurrency
+	if concurrency < 1 {
+		concurrency = 1
+	}
+
+	results := make([]bridge.BatchPaymentSourceResult, len(sources))
+	itemResults := make([][]bridge.BatchPaymentItemResult, len(sources))
+	var wg sync.WaitGroup
+	jobs := make(chan int)
+
+	for i := 0; i < concurrency && i < len(sources); i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := range jobs {
+				var sourcePayments []bridge.BatchPaymentItem
+				var sourceOperations bridge.Operations
+				var sourceSigners []string
+				for _, i := range groups[j] {
+					sourcePayments = append(sourcePayments, request.Payments[i])
+					sourceOperations = append(sourceOperations, operations[i])
+					sourceSigners = append(sourceSigners, signers[i])
+				}
+
+				// Every job writes its own results only
+				itemResults[j] = rh.submitSourceTransaction(r, request, requestHash, j, sources[j], sourcePayments, sourceOperations, distinctSigners(sourceSigners), memo, items, &results[j])
+			}
+		}()
+	}
+
+	for j := range sources {
+		jobs <- j
+	}
+	close(jobs)
+	wg.Wait()
+
+	var sourceItemResults []bridge.BatchPaymentItemResult
+	for _, results := range itemResults {
+		sourceItemResults = append(sourceItemResults, results...)
+	}
+
+	for _, result := range results {
+		if result.Error != nil {
+			errorResponse := bridge.NewBatchPaymentSourceFailedError(results)
+			log.WithFields(errorResponse.LogData).Print(errorResponse.Error())
+			server.Write(w, withBatchItems(errorResponse, items.withSkipped(sourceItemResults)))
+			return
+		}
+	}
+
+	server.Write(w, &bridge.BatchPaymentResponse{Sources: results, Items: items.withSkipped(sourceItemResults)})
+}
+
+// submitSourceTransaction submits the transaction of the source account with index j of a multi-source
+// batch sending payments and sets its result. It returns results of keyed payments (items is nil when
+// payments have no keys).
+func (rh *RequestHandler) submitSourceTransaction(r *http.Request, request bridge.BatchPaymentRequest, requestHash string, j int, source string, payments []bridge.BatchPaymentItem, operations bridge.Operations, signers []string, memo interface{}, items *batchItems, result *bridge.BatchPaymentSourceResult) []bridge.BatchPaymentItemResult {
+	kp, _ := keypair.Parse(source)
+	result.Source = kp.Address()
+
+	// payment_id must be unique so every transaction gets its own
+	var paymentID *string
+	if request.ID != "" {
+		transactionID := request.ID + "-" + strconv.Itoa(j)
+		paymentID = &transactionID
+	}
+
+	if requestExpired(r) {
+		log.WithFields(log.Fields{"source": result.Source}).Print("Request deadline exceeded, transaction not submitted")
+		result.SetError(protocols.RequestTimeoutError)
+		return rh.saveBatchItems(items, payments, nil, nil, protocols.RequestTimeoutError)
+	}
+
+	if items != nil {
+		var errorResponse *protocols.ErrorResponse
+		paymentID, errorResponse = rh.claimBatchItems(items, payments)
+		if errorResponse != nil {
+			log.WithFields(errorResponse.Data).WithFields(log.Fields{"source": result.Source}).Print(errorResponse.Error())
+			result.SetError(errorResponse)
+			return unsentBatchItemResults(payments, errorResponse)
+		}
+	}
+
+	submitResponse, err := rh.submitBatchTransaction(paymentID, requestHash, source, withBaseFee(operations, request.PerOpFee), memo, signers...)
+	if err != nil {
+		log.WithFields(log.Fields{"error": err, "source": result.Source}).Error("Error submitting transaction")
+		result.SetError(protocols.InternalServerError)
+		rh.publishBatchPaymentEvents(r, &request, payments, nil, protocols.InternalServerError)
+		return rh.saveBatchItems(items, payments, paymentID, nil, protocols.InternalServerError)
+	}
+
+	errorResponse := rh.errorFromHorizonResponse(submitResponse)
+	rh.publishBatchPaymentEvents(r, &request, payments, &submitResponse, errorResponse)
+	if errorResponse != nil {
+		log.WithFields(errorResponse.LogData).WithFields(log.Fields{"source": result.Source}).Error(errorResponse.Error())
+		result.SetError(errorResponse)
+		return rh.saveBatchItems(items, payments, paymentID, &submitResponse, errorResponse)
+	}
+
+	if !request.IncludeMeta {
+		submitResponse.ResultMetaXdr = nil
+	}
+	result.Transaction = &submitResponse
+	return rh.saveBatchItems(items, payments, paymentID, &submitResponse, nil)
+}
+
+// publishBatchPaymentEvents publishes events of payments sent in a transaction of the batch: confirmed
+// when the transaction is in a ledger, submitted when its outcome is unknown and failed with the code
+// of errorResponse otherwise. response is nil when the transaction has not been submitted.
+func (rh *RequestHandler) publishBatchPaymentEvents(r *http.Request, request *bridge.BatchPaymentRequest, payments []bridge.BatchPaymentItem, response *horizon.SubmitTransactionResponse, errorResponse *protocols.ErrorResponse) {
+	if rh.Events == nil {
+		return
+	}
+
+	for _, payment := range payments {
+		event := events.PaymentEvent{
+			RequestID:   server.RequestID(r),
+			PaymentID:   request.ID,
+			Key:         payment.Key,
+			Destination: payment.Destination,
+			Amount:      payment.Amount,
+			AssetCode:   payment.AssetCode,
+			AssetIssuer: payment.AssetIssuer,
+			Time:        time.Now().UTC(),
+		}
+		if response != nil {
+			event.Hash = response.Hash
+		}
+
+		switch {
+		case errorResponse != nil:
+			event.Type = events.PaymentFailed
+			event.Error = errorResponse.Code
+		case response.Ledger != nil:
+			event.Type = events.PaymentConfirmed
+			event.Ledger = response.Ledger
+		default:
+			event.Type = events.PaymentSubmitted
+		}
+
+		rh.Events.PublishPayment(event)
+	}
+}
+
+// batchSources returns distinct transaction sources (seeds) of the payments, in the order of their
+// first payments, and indexes of the payments of each of them
+func batchSources(payments []bridge.BatchPaymentItem) (sources []string, groups [][]int) {
+	index := make(map[string]int)
+	for i, payment := range payments {
+		j, exists := index[payment.Source]
+		if !exists {
+			j = len(sources)
+			index[payment.Source] = j
+			sources = append(sources, payment.Source)
+			groups = append(groups, nil)
+		}
+		groups[j] = append(groups[j], i)
+	}
+	return
+}
+
+// operationSigners returns seeds of `operation_source` accounts of the payments, in the order of payments.
+// Seeds are looked up in the config, payments without operation source or sent from their transaction
+// source get an empty seed.
+func (rh *RequestHandler) operationSigners(payments []bridge.BatchPaymentItem) ([]string, *protocols.ErrorResponse) {
+	signers := make([]string, len(payments))
+	for i, payment := range payments {
+		var sourceAccountID string
+		if kp, err := keypair.Parse(payment.Source); err == nil {
+			sourceAccountID = kp.Address()
+		}
+
+		if payment.OperationSource == "" || payment.OperationSource == sourceAccountID {
+			continue
+		}
+
+		field := "payments[" + strconv.Itoa(i) + "][operation_source]"
+
+		// Token holders can send payments from their own account only
+		if len(rh.Config.AuthTokens) > 0 {
+			return nil, protocols.NewInvalidParameterError(field, payment.OperationSource, "Operation source is not accepted when bearer token authentication is enabled.")
+		}
+
+		signers[i] = rh.configuredSeed(payment.OperationSource)
+		if signers[i] == "" {
+			return nil, protocols.NewInvalidParameterError(field, payment.OperationSource, "Seed of operation source not found in config.")
+		}
+	}
+
+	return signers, nil
+}
+
+// duplicatePayments returns pairs of indexes of payments identical to a preceding payment and
+// of that preceding payment, in the order of payments. Payments are identical when they send
+// the same amount of the same asset to the same account using the same operation and sources.
+// Payments with different keys are never identical.
+func duplicatePayments(payments []bridge.BatchPaymentItem, destinations map[string]string) (duplicates [][2]int) {
+	type paymentKey struct {
+		accountID, assetCode, assetIssuer, operationSource, source, operation, key string
+		amount                                                                     xdr.Int64
+	}
+
+	seen := make(map[paymentKey]int)
+	for i, payment := range payments {
+		// Amounts have been validated already, parsing makes `1` and `1.0` identical
+		paymentAmount, _ := amount.Parse(payment.Amount)
+		key := paymentKey{
+			accountID:       destinations[payment.Destination],
+			assetCode:       payment.AssetCode,
+			assetIssuer:     payment.AssetIssuer,
+			operationSource: payment.OperationSource,
+			source:          payment.Source,
+			operation:       payment.Operation,
+			key:             payment.Key,
+			amount:          paymentAmount,
+		}
+
+		if original, exists := seen[key]; exists {
+			duplicates = append(duplicates, [2]int{i, original})
+			continue
+		}
+		seen[key] = i
+	}
+	return
+}
+
+// orderingProblem returns the index of the first payment to or from (`operation_source`) an account
+// created by a create_account operation applied after it and the index of that operation, or -1, -1
+// when there is none. Operations of a transaction are applied in request order but transactions of
+// different source accounts are submitted concurrently, so an account created in a transaction of
+// another source may not exist yet when the payment is applied.
+func orderingProblem(payments []bridge.BatchPaymentItem, destinations map[string]string, operations bridge.Operations) (int, int) {
+	created := make(map[string]int)
+	for i, operation := range operations {
+		if _, ok := operation.(b.CreateAccountBuilder); !ok {
+			continue
+		}
+		accountID := destinations[payments[i].Destination]
+		if _, exists := created[accountID]; !exists {
+			created[accountID] = i
+		}
+	}
+
+	for i, payment := range payments {
+		for _, accountID := range []string{destinations[payment.Destination], payment.OperationSource} {
+			j, ok := created[accountID]
+			if !ok || j == i {
+				continue
+			}
+			if j > i || payments[j].Source != payment.Source {
+				return i, j
+			}
+		}
+	}
+	return -1, -1
+}
+
+// withoutDuplicates returns payments without the duplicates found by duplicatePayments
+func withoutDuplicates(payments []bridge.BatchPaymentItem, duplicates [][2]int) []bridge.BatchPaymentItem {
+	skip := make(map[int]bool)
+	for _, duplicate := range duplicates {
+		skip[duplicate[0]] = true
+	}
+
+	var result []bridge.BatchPaymentItem
+	for i, payment := range payments {
+		if !skip[i] {
+			result = append(result, payment)
+		}
+	}
+	return result
+}
+
+// distinctSigners returns unique non-empty seeds
+func distinctSigners(seeds []string) []string {
+	var signers []string
+	seen := make(map[string]bool)
+	for _, seed := range seeds {
+		if seed == "" || seen[seed] {
+			continue
+		}
+		seen[seed] = true
+		signers = append(signers, seed)
+	}
+	return signers
+}
+
+// batchItems are stored results of keyed payments of a batch (see loadBatchItems)
+type batchItems struct {
+	// Results of payments left to send, new or failed, by key
+	stored map[string]*entities.BatchItem
+	// Results of payments sent by previous requests
+	skipped []bridge.BatchPaymentItemResult
+	// Transactions of multi-source batches are submitted concurrently
+	mutex sync.Mutex
+}
+
+// get returns the stored result of the payment with key
+func (items *batchItems) get(key string) *entities.BatchItem {
+	items.mutex.Lock()
+	defer items.mutex.Unlock()
+	return items.stored[key]
+}
+
+// set replaces the stored result of the payment with key
+func (items *batchItems) set(key string, item *entities.BatchItem) {
+	items.mutex.Lock()
+	defer items.mutex.Unlock()
+	items.stored[key] = item
+}
+
+// withSkipped returns results of payments sent by previous requests followed by results. It returns
+// nil when items is nil, for batches without keys.
+func (items *batchItems) withSkipped(results []bridge.BatchPaymentItemResult) []bridge.BatchPaymentItemResult {
+	if items == nil {
+		return nil
+	}
+	return append(append([]bridge.BatchPaymentItemResult{}, items.skipped...), results...)
+}
+
+// defaultPendingBatchItemTimeout is the time a pending payment without a stored transaction is
+// considered in progress when `request_timeout` is not set (see resolvePendingBatchItem)
+const defaultPendingBatchItemTimeout = time.Minute
+
+// loadBatchItems loads stored results of payments of the batch by their keys and removes payments
+// that have already been sent successfully from the request. Outcomes of payments left pending by
+// previous requests are checked first (see resolvePendingBatchItem). PaymentIdempotencyKeyConflict
+// error is returned when a key has been used by a payment with different params.
+func (rh *RequestHandler) loadBatchItems(request *bridge.BatchPaymentRequest) (*batchItems, *protocols.ErrorResponse) {
+	items := &batchItems{stored: make(map[string]*entities.BatchItem)}
+	var payments []bridge.BatchPaymentItem
+	// Results of transactions of pending payments by payment ID
+	outcomes := make(map[string]batchTransactionOutcome)
+
+	for i, payment := range request.Payments {
+		requestHash := batchItemHash(request, payment)
+
+		item, err := rh.Repository.GetBatchItemByKey(payment.Key)
+		if err != nil {
+			log.WithFields(log.Fields{"key": payment.Key, "err": err}).Error("Error getting batch item")
+			return nil, protocols.InternalServerError
+		}
+
+		if item != nil && item.RequestHash != requestHash {
+			errorResponse := *bridge.PaymentIdempotencyKeyConflict
+			errorResponse.Data = map[string]interface{}{"name": "payments[" + strconv.Itoa(i) + "][key]"}
+			return nil, &errorResponse
+		}
+
+		if item != nil && item.Status == entities.BatchItemStatusPending {
+			errorResponse := rh.resolvePendingBatchItem(item, outcomes)
+			if errorResponse != nil {
+				return nil, errorResponse
+			}
+		}
+
+		switch {
+		case item == nil:
+			item = &entities.BatchItem{ItemKey: payment.Key, RequestHash: requestHash}
+		case item.Status == entities.BatchItemStatusSuccess:
+			result := batchItemResult(item)
+			result.Skipped = true
+			items.skipped = append(items.skipped, result)
+			continue
+		}
+
+		items.stored[payment.Key] = item
+		payments = append(payments, payment)
+	}
+
+	request.Payments = payments
+	return items, nil
+}
+
+// batchTransactionOutcome is the result of a transaction sending pending payments
+type batchTransactionOutcome struct {
+	// Empty when the transaction has not been submitted
+	hash string
+	// nil when the transaction succeeded
+	errorResponse *protocols.ErrorResponse
+}
+
+// resolvePendingBatchItem updates a payment left pending by a previous request with the result of its
+// transaction, stored by the submitter with the payment ID of the item before it was submitted. The
+// transaction is checked using ResubmitTransaction: its result is returned when it's already in a
+// ledger, otherwise the same transaction is submitted again, so the payment can never be sent twice.
+// Payments without a stored transaction have never been submitted and fail, unless they were marked
+// pending recently and are still being sent by the other request: BatchPaymentInProgress error is
+// returned then. The payment stays pending (and InternalServerError is returned) when the outcome
+// is still unknown. outcomes caches results of transactions by payment ID.
+func (rh *RequestHandler) resolvePendingBatchItem(item *entities.BatchItem, outcomes map[string]batchTransactionOutcome) *protocols.ErrorResponse {
+	var paymentID string
+	if item.PaymentID != nil {
+		paymentID = *item.PaymentID
+	}
+
+	outcome, checked := outcomes[paymentID]
+	if !checked {
+		var sentTransaction *entities.SentTransaction
+		if paymentID != "" {
+			var err error
+			sentTransaction, err = rh.Repository.GetSentTransactionByPaymentID(paymentID)
+			if err != nil {
+				log.WithFields(log.Fields{"paymentID": paymentID, "err": err}).Error("Error getting sent transaction")
+				return protocols.InternalServerError
+			}
+		}
+
+		if sentTransaction == nil {
+			timeout := defaultPendingBatchItemTimeout
+			if rh.Config.RequestTimeout > 0 {
+				timeout = time.Duration(rh.Config.RequestTimeout) * time.Second
+			}
+			if time.Since(item.UpdatedAt) < timeout {
+				return bridge.NewBatchPaymentInProgressError(item.ItemKey)
+			}
+			outcome.errorResponse = protocols.InternalServerError
+		} else {
+			log.WithFields(log.Fields{"key": item.ItemKey, "hash": sentTransaction.TransactionID}).Info("Checking outcome of pending batch payment")
+			response, err := rh.TransactionSubmitter.ResubmitTransaction(sentTransaction.EnvelopeXdr)
+			if err != nil || transactionOutcomeUnknown(response) {
+				log.WithFields(log.Fields{"key": item.ItemKey, "hash": sentTransaction.TransactionID, "err": err}).Error("Outcome of pending batch payment is still unknown")
+				return protocols.InternalServerError
+			}
+
+			outcome.hash = sentTransaction.TransactionID
+			outcome.errorResponse = rh.errorFromHorizonResponse(response)
+		}
+		outcomes[paymentID] = outcome
+	}
+
+	var transactionID *string
+	if outcome.hash != "" {
+		transactionID = &outcome.hash
+	}
+
+	if outcome.errorResponse == nil {
+		item.MarkSucceeded(outcome.hash)
+	} else {
+		item.MarkFailed(transactionID, outcome.errorResponse.Code)
+	}
+
+	err := rh.EntityManager.Persist(item)
+	if err != nil {
+		log.WithFields(log.Fields{"key": item.ItemKey, "err": err}).Warn("Error saving batch item")
+	}
+	return nil
+}
+
+// claimBatchItems marks keyed payments sent in a single transaction pending before it's submitted and
+// returns a new payment ID the transaction must be sent with, so its outcome can be checked when the
+// batch is retried (see resolvePendingBatchItem). Items are inserted anew (failed ones are deleted
+// first) so only one of concurrent requests with the same key can insert one thanks to the unique key.
+// BatchPaymentInProgress error is returned to the others and items claimed before are released.
+func (rh *RequestHandler) claimBatchItems(items *batchItems, payments []bridge.BatchPaymentItem) (*string, *protocols.ErrorResponse) {
+	paymentID := newBatchPaymentID()
+	var claimed []*entities.BatchItem
+
+	for _, payment := range payments {
+		stored := items.get(payment.Key)
+		if !stored.IsNew() {
+			err := rh.EntityManager.Delete(stored)
+			if err != nil {
+				log.WithFields(log.Fields{"key": payment.Key, "err": err}).Error("Error deleting batch item")
+				rh.releaseBatchItems(claimed)
+				return nil, protocols.InternalServerError
+			}
+		}
+
+		item := &entities.BatchItem{ItemKey: payment.Key, RequestHash: stored.RequestHash}
+		item.MarkPending(paymentID, nil)
+		err := rh.EntityManager.Persist(item)
+		if err != nil {
+			rh.releaseBatchItems(claimed)
+
+			// Inserted by another request in the meantime
+			existing, getErr := rh.Repository.GetBatchItemByKey(payment.Key)
+			if getErr == nil && existing != nil {
+				return nil, bridge.NewBatchPaymentInProgressError(payment.Key)
+			}
+
+			log.WithFields(log.Fields{"key": payment.Key, "err": err}).Error("Error saving batch item")
+			return nil, protocols.InternalServerError
+		}
+
+		claimed = append(claimed, item)
+		items.set(payment.Key, item)
+	}
+
+	return &paymentID, nil
+}
+
+// releaseBatchItems deletes pending items of a transaction that will not be submitted. Errors are
+// only logged, such items fail when the batch is retried after they time out.
+func (rh *RequestHandler) releaseBatchItems(claimed []*entities.BatchItem) {
+	for _, item := range claimed {
+		err := rh.EntityManager.Delete(item)
+		if err != nil {
+			log.WithFields(log.Fields{"key": item.ItemKey, "err": err}).Warn("Error deleting batch item")
+		}
+	}
+}
+
+// newBatchPaymentID returns a random payment ID of a transaction sending keyed payments
+func newBatchPaymentID() string {
+	id := make([]byte, 16)
+	rand.Read(id)
+	return "batch-" + hex.EncodeToString(id)
+}
+
+// saveBatchItems saves results of keyed payments sent in a single transaction with paymentID and
+// returns them. response is nil when the transaction has not been submitted or submission returned
+// an error: payments fail with errorResponse then, unless the transaction has been stored with
+// paymentID so it may have been submitted. Such payments, and payments of a transaction with unknown
+// outcome (ex. Horizon timed out waiting for the ledger), stay pending and their outcome is checked
+// when the batch is retried. errorResponse is nil when the transaction succeeded. Errors are only
+// logged as the transaction has already been submitted.
+func (rh *RequestHandler) saveBatchItems(items *batchItems, payments []bridge.BatchPaymentItem, paymentID *string, response *horizon.SubmitTransactionResponse, errorResponse *protocols.ErrorResponse) []bridge.BatchPaymentItemResult {
+	if items == nil {
+		return nil
+	}
+
+	var hash *string
+	pending := false
+	switch {
+	case response != nil:
+		hash = &response.Hash
+		pending = errorResponse == nil && transactionOutcomeUnknown(*response)
+	case paymentID != nil:
+		sentTransaction, err := rh.Repository.GetSentTransactionByPaymentID(*paymentID)
+		if err != nil || sentTransaction != nil {
+			log.WithFields(log.Fields{"paymentID": *paymentID, "err": err}).Warn("Transaction may have been submitted, batch payments left pending")
+			pending = true
+		}
+		if sentTransaction != nil {
+			hash = &sentTransaction.TransactionID
+		}
+	}
+
+	var results []bridge.BatchPaymentItemResult
+	for _, payment := range payments {
+		item := items.get(payment.Key)
+		switch {
+		case pending:
+			item.MarkPending(*paymentID, hash)
+		case errorResponse == nil:
+			item.MarkSucceeded(*hash)
+		default:
+			item.MarkFailed(hash, errorResponse.Code)
+		}
+
+		err := rh.EntityManager.Persist(item)
+		if err != nil {
+			log.WithFields(log.Fields{"key": item.ItemKey, "err": err}).Warn("Error saving batch item")
+		}
+		results = append(results, batchItemResult(item))
+	}
+	return results
+}
+
+// transactionOutcomeUnknown checks if the transaction of the submit response has neither been included
+// in a ledger nor rejected
+func transactionOutcomeUnknown(response horizon.SubmitTransactionResponse) bool {
+	return response.Ledger == nil && response.Extras == nil
+}
+
+// unsentBatchItemResults returns results of keyed payments that have not been sent because of errorResponse.
+// Nothing is stored, their stored results (if any) are not changed.
+func unsentBatchItemResults(payments []bridge.BatchPaymentItem, errorResponse *protocols.ErrorResponse) []bridge.BatchPaymentItemResult {
+	var results []bridge.BatchPaymentItemResult
+	for _, payment := range payments {
+		results = append(results, bridge.BatchPaymentItemResult{
+			Key:    payment.Key,
+			Status: string(entities.BatchItemStatusFailure),
+			Error:  errorResponse.Code,
+		})
+	}
+	return results
+}
+
+// batchItemResult converts a stored result of a keyed payment to the response
+func batchItemResult(item *entities.BatchItem) bridge.BatchPaymentItemResult {
+	result := bridge.BatchPaymentItemResult{
+		Key:    item.ItemKey,
+		Status: string(item.Status),
+	}
+	if item.TransactionID != nil {
+		result.Hash = *item.TransactionID
+	}
+	if item.Error != nil {
+		result.Error = *item.Error
+	}
+	return result
+}
+
+// batchRequestHash returns hex encoded SHA-256 hash of normalized params of the batch, used to detect
+// reuse of the batch ID with different params. Params that do not change sent transactions are
+// ignored. Transaction sources of payments must be set.
+func batchRequestHash(request *bridge.BatchPaymentRequest) string {
+	values := url.Values{
+		"per_op_fee": {strconv.FormatUint(request.PerOpFee, 10)},
+	}
+	for _, payment := range request.Payments {
+		values.Add("payment", batchItemHash(request, payment))
+	}
+
+	hash := sha256.Sum256([]byte(values.Encode()))
+	return hex.EncodeToString(hash[:])
+}
+
+// checkBatchID returns PaymentIdempotencyKeyConflict error when transactions have been sent with the
+// batch ID (the ID itself or `<id>-0`, the first of split or multi-source transactions) by a previous
+// request with different params
+func (rh *RequestHandler) checkBatchID(id, requestHash string) *protocols.ErrorResponse {
+	for _, paymentID := range []string{id, id + "-0"} {
+		sentTransaction, err := rh.Repository.GetSentTransactionByPaymentID(paymentID)
+		if err != nil {
+			log.WithFields(log.Fields{"paymentID": paymentID, "err": err}).Error("Error getting sent transaction")
+			return protocols.InternalServerError
+		}
+
+		if sentTransaction != nil && sentTransaction.RequestHash != nil && *sentTransaction.RequestHash != requestHash {
+			return bridge.PaymentIdempotencyKeyConflict
+		}
+	}
+	return nil
+}
+
+// submitBatchTransaction submits a transaction of the batch like TransactionSubmitter.SubmitTransaction
+// and stores requestHash with it. When a transaction has already been sent with paymentID by a previous
+// request it is resubmitted instead (its result is returned when it's already in a ledger).
+func (rh *RequestHandler) submitBatchTransaction(paymentID *string, requestHash, source string, operations bridge.Operations, memo interface{}, signers ...string) (horizon.SubmitTransactionResponse, error) {
+	if paymentID != nil {
+		sentTransaction, err := rh.Repository.GetSentTransactionByPaymentID(*paymentID)
+		if err != nil {
+			return horizon.SubmitTransactionResponse{}, err
+		}
+
+		if sentTransaction != nil {
+			log.WithFields(log.Fields{"paymentID": *paymentID, "tx": sentTransaction.EnvelopeXdr}).Info("Transaction with given ID already exists, resubmitting...")
+			return rh.TransactionSubmitter.ResubmitTransaction(sentTransaction.EnvelopeXdr)
+		}
+	}
+
+	response, err := rh.TransactionSubmitter.SubmitTransaction(paymentID, source, operations, memo, signers...)
+	rh.saveRequestHash(paymentID, requestHash)
+	return response, err
+}
+
+// batchItemHash returns hex encoded SHA-256 hash of normalized params of a payment of the batch,
+// including the transaction memo. The secret seed of the transaction source is replaced with its address.
+func batchItemHash(request *bridge.BatchPaymentRequest, payment bridge.BatchPaymentItem) string {
+	values := url.Values{
+		"destination":      {payment.Destination},
+		"amount":           {payment.Amount},
+		"asset_code":       {payment.AssetCode},
+		"asset_issuer":     {payment.AssetIssuer},
+		"operation_source": {payment.OperationSource},
+		"source":           {payment.Source},
+		"operation":        {payment.Operation},
+		"memo_type":        {request.MemoType},
+		"memo":             {request.Memo},
+	}
+
+	if kp, err := keypair.Parse(payment.Source); err == nil {
+		values.Set("source", kp.Address())
+	}
+
+	if value, err := amount.Parse(payment.Amount); err == nil {
+		values.Set("amount", amount.String(value))
+	}
+
+	hash := sha256.Sum256([]byte(values.Encode()))
+	return hex.EncodeToString(hash[:])
+}
+
+// withBatchItems returns a copy of errorResponse with results of keyed payments added to its data
+func withBatchItems(errorResponse *protocols.ErrorResponse, results []bridge.BatchPaymentItemResult) *protocols.ErrorResponse {
+	if len(results) == 0 {
+		return errorResponse
+	}
+
+	response := *errorResponse
+	response.Data = map[string]interface{}{}
+	for k, v := range errorResponse.Data {
+		response.Data[k] = v
+	}
+	response.Data["items"] = results
+	return &response
+}
+
+// withSubmittedTransactions returns a copy of errorResponse with hashes of already submitted transactions added to its data
+func withSubmittedTransactions(errorResponse *protocols.ErrorResponse, submitted []string) *protocols.ErrorResponse {
+	if len(submitted) == 0 {
+		return errorResponse
+	}
+
+	response := *errorResponse
+	response.Data = map[string]interface{}{}
+	for k, v := range errorResponse.Data {
+		response.Data[k] = v
+	}
+	response.Data["submitted_transactions"] = submitted
+	return &response
+}
+
+// resolveDestinations resolves all unique destinations of the batch concurrently, running at most
+// `batch.federation_concurrency` federation lookups at a time. It returns a map of destination
+// to account ID and a map of destination to error message for destinations that failed.
+func (rh *RequestHandler) resolveDestinations(payments []bridge.BatchPaymentItem) (map[string]string, map[string]string) {
+	resolved := make(map[string]string)
+	failed := make(map[string]string)
+	var toResolve []string
+
+	for _, payment := range payments {
+		if _, exists := resolved[payment.Destination]; exists {
+			continue
+		}
+
+		_, _, err := address.Split(payment.Destination)
+		if err != nil {
+			// Not a stellar address, use as account ID
+			resolved[payment.Destination] = payment.Destination
+		} else {
+			resolved[payment.Destination] = ""
+			toResolve = append(toResolve, payment.Destination)
+		}
+	}
+
+	concurrency := rh.Config.Batch.FederationConcurrency
+	if concurrency < 1 {
+		concurrency = 1
+	}
+
+	var mutex sync.Mutex
+	var wg sync.WaitGroup
+	jobs := make(chan string)
+
+	for i := 0; i < concurrency && i < len(toResolve); i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for destination := range jobs {
+				response, err := rh.FederationResolver.LookupByAddress(destination)
+
+				mutex.Lock()
+				switch {
+				case err != nil:
+					log.WithFields(log.Fields{"destination": destination, "err": err}).Print("Cannot resolve address")
+					failed[destination] = federationLookupError(err).Message
+				case response.MemoType != "":
+					// All payments share a single transaction memo
+					failed[destination] = "Destination requires a memo and cannot be paid in a batch."
+				default:
+					resolved[destination] = response.AccountID
+				}
+				mutex.Unlock()
+			}
+		}()
+	}
+
+	for _, destination := range toResolve {
+		jobs <- destination
+	}
+	close(jobs)
+	wg.Wait()
+
+	for destination, accountID := range resolved {
+		if _, exists := failed[destination]; exists {
+			continue
+		}
+		if !protocols.IsValidAccountID(accountID) {
+			failed[destination] = "Destination public key must start with `G`."
+		}
+	}
+
+	return resolved, failed
+}
+
+// batchPaymentInvalidAmount returns PaymentInvalidAmount error of the i-th payment of the batch
+func batchPaymentInvalidAmount(i int) *protocols.ErrorResponse {
+	errorResponse := *bridge.PaymentInvalidAmount
+	errorResponse.Data = map[string]interface{}{"name": "payments[" + strconv.Itoa(i) + "][amount]"}
+	return &errorResponse
+}
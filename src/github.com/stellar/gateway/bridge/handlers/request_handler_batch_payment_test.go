@@ -0,0 +1,1386 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	. "github.com/smartystreets/goconvey/convey"
+	"github.com/stellar/gateway/bridge/config"
+	"github.com/stellar/gateway/db/entities"
+	"github.com/stellar/gateway/events"
+	"github.com/stellar/gateway/horizon"
+	"github.com/stellar/gateway/mocks"
+	"github.com/stellar/gateway/net"
+	"github.com/stellar/gateway/protocols/bridge"
+	"github.com/stellar/gateway/test"
+	"github.com/stellar/go/build"
+	"github.com/stellar/go/keypair"
+	"github.com/stellar/go/protocols/federation"
+	"github.com/stellar/go/xdr"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRequestHandlerBatchPayment(t *testing.T) {
+	c := &config.Config{
+		NetworkPassphrase: "Test SDF Network ; September 2015",
+		Accounts: config.Accounts{
+			BaseSeed: "SBKKWO3ZVDDEHDJILGHPHCJCFD2GNUAYIUDMRAS326HLUEQ7ZFXWIGQK",
+		},
+		Batch: config.Batch{
+			FederationConcurrency: 2,
+		},
+	}
+	mockHorizon := new(mocks.MockHorizon)
+	mockTransactionSubmitter := new(mocks.MockTransactionSubmitter)
+	mockFederationResolver := new(mocks.MockFederationResolver)
+	mockRepository := new(mocks.MockRepository)
+	mockEntityManager := new(mocks.MockEntityManager)
+
+	requestHandler := RequestHandler{
+		Config:               c,
+		Horizon:              mockHorizon,
+		TransactionSubmitter: mockTransactionSubmitter,
+		FederationResolver:   mockFederationResolver,
+		Repository:           mockRepository,
+		EntityManager:        mockEntityManager,
+	}
+
+	testServer := httptest.NewServer(http.HandlerFunc(requestHandler.BatchPayment))
+	defer testServer.Close()
+
+	Convey("Given batch payment request", t, func() {
+		Convey("When payments are empty", func() {
+			data := test.StringToJSONMap(`{"payments": []}`)
+
+			Convey("it should return error", func() {
+				statusCode, response := net.JSONGetResponse(testServer, data)
+				responseString := strings.TrimSpace(string(response))
+				assert.Equal(t, 400, statusCode)
+				expected := test.StringToJSONMap(`{
+  "code": "batch_empty",
+  "error_code": 400,
+  "message": "Batch must contain at least one payment."
+}`)
+				assert.Equal(t, expected, test.StringToJSONMap(responseString))
+			})
+		})
+
+		Convey("When one of payments has zero amount", func() {
+			data := test.StringToJSONMap(`{
+  "payments": [
+    {"destination": "GC56HD5MFIQTDSETNOJ7LARBUDPUPK6WHNGMYRTLHLK5H4LJ7BJHLDYP", "amount": "1"},
+    {"destination": "GC56HD5MFIQTDSETNOJ7LARBUDPUPK6WHNGMYRTLHLK5H4LJ7BJHLDYP", "amount": "0"}
+  ]
+}`)
+
+			Convey("it should return error", func() {
+				statusCode, response := net.JSONGetResponse(testServer, data)
+				responseString := strings.TrimSpace(string(response))
+				assert.Equal(t, 400, statusCode)
+				expected := test.StringToJSONMap(`{
+  "code": "payment_invalid_amount",
+  "error_code": 354,
+  "message": "Amount must be greater than zero. Zero amount payments are not allowed and accounts cannot be created with zero starting balance.",
+  "data": {"name": "payments[1][amount]"}
+}`)
+				assert.Equal(t, expected, test.StringToJSONMap(responseString))
+			})
+		})
+
+		Convey("When one of payments has more decimals than allowed for the asset", func() {
+			decimals := 0
+			c.Assets = []config.Asset{{Code: "XLM", DisplayDecimals: &decimals}}
+			Reset(func() { c.Assets = nil })
+
+			data := test.StringToJSONMap(`{
+  "payments": [
+    {"destination": "GC56HD5MFIQTDSETNOJ7LARBUDPUPK6WHNGMYRTLHLK5H4LJ7BJHLDYP", "amount": "1"},
+    {"destination": "GC56HD5MFIQTDSETNOJ7LARBUDPUPK6WHNGMYRTLHLK5H4LJ7BJHLDYP", "amount": "1.5"}
+  ]
+}`)
+
+			Convey("it should return error", func() {
+				statusCode, response := net.JSONGetResponse(testServer, data)
+				responseString := strings.TrimSpace(string(response))
+				assert.Equal(t, 400, statusCode)
+				expected := test.StringToJSONMap(`{
+  "code": "invalid_parameter",
+  "error_code": 101,
+  "message": "Invalid parameter.",
+  "data": {
+    "name": "payments[1][amount]"
+  }
+}`)
+				assert.Equal(t, expected, test.StringToJSONMap(responseString, "more_info"))
+			})
+		})
+
+		Convey("When one of payments sends asset not allowed by asset allow list", func() {
+			c.AssetAllowList = config.AssetAllowList{Enabled: true, Native: true}
+			Reset(func() { c.AssetAllowList = config.AssetAllowList{} })
+
+			data := test.StringToJSONMap(`{
+  "payments": [
+    {"destination": "GC56HD5MFIQTDSETNOJ7LARBUDPUPK6WHNGMYRTLHLK5H4LJ7BJHLDYP", "amount": "1"},
+    {"destination": "GC56HD5MFIQTDSETNOJ7LARBUDPUPK6WHNGMYRTLHLK5H4LJ7BJHLDYP", "amount": "1", "asset_code": "USD", "asset_issuer": "GBDOSO3K4JTGSWJSIHXAOFIBMAABVM3YK3FI6VJPKIHHM56XAFIUCGD6"}
+  ]
+}`)
+
+			Convey("it should return error", func() {
+				statusCode, response := net.JSONGetResponse(testServer, data)
+				responseString := strings.TrimSpace(string(response))
+				assert.Equal(t, 400, statusCode)
+				expected := test.StringToJSONMap(`{
+  "code": "asset_not_allowed",
+  "error_code": 314,
+  "message": "Asset is not allowed by this server.",
+  "data": {
+    "asset_code": "USD",
+    "asset_issuer": "GBDOSO3K4JTGSWJSIHXAOFIBMAABVM3YK3FI6VJPKIHHM56XAFIUCGD6"
+  }
+}`)
+				assert.Equal(t, expected, test.StringToJSONMap(responseString))
+			})
+		})
+
+		Convey("When payment has unknown fields and unknown params are rejected", func() {
+			c.UnknownParams = "reject"
+			Reset(func() { c.UnknownParams = "" })
+
+			data := test.StringToJSONMap(`{
+  "payments": [
+    {"destnation": "GC56HD5MFIQTDSETNOJ7LARBUDPUPK6WHNGMYRTLHLK5H4LJ7BJHLDYP", "amount": "1"}
+  ]
+}`)
+
+			Convey("it should return error", func() {
+				statusCode, response := net.JSONGetResponse(testServer, data)
+				responseString := strings.TrimSpace(string(response))
+				assert.Equal(t, 400, statusCode)
+				expected := test.StringToJSONMap(`{
+  "code": "invalid_parameter",
+  "error_code": 101,
+  "message": "Invalid parameter.",
+  "data": {
+    "name": "payments[0][destnation]"
+  }
+}`)
+				assert.Equal(t, expected, test.StringToJSONMap(responseString, "more_info"))
+			})
+		})
+
+		Convey("When some destinations cannot be resolved", func() {
+			data := test.StringToJSONMap(`{
+  "payments": [
+    {"destination": "alice*stellar.org", "amount": "1", "asset_code": "USD", "asset_issuer": "GDSIKW43UA6JTOA47WVEBCZ4MYC74M3GNKNXTVDXFHXYYTNO5GGVN632"},
+    {"destination": "bob*stellar.org", "amount": "2", "asset_code": "USD", "asset_issuer": "GDSIKW43UA6JTOA47WVEBCZ4MYC74M3GNKNXTVDXFHXYYTNO5GGVN632"},
+    {"destination": "carol*stellar.org", "amount": "3", "asset_code": "USD", "asset_issuer": "GDSIKW43UA6JTOA47WVEBCZ4MYC74M3GNKNXTVDXFHXYYTNO5GGVN632"}
+  ]
+}`)
+
+			mockFederationResolver.On("LookupByAddress", "alice*stellar.org").Return(
+				&federation.NameResponse{AccountID: "GAPCT362RATBUJ37RN2MOKQIZLHSJMO33MMCSRUXTTHIGVDYWOFG5HDS"},
+				nil,
+			).Once()
+			mockFederationResolver.On("LookupByAddress", "bob*stellar.org").Return(
+				&federation.NameResponse{},
+				errors.New("federation error"),
+			).Once()
+			mockFederationResolver.On("LookupByAddress", "carol*stellar.org").Return(
+				&federation.NameResponse{
+					AccountID: "GDSIKW43UA6JTOA47WVEBCZ4MYC74M3GNKNXTVDXFHXYYTNO5GGVN632",
+					MemoType:  "id",
+					Memo:      federation.Memo{"1"},
+				},
+				nil,
+			).Once()
+
+			Convey("it should return error listing failed destinations", func() {
+				statusCode, response := net.JSONGetResponse(testServer, data)
+				responseString := strings.TrimSpace(string(response))
+				assert.Equal(t, 400, statusCode)
+				expected := test.StringToJSONMap(`{
+  "code": "cannot_resolve_destinations",
+  "error_code": 401,
+  "message": "Cannot resolve one or more destinations.",
+  "data": {
+    "destinations": {
+      "bob*stellar.org": "Cannot resolve federated Stellar address.",
+      "carol*stellar.org": "Destination requires a memo and cannot be paid in a batch."
+    }
+  }
+}`)
+				assert.Equal(t, expected, test.StringToJSONMap(responseString))
+			})
+		})
+
+		Convey("When all destinations are resolved", func() {
+			data := test.StringToJSONMap(`{
+  "payments": [
+    {"destination": "alice*stellar.org", "amount": "1", "asset_code": "USD", "asset_issuer": "GDSIKW43UA6JTOA47WVEBCZ4MYC74M3GNKNXTVDXFHXYYTNO5GGVN632"},
+    {"destination": "alice*stellar.org", "amount": "2", "asset_code": "USD", "asset_issuer": "GDSIKW43UA6JTOA47WVEBCZ4MYC74M3GNKNXTVDXFHXYYTNO5GGVN632"},
+    {"destination": "GDSIKW43UA6JTOA47WVEBCZ4MYC74M3GNKNXTVDXFHXYYTNO5GGVN632", "amount": "3", "asset_code": "USD", "asset_issuer": "GDSIKW43UA6JTOA47WVEBCZ4MYC74M3GNKNXTVDXFHXYYTNO5GGVN632"}
+  ]
+}`)
+
+			// Duplicated destinations are resolved once
+			mockFederationResolver.On("LookupByAddress", "alice*stellar.org").Return(
+				&federation.NameResponse{AccountID: "GAPCT362RATBUJ37RN2MOKQIZLHSJMO33MMCSRUXTTHIGVDYWOFG5HDS"},
+				nil,
+			).Once()
+
+			var ledger uint64 = 1988728
+			horizonResponse := horizon.SubmitTransactionResponse{Hash: "6a0049b44e0d0341bd52f131c74383e6ccd2b74b92c829c990994d24bbfcfa7a", Ledger: &ledger}
+
+			mockTransactionSubmitter.On(
+				"SubmitTransaction",
+				(*string)(nil),
+				c.Accounts.BaseSeed,
+				mock.AnythingOfType("bridge.Operations"),
+				nil,
+			).Run(func(args mock.Arguments) {
+				operations := args.Get(2).(bridge.Operations)
+				require.Len(t, operations, 3)
+				destination := func(i int) string {
+					accountID := operations[i].(build.PaymentBuilder).P.Destination
+					return accountID.Address()
+				}
+				assert.Equal(t, "GAPCT362RATBUJ37RN2MOKQIZLHSJMO33MMCSRUXTTHIGVDYWOFG5HDS", destination(0))
+				assert.Equal(t, "GAPCT362RATBUJ37RN2MOKQIZLHSJMO33MMCSRUXTTHIGVDYWOFG5HDS", destination(1))
+				assert.Equal(t, "GDSIKW43UA6JTOA47WVEBCZ4MYC74M3GNKNXTVDXFHXYYTNO5GGVN632", destination(2))
+			}).Return(horizonResponse, nil).Once()
+
+			Convey("it should submit a single transaction", func() {
+				statusCode, response := net.JSONGetResponse(testServer, data)
+				responseString := strings.TrimSpace(string(response))
+				assert.Equal(t, 200, statusCode)
+				expected := test.StringToJSONMap(`{
+  "hash": "6a0049b44e0d0341bd52f131c74383e6ccd2b74b92c829c990994d24bbfcfa7a",
+  "ledger": 1988728
+}`)
+				assert.Equal(t, expected, test.StringToJSONMap(responseString))
+			})
+		})
+	})
+
+	Convey("Given batch payment request exceeding max operations", t, func() {
+		c.Batch.MaxOperations = 2
+		Reset(func() {
+			c.Batch.MaxOperations = 0
+			c.Batch.SplitTransactions = false
+		})
+
+		data := test.StringToJSONMap(`{
+  "id": "batch",
+  "payments": [
+    {"destination": "GAPCT362RATBUJ37RN2MOKQIZLHSJMO33MMCSRUXTTHIGVDYWOFG5HDS", "amount": "1", "asset_code": "USD", "asset_issuer": "GDSIKW43UA6JTOA47WVEBCZ4MYC74M3GNKNXTVDXFHXYYTNO5GGVN632"},
+    {"destination": "GAPCT362RATBUJ37RN2MOKQIZLHSJMO33MMCSRUXTTHIGVDYWOFG5HDS", "amount": "2", "asset_code": "USD", "asset_issuer": "GDSIKW43UA6JTOA47WVEBCZ4MYC74M3GNKNXTVDXFHXYYTNO5GGVN632"},
+    {"destination": "GAPCT362RATBUJ37RN2MOKQIZLHSJMO33MMCSRUXTTHIGVDYWOFG5HDS", "amount": "3", "asset_code": "USD", "asset_issuer": "GDSIKW43UA6JTOA47WVEBCZ4MYC74M3GNKNXTVDXFHXYYTNO5GGVN632"}
+  ]
+}`)
+
+		// Batch ID has not been used
+		mockRepository.On("GetSentTransactionByPaymentID", "batch").Return(nil, nil).Once()
+		mockRepository.On("GetSentTransactionByPaymentID", "batch-0").Return(nil, nil).Once()
+
+		Convey("When splitting is disabled", func() {
+			Convey("it should return error", func() {
+				statusCode, response := net.JSONGetResponse(testServer, data)
+				responseString := strings.TrimSpace(string(response))
+				assert.Equal(t, 400, statusCode)
+				expected := test.StringToJSONMap(`{
+  "code": "batch_too_many_operations",
+  "error_code": 402,
+  "message": "Batch exceeds maximum number of operations in a transaction.",
+  "data": {
+    "max_operations": 2
+  }
+}`)
+				assert.Equal(t, expected, test.StringToJSONMap(responseString))
+			})
+		})
+
+		Convey("When splitting is enabled", func() {
+			c.Batch.SplitTransactions = true
+
+			var ledger uint64 = 1988728
+			firstID := "batch-0"
+			secondID := "batch-1"
+
+			mockRepository.On("GetSentTransactionByPaymentID", firstID).Return(nil, nil).Twice()
+			mockRepository.On("GetSentTransactionByPaymentID", secondID).Return(nil, nil).Twice()
+
+			mockTransactionSubmitter.On(
+				"SubmitTransaction",
+				&firstID,
+				c.Accounts.BaseSeed,
+				mock.AnythingOfType("bridge.Operations"),
+				nil,
+			).Run(func(args mock.Arguments) {
+				assert.Len(t, args.Get(2).(bridge.Operations), 2)
+			}).Return(horizon.SubmitTransactionResponse{Hash: "a", Ledger: &ledger}, nil).Once()
+
+			mockTransactionSubmitter.On(
+				"SubmitTransaction",
+				&secondID,
+				c.Accounts.BaseSeed,
+				mock.AnythingOfType("bridge.Operations"),
+				nil,
+			).Run(func(args mock.Arguments) {
+				assert.Len(t, args.Get(2).(bridge.Operations), 1)
+			}).Return(horizon.SubmitTransactionResponse{Hash: "b", Ledger: &ledger}, nil).Once()
+
+			Convey("it should submit multiple transactions", func() {
+				statusCode, response := net.JSONGetResponse(testServer, data)
+				responseString := strings.TrimSpace(string(response))
+				assert.Equal(t, 200, statusCode)
+				expected := test.StringToJSONMap(`{
+  "transactions": [
+    {"hash": "a", "ledger": 1988728},
+    {"hash": "b", "ledger": 1988728}
+  ]
+}`)
+				assert.Equal(t, expected, test.StringToJSONMap(responseString))
+			})
+		})
+	})
+
+	Convey("Given batch payment request with operation sources", t, func() {
+		c.Assets = []config.Asset{
+			{
+				Code:   "USD",
+				Issuer: "GDSIKW43UA6JTOA47WVEBCZ4MYC74M3GNKNXTVDXFHXYYTNO5GGVN632",
+				// GCF3WVYTHF75PEG6622G5G6KU26GOSDQPDHSCJ3DQD7VONH4EYVDOGKJ
+				Seed: "SDWLS4G3XCNIYPKXJWWGGJT6UDY63WV6PEFTWP7JZMQB4RE7EUJQN5XM",
+			},
+		}
+		Reset(func() {
+			c.Assets = nil
+		})
+
+		Convey("When seed of operation source is in the config", func() {
+			data := test.StringToJSONMap(`{
+  "source": "SBKKWO3ZVDDEHDJILGHPHCJCFD2GNUAYIUDMRAS326HLUEQ7ZFXWIGQK",
+  "payments": [
+    {"destination": "GAPCT362RATBUJ37RN2MOKQIZLHSJMO33MMCSRUXTTHIGVDYWOFG5HDS", "amount": "1", "asset_code": "USD", "asset_issuer": "GDSIKW43UA6JTOA47WVEBCZ4MYC74M3GNKNXTVDXFHXYYTNO5GGVN632", "operation_source": "GCF3WVYTHF75PEG6622G5G6KU26GOSDQPDHSCJ3DQD7VONH4EYVDOGKJ"},
+    {"destination": "GAPCT362RATBUJ37RN2MOKQIZLHSJMO33MMCSRUXTTHIGVDYWOFG5HDS", "amount": "2", "asset_code": "USD", "asset_issuer": "GDSIKW43UA6JTOA47WVEBCZ4MYC74M3GNKNXTVDXFHXYYTNO5GGVN632", "operation_source": "GCF3WVYTHF75PEG6622G5G6KU26GOSDQPDHSCJ3DQD7VONH4EYVDOGKJ"},
+    {"destination": "GAPCT362RATBUJ37RN2MOKQIZLHSJMO33MMCSRUXTTHIGVDYWOFG5HDS", "amount": "3", "asset_code": "USD", "asset_issuer": "GDSIKW43UA6JTOA47WVEBCZ4MYC74M3GNKNXTVDXFHXYYTNO5GGVN632"}
+  ]
+}`)
+
+			var ledger uint64 = 1988728
+			mockTransactionSubmitter.On(
+				"SubmitTransaction",
+				(*string)(nil),
+				c.Accounts.BaseSeed,
+				mock.AnythingOfType("bridge.Operations"),
+				nil,
+				[]string{"SDWLS4G3XCNIYPKXJWWGGJT6UDY63WV6PEFTWP7JZMQB4RE7EUJQN5XM"},
+			).Run(func(args mock.Arguments) {
+				operations := args.Get(2).(bridge.Operations)
+				require.Len(t, operations, 3)
+				assert.Equal(t, "GCF3WVYTHF75PEG6622G5G6KU26GOSDQPDHSCJ3DQD7VONH4EYVDOGKJ", operations[0].(build.PaymentBuilder).O.SourceAccount.Address())
+				assert.Equal(t, "GCF3WVYTHF75PEG6622G5G6KU26GOSDQPDHSCJ3DQD7VONH4EYVDOGKJ", operations[1].(build.PaymentBuilder).O.SourceAccount.Address())
+				assert.Nil(t, operations[2].(build.PaymentBuilder).O.SourceAccount)
+			}).Return(horizon.SubmitTransactionResponse{Hash: "a", Ledger: &ledger}, nil).Once()
+
+			Convey("it should sign transaction with operation sources seeds", func() {
+				statusCode, _ := net.JSONGetResponse(testServer, data)
+				assert.Equal(t, 200, statusCode)
+			})
+		})
+
+		Convey("When source is not sent", func() {
+			data := test.StringToJSONMap(`{
+  "payments": [
+    {"destination": "GAPCT362RATBUJ37RN2MOKQIZLHSJMO33MMCSRUXTTHIGVDYWOFG5HDS", "amount": "1", "asset_code": "USD", "asset_issuer": "GDSIKW43UA6JTOA47WVEBCZ4MYC74M3GNKNXTVDXFHXYYTNO5GGVN632"}
+  ]
+}`)
+
+			var ledger uint64 = 1988728
+			mockTransactionSubmitter.On(
+				"SubmitTransaction",
+				(*string)(nil),
+				"SDWLS4G3XCNIYPKXJWWGGJT6UDY63WV6PEFTWP7JZMQB4RE7EUJQN5XM",
+				mock.AnythingOfType("bridge.Operations"),
+				nil,
+			).Return(horizon.SubmitTransactionResponse{Hash: "a", Ledger: &ledger}, nil).Once()
+
+			Convey("it should send the payment from the seed of the asset", func() {
+				statusCode, _ := net.JSONGetResponse(testServer, data)
+				assert.Equal(t, 200, statusCode)
+			})
+		})
+
+		Convey("When seed of operation source is not in the config", func() {
+			data := test.StringToJSONMap(`{
+  "payments": [
+    {"destination": "GAPCT362RATBUJ37RN2MOKQIZLHSJMO33MMCSRUXTTHIGVDYWOFG5HDS", "amount": "1", "asset_code": "USD", "asset_issuer": "GDSIKW43UA6JTOA47WVEBCZ4MYC74M3GNKNXTVDXFHXYYTNO5GGVN632", "operation_source": "GAPCT362RATBUJ37RN2MOKQIZLHSJMO33MMCSRUXTTHIGVDYWOFG5HDS"}
+  ]
+}`)
+
+			Convey("it should return error", func() {
+				statusCode, response := net.JSONGetResponse(testServer, data)
+				responseString := strings.TrimSpace(string(response))
+				assert.Equal(t, 400, statusCode)
+				expected := test.StringToJSONMap(`{
+  "code": "invalid_parameter",
+  "error_code": 101,
+  "message": "Invalid parameter.",
+  "data": {
+    "name": "payments[0][operation_source]"
+  }
+}`)
+				assert.Equal(t, expected, test.StringToJSONMap(responseString, "more_info"))
+			})
+		})
+	})
+
+	Convey("Given batch payment request with multiple source accounts", t, func() {
+		c.Batch.SourceConcurrency = 2
+		Reset(func() {
+			c.Batch.SourceConcurrency = 0
+		})
+
+		// GCF3WVYTHF75PEG6622G5G6KU26GOSDQPDHSCJ3DQD7VONH4EYVDOGKJ
+		otherSeed := "SDWLS4G3XCNIYPKXJWWGGJT6UDY63WV6PEFTWP7JZMQB4RE7EUJQN5XM"
+
+		data := test.StringToJSONMap(`{
+  "id": "batch",
+  "payments": [
+    {"destination": "GAPCT362RATBUJ37RN2MOKQIZLHSJMO33MMCSRUXTTHIGVDYWOFG5HDS", "amount": "1", "asset_code": "USD", "asset_issuer": "GDSIKW43UA6JTOA47WVEBCZ4MYC74M3GNKNXTVDXFHXYYTNO5GGVN632"},
+    {"destination": "GAPCT362RATBUJ37RN2MOKQIZLHSJMO33MMCSRUXTTHIGVDYWOFG5HDS", "amount": "2", "asset_code": "USD", "asset_issuer": "GDSIKW43UA6JTOA47WVEBCZ4MYC74M3GNKNXTVDXFHXYYTNO5GGVN632", "source": "SDWLS4G3XCNIYPKXJWWGGJT6UDY63WV6PEFTWP7JZMQB4RE7EUJQN5XM"},
+    {"destination": "GAPCT362RATBUJ37RN2MOKQIZLHSJMO33MMCSRUXTTHIGVDYWOFG5HDS", "amount": "3", "asset_code": "USD", "asset_issuer": "GDSIKW43UA6JTOA47WVEBCZ4MYC74M3GNKNXTVDXFHXYYTNO5GGVN632"}
+  ]
+}`)
+
+		var ledger uint64 = 1988728
+		firstID := "batch-0"
+		secondID := "batch-1"
+
+		// Batch ID has not been used
+		mockRepository.On("GetSentTransactionByPaymentID", "batch").Return(nil, nil).Once()
+		mockRepository.On("GetSentTransactionByPaymentID", firstID).Return(nil, nil).Once()
+
+		submitFirst := func() {
+			mockRepository.On("GetSentTransactionByPaymentID", firstID).Return(nil, nil).Twice()
+			mockTransactionSubmitter.On(
+				"SubmitTransaction",
+				&firstID,
+				c.Accounts.BaseSeed,
+				mock.AnythingOfType("bridge.Operations"),
+				nil,
+			).Run(func(args mock.Arguments) {
+				assert.Len(t, args.Get(2).(bridge.Operations), 2)
+			}).Return(horizon.SubmitTransactionResponse{Hash: "a", Ledger: &ledger}, nil).Once()
+		}
+
+		Convey("When all transactions succeed", func() {
+			submitFirst()
+			mockRepository.On("GetSentTransactionByPaymentID", secondID).Return(nil, nil).Twice()
+			mockTransactionSubmitter.On(
+				"SubmitTransaction",
+				&secondID,
+				otherSeed,
+				mock.AnythingOfType("bridge.Operations"),
+				nil,
+			).Run(func(args mock.Arguments) {
+				assert.Len(t, args.Get(2).(bridge.Operations), 1)
+			}).Return(horizon.SubmitTransactionResponse{Hash: "b", Ledger: &ledger}, nil).Once()
+
+			Convey("it should submit a transaction per source account", func() {
+				statusCode, response := net.JSONGetResponse(testServer, data)
+				responseString := strings.TrimSpace(string(response))
+				assert.Equal(t, 200, statusCode)
+				expected := test.StringToJSONMap(`{
+  "sources": [
+    {"source": "GAHA6GRCLCCN7XE2NEEUDSIVOFBOQ6GLSYXVLYCJXJKLPMDR5XB5XZZJ", "transaction": {"hash": "a", "ledger": 1988728}},
+    {"source": "GCF3WVYTHF75PEG6622G5G6KU26GOSDQPDHSCJ3DQD7VONH4EYVDOGKJ", "transaction": {"hash": "b", "ledger": 1988728}}
+  ]
+}`)
+				assert.Equal(t, expected, test.StringToJSONMap(responseString))
+			})
+		})
+
+		Convey("When one of the transactions fails", func() {
+			submitFirst()
+			mockRepository.On("GetSentTransactionByPaymentID", secondID).Return(nil, nil).Twice()
+			mockTransactionSubmitter.On(
+				"SubmitTransaction",
+				&secondID,
+				otherSeed,
+				mock.AnythingOfType("bridge.Operations"),
+				nil,
+			).Return(horizon.SubmitTransactionResponse{}, errors.New("connection refused")).Once()
+
+			Convey("it should return error with results of all source accounts", func() {
+				statusCode, response := net.JSONGetResponse(testServer, data)
+				responseString := strings.TrimSpace(string(response))
+				assert.Equal(t, 500, statusCode)
+				expected := test.StringToJSONMap(`{
+  "code": "batch_source_failed",
+  "error_code": 405,
+  "message": "Transactions of one or more source accounts of the batch failed.",
+  "data": {
+    "sources": [
+      {"source": "GAHA6GRCLCCN7XE2NEEUDSIVOFBOQ6GLSYXVLYCJXJKLPMDR5XB5XZZJ", "transaction": {"hash": "a", "ledger": 1988728}},
+      {"source": "GCF3WVYTHF75PEG6622G5G6KU26GOSDQPDHSCJ3DQD7VONH4EYVDOGKJ", "error": {"code": "internal_server_error", "error_code": 100, "message": "Internal Server Error, please try again."}}
+    ]
+  }
+}`)
+				assert.Equal(t, expected, test.StringToJSONMap(responseString))
+			})
+		})
+
+		Convey("When payments of a source account exceed max operations", func() {
+			c.Batch.MaxOperations = 1
+			Reset(func() {
+				c.Batch.MaxOperations = 0
+			})
+
+			Convey("it should return error", func() {
+				statusCode, response := net.JSONGetResponse(testServer, data)
+				responseString := strings.TrimSpace(string(response))
+				assert.Equal(t, 400, statusCode)
+				expected := test.StringToJSONMap(`{
+  "code": "batch_too_many_operations",
+  "error_code": 402,
+  "message": "Batch exceeds maximum number of operations in a transaction.",
+  "data": {
+    "max_operations": 1
+  }
+}`)
+				assert.Equal(t, expected, test.StringToJSONMap(responseString))
+			})
+		})
+	})
+
+	Convey("Given batch payment request with payment keys", t, func() {
+		c.Batch.SourceConcurrency = 2
+		Reset(func() {
+			c.Batch.SourceConcurrency = 0
+		})
+
+		// GCF3WVYTHF75PEG6622G5G6KU26GOSDQPDHSCJ3DQD7VONH4EYVDOGKJ
+		otherSeed := "SDWLS4G3XCNIYPKXJWWGGJT6UDY63WV6PEFTWP7JZMQB4RE7EUJQN5XM"
+
+		body := `{
+  "payments": [
+    {"key": "k1", "destination": "GAPCT362RATBUJ37RN2MOKQIZLHSJMO33MMCSRUXTTHIGVDYWOFG5HDS", "amount": "1", "asset_code": "USD", "asset_issuer": "GDSIKW43UA6JTOA47WVEBCZ4MYC74M3GNKNXTVDXFHXYYTNO5GGVN632"},
+    {"key": "k2", "destination": "GAPCT362RATBUJ37RN2MOKQIZLHSJMO33MMCSRUXTTHIGVDYWOFG5HDS", "amount": "2", "asset_code": "USD", "asset_issuer": "GDSIKW43UA6JTOA47WVEBCZ4MYC74M3GNKNXTVDXFHXYYTNO5GGVN632", "source": "SDWLS4G3XCNIYPKXJWWGGJT6UDY63WV6PEFTWP7JZMQB4RE7EUJQN5XM"},
+    {"key": "k3", "destination": "GAPCT362RATBUJ37RN2MOKQIZLHSJMO33MMCSRUXTTHIGVDYWOFG5HDS", "amount": "3", "asset_code": "USD", "asset_issuer": "GDSIKW43UA6JTOA47WVEBCZ4MYC74M3GNKNXTVDXFHXYYTNO5GGVN632"}
+  ]
+}`
+		data := test.StringToJSONMap(body)
+
+		// Hashes of payments with sources set as in the handler
+		var request bridge.BatchPaymentRequest
+		require.NoError(t, json.Unmarshal([]byte(body), &request))
+		hashes := map[string]string{}
+		for _, payment := range request.Payments {
+			if payment.Source == "" {
+				payment.Source = c.Accounts.BaseSeed
+			}
+			hashes[payment.Key] = batchItemHash(&request, payment)
+		}
+
+		var ledger uint64 = 1988728
+
+		// Keyed payments are sent with random payment IDs
+		isPaymentID := func(id string) bool {
+			return strings.HasPrefix(id, "batch-") && len(id) == len("batch-")+32
+		}
+		paymentID := mock.MatchedBy(isPaymentID)
+		paymentIDPointer := mock.MatchedBy(func(id *string) bool { return id != nil && isPaymentID(*id) })
+
+		Convey("When keys are not unique", func() {
+			data := test.StringToJSONMap(`{
+  "payments": [
+    {"key": "k1", "destination": "GAPCT362RATBUJ37RN2MOKQIZLHSJMO33MMCSRUXTTHIGVDYWOFG5HDS", "amount": "1"},
+    {"key": "k1", "destination": "GAPCT362RATBUJ37RN2MOKQIZLHSJMO33MMCSRUXTTHIGVDYWOFG5HDS", "amount": "2"}
+  ]
+}`)
+
+			Convey("it should return error", func() {
+				statusCode, response := net.JSONGetResponse(testServer, data)
+				responseString := strings.TrimSpace(string(response))
+				assert.Equal(t, 400, statusCode)
+				expected := test.StringToJSONMap(`{
+  "code": "invalid_parameter",
+  "error_code": 101,
+  "message": "Invalid parameter.",
+  "more_info": "Key must be unique within the batch.",
+  "data": {
+    "name": "payments[1][key]"
+  }
+}`)
+				assert.Equal(t, expected, test.StringToJSONMap(responseString))
+			})
+		})
+
+		Convey("When only some payments have keys", func() {
+			data := test.StringToJSONMap(`{
+  "payments": [
+    {"key": "k1", "destination": "GAPCT362RATBUJ37RN2MOKQIZLHSJMO33MMCSRUXTTHIGVDYWOFG5HDS", "amount": "1"},
+    {"destination": "GAPCT362RATBUJ37RN2MOKQIZLHSJMO33MMCSRUXTTHIGVDYWOFG5HDS", "amount": "2"}
+  ]
+}`)
+
+			Convey("it should return error", func() {
+				statusCode, response := net.JSONGetResponse(testServer, data)
+				assert.Equal(t, 400, statusCode)
+				responseMap := test.StringToJSONMap(string(response))
+				assert.Equal(t, "missing_parameter", responseMap["code"])
+				assert.Equal(t, "payments[1][key]", responseMap["data"].(map[string]interface{})["name"])
+			})
+		})
+
+		Convey("When keys are sent with batch id", func() {
+			data["id"] = "batch"
+
+			Convey("it should return error", func() {
+				statusCode, response := net.JSONGetResponse(testServer, data)
+				assert.Equal(t, 400, statusCode)
+				responseMap := test.StringToJSONMap(string(response))
+				assert.Equal(t, "invalid_parameter", responseMap["code"])
+				assert.Equal(t, "id", responseMap["data"].(map[string]interface{})["name"])
+			})
+		})
+
+		Convey("When payments are sent for the first time and one of the transactions fails", func() {
+			for _, key := range []string{"k1", "k2", "k3"} {
+				mockRepository.On("GetBatchItemByKey", key).Return(nil, nil).Once()
+			}
+
+			saved := map[string]entities.BatchItem{}
+			var mutex sync.Mutex
+			// Items are saved pending before transactions are submitted
+			assertPending := func(args mock.Arguments, keys ...string) {
+				mutex.Lock()
+				defer mutex.Unlock()
+				for _, key := range keys {
+					require.NotNil(t, saved[key].PaymentID)
+					assert.Equal(t, entities.BatchItemStatusPending, saved[key].Status)
+					assert.Equal(t, *args.Get(0).(*string), *saved[key].PaymentID)
+				}
+			}
+
+			mockTransactionSubmitter.On(
+				"SubmitTransaction",
+				paymentIDPointer,
+				c.Accounts.BaseSeed,
+				mock.AnythingOfType("bridge.Operations"),
+				nil,
+			).Run(func(args mock.Arguments) {
+				assertPending(args, "k1", "k3")
+			}).Return(horizon.SubmitTransactionResponse{Hash: "a", Ledger: &ledger}, nil).Once()
+			mockTransactionSubmitter.On(
+				"SubmitTransaction",
+				paymentIDPointer,
+				otherSeed,
+				mock.AnythingOfType("bridge.Operations"),
+				nil,
+			).Run(func(args mock.Arguments) {
+				assertPending(args, "k2")
+			}).Return(horizon.SubmitTransactionResponse{}, errors.New("connection refused")).Once()
+
+			// Transaction failing with connection error has not been stored
+			mockRepository.On("GetSentTransactionByPaymentID", paymentID).Return(nil, nil).Times(5)
+			mockEntityManager.On("Persist", mock.AnythingOfType("*entities.BatchItem")).Run(func(args mock.Arguments) {
+				item := args.Get(0).(*entities.BatchItem)
+				mutex.Lock()
+				saved[item.ItemKey] = *item
+				mutex.Unlock()
+			}).Return(nil).Times(6)
+
+			Convey("it should save and return results of all payments", func() {
+				statusCode, response := net.JSONGetResponse(testServer, data)
+				responseString := strings.TrimSpace(string(response))
+				assert.Equal(t, 500, statusCode)
+				expected := test.StringToJSONMap(`{
+  "code": "batch_source_failed",
+  "error_code": 405,
+  "message": "Transactions of one or more source accounts of the batch failed.",
+  "data": {
+    "sources": [
+      {"source": "GAHA6GRCLCCN7XE2NEEUDSIVOFBOQ6GLSYXVLYCJXJKLPMDR5XB5XZZJ", "transaction": {"hash": "a", "ledger": 1988728}},
+      {"source": "GCF3WVYTHF75PEG6622G5G6KU26GOSDQPDHSCJ3DQD7VONH4EYVDOGKJ", "error": {"code": "internal_server_error", "error_code": 100, "message": "Internal Server Error, please try again."}}
+    ],
+    "items": [
+      {"key": "k1", "status": "success", "hash": "a"},
+      {"key": "k3", "status": "success", "hash": "a"},
+      {"key": "k2", "status": "failure", "error": "internal_server_error"}
+    ]
+  }
+}`)
+				assert.Equal(t, expected, test.StringToJSONMap(responseString))
+
+				require.Len(t, saved, 3)
+				assert.Equal(t, entities.BatchItemStatusSuccess, saved["k1"].Status)
+				assert.Equal(t, "a", *saved["k1"].TransactionID)
+				assert.Equal(t, hashes["k1"], saved["k1"].RequestHash)
+				assert.Equal(t, entities.BatchItemStatusFailure, saved["k2"].Status)
+				assert.Nil(t, saved["k2"].TransactionID)
+			})
+		})
+
+		Convey("When the batch is retried", func() {
+			succeeded := func(key string) *entities.BatchItem {
+				hash := "a"
+				return &entities.BatchItem{ItemKey: key, Status: entities.BatchItemStatusSuccess, TransactionID: &hash, RequestHash: hashes[key]}
+			}
+			failure := "internal_server_error"
+			failed := &entities.BatchItem{ItemKey: "k2", Status: entities.BatchItemStatusFailure, Error: &failure, RequestHash: hashes["k2"]}
+			failed.SetExists()
+			mockRepository.On("GetBatchItemByKey", "k1").Return(succeeded("k1"), nil).Once()
+			mockRepository.On("GetBatchItemByKey", "k2").Return(failed, nil).Once()
+			mockRepository.On("GetBatchItemByKey", "k3").Return(succeeded("k3"), nil).Once()
+
+			// Failed item is replaced with a new pending one
+			mockEntityManager.On("Delete", failed).Return(nil).Once()
+			mockEntityManager.On("Persist", mock.AnythingOfType("*entities.BatchItem")).Run(func(args mock.Arguments) {
+				item := args.Get(0).(*entities.BatchItem)
+				assert.Equal(t, "k2", item.ItemKey)
+				assert.Equal(t, entities.BatchItemStatusPending, item.Status)
+			}).Return(nil).Once()
+
+			mockRepository.On("GetSentTransactionByPaymentID", paymentID).Return(nil, nil).Twice()
+			mockTransactionSubmitter.On(
+				"SubmitTransaction",
+				paymentIDPointer,
+				otherSeed,
+				mock.AnythingOfType("bridge.Operations"),
+				nil,
+			).Run(func(args mock.Arguments) {
+				assert.Len(t, args.Get(2).(bridge.Operations), 1)
+			}).Return(horizon.SubmitTransactionResponse{Hash: "b", Ledger: &ledger}, nil).Once()
+			mockEntityManager.On("Persist", mock.AnythingOfType("*entities.BatchItem")).Run(func(args mock.Arguments) {
+				item := args.Get(0).(*entities.BatchItem)
+				assert.Equal(t, "k2", item.ItemKey)
+				assert.Equal(t, entities.BatchItemStatusSuccess, item.Status)
+				assert.Nil(t, item.Error)
+			}).Return(nil).Once()
+
+			Convey("it should send only payments that have not been sent", func() {
+				statusCode, response := net.JSONGetResponse(testServer, data)
+				responseString := strings.TrimSpace(string(response))
+				assert.Equal(t, 200, statusCode)
+				expected := test.StringToJSONMap(`{
+  "transactions": [
+    {"hash": "b", "ledger": 1988728}
+  ],
+  "items": [
+    {"key": "k1", "status": "success", "hash": "a", "skipped": true},
+    {"key": "k3", "status": "success", "hash": "a", "skipped": true},
+    {"key": "k2", "status": "success", "hash": "b"}
+  ]
+}`)
+				assert.Equal(t, expected, test.StringToJSONMap(responseString))
+			})
+		})
+
+		Convey("When the outcome of a transaction of the previous request is unknown", func() {
+			succeeded := func(key string) *entities.BatchItem {
+				hash := "a"
+				return &entities.BatchItem{ItemKey: key, Status: entities.BatchItemStatusSuccess, TransactionID: &hash, RequestHash: hashes[key]}
+			}
+			previousID := "batch-previous"
+			pending := &entities.BatchItem{ItemKey: "k2", RequestHash: hashes["k2"]}
+			pending.MarkPending(previousID, nil)
+			mockRepository.On("GetBatchItemByKey", "k1").Return(succeeded("k1"), nil).Once()
+			mockRepository.On("GetBatchItemByKey", "k2").Return(pending, nil).Once()
+			mockRepository.On("GetBatchItemByKey", "k3").Return(succeeded("k3"), nil).Once()
+
+			sentTransaction := &entities.SentTransaction{PaymentID: &previousID, TransactionID: "b", EnvelopeXdr: "envelope_b"}
+			mockRepository.On("GetSentTransactionByPaymentID", previousID).Return(sentTransaction, nil).Once()
+
+			Convey("When the transaction has been applied", func() {
+				mockTransactionSubmitter.On("ResubmitTransaction", "envelope_b").Return(horizon.SubmitTransactionResponse{Hash: "b", Ledger: &ledger}, nil).Once()
+				mockEntityManager.On("Persist", pending).Run(func(args mock.Arguments) {
+					assert.Equal(t, entities.BatchItemStatusSuccess, pending.Status)
+				}).Return(nil).Once()
+
+				Convey("it should not send the payment again", func() {
+					calls := len(mockTransactionSubmitter.Calls)
+					statusCode, response := net.JSONGetResponse(testServer, data)
+					responseString := strings.TrimSpace(string(response))
+					assert.Equal(t, 200, statusCode)
+					expected := test.StringToJSONMap(`{
+  "items": [
+    {"key": "k1", "status": "success", "hash": "a", "skipped": true},
+    {"key": "k2", "status": "success", "hash": "b", "skipped": true},
+    {"key": "k3", "status": "success", "hash": "a", "skipped": true}
+  ]
+}`)
+					assert.Equal(t, expected, test.StringToJSONMap(responseString))
+					// The stored transaction is checked only
+					require.Len(t, mockTransactionSubmitter.Calls, calls+1)
+					assert.Equal(t, "ResubmitTransaction", mockTransactionSubmitter.Calls[calls].Method)
+				})
+			})
+
+			Convey("When the outcome is still unknown", func() {
+				mockTransactionSubmitter.On("ResubmitTransaction", "envelope_b").Return(horizon.SubmitTransactionResponse{}, errors.New("connection refused")).Once()
+
+				Convey("it should return error and keep the payment pending", func() {
+					calls := len(mockTransactionSubmitter.Calls)
+					statusCode, _ := net.JSONGetResponse(testServer, data)
+					assert.Equal(t, 500, statusCode)
+					assert.Equal(t, entities.BatchItemStatusPending, pending.Status)
+					assert.Len(t, mockTransactionSubmitter.Calls, calls+1)
+				})
+			})
+		})
+
+		Convey("When a payment is being sent by another request", func() {
+			pending := &entities.BatchItem{ItemKey: "k1", RequestHash: hashes["k1"]}
+			pending.MarkPending("batch-other", nil)
+			mockRepository.On("GetBatchItemByKey", "k1").Return(pending, nil).Once()
+			// Transaction not signed and stored yet
+			mockRepository.On("GetSentTransactionByPaymentID", "batch-other").Return(nil, nil).Once()
+
+			Convey("it should return error", func() {
+				calls := len(mockTransactionSubmitter.Calls)
+				statusCode, response := net.JSONGetResponse(testServer, data)
+				responseString := strings.TrimSpace(string(response))
+				assert.Equal(t, 409, statusCode)
+				expected := test.StringToJSONMap(`{
+  "code": "batch_payment_in_progress",
+  "error_code": 409,
+  "message": "Payment with given key is being sent by another request. Repeat the request later.",
+  "data": {
+    "key": "k1"
+  }
+}`)
+				assert.Equal(t, expected, test.StringToJSONMap(responseString))
+				assert.Len(t, mockTransactionSubmitter.Calls, calls)
+			})
+		})
+
+		Convey("When a concurrent request with the same keys saves its items first", func() {
+			data := test.StringToJSONMap(`{
+  "payments": [
+    {"key": "k4", "destination": "GAPCT362RATBUJ37RN2MOKQIZLHSJMO33MMCSRUXTTHIGVDYWOFG5HDS", "amount": "1", "asset_code": "USD", "asset_issuer": "GDSIKW43UA6JTOA47WVEBCZ4MYC74M3GNKNXTVDXFHXYYTNO5GGVN632"},
+    {"key": "k5", "destination": "GAPCT362RATBUJ37RN2MOKQIZLHSJMO33MMCSRUXTTHIGVDYWOFG5HDS", "amount": "2", "asset_code": "USD", "asset_issuer": "GDSIKW43UA6JTOA47WVEBCZ4MYC74M3GNKNXTVDXFHXYYTNO5GGVN632"}
+  ]
+}`)
+			mockRepository.On("GetBatchItemByKey", "k4").Return(nil, nil).Once()
+			mockRepository.On("GetBatchItemByKey", "k5").Return(nil, nil).Once()
+
+			var claimed *entities.BatchItem
+			mockEntityManager.On("Persist", mock.AnythingOfType("*entities.BatchItem")).Run(func(args mock.Arguments) {
+				claimed = args.Get(0).(*entities.BatchItem)
+				assert.Equal(t, "k4", claimed.ItemKey)
+			}).Return(nil).Once()
+			// Unique key violation
+			mockEntityManager.On("Persist", mock.AnythingOfType("*entities.BatchItem")).Return(errors.New("duplicate key")).Once()
+			mockRepository.On("GetBatchItemByKey", "k5").Return(&entities.BatchItem{ItemKey: "k5", Status: entities.BatchItemStatusPending}, nil).Once()
+			mockEntityManager.On("Delete", mock.AnythingOfType("*entities.BatchItem")).Run(func(args mock.Arguments) {
+				assert.Equal(t, claimed, args.Get(0))
+			}).Return(nil).Once()
+
+			Convey("it should release its items and return error", func() {
+				calls := len(mockTransactionSubmitter.Calls)
+				statusCode, response := net.JSONGetResponse(testServer, data)
+				assert.Equal(t, 409, statusCode)
+				responseMap := test.StringToJSONMap(string(response))
+				assert.Equal(t, "batch_payment_in_progress", responseMap["code"])
+				assert.Equal(t, "k5", responseMap["data"].(map[string]interface{})["key"])
+				assert.Len(t, mockTransactionSubmitter.Calls, calls)
+			})
+		})
+
+		Convey("When all payments have already been sent", func() {
+			for _, key := range []string{"k1", "k2", "k3"} {
+				hash := "a"
+				mockRepository.On("GetBatchItemByKey", key).Return(&entities.BatchItem{ItemKey: key, Status: entities.BatchItemStatusSuccess, TransactionID: &hash, RequestHash: hashes[key]}, nil).Once()
+			}
+
+			Convey("it should not submit any transaction", func() {
+				calls := len(mockTransactionSubmitter.Calls)
+				statusCode, response := net.JSONGetResponse(testServer, data)
+				assert.Equal(t, 200, statusCode)
+				items := test.StringToJSONMap(string(response))["items"].([]interface{})
+				assert.Len(t, items, 3)
+				assert.Len(t, mockTransactionSubmitter.Calls, calls)
+			})
+		})
+
+		Convey("When a key has been used by a payment with different params", func() {
+			mockRepository.On("GetBatchItemByKey", "k1").Return(&entities.BatchItem{ItemKey: "k1", Status: entities.BatchItemStatusSuccess, RequestHash: "other"}, nil).Once()
+
+			Convey("it should return error", func() {
+				statusCode, response := net.JSONGetResponse(testServer, data)
+				responseString := strings.TrimSpace(string(response))
+				assert.Equal(t, 409, statusCode)
+				responseMap := test.StringToJSONMap(responseString)
+				assert.Equal(t, "idempotency_key_conflict", responseMap["code"])
+				assert.Equal(t, "payments[0][key]", responseMap["data"].(map[string]interface{})["name"])
+			})
+		})
+	})
+
+	Convey("Given batch payment request with identical payments", t, func() {
+		Reset(func() {
+			c.Batch.Duplicates = ""
+		})
+
+		data := test.StringToJSONMap(`{
+  "payments": [
+    {"destination": "GAPCT362RATBUJ37RN2MOKQIZLHSJMO33MMCSRUXTTHIGVDYWOFG5HDS", "amount": "1", "asset_code": "USD", "asset_issuer": "GDSIKW43UA6JTOA47WVEBCZ4MYC74M3GNKNXTVDXFHXYYTNO5GGVN632"},
+    {"destination": "GAPCT362RATBUJ37RN2MOKQIZLHSJMO33MMCSRUXTTHIGVDYWOFG5HDS", "amount": "2", "asset_code": "USD", "asset_issuer": "GDSIKW43UA6JTOA47WVEBCZ4MYC74M3GNKNXTVDXFHXYYTNO5GGVN632"},
+    {"destination": "GAPCT362RATBUJ37RN2MOKQIZLHSJMO33MMCSRUXTTHIGVDYWOFG5HDS", "amount": "1.0", "asset_code": "USD", "asset_issuer": "GDSIKW43UA6JTOA47WVEBCZ4MYC74M3GNKNXTVDXFHXYYTNO5GGVN632"}
+  ]
+}`)
+
+		Convey("When duplicates are rejected", func() {
+			c.Batch.Duplicates = "reject"
+
+			Convey("it should return error", func() {
+				statusCode, response := net.JSONGetResponse(testServer, data)
+				responseString := strings.TrimSpace(string(response))
+				assert.Equal(t, 400, statusCode)
+				expected := test.StringToJSONMap(`{
+  "code": "batch_duplicate_payment",
+  "error_code": 403,
+  "message": "Batch contains identical payments.",
+  "data": {
+    "name": "payments[2]",
+    "duplicate_of": "payments[0]"
+  }
+}`)
+				assert.Equal(t, expected, test.StringToJSONMap(responseString))
+			})
+		})
+
+		Convey("When duplicates are collapsed", func() {
+			c.Batch.Duplicates = "collapse"
+
+			var ledger uint64 = 1988728
+			mockTransactionSubmitter.On(
+				"SubmitTransaction",
+				(*string)(nil),
+				c.Accounts.BaseSeed,
+				mock.AnythingOfType("bridge.Operations"),
+				nil,
+			).Run(func(args mock.Arguments) {
+				operations := args.Get(2).(bridge.Operations)
+				require.Len(t, operations, 2)
+				assert.Equal(t, xdr.Int64(10000000), operations[0].(build.PaymentBuilder).P.Amount)
+				assert.Equal(t, xdr.Int64(20000000), operations[1].(build.PaymentBuilder).P.Amount)
+			}).Return(horizon.SubmitTransactionResponse{Hash: "a", Ledger: &ledger}, nil).Once()
+
+			Convey("it should submit a transaction without duplicates", func() {
+				statusCode, _ := net.JSONGetResponse(testServer, data)
+				assert.Equal(t, 200, statusCode)
+			})
+		})
+	})
+
+	Convey("Given batch payment request paying an account before it's created", t, func() {
+		Reset(func() {
+			c.Batch.Ordering = ""
+		})
+
+		data := test.StringToJSONMap(`{
+  "payments": [
+    {"destination": "GBKGHBQUXUCUECDOXYGJI6G7MRA7PUQSR7D744E4QQD7TYCPWICIZM65", "amount": "1", "asset_code": "USD", "asset_issuer": "GDSIKW43UA6JTOA47WVEBCZ4MYC74M3GNKNXTVDXFHXYYTNO5GGVN632"},
+    {"destination": "GBKGHBQUXUCUECDOXYGJI6G7MRA7PUQSR7D744E4QQD7TYCPWICIZM65", "amount": "5", "operation": "create_account"}
+  ]
+}`)
+
+		Convey("When ordering problems are rejected", func() {
+			c.Batch.Ordering = "reject"
+
+			Convey("it should return error", func() {
+				statusCode, response := net.JSONGetResponse(testServer, data)
+				responseString := strings.TrimSpace(string(response))
+				assert.Equal(t, 400, statusCode)
+				expected := test.StringToJSONMap(`{
+  "code": "batch_ordering",
+  "error_code": 408,
+  "message": "Payment depends on an operation applied after it.",
+  "data": {
+    "name": "payments[0]",
+    "depends_on": "payments[1]"
+  }
+}`)
+				assert.Equal(t, expected, test.StringToJSONMap(responseString))
+			})
+		})
+
+		Convey("When ordering problems are only logged", func() {
+			c.Batch.Ordering = "warn"
+
+			var ledger uint64 = 1988729
+			mockTransactionSubmitter.On(
+				"SubmitTransaction",
+				(*string)(nil),
+				c.Accounts.BaseSeed,
+				mock.AnythingOfType("bridge.Operations"),
+				nil,
+			).Run(func(args mock.Arguments) {
+				operations := args.Get(2).(bridge.Operations)
+				require.Len(t, operations, 2)
+				// Operations are sent in request order
+				assert.IsType(t, build.PaymentBuilder{}, operations[0])
+				assert.IsType(t, build.CreateAccountBuilder{}, operations[1])
+			}).Return(horizon.SubmitTransactionResponse{Hash: "b", Ledger: &ledger}, nil).Once()
+
+			Convey("it should submit the transaction", func() {
+				statusCode, _ := net.JSONGetResponse(testServer, data)
+				assert.Equal(t, 200, statusCode)
+			})
+		})
+	})
+
+	Convey("Given batch payment request exceeding batch limits", t, func() {
+		Reset(func() {
+			c.Batch.MaxPayments = 0
+			c.Batch.MaxAmounts = nil
+		})
+
+		data := test.StringToJSONMap(`{
+  "payments": [
+    {"destination": "GAPCT362RATBUJ37RN2MOKQIZLHSJMO33MMCSRUXTTHIGVDYWOFG5HDS", "amount": "60", "asset_code": "USD", "asset_issuer": "GDSIKW43UA6JTOA47WVEBCZ4MYC74M3GNKNXTVDXFHXYYTNO5GGVN632"},
+    {"destination": "GC56HD5MFIQTDSETNOJ7LARBUDPUPK6WHNGMYRTLHLK5H4LJ7BJHLDYP", "amount": "50.5", "asset_code": "USD", "asset_issuer": "GDSIKW43UA6JTOA47WVEBCZ4MYC74M3GNKNXTVDXFHXYYTNO5GGVN632"},
+    {"destination": "GC56HD5MFIQTDSETNOJ7LARBUDPUPK6WHNGMYRTLHLK5H4LJ7BJHLDYP", "amount": "10"}
+  ]
+}`)
+
+		Convey("When batch has more payments than allowed", func() {
+			c.Batch.MaxPayments = 2
+
+			Convey("it should return error", func() {
+				statusCode, response := net.JSONGetResponse(testServer, data)
+				responseString := strings.TrimSpace(string(response))
+				assert.Equal(t, 400, statusCode)
+				expected := test.StringToJSONMap(`{
+  "code": "batch_too_many_payments",
+  "error_code": 406,
+  "message": "Batch exceeds maximum number of payments allowed by this server.",
+  "data": {
+    "payments": 3,
+    "max_payments": 2
+  }
+}`)
+				assert.Equal(t, expected, test.StringToJSONMap(responseString))
+			})
+		})
+
+		Convey("When total amount of an asset exceeds its limit", func() {
+			c.Batch.MaxAmounts = []config.BatchMaxAmount{
+				{Amount: "100"},
+				{AssetCode: "USD", AssetIssuer: "GDSIKW43UA6JTOA47WVEBCZ4MYC74M3GNKNXTVDXFHXYYTNO5GGVN632", Amount: "100"},
+			}
+
+			Convey("it should return error with the total amount", func() {
+				statusCode, response := net.JSONGetResponse(testServer, data)
+				responseString := strings.TrimSpace(string(response))
+				assert.Equal(t, 400, statusCode)
+				expected := test.StringToJSONMap(`{
+  "code": "batch_amount_too_high",
+  "error_code": 407,
+  "message": "Total amount of an asset sent by the batch exceeds the maximum allowed by this server.",
+  "data": {
+    "asset_code": "USD",
+    "asset_issuer": "GDSIKW43UA6JTOA47WVEBCZ4MYC74M3GNKNXTVDXFHXYYTNO5GGVN632",
+    "amount": "110.5000000",
+    "max_amount": "100.0000000"
+  }
+}`)
+				assert.Equal(t, expected, test.StringToJSONMap(responseString))
+			})
+		})
+	})
+
+	Convey("Given batch payment request when issuance only is enabled", t, func() {
+		c.IssuanceOnly = true
+		Reset(func() { c.IssuanceOnly = false })
+
+		data := test.StringToJSONMap(`{
+  "payments": [
+    {"destination": "GAPCT362RATBUJ37RN2MOKQIZLHSJMO33MMCSRUXTTHIGVDYWOFG5HDS", "amount": "1", "asset_code": "USD", "asset_issuer": "GCF3WVYTHF75PEG6622G5G6KU26GOSDQPDHSCJ3DQD7VONH4EYVDOGKJ", "operation_source": "GCF3WVYTHF75PEG6622G5G6KU26GOSDQPDHSCJ3DQD7VONH4EYVDOGKJ"},
+    {"destination": "GAPCT362RATBUJ37RN2MOKQIZLHSJMO33MMCSRUXTTHIGVDYWOFG5HDS", "amount": "2"},
+    {"destination": "GAPCT362RATBUJ37RN2MOKQIZLHSJMO33MMCSRUXTTHIGVDYWOFG5HDS", "amount": "3", "asset_code": "USD", "asset_issuer": "GCF3WVYTHF75PEG6622G5G6KU26GOSDQPDHSCJ3DQD7VONH4EYVDOGKJ"}
+  ]
+}`)
+
+		Convey("When a payment sends asset not issued by its source", func() {
+			Convey("it should return error", func() {
+				statusCode, response := net.JSONGetResponse(testServer, data)
+				assert.Equal(t, 400, statusCode)
+				responseMap := test.StringToJSONMap(string(response))
+				assert.Equal(t, "not_issuance", responseMap["code"])
+				assert.Equal(t, map[string]interface{}{
+					"name":         "payments[2][asset_issuer]",
+					"asset_code":   "USD",
+					"asset_issuer": "GCF3WVYTHF75PEG6622G5G6KU26GOSDQPDHSCJ3DQD7VONH4EYVDOGKJ",
+					"source":       keypair.MustParse(c.Accounts.BaseSeed).Address(),
+				}, responseMap["data"])
+			})
+		})
+	})
+
+	Convey("Given batch payment request with per operation fee", t, func() {
+		c.Batch.MaxTransactionFee = 500
+		Reset(func() {
+			c.Batch.MaxTransactionFee = 0
+		})
+
+		data := test.StringToJSONMap(`{
+  "per_op_fee": 200,
+  "payments": [
+    {"destination": "GAPCT362RATBUJ37RN2MOKQIZLHSJMO33MMCSRUXTTHIGVDYWOFG5HDS", "amount": "1", "asset_code": "USD", "asset_issuer": "GDSIKW43UA6JTOA47WVEBCZ4MYC74M3GNKNXTVDXFHXYYTNO5GGVN632"},
+    {"destination": "GAPCT362RATBUJ37RN2MOKQIZLHSJMO33MMCSRUXTTHIGVDYWOFG5HDS", "amount": "2", "asset_code": "USD", "asset_issuer": "GDSIKW43UA6JTOA47WVEBCZ4MYC74M3GNKNXTVDXFHXYYTNO5GGVN632"}
+  ]
+}`)
+
+		Convey("When the fee is within the limit", func() {
+			var ledger uint64 = 1988728
+			mockTransactionSubmitter.On(
+				"SubmitTransaction",
+				(*string)(nil),
+				c.Accounts.BaseSeed,
+				mock.AnythingOfType("bridge.Operations"),
+				nil,
+			).Run(func(args mock.Arguments) {
+				tx, err := build.Transaction(args.Get(2).(bridge.Operations))
+				require.NoError(t, err)
+				assert.Equal(t, xdr.Uint32(400), tx.TX.Fee)
+			}).Return(horizon.SubmitTransactionResponse{Hash: "a", Ledger: &ledger}, nil).Once()
+
+			Convey("it should submit the transaction with the given fee", func() {
+				statusCode, _ := net.JSONGetResponse(testServer, data)
+				assert.Equal(t, 200, statusCode)
+			})
+		})
+
+		Convey("When the fee exceeds the limit", func() {
+			data["per_op_fee"] = 300
+
+			Convey("it should return error", func() {
+				statusCode, response := net.JSONGetResponse(testServer, data)
+				responseString := strings.TrimSpace(string(response))
+				assert.Equal(t, 400, statusCode)
+				expected := test.StringToJSONMap(`{
+  "code": "batch_fee_too_high",
+  "error_code": 404,
+  "message": "Transaction fee of the batch exceeds the maximum fee allowed by this server.",
+  "data": {
+    "fee": 600,
+    "max_fee": 500
+  }
+}`)
+				assert.Equal(t, expected, test.StringToJSONMap(responseString))
+			})
+		})
+
+		Convey("When the fee is lower than the minimum fee", func() {
+			data["per_op_fee"] = 50
+
+			Convey("it should return error", func() {
+				statusCode, response := net.JSONGetResponse(testServer, data)
+				assert.Equal(t, 400, statusCode)
+				assert.Equal(t, "per_op_fee", test.StringToJSONMap(string(response))["data"].(map[string]interface{})["name"])
+			})
+		})
+	})
+
+	Convey("Given batch payment request sent again with the same id", t, func() {
+		body := `{
+  "id": "retried-batch",
+  "payments": [
+    {"destination": "GAPCT362RATBUJ37RN2MOKQIZLHSJMO33MMCSRUXTTHIGVDYWOFG5HDS", "amount": "1", "asset_code": "USD", "asset_issuer": "GDSIKW43UA6JTOA47WVEBCZ4MYC74M3GNKNXTVDXFHXYYTNO5GGVN632"}
+  ]
+}`
+		paymentID := "retried-batch"
+		sentTransaction := &entities.SentTransaction{PaymentID: &paymentID, EnvelopeXdr: "envelope_xdr"}
+		var ledger uint64 = 1988728
+		submitResponse := horizon.SubmitTransactionResponse{Hash: "a", Ledger: &ledger}
+
+		// First request
+		mockRepository.On("GetSentTransactionByPaymentID", paymentID).Return(nil, nil).Once()
+		mockRepository.On("GetSentTransactionByPaymentID", "retried-batch-0").Return(nil, nil).Once()
+		mockRepository.On("GetSentTransactionByPaymentID", paymentID).Return(nil, nil).Once()
+		mockTransactionSubmitter.On(
+			"SubmitTransaction",
+			&paymentID,
+			c.Accounts.BaseSeed,
+			mock.AnythingOfType("bridge.Operations"),
+			nil,
+		).Return(submitResponse, nil).Once()
+		mockRepository.On("GetSentTransactionByPaymentID", paymentID).Return(sentTransaction, nil).Once()
+		mockEntityManager.On("Persist", sentTransaction).Run(func(args mock.Arguments) {
+			assert.NotNil(t, sentTransaction.RequestHash)
+		}).Return(nil).Once()
+
+		statusCode, response := net.JSONGetResponse(testServer, test.StringToJSONMap(body))
+		require.Equal(t, 200, statusCode)
+		expected := test.StringToJSONMap(`{"hash": "a", "ledger": 1988728}`)
+		assert.Equal(t, expected, test.StringToJSONMap(strings.TrimSpace(string(response))))
+
+		Convey("When params are the same", func() {
+			mockRepository.On("GetSentTransactionByPaymentID", paymentID).Return(sentTransaction, nil).Once()
+			mockRepository.On("GetSentTransactionByPaymentID", "retried-batch-0").Return(nil, nil).Once()
+			mockRepository.On("GetSentTransactionByPaymentID", paymentID).Return(sentTransaction, nil).Once()
+			mockTransactionSubmitter.On("ResubmitTransaction", "envelope_xdr").Return(submitResponse, nil).Once()
+
+			Convey("it should resubmit the stored transaction", func() {
+				submitted := len(mockTransactionSubmitter.Calls)
+
+				statusCode, response := net.JSONGetResponse(testServer, test.StringToJSONMap(body))
+				assert.Equal(t, 200, statusCode)
+				assert.Equal(t, expected, test.StringToJSONMap(strings.TrimSpace(string(response))))
+				require.Len(t, mockTransactionSubmitter.Calls, submitted+1)
+				assert.Equal(t, "ResubmitTransaction", mockTransactionSubmitter.Calls[submitted].Method)
+			})
+		})
+
+		Convey("When params are different", func() {
+			mockRepository.On("GetSentTransactionByPaymentID", paymentID).Return(sentTransaction, nil).Once()
+
+			Convey("it should return error", func() {
+				submitted := len(mockTransactionSubmitter.Calls)
+
+				statusCode, response := net.JSONGetResponse(testServer, test.StringToJSONMap(strings.Replace(body, `"amount": "1"`, `"amount": "2"`, 1)))
+				assert.Equal(t, 409, statusCode)
+				expected := test.StringToJSONMap(`{
+  "code": "idempotency_key_conflict",
+  "error_code": 312,
+  "message": "Payment with given id has already been sent with different params."
+}`)
+				assert.Equal(t, expected, test.StringToJSONMap(strings.TrimSpace(string(response))))
+				assert.Len(t, mockTransactionSubmitter.Calls, submitted)
+			})
+		})
+	})
+
+	Convey("Given batch payment request when events are published", t, func() {
+		mockEventsPublisher := new(mocks.MockEventsPublisher)
+		requestHandler.Events = mockEventsPublisher
+		c.Batch.MaxOperations = 2
+		c.Batch.SplitTransactions = true
+		Reset(func() {
+			requestHandler.Events = nil
+			c.Batch.MaxOperations = 0
+			c.Batch.SplitTransactions = false
+		})
+
+		data := test.StringToJSONMap(`{
+  "id": "events",
+  "payments": [
+    {"destination": "GAPCT362RATBUJ37RN2MOKQIZLHSJMO33MMCSRUXTTHIGVDYWOFG5HDS", "amount": "1", "asset_code": "USD", "asset_issuer": "GDSIKW43UA6JTOA47WVEBCZ4MYC74M3GNKNXTVDXFHXYYTNO5GGVN632"},
+    {"destination": "GAPCT362RATBUJ37RN2MOKQIZLHSJMO33MMCSRUXTTHIGVDYWOFG5HDS", "amount": "2", "asset_code": "USD", "asset_issuer": "GDSIKW43UA6JTOA47WVEBCZ4MYC74M3GNKNXTVDXFHXYYTNO5GGVN632"},
+    {"destination": "GAPCT362RATBUJ37RN2MOKQIZLHSJMO33MMCSRUXTTHIGVDYWOFG5HDS", "amount": "3", "asset_code": "USD", "asset_issuer": "GDSIKW43UA6JTOA47WVEBCZ4MYC74M3GNKNXTVDXFHXYYTNO5GGVN632"}
+  ]
+}`)
+
+		var ledger uint64 = 1988728
+		firstID := "events-0"
+		secondID := "events-1"
+		mockRepository.On("GetSentTransactionByPaymentID", "events").Return(nil, nil).Once()
+		mockRepository.On("GetSentTransactionByPaymentID", firstID).Return(nil, nil).Times(3)
+		mockRepository.On("GetSentTransactionByPaymentID", secondID).Return(nil, nil).Twice()
+
+		mockTransactionSubmitter.On(
+			"SubmitTransaction",
+			&firstID,
+			c.Accounts.BaseSeed,
+			mock.AnythingOfType("bridge.Operations"),
+			nil,
+		).Return(horizon.SubmitTransactionResponse{Hash: "a", Ledger: &ledger}, nil).Once()
+		mockTransactionSubmitter.On(
+			"SubmitTransaction",
+			&secondID,
+			c.Accounts.BaseSeed,
+			mock.AnythingOfType("bridge.Operations"),
+			nil,
+		).Return(horizon.SubmitTransactionResponse{
+			Hash:   "b",
+			Extras: &horizon.SubmitTransactionResponseExtras{ResultXdr: "AAAAAAAAAGT/////AAAAAQAAAAAAAAAB/////gAAAAA="}, // payment_underfunded
+		}, nil).Once()
+
+		var published []events.PaymentEvent
+		mockEventsPublisher.On("PublishPayment", mock.AnythingOfType("events.PaymentEvent")).Run(func(args mock.Arguments) {
+			published = append(published, args.Get(0).(events.PaymentEvent))
+		}).Times(3)
+
+		Convey("it should publish an event for every payment of submitted transactions", func() {
+			statusCode, _ := net.JSONGetResponse(testServer, data)
+			assert.Equal(t, 400, statusCode)
+
+			mockEventsPublisher.AssertExpectations(t)
+			require.Len(t, published, 3)
+			for i, event := range published[:2] {
+				assert.Equal(t, events.PaymentConfirmed, event.Type)
+				assert.Equal(t, "events", event.PaymentID)
+				assert.Equal(t, "a", event.Hash)
+				assert.Equal(t, ledger, *event.Ledger)
+				assert.Equal(t, strconv.Itoa(i+1), event.Amount)
+				assert.Equal(t, "GAPCT362RATBUJ37RN2MOKQIZLHSJMO33MMCSRUXTTHIGVDYWOFG5HDS", event.Destination)
+				assert.Equal(t, "USD", event.AssetCode)
+			}
+			assert.Equal(t, events.PaymentFailed, published[2].Type)
+			assert.Equal(t, "b", published[2].Hash)
+			assert.Equal(t, "payment_underfunded", published[2].Error)
+			assert.Equal(t, "3", published[2].Amount)
+		})
+	})
+
+	Convey("Given XLM batch payment when Horizon rate limits loading the destination", t, func() {
+		destination := "GAPCT362RATBUJ37RN2MOKQIZLHSJMO33MMCSRUXTTHIGVDYWOFG5HDS"
+		body := `{"payments": [{"destination": "` + destination + `", "amount": "1"}]}`
+		r := httptest.NewRequest("POST", "/batch-payment", strings.NewReader(body))
+		w := httptest.NewRecorder()
+
+		mockHorizon.On("LoadAccount", destination).Return(
+			horizon.AccountResponse{},
+			&horizon.RateLimitedError{RetryAfter: 1500 * time.Millisecond},
+		).Once()
+
+		Convey("it should return error with retry hint instead of creating the account", func() {
+			requestHandler.BatchPayment(w, r)
+			assert.Equal(t, 503, w.Code)
+			assert.Equal(t, "2", w.Header().Get("Retry-After"))
+			expected := test.StringToJSONMap(`{
+  "code": "horizon_rate_limited",
+  "error_code": 105,
+  "message": "Horizon server is rate limiting requests, please try again later.",
+  "data": {
+    "retry_after": 2
+  }
+}`)
+			assert.Equal(t, expected, test.StringToJSONMap(w.Body.String()))
+		})
+	})
+
+	Convey("Given batch payment request after request deadline", t, func() {
+		body := `{"payments": [{"destination": "GAPCT362RATBUJ37RN2MOKQIZLHSJMO33MMCSRUXTTHIGVDYWOFG5HDS", "amount": "1", "asset_code": "USD", "asset_issuer": "GDSIKW43UA6JTOA47WVEBCZ4MYC74M3GNKNXTVDXFHXYYTNO5GGVN632"}]}`
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		r := httptest.NewRequest("POST", "/batch-payment", strings.NewReader(body)).WithContext(ctx)
+		w := httptest.NewRecorder()
+
+		Convey("it should not submit the transaction", func() {
+			requestHandler.BatchPayment(w, r)
+			assert.Equal(t, 504, w.Code)
+			expected := test.StringToJSONMap(`{
+  "code": "request_timeout",
+  "error_code": 104,
+  "message": "Request has not been processed in time. Repeat it with the same ` + "`id`" + ` to check its status."
+}`)
+			assert.Equal(t, expected, test.StringToJSONMap(w.Body.String()))
+		})
+	})
+}
@@ -42,6 +42,23 @@ func (rh *RequestHandler) Builder(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// When bearer tokens are configured transaction is signed using a seed assigned to the token
+	if len(rh.Config.AuthTokens) > 0 {
+		if len(request.Signers) > 0 {
+			log.Print("signers param sent when bearer token authentication is enabled")
+			server.Write(w, protocols.NewInvalidParameterError("signers", "", "Signers param is not accepted. Use `Authorization: Bearer` header instead."))
+			return
+		}
+
+		seed, ok := rh.seedFromAuthorization(r)
+		if !ok {
+			log.Print("Missing or invalid bearer token")
+			server.Write(w, protocols.UnauthorizedError)
+			return
+		}
+		request.Signers = []string{seed}
+	}
+
 	if request.SequenceNumber == "" {
 		accountResponse, err := rh.Horizon.LoadAccount(request.Source)
 		if err != nil {
@@ -83,6 +100,13 @@ func (rh *RequestHandler) Builder(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	hash, err := tx.HashHex()
+	if err != nil {
+		log.WithFields(log.Fields{"err": err, "request": request}).Error("Error calculating transaction hash")
+		server.Write(w, protocols.InternalServerError)
+		return
+	}
+
 	txe, err := tx.Sign(request.Signers...)
 	if err != nil {
 		log.WithFields(log.Fields{"err": err, "request": request}).Error("Error signing transaction")
@@ -97,5 +121,5 @@ func (rh *RequestHandler) Builder(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	server.Write(w, &bridge.BuilderResponse{TransactionEnvelope: txeB64})
+	server.Write(w, &bridge.BuilderResponse{TransactionEnvelope: txeB64, Hash: hash})
 }
@@ -69,7 +69,8 @@ func TestRequestHandlerBuilder(t *testing.T) {
 				responseString := strings.TrimSpace(string(response))
 				assert.Equal(t, 200, statusCode)
 				expected := test.StringToJSONMap(`{
-		"transaction_envelope": "AAAAAGySS3ZylffFaVZqZD6lNCUjCizHz7MLPwkN7Mxh4XN5AAAAZAAAAAAAAAB8AAAAAAAAAAAAAAABAAAAAAAAAAAAAAAAnEM7m3lksnFftHMGxdt6HTitUQSfvVvjk8JfduWfK+cAAAAAHc1lAAAAAAAAAAABn420/AAAAECZTxo7tUr19fExL97C9wjIjRj0A7NK6gUVt7LwUrKqGsVxM6Un1L907brqp6hEjrqWlfvZchwgFv6syME3rXQE"
+		"transaction_envelope": "AAAAAGySS3ZylffFaVZqZD6lNCUjCizHz7MLPwkN7Mxh4XN5AAAAZAAAAAAAAAB8AAAAAAAAAAAAAAABAAAAAAAAAAAAAAAAnEM7m3lksnFftHMGxdt6HTitUQSfvVvjk8JfduWfK+cAAAAAHc1lAAAAAAAAAAABn420/AAAAECZTxo7tUr19fExL97C9wjIjRj0A7NK6gUVt7LwUrKqGsVxM6Un1L907brqp6hEjrqWlfvZchwgFv6syME3rXQE",
+		"hash": "05c918c975ab3bf0b79b0563725278a1d3f919ef262314b3ab43117b03966df7"
 		}`)
 				assert.Equal(t, expected, test.StringToJSONMap(responseString))
 			})
@@ -96,7 +97,8 @@ func TestRequestHandlerBuilder(t *testing.T) {
 				responseString := strings.TrimSpace(string(response))
 				assert.Equal(t, 200, statusCode)
 				expected := test.StringToJSONMap(`{
-  "transaction_envelope": "AAAAAGySS3ZylffFaVZqZD6lNCUjCizHz7MLPwkN7Mxh4XN5AAAAZAAAAAAAAAB7AAAAAAAAAAAAAAABAAAAAAAAAAAAAAAAnEM7m3lksnFftHMGxdt6HTitUQSfvVvjk8JfduWfK+cAAAAAHc1lAAAAAAAAAAABn420/AAAAECXY+neSolhAeHUXf+UrOV6PjeJnvLM/HqjOlOEWD3hmu/z9aBksDu9zqa26jS14eMpZzq8sofnnvt248FUO+cP"
+  "transaction_envelope": "AAAAAGySS3ZylffFaVZqZD6lNCUjCizHz7MLPwkN7Mxh4XN5AAAAZAAAAAAAAAB7AAAAAAAAAAAAAAABAAAAAAAAAAAAAAAAnEM7m3lksnFftHMGxdt6HTitUQSfvVvjk8JfduWfK+cAAAAAHc1lAAAAAAAAAAABn420/AAAAECXY+neSolhAeHUXf+UrOV6PjeJnvLM/HqjOlOEWD3hmu/z9aBksDu9zqa26jS14eMpZzq8sofnnvt248FUO+cP",
+  "hash": "8376a64d4d0d116be143c00ed6181d8bb64ae74c9ba7b773277a6926e7a20bed"
 }`)
 				assert.Equal(t, expected, test.StringToJSONMap(responseString))
 			})
@@ -127,7 +129,8 @@ func TestRequestHandlerBuilder(t *testing.T) {
 				responseString := strings.TrimSpace(string(response))
 				assert.Equal(t, 200, statusCode)
 				expected := test.StringToJSONMap(`{
-  "transaction_envelope": "AAAAAGySS3ZylffFaVZqZD6lNCUjCizHz7MLPwkN7Mxh4XN5AAAAZAAAAAAAAAB7AAAAAAAAAAAAAAABAAAAAAAAAAEAAAAAnEM7m3lksnFftHMGxdt6HTitUQSfvVvjk8JfduWfK+cAAAABVVNEAAAAAAAESbnnY5csrN1ENj8qA1CADFMTnA6CY8g2Scq4Ix6xjwAAAAA7msoAAAAAAAAAAAGfjbT8AAAAQGlQbmCv74lzQpjUOn8dsQ9/BFCKHSev6DLo4lS2wcS20GpfIjGZSXIAry/3porFM+3xrvBWlIH9Tr/QFKjqRAU="
+  "transaction_envelope": "AAAAAGySS3ZylffFaVZqZD6lNCUjCizHz7MLPwkN7Mxh4XN5AAAAZAAAAAAAAAB7AAAAAAAAAAAAAAABAAAAAAAAAAEAAAAAnEM7m3lksnFftHMGxdt6HTitUQSfvVvjk8JfduWfK+cAAAABVVNEAAAAAAAESbnnY5csrN1ENj8qA1CADFMTnA6CY8g2Scq4Ix6xjwAAAAA7msoAAAAAAAAAAAGfjbT8AAAAQGlQbmCv74lzQpjUOn8dsQ9/BFCKHSev6DLo4lS2wcS20GpfIjGZSXIAry/3porFM+3xrvBWlIH9Tr/QFKjqRAU=",
+  "hash": "591cbd2d6e4820246a8a265e6a7bfda6be0cc01dfe649b71be5b74a44e3c514f"
 }`)
 				assert.Equal(t, expected, test.StringToJSONMap(responseString))
 			})
@@ -171,7 +174,8 @@ func TestRequestHandlerBuilder(t *testing.T) {
 				responseString := strings.TrimSpace(string(response))
 				assert.Equal(t, 200, statusCode)
 				expected := test.StringToJSONMap(`{
-  "transaction_envelope": "AAAAAGySS3ZylffFaVZqZD6lNCUjCizHz7MLPwkN7Mxh4XN5AAAAZAAAAAAAAAB7AAAAAAAAAAAAAAABAAAAAQAAAABwugEhObLKgwIC2czGYHY/xs5Sos3NVVXGiOtLt9HKEAAAAAIAAAABVVNEAAAAAAAESbnnY5csrN1ENj8qA1CADFMTnA6CY8g2Scq4Ix6xjwAAAAA7msoAAAAAAJxDO5t5ZLJxX7RzBsXbeh04rVEEn71b45PCX3blnyvnAAAAAUVVUgAAAAAA3JYqY1mMuLpSZ0NesugENpycEoFpXvbBTzoCupeValMAAAABKgXyAAAAAAIAAAACQUJDREVGRwAAAAAAAAAAAPkUHPo9g8Y9Lf6NqplxfS43DK2BvDrTnzslKRdxRDlLAAAAAAAAAAAAAAABn420/AAAAEA9DEvKZhLwLcStP8/ZsqaEAdlNc91Eyz5mLUiN19etsIYaTPNugsVEWYJOiulXXSIwwitoyxQ1t2jr6VS0mXcB"
+  "transaction_envelope": "AAAAAGySS3ZylffFaVZqZD6lNCUjCizHz7MLPwkN7Mxh4XN5AAAAZAAAAAAAAAB7AAAAAAAAAAAAAAABAAAAAQAAAABwugEhObLKgwIC2czGYHY/xs5Sos3NVVXGiOtLt9HKEAAAAAIAAAABVVNEAAAAAAAESbnnY5csrN1ENj8qA1CADFMTnA6CY8g2Scq4Ix6xjwAAAAA7msoAAAAAAJxDO5t5ZLJxX7RzBsXbeh04rVEEn71b45PCX3blnyvnAAAAAUVVUgAAAAAA3JYqY1mMuLpSZ0NesugENpycEoFpXvbBTzoCupeValMAAAABKgXyAAAAAAIAAAACQUJDREVGRwAAAAAAAAAAAPkUHPo9g8Y9Lf6NqplxfS43DK2BvDrTnzslKRdxRDlLAAAAAAAAAAAAAAABn420/AAAAEA9DEvKZhLwLcStP8/ZsqaEAdlNc91Eyz5mLUiN19etsIYaTPNugsVEWYJOiulXXSIwwitoyxQ1t2jr6VS0mXcB",
+  "hash": "ffd59787625558e1009c02e96906c11c0a74810645214d9524a538cfb5c6360b"
 }`)
 				assert.Equal(t, expected, test.StringToJSONMap(responseString))
 			})
@@ -207,7 +211,8 @@ func TestRequestHandlerBuilder(t *testing.T) {
 				responseString := strings.TrimSpace(string(response))
 				assert.Equal(t, 200, statusCode)
 				expected := test.StringToJSONMap(`{
-  "transaction_envelope": "AAAAAGySS3ZylffFaVZqZD6lNCUjCizHz7MLPwkN7Mxh4XN5AAAAZAAAAAAAAAB7AAAAAAAAAAAAAAABAAAAAAAAAAMAAAABRVVSAAAAAADclipjWYy4ulJnQ16y6AQ2nJwSgWle9sFPOgK6l5VqUwAAAAFVU0QAAAAAAARJuedjlyys3UQ2PyoDUIAMUxOcDoJjyDZJyrgjHrGPAAABH3GCoAACMHl9AL68IAAAAAAAAABkAAAAAAAAAAGfjbT8AAAAQEpMML2mghfM2Dzkpw6eT1N00rrIC7v3xe8zy7yc8rcGzFxIw/4/E69uq+rst+xDoeMTn0b3iBtjr2DEV52o/wE="
+  "transaction_envelope": "AAAAAGySS3ZylffFaVZqZD6lNCUjCizHz7MLPwkN7Mxh4XN5AAAAZAAAAAAAAAB7AAAAAAAAAAAAAAABAAAAAAAAAAMAAAABRVVSAAAAAADclipjWYy4ulJnQ16y6AQ2nJwSgWle9sFPOgK6l5VqUwAAAAFVU0QAAAAAAARJuedjlyys3UQ2PyoDUIAMUxOcDoJjyDZJyrgjHrGPAAABH3GCoAACMHl9AL68IAAAAAAAAABkAAAAAAAAAAGfjbT8AAAAQEpMML2mghfM2Dzkpw6eT1N00rrIC7v3xe8zy7yc8rcGzFxIw/4/E69uq+rst+xDoeMTn0b3iBtjr2DEV52o/wE=",
+  "hash": "e935c3d341cee3e18803043f08bed39002d13d46fa2ca1ba414cb75b95188b70"
 }`)
 				assert.Equal(t, expected, test.StringToJSONMap(responseString))
 			})
@@ -242,7 +247,8 @@ func TestRequestHandlerBuilder(t *testing.T) {
 				responseString := strings.TrimSpace(string(response))
 				assert.Equal(t, 200, statusCode)
 				expected := test.StringToJSONMap(`{
-  "transaction_envelope": "AAAAAGySS3ZylffFaVZqZD6lNCUjCizHz7MLPwkN7Mxh4XN5AAAAZAAAAAAAAAB7AAAAAAAAAAAAAAABAAAAAAAAAAQAAAABRVVSAAAAAADclipjWYy4ulJnQ16y6AQ2nJwSgWle9sFPOgK6l5VqUwAAAAFVU0QAAAAAAARJuedjlyys3UQ2PyoDUIAMUxOcDoJjyDZJyrgjHrGPAAABH3GCoAACMHl9AL68IAAAAAAAAAABn420/AAAAEAtK8juIThYp4LXtgpN8gVNRR42iiR6tz8euSKqqqzKGELCHcPrmFUuYqtecrJi8CyPCYTp0nqGY9mtJCHFYpsC"
+  "transaction_envelope": "AAAAAGySS3ZylffFaVZqZD6lNCUjCizHz7MLPwkN7Mxh4XN5AAAAZAAAAAAAAAB7AAAAAAAAAAAAAAABAAAAAAAAAAQAAAABRVVSAAAAAADclipjWYy4ulJnQ16y6AQ2nJwSgWle9sFPOgK6l5VqUwAAAAFVU0QAAAAAAARJuedjlyys3UQ2PyoDUIAMUxOcDoJjyDZJyrgjHrGPAAABH3GCoAACMHl9AL68IAAAAAAAAAABn420/AAAAEAtK8juIThYp4LXtgpN8gVNRR42iiR6tz8euSKqqqzKGELCHcPrmFUuYqtecrJi8CyPCYTp0nqGY9mtJCHFYpsC",
+  "hash": "60e6a4ea345497ff10b1c8c21889970ea7b4c448953c9cb1fb0c3e01eb2fbf90"
 }`)
 				assert.Equal(t, expected, test.StringToJSONMap(responseString))
 			})
@@ -279,7 +285,8 @@ func TestRequestHandlerBuilder(t *testing.T) {
 				responseString := strings.TrimSpace(string(response))
 				assert.Equal(t, 200, statusCode)
 				expected := test.StringToJSONMap(`{
-  "transaction_envelope": "AAAAAGySS3ZylffFaVZqZD6lNCUjCizHz7MLPwkN7Mxh4XN5AAAAZAAAAAAAAAB7AAAAAAAAAAAAAAABAAAAAAAAAAUAAAABAAAAAFj81cxPv2gGYRVpEapmXzvf6/ohoMAkV3yYtxPbu9a/AAAAAQAAAAQAAAABAAAAAwAAAAEAAABkAAAAAQAAAAEAAAABAAAAAgAAAAEAAAADAAAAAQAAAAtzdGVsbGFyLm9yZwAAAAABAAAAAD1WJTBmoBe9F1apWYHS5eUpAVITjFgTvUMiGEfdMio5AAAABQAAAAAAAAABn420/AAAAEAtQAlVOLBR6sb/YHRg7XcSEPSJ07irs6cCSDpK95rYE7Ga5ghiLXHqRJQ2B9cMmf8FYqzeaHdYPiESZqowhb0F"
+  "transaction_envelope": "AAAAAGySS3ZylffFaVZqZD6lNCUjCizHz7MLPwkN7Mxh4XN5AAAAZAAAAAAAAAB7AAAAAAAAAAAAAAABAAAAAAAAAAUAAAABAAAAAFj81cxPv2gGYRVpEapmXzvf6/ohoMAkV3yYtxPbu9a/AAAAAQAAAAQAAAABAAAAAwAAAAEAAABkAAAAAQAAAAEAAAABAAAAAgAAAAEAAAADAAAAAQAAAAtzdGVsbGFyLm9yZwAAAAABAAAAAD1WJTBmoBe9F1apWYHS5eUpAVITjFgTvUMiGEfdMio5AAAABQAAAAAAAAABn420/AAAAEAtQAlVOLBR6sb/YHRg7XcSEPSJ07irs6cCSDpK95rYE7Ga5ghiLXHqRJQ2B9cMmf8FYqzeaHdYPiESZqowhb0F",
+  "hash": "9e4c0d88b5106bd1a2f85e7ac91e609e87f12c1a2de52f6e09484709dfa5f21c"
 }`)
 				assert.Equal(t, expected, test.StringToJSONMap(responseString))
 			})
@@ -308,7 +315,8 @@ func TestRequestHandlerBuilder(t *testing.T) {
 				responseString := strings.TrimSpace(string(response))
 				assert.Equal(t, 200, statusCode)
 				expected := test.StringToJSONMap(`{
-  "transaction_envelope": "AAAAAGySS3ZylffFaVZqZD6lNCUjCizHz7MLPwkN7Mxh4XN5AAAAZAAAAAAAAAB7AAAAAAAAAAAAAAABAAAAAAAAAAYAAAABVVNEAAAAAACOaNWzuCu3XawUge2Ggh+BnN/PbrvNQD4yKzuY8PdvLX//////////AAAAAAAAAAGfjbT8AAAAQFftcSiqTvZOQwDJnoJ7buLgYXyjRacggCZ7yEhnPN4eXxlpQycvLLFa3U8xv0Mcnx5frSNKxu0sDIOm88Iicw8="
+  "transaction_envelope": "AAAAAGySS3ZylffFaVZqZD6lNCUjCizHz7MLPwkN7Mxh4XN5AAAAZAAAAAAAAAB7AAAAAAAAAAAAAAABAAAAAAAAAAYAAAABVVNEAAAAAACOaNWzuCu3XawUge2Ggh+BnN/PbrvNQD4yKzuY8PdvLX//////////AAAAAAAAAAGfjbT8AAAAQFftcSiqTvZOQwDJnoJ7buLgYXyjRacggCZ7yEhnPN4eXxlpQycvLLFa3U8xv0Mcnx5frSNKxu0sDIOm88Iicw8=",
+  "hash": "a385170ac4d06b3cb5b41056ceb33fa34353369bbc104c7102ac54f641c7da69"
 }`)
 				assert.Equal(t, expected, test.StringToJSONMap(responseString))
 			})
@@ -336,7 +344,8 @@ func TestRequestHandlerBuilder(t *testing.T) {
 				responseString := strings.TrimSpace(string(response))
 				assert.Equal(t, 200, statusCode)
 				expected := test.StringToJSONMap(`{
-  "transaction_envelope": "AAAAAGySS3ZylffFaVZqZD6lNCUjCizHz7MLPwkN7Mxh4XN5AAAAZAAAAAAAAAB7AAAAAAAAAAAAAAABAAAAAAAAAAcAAAAAVn9+cDxbFZIwIiCRtDXQ5WecD382wKC/HVQP370D6NkAAAACVVNEVVNEAAAAAAAAAAAAAQAAAAAAAAABn420/AAAAEA9Ht9mJaKdYoRg/rAX/cl/Q89Juhmi8f7iGBdCrSVAs+VN7NVJXR+0aZpoZIjcJD/QBPiuzZIK1ea2fN7I0I8J"
+  "transaction_envelope": "AAAAAGySS3ZylffFaVZqZD6lNCUjCizHz7MLPwkN7Mxh4XN5AAAAZAAAAAAAAAB7AAAAAAAAAAAAAAABAAAAAAAAAAcAAAAAVn9+cDxbFZIwIiCRtDXQ5WecD382wKC/HVQP370D6NkAAAACVVNEVVNEAAAAAAAAAAAAAQAAAAAAAAABn420/AAAAEA9Ht9mJaKdYoRg/rAX/cl/Q89Juhmi8f7iGBdCrSVAs+VN7NVJXR+0aZpoZIjcJD/QBPiuzZIK1ea2fN7I0I8J",
+  "hash": "cc735b7f660d448befe4d60023aafb31edace4b5efc4acc75432c62bcda7d64c"
 }`)
 				assert.Equal(t, expected, test.StringToJSONMap(responseString))
 			})
@@ -362,7 +371,8 @@ func TestRequestHandlerBuilder(t *testing.T) {
 				responseString := strings.TrimSpace(string(response))
 				assert.Equal(t, 200, statusCode)
 				expected := test.StringToJSONMap(`{
-  "transaction_envelope": "AAAAAGySS3ZylffFaVZqZD6lNCUjCizHz7MLPwkN7Mxh4XN5AAAAZAAAAAAAAAB7AAAAAAAAAAAAAAABAAAAAAAAAAgAAAAAVn9+cDxbFZIwIiCRtDXQ5WecD382wKC/HVQP370D6NkAAAAAAAAAAZ+NtPwAAABALCyRn/E/CgLdPWGgP+1pd2Lkf3jWgNANKQ4QeGgUxgROhqkTUXaPA6XzOWS8yUpzZMufl6nkh8UFqa6Hc1emCA=="
+  "transaction_envelope": "AAAAAGySS3ZylffFaVZqZD6lNCUjCizHz7MLPwkN7Mxh4XN5AAAAZAAAAAAAAAB7AAAAAAAAAAAAAAABAAAAAAAAAAgAAAAAVn9+cDxbFZIwIiCRtDXQ5WecD382wKC/HVQP370D6NkAAAAAAAAAAZ+NtPwAAABALCyRn/E/CgLdPWGgP+1pd2Lkf3jWgNANKQ4QeGgUxgROhqkTUXaPA6XzOWS8yUpzZMufl6nkh8UFqa6Hc1emCA==",
+  "hash": "b6443febb45d3fe1da242856a3b60c3294d0a7bf9d1daf9b00655b7b8a16280e"
 }`)
 				assert.Equal(t, expected, test.StringToJSONMap(responseString))
 			})
@@ -386,7 +396,8 @@ func TestRequestHandlerBuilder(t *testing.T) {
 				responseString := strings.TrimSpace(string(response))
 				assert.Equal(t, 200, statusCode)
 				expected := test.StringToJSONMap(`{
-  "transaction_envelope": "AAAAAGySS3ZylffFaVZqZD6lNCUjCizHz7MLPwkN7Mxh4XN5AAAAZAAAAAAAAAB7AAAAAAAAAAAAAAABAAAAAAAAAAkAAAAAAAAAAZ+NtPwAAABAlBFCwJ3VzBd+CE+n3mA4t71SVrDIjSgRyBnz9zYLN7qkqu8AD6cyvMRj8/alSozSPAZcSe+qBEO7E5biR+YrAA=="
+  "transaction_envelope": "AAAAAGySS3ZylffFaVZqZD6lNCUjCizHz7MLPwkN7Mxh4XN5AAAAZAAAAAAAAAB7AAAAAAAAAAAAAAABAAAAAAAAAAkAAAAAAAAAAZ+NtPwAAABAlBFCwJ3VzBd+CE+n3mA4t71SVrDIjSgRyBnz9zYLN7qkqu8AD6cyvMRj8/alSozSPAZcSe+qBEO7E5biR+YrAA==",
+  "hash": "f2faba1d341373a02a69202aeb63a36572b658a1437d69b6998ac81d98d19877"
 }`)
 				assert.Equal(t, expected, test.StringToJSONMap(responseString))
 			})
@@ -413,7 +424,56 @@ func TestRequestHandlerBuilder(t *testing.T) {
 				responseString := strings.TrimSpace(string(response))
 				assert.Equal(t, 200, statusCode)
 				expected := test.StringToJSONMap(`{
-  "transaction_envelope": "AAAAAGySS3ZylffFaVZqZD6lNCUjCizHz7MLPwkN7Mxh4XN5AAAAZAAAAAAAAAB7AAAAAAAAAAAAAAABAAAAAAAAAAoAAAAJdGVzdF9kYXRhAAAAAAAAAQAAAAYBAgMEBQYAAAAAAAAAAAABn420/AAAAEBkO27ebDbsn1WzzLH5lUfJH3Y0Pgd1dlRx3Ip1dEZkvRPFFDLZuXi5DlW9uxNgeqThNsqnK7PPHfhyuWBVQpgN"
+  "transaction_envelope": "AAAAAGySS3ZylffFaVZqZD6lNCUjCizHz7MLPwkN7Mxh4XN5AAAAZAAAAAAAAAB7AAAAAAAAAAAAAAABAAAAAAAAAAoAAAAJdGVzdF9kYXRhAAAAAAAAAQAAAAYBAgMEBQYAAAAAAAAAAAABn420/AAAAEBkO27ebDbsn1WzzLH5lUfJH3Y0Pgd1dlRx3Ip1dEZkvRPFFDLZuXi5DlW9uxNgeqThNsqnK7PPHfhyuWBVQpgN",
+  "hash": "63c2bdc9dda5e806f93a24f957199d4ea332251c37882ec10ae3dd8464ca7bb6"
+}`)
+				assert.Equal(t, expected, test.StringToJSONMap(responseString))
+			})
+		})
+
+		Convey("ManageOffer price", func() {
+			request := func(price string) map[string]interface{} {
+				return test.StringToJSONMap(`{
+  "source": "GBWJES3WOKK7PRLJKZVGIPVFGQSSGCRMY7H3GCZ7BEG6ZTDB4FZXTPJ5",
+  "sequence_number": "123",
+  "operations": [
+    {
+        "type": "manage_offer",
+        "body": {
+        	"selling": {},
+        	"buying": {
+        		"code": "USD",
+        		"issuer": "GACETOPHMOLSZLG5IQ3D6KQDKCAAYUYTTQHIEY6IGZE4VOBDD2YY6YAO"
+        	},
+        	"amount": "100",
+        	"price": "` + price + `"
+        }
+    }
+  ],
+  "signers": ["SABY7FRMMJWPBTKQQ2ZN43AUJQ3Z2ZAK36VYSG2SPE2ABNQXA66H5E5G"]
+}`)
+			}
+
+			Convey("decimal and fraction should build the same transaction", func() {
+				statusCode, decimalResponse := net.JSONGetResponse(testServer, request("1.25"))
+				assert.Equal(t, 200, statusCode)
+				statusCode, fractionResponse := net.JSONGetResponse(testServer, request("5/4"))
+				assert.Equal(t, 200, statusCode)
+				assert.Equal(t, string(decimalResponse), string(fractionResponse))
+			})
+
+			Convey("price that cannot be represented exactly should return error", func() {
+				statusCode, response := net.JSONGetResponse(testServer, request("0.00000000001"))
+				responseString := strings.TrimSpace(string(response))
+				assert.Equal(t, 400, statusCode)
+				expected := test.StringToJSONMap(`{
+  "code": "invalid_parameter",
+  "error_code": 101,
+  "message": "Invalid parameter.",
+  "more_info": "Price cannot be represented exactly as a fraction of 32-bit integers. Use fewer decimal places.",
+  "data": {
+    "name": "price"
+  }
 }`)
 				assert.Equal(t, expected, test.StringToJSONMap(responseString))
 			})
@@ -0,0 +1,78 @@
+package handlers
+
+import (
+	"net/http"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/stellar/gateway/protocols"
+	"github.com/stellar/gateway/protocols/bridge"
+	"github.com/stellar/gateway/server"
+	b "github.com/stellar/go/build"
+)
+
+// ChangeTrust implements /change-trust endpoint. It creates, updates or, when `limit` is zero,
+// removes a trustline of the source account.
+func (rh *RequestHandler) ChangeTrust(w http.ResponseWriter, r *http.Request) {
+	request := &bridge.ChangeTrustRequest{}
+	err := request.FromRequest(r)
+	if err != nil {
+		log.Error(err.Error())
+		server.Write(w, protocols.InvalidParameterError)
+		return
+	}
+
+	err = request.Validate()
+	if err != nil {
+		errorResponse := err.(*protocols.ErrorResponse)
+		log.WithFields(errorResponse.LogData).Error(errorResponse.Error())
+		server.Write(w, errorResponse)
+		return
+	}
+
+	// When bearer tokens are configured source account is determined by the token only
+	if len(rh.Config.AuthTokens) > 0 {
+		if request.Source != "" {
+			log.Print("source param sent when bearer token authentication is enabled")
+			server.Write(w, protocols.NewInvalidParameterError("source", "", "Source param is not accepted. Use `Authorization: Bearer` header instead."))
+			return
+		}
+
+		seed, ok := rh.seedFromAuthorization(r)
+		if !ok {
+			log.Print("Missing or invalid bearer token")
+			server.Write(w, protocols.UnauthorizedError)
+			return
+		}
+		request.Source = seed
+	}
+
+	if request.Source == "" {
+		request.Source = rh.Config.Accounts.BaseSeed
+	}
+
+	var operation b.ChangeTrustBuilder
+	switch {
+	case request.RemovesTrust():
+		operation = b.RemoveTrust(request.AssetCode, request.AssetIssuer)
+	case request.Limit != "":
+		operation = b.Trust(request.AssetCode, request.AssetIssuer, b.Limit(request.Limit))
+	default:
+		operation = b.Trust(request.AssetCode, request.AssetIssuer)
+	}
+
+	submitResponse, err := rh.TransactionSubmitter.SubmitTransaction(nil, request.Source, operation, nil)
+	if err != nil {
+		log.WithFields(log.Fields{"err": err}).Error("Error submitting transaction")
+		rh.writeHorizonError(w, err)
+		return
+	}
+
+	errorResponse := rh.errorFromHorizonResponse(submitResponse)
+	if errorResponse != nil {
+		log.WithFields(errorResponse.LogData).Error(errorResponse.Error())
+		server.Write(w, errorResponse)
+		return
+	}
+
+	server.Write(w, &submitResponse)
+}
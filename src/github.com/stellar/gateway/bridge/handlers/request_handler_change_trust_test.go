@@ -0,0 +1,129 @@
+package handlers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+	"github.com/stellar/gateway/bridge/config"
+	"github.com/stellar/gateway/horizon"
+	"github.com/stellar/gateway/mocks"
+	"github.com/stellar/gateway/net"
+	"github.com/stellar/gateway/test"
+	b "github.com/stellar/go/build"
+	"github.com/stellar/go/xdr"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestRequestHandlerChangeTrust(t *testing.T) {
+	mockTransactionSubmitter := new(mocks.MockTransactionSubmitter)
+
+	config := config.Config{
+		Accounts: config.Accounts{
+			BaseSeed: "SDWLS4G3XCNIYPKXJWWGGJT6UDY63WV6PEFTWP7JZMQB4RE7EUJQN5XM",
+		},
+	}
+
+	requestHandler := RequestHandler{Config: &config, TransactionSubmitter: mockTransactionSubmitter}
+	testServer := httptest.NewServer(http.HandlerFunc(requestHandler.ChangeTrust))
+	defer testServer.Close()
+
+	var ledger uint64
+	ledger = 1988728
+	successResponse := horizon.SubmitTransactionResponse{Hash: "6a0049b44e0d0341bd52f131c74383e6ccd2b74b92c829c990994d24bbfcfa7a", Ledger: &ledger}
+
+	Convey("Given change trust request", t, func() {
+		params := url.Values{
+			"asset_code":   {"USD"},
+			"asset_issuer": {"GDSIKW43UA6JTOA47WVEBCZ4MYC74M3GNKNXTVDXFHXYYTNO5GGVN632"},
+		}
+
+		Convey("When limit is invalid", func() {
+			params.Set("limit", "-1")
+
+			Convey("it should return error", func() {
+				statusCode, response := net.GetResponse(testServer, params)
+				responseString := strings.TrimSpace(string(response))
+				assert.Equal(t, 400, statusCode)
+				expected := test.StringToJSONMap(`{
+  "code": "invalid_parameter",
+  "error_code": 101,
+  "message": "Invalid parameter.",
+  "more_info": "Limit is not a valid amount.",
+  "data": {
+    "name": "limit"
+  }
+}`)
+				assert.Equal(t, expected, test.StringToJSONMap(responseString))
+			})
+		})
+
+		Convey("When limit is not sent", func() {
+			mockTransactionSubmitter.On(
+				"SubmitTransaction",
+				(*string)(nil),
+				"SDWLS4G3XCNIYPKXJWWGGJT6UDY63WV6PEFTWP7JZMQB4RE7EUJQN5XM",
+				b.Trust("USD", "GDSIKW43UA6JTOA47WVEBCZ4MYC74M3GNKNXTVDXFHXYYTNO5GGVN632"),
+				nil,
+			).Return(successResponse, nil).Once()
+
+			Convey("it should trust the asset with max limit", func() {
+				statusCode, _ := net.GetResponse(testServer, params)
+				assert.Equal(t, 200, statusCode)
+			})
+		})
+
+		Convey("When limit is sent", func() {
+			params.Set("limit", "1000")
+
+			mockTransactionSubmitter.On(
+				"SubmitTransaction",
+				(*string)(nil),
+				"SDWLS4G3XCNIYPKXJWWGGJT6UDY63WV6PEFTWP7JZMQB4RE7EUJQN5XM",
+				mock.AnythingOfType("build.ChangeTrustBuilder"),
+				nil,
+			).Run(func(args mock.Arguments) {
+				operation := args.Get(2).(b.ChangeTrustBuilder)
+				assert.Equal(t, xdr.Int64(1000*10000000), operation.CT.Limit)
+			}).Return(successResponse, nil).Once()
+
+			Convey("it should set the limit", func() {
+				statusCode, _ := net.GetResponse(testServer, params)
+				assert.Equal(t, 200, statusCode)
+			})
+		})
+
+		Convey("When limit is zero", func() {
+			params.Set("limit", "0")
+
+			mockTransactionSubmitter.On(
+				"SubmitTransaction",
+				(*string)(nil),
+				"SDWLS4G3XCNIYPKXJWWGGJT6UDY63WV6PEFTWP7JZMQB4RE7EUJQN5XM",
+				b.RemoveTrust("USD", "GDSIKW43UA6JTOA47WVEBCZ4MYC74M3GNKNXTVDXFHXYYTNO5GGVN632"),
+				nil,
+			).Return(horizon.SubmitTransactionResponse{
+				Extras: &horizon.SubmitTransactionResponseExtras{
+					// op_invalid_limit
+					ResultXdr: "AAAAAAAAAGT/////AAAAAQAAAAAAAAAG/////QAAAAA=",
+				},
+			}, nil).Once()
+
+			Convey("it should remove the trustline and return error when balance is not zero", func() {
+				statusCode, response := net.GetResponse(testServer, params)
+				responseString := strings.TrimSpace(string(response))
+				assert.Equal(t, 400, statusCode)
+				expected := test.StringToJSONMap(`{
+  "code": "change_trust_invalid_limit",
+  "error_code": 702,
+  "message": "Limit cannot be lower than the balance. Trustline can be removed only when the balance is zero."
+}`)
+				assert.Equal(t, expected, test.StringToJSONMap(responseString))
+			})
+		})
+	})
+}
@@ -0,0 +1,154 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/stellar/gateway/db/entities"
+	"github.com/stellar/gateway/horizon"
+	"github.com/stellar/gateway/protocols"
+	"github.com/stellar/gateway/protocols/bridge"
+)
+
+// complianceQueueBatchSize is the maximum number of queued payments processed in a single run
+const complianceQueueBatchSize = 10
+
+// ProcessComplianceQueue sends payments queued while compliance server was unavailable or pending, oldest
+// first. Processing stops when compliance server is still unavailable. Payments that are pending or got
+// one of `compliance_queue.retry_status_codes` stay in the queue until `compliance_queue.max_attempts`
+// is reached, payments rejected by compliance server or Stellar network are marked as failed. Payments
+// with `id` whose transaction has been sent in a previous attempt are resubmitted instead, payments
+// without `id` whose transaction cannot be submitted are marked as unknown since they may have been sent.
+func (rh *RequestHandler) ProcessComplianceQueue() {
+	payments, err := rh.Repository.GetPendingQueuedPayments(complianceQueueBatchSize)
+	if err != nil {
+		log.WithFields(log.Fields{"err": err}).Error("Error loading queued payments")
+		return
+	}
+
+	for _, payment := range payments {
+		request, err := rh.queuedPaymentRequest(payment)
+		if err != nil {
+			log.WithFields(log.Fields{"id": *payment.ID, "err": err}).Error("Cannot restore queued payment")
+			payment.MarkFailed(err.Error())
+			rh.persistQueuedPayment(payment)
+			continue
+		}
+
+		if request.ID != "" && rh.resubmitQueuedPayment(payment, request.ID) {
+			rh.checkQueuedPaymentAttempts(payment)
+			log.WithFields(log.Fields{"id": *payment.ID, "status": payment.Status}).Info("Processed queued payment")
+			rh.persistQueuedPayment(payment)
+			continue
+		}
+
+		response, err := rh.sendComplianceProtocolPayment(request)
+		if err == errComplianceUnavailable {
+			payment.MarkAttempt(err.Error())
+			rh.checkQueuedPaymentAttempts(payment)
+			rh.persistQueuedPayment(payment)
+			return
+		}
+
+		if err == errComplianceSubmission && request.ID == "" {
+			// Sending a new transaction could pay twice
+			payment.MarkUnknown(err.Error())
+		} else if err != nil {
+			payment.MarkAttempt(err.Error())
+		} else if errorResponse, ok := response.(*protocols.ErrorResponse); ok {
+			if errorResponse.Code == bridge.PaymentPending.Code {
+				payment.MarkAttempt(errorResponse.Code)
+			} else {
+				payment.MarkFailed(errorResponse.Code)
+			}
+		} else {
+			payment.MarkSent()
+		}
+
+		rh.checkQueuedPaymentAttempts(payment)
+		log.WithFields(log.Fields{"id": *payment.ID, "status": payment.Status}).Info("Processed queued payment")
+		rh.persistQueuedPayment(payment)
+	}
+}
+
+// resubmitQueuedPayment resubmits the transaction sent for the queued payment with the given `id` in a
+// previous attempt, the same way /payment does, instead of asking compliance server for a new one. It
+// returns false when no transaction has been sent for the payment yet.
+func (rh *RequestHandler) resubmitQueuedPayment(payment *entities.QueuedPayment, paymentID string) bool {
+	sentTransaction, err := rh.Repository.GetSentTransactionByPaymentID(paymentID)
+	if err != nil {
+		log.WithFields(log.Fields{"id": *payment.ID, "err": err}).Error("Error getting sent transaction")
+		payment.MarkAttempt(err.Error())
+		return true
+	}
+
+	if sentTransaction == nil {
+		return false
+	}
+
+	// Transactions failed in a ledger are found by resubmission and returned as successful
+	if sentTransaction.Status == entities.SentTransactionStatusFailure {
+		errorResponse := protocols.InternalServerError
+		if sentTransaction.ResultXdr != nil {
+			errorResponse = bridge.ErrorFromHorizonResponse(horizon.SubmitTransactionResponse{
+				Extras: &horizon.SubmitTransactionResponseExtras{ResultXdr: *sentTransaction.ResultXdr},
+			})
+		}
+		payment.MarkFailed(errorResponse.Code)
+		return true
+	}
+
+	log.WithFields(log.Fields{"id": *payment.ID, "paymentID": paymentID, "tx": sentTransaction.EnvelopeXdr}).Info("Transaction of queued payment already exists, resubmitting...")
+	response, err := rh.TransactionSubmitter.ResubmitTransaction(sentTransaction.EnvelopeXdr)
+	if err != nil {
+		// Resubmitting the same envelope cannot pay twice
+		payment.MarkAttempt(err.Error())
+	} else if errorResponse := bridge.ErrorFromHorizonResponse(response); errorResponse != nil {
+		payment.MarkFailed(errorResponse.Code)
+	} else {
+		payment.MarkSent()
+	}
+	return true
+}
+
+// queuedPaymentRequest rebuilds payment request from the queued payment adding source seed from the config
+func (rh *RequestHandler) queuedPaymentRequest(payment *entities.QueuedPayment) (*bridge.PaymentRequest, error) {
+	seed := rh.configuredSeed(payment.Source)
+	if seed == "" {
+		return nil, errors.New("Source seed not found in config")
+	}
+
+	r, err := http.NewRequest("POST", "/payment", strings.NewReader(payment.Request))
+	if err != nil {
+		return nil, err
+	}
+	r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	request := &bridge.PaymentRequest{}
+	err = request.FromRequest(r)
+	if err != nil {
+		return nil, err
+	}
+
+	request.Source = seed
+	return request, nil
+}
+
+// checkQueuedPaymentAttempts marks the payment as failed when it's still queued after
+// `compliance_queue.max_attempts` attempts
+func (rh *RequestHandler) checkQueuedPaymentAttempts(payment *entities.QueuedPayment) {
+	maxAttempts := rh.Config.ComplianceQueue.MaxAttempts
+	if maxAttempts > 0 && payment.Status == entities.QueuedPaymentStatusQueued && payment.Attempts >= maxAttempts {
+		log.WithFields(log.Fields{"id": *payment.ID, "attempts": payment.Attempts}).Warn("Queued payment reached max attempts")
+		payment.Status = entities.QueuedPaymentStatusFailed
+	}
+}
+
+func (rh *RequestHandler) persistQueuedPayment(payment *entities.QueuedPayment) {
+	err := rh.EntityManager.Persist(payment)
+	if err != nil {
+		log.WithFields(log.Fields{"id": *payment.ID, "err": err}).Error("Error persisting queued payment")
+	}
+}
@@ -0,0 +1,253 @@
+package handlers
+
+import (
+	"errors"
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+	"github.com/stellar/gateway/bridge/config"
+	"github.com/stellar/gateway/db/entities"
+	"github.com/stellar/gateway/horizon"
+	"github.com/stellar/gateway/mocks"
+	"github.com/stellar/gateway/net"
+	callback "github.com/stellar/gateway/protocols/compliance"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestRequestHandlerProcessComplianceQueue(t *testing.T) {
+	c := &config.Config{
+		NetworkPassphrase: "Test SDF Network ; September 2015",
+		Compliance:        "http://compliance",
+		Accounts: config.Accounts{
+			// GAHA6GRCLCCN7XE2NEEUDSIVOFBOQ6GLSYXVLYCJXJKLPMDR5XB5XZZJ
+			BaseSeed: "SBKKWO3ZVDDEHDJILGHPHCJCFD2GNUAYIUDMRAS326HLUEQ7ZFXWIGQK",
+		},
+		ComplianceQueue: config.ComplianceQueue{
+			Enabled:       true,
+			RetryInterval: 30,
+		},
+	}
+	mockRepository := new(mocks.MockRepository)
+	mockHTTPClient := new(mocks.MockHTTPClient)
+	mockEntityManager := new(mocks.MockEntityManager)
+	mockTransactionSubmitter := new(mocks.MockTransactionSubmitter)
+
+	requestHandler := RequestHandler{
+		Config:               c,
+		Client:               mockHTTPClient,
+		Repository:           mockRepository,
+		EntityManager:        mockEntityManager,
+		TransactionSubmitter: mockTransactionSubmitter,
+	}
+
+	queuedPayment := func(id int64, source string) *entities.QueuedPayment {
+		return &entities.QueuedPayment{
+			ID:      &id,
+			Status:  entities.QueuedPaymentStatusQueued,
+			Source:  source,
+			Request: "amount=20&asset_code=USD&asset_issuer=GAMVF7G4GJC4A7JMFJWLUAEIBFQD5RT3DCB5DC5TJDEKQBBACQ4JZVEE&destination=bob%2Astellar.org&extra_memo=hello+world&sender=alice%2Astellar.org",
+		}
+	}
+
+	Convey("Given queued payments", t, func() {
+		first := queuedPayment(1, "GAHA6GRCLCCN7XE2NEEUDSIVOFBOQ6GLSYXVLYCJXJKLPMDR5XB5XZZJ")
+		second := queuedPayment(2, "GAHA6GRCLCCN7XE2NEEUDSIVOFBOQ6GLSYXVLYCJXJKLPMDR5XB5XZZJ")
+
+		mockRepository.On("GetPendingQueuedPayments", complianceQueueBatchSize).Return(
+			[]*entities.QueuedPayment{first, second},
+			nil,
+		).Once()
+
+		Convey("When compliance server is still unavailable", func() {
+			mockHTTPClient.On(
+				"PostForm",
+				"http://compliance/send",
+				mock.AnythingOfType("url.Values"),
+			).Return(
+				net.BuildHTTPResponse(503, "unavailable"),
+				errors.New("connection refused"),
+			).Once()
+
+			mockEntityManager.On("Persist", first).Return(nil).Once()
+
+			Convey("it should record the attempt and stop", func() {
+				requestHandler.ProcessComplianceQueue()
+				assert.Equal(t, entities.QueuedPaymentStatusQueued, first.Status)
+				assert.Equal(t, 1, first.Attempts)
+				assert.Equal(t, 0, second.Attempts)
+				mockEntityManager.AssertExpectations(t)
+			})
+		})
+
+		Convey("When compliance server responds", func() {
+			mockHTTPClient.On(
+				"PostForm",
+				"http://compliance/send",
+				mock.AnythingOfType("url.Values"),
+			).Return(
+				net.BuildHTTPResponse(200, "{\"auth_response\": {\"info_status\": \"pending\", \"pending\": 3600}}"),
+				nil,
+			).Once()
+			mockHTTPClient.On(
+				"PostForm",
+				"http://compliance/send",
+				mock.AnythingOfType("url.Values"),
+			).Return(
+				net.BuildHTTPResponse(200, "{\"auth_response\": {\"tx_status\": \"denied\"}}"),
+				nil,
+			).Once()
+
+			mockEntityManager.On("Persist", first).Return(nil).Once()
+			mockEntityManager.On("Persist", second).Return(nil).Once()
+
+			Convey("it should keep pending payments and fail denied ones", func() {
+				requestHandler.ProcessComplianceQueue()
+				assert.Equal(t, entities.QueuedPaymentStatusQueued, first.Status)
+				assert.Equal(t, "pending", *first.LastError)
+				assert.Equal(t, entities.QueuedPaymentStatusFailed, second.Status)
+				assert.Equal(t, "denied", *second.LastError)
+				mockEntityManager.AssertExpectations(t)
+			})
+		})
+	})
+
+	Convey("Given queued payments and retry config", t, func() {
+		c.ComplianceQueue.RetryStatusCodes = []int{409}
+		c.ComplianceQueue.MaxAttempts = 3
+		Reset(func() {
+			c.ComplianceQueue.RetryStatusCodes = nil
+			c.ComplianceQueue.MaxAttempts = 0
+		})
+
+		first := queuedPayment(4, "GAHA6GRCLCCN7XE2NEEUDSIVOFBOQ6GLSYXVLYCJXJKLPMDR5XB5XZZJ")
+		second := queuedPayment(5, "GAHA6GRCLCCN7XE2NEEUDSIVOFBOQ6GLSYXVLYCJXJKLPMDR5XB5XZZJ")
+		second.Attempts = 2
+
+		mockRepository.On("GetPendingQueuedPayments", complianceQueueBatchSize).Return(
+			[]*entities.QueuedPayment{first, second},
+			nil,
+		).Once()
+
+		mockHTTPClient.On(
+			"PostForm",
+			"http://compliance/send",
+			mock.AnythingOfType("url.Values"),
+		).Return(
+			net.BuildHTTPResponse(409, "manual review in progress"),
+			nil,
+		).Twice()
+
+		mockEntityManager.On("Persist", first).Return(nil).Once()
+		mockEntityManager.On("Persist", second).Return(nil).Once()
+
+		Convey("it should continue processing and fail payments reaching max attempts", func() {
+			requestHandler.ProcessComplianceQueue()
+			assert.Equal(t, entities.QueuedPaymentStatusQueued, first.Status)
+			assert.Equal(t, 1, first.Attempts)
+			assert.Equal(t, entities.QueuedPaymentStatusFailed, second.Status)
+			assert.Equal(t, 3, second.Attempts)
+			assert.Equal(t, "Transient error response from compliance server", *second.LastError)
+			mockEntityManager.AssertExpectations(t)
+		})
+	})
+
+	Convey("Given queued payment whose transaction submission failed", t, func() {
+		complianceResponse := callback.SendResponse{
+			TransactionXdr: "AAAAAC3/58Z9rycNLmF6voWX9VmDETFVGhFoWf66mcMuir/DAAAAZAAAAAAAAAAAAAAAAAAAAAO5TSe5k00+CKUuUtfafav6xITv43pTgO6QiPes4u/N6QAAAAEAAAAAAAAAAQAAAAAZUvzcMkXAfSwqbLoAiAlgPsZ7GIPRi7NIyKgEIBQ4nAAAAAFVU0QAAAAAABlS/NwyRcB9LCpsugCICWA+xnsYg9GLs0jIqAQgFDicAAAAAAvrwgAAAAAA",
+		}
+		payment := queuedPayment(6, "GAHA6GRCLCCN7XE2NEEUDSIVOFBOQ6GLSYXVLYCJXJKLPMDR5XB5XZZJ")
+		// Transaction is built for a different source
+		c.ComplianceCheck = "none"
+		Reset(func() { c.ComplianceCheck = "" })
+
+		mockRepository.On("GetPendingQueuedPayments", complianceQueueBatchSize).Return(
+			[]*entities.QueuedPayment{payment},
+			nil,
+		).Once()
+		mockEntityManager.On("Persist", payment).Return(nil).Once()
+
+		Convey("When payment has no id", func() {
+			mockHTTPClient.On(
+				"PostForm",
+				"http://compliance/send",
+				mock.AnythingOfType("url.Values"),
+			).Return(
+				net.BuildHTTPResponse(200, string(complianceResponse.Marshal())),
+				nil,
+			).Once()
+			mockTransactionSubmitter.On(
+				"SignAndSubmitRawTransaction",
+				(*string)(nil),
+				"SBKKWO3ZVDDEHDJILGHPHCJCFD2GNUAYIUDMRAS326HLUEQ7ZFXWIGQK",
+				mock.AnythingOfType("*xdr.Transaction"),
+			).Return(horizon.SubmitTransactionResponse{}, errors.New("timeout")).Once()
+
+			Convey("it should mark the payment as unknown", func() {
+				requestHandler.ProcessComplianceQueue()
+				assert.Equal(t, entities.QueuedPaymentStatusUnknown, payment.Status)
+				assert.Equal(t, "Error submitting compliance transaction", *payment.LastError)
+				mockEntityManager.AssertExpectations(t)
+			})
+		})
+
+		Convey("When payment with id has been sent in a previous attempt", func() {
+			payment.Request += "&id=queued-1"
+			sentTransaction := &entities.SentTransaction{
+				Status:      entities.SentTransactionStatusSending,
+				EnvelopeXdr: "envelope_xdr",
+			}
+			mockRepository.On("GetSentTransactionByPaymentID", "queued-1").Return(sentTransaction, nil).Once()
+
+			Convey("it should resubmit the transaction instead of sending a new one", func() {
+				calls := len(mockHTTPClient.Calls)
+				var ledger uint64 = 1988727
+				mockTransactionSubmitter.On("ResubmitTransaction", "envelope_xdr").Return(
+					horizon.SubmitTransactionResponse{Hash: "6a0049b44e0d0341bd52f131c74383e6ccd2b74b92c829c990994d24bbfcfa7a", Ledger: &ledger},
+					nil,
+				).Once()
+
+				requestHandler.ProcessComplianceQueue()
+				assert.Equal(t, entities.QueuedPaymentStatusSent, payment.Status)
+				assert.Len(t, mockHTTPClient.Calls, calls)
+				mockEntityManager.AssertExpectations(t)
+			})
+
+			Convey("it should keep the payment queued when resubmission fails", func() {
+				mockTransactionSubmitter.On("ResubmitTransaction", "envelope_xdr").Return(
+					horizon.SubmitTransactionResponse{},
+					errors.New("timeout"),
+				).Once()
+
+				requestHandler.ProcessComplianceQueue()
+				assert.Equal(t, entities.QueuedPaymentStatusQueued, payment.Status)
+				assert.Equal(t, "timeout", *payment.LastError)
+			})
+
+			Convey("it should mark the payment as failed when the transaction failed", func() {
+				resultXdr := "AAAAAAAAAGT/////AAAAAQAAAAAAAAAB/////gAAAAA=" // payment_underfunded
+				sentTransaction.MarkFailed(resultXdr)
+
+				requestHandler.ProcessComplianceQueue()
+				assert.Equal(t, entities.QueuedPaymentStatusFailed, payment.Status)
+				assert.Equal(t, "payment_underfunded", *payment.LastError)
+			})
+		})
+	})
+
+	Convey("Given queued payment of an account without seed in the config", t, func() {
+		unknown := queuedPayment(3, "GAW77Z6GPWXSODJOMF5L5BMX6VMYGEJRKUNBC2CZ725JTQZORK74HQQD")
+		mockRepository.On("GetPendingQueuedPayments", complianceQueueBatchSize).Return(
+			[]*entities.QueuedPayment{unknown},
+			nil,
+		).Once()
+
+		mockEntityManager.On("Persist", unknown).Return(nil).Once()
+
+		Convey("it should mark the payment as failed", func() {
+			requestHandler.ProcessComplianceQueue()
+			assert.Equal(t, entities.QueuedPaymentStatusFailed, unknown.Status)
+			assert.Equal(t, "Source seed not found in config", *unknown.LastError)
+		})
+	})
+}
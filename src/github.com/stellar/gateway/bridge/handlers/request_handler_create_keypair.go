@@ -4,6 +4,7 @@ import (
 	"encoding/json"
 	log "github.com/sirupsen/logrus"
 	"net/http"
+	"strconv"
 
 	"github.com/stellar/gateway/protocols"
 	"github.com/stellar/gateway/server"
@@ -32,3 +33,43 @@ func (rh *RequestHandler) CreateKeypair(w http.ResponseWriter, r *http.Request)
 
 	w.Write(response)
 }
+
+// maxKeypairs is the maximum number of key pairs generated by a single /keypair request
+const maxKeypairs = 100
+
+// Keypair implements /keypair endpoint. It creates `count` (default: 1) new random key pairs.
+func (rh *RequestHandler) Keypair(w http.ResponseWriter, r *http.Request) {
+	count := 1
+	if value := r.PostFormValue("count"); value != "" {
+		var err error
+		count, err = strconv.Atoi(value)
+		if err != nil || count < 1 || count > maxKeypairs {
+			log.WithFields(log.Fields{"count": value}).Print("Invalid count param")
+			server.Write(w, protocols.NewInvalidParameterError("count", value, "Count must be a number between 1 and "+strconv.Itoa(maxKeypairs)+"."))
+			return
+		}
+	}
+
+	keypairs := make([]KeyPair, 0, count)
+	for i := 0; i < count; i++ {
+		kp, err := keypair.Random()
+		if err != nil {
+			log.WithFields(log.Fields{"err": err}).Error("Error generating random keypair")
+			server.Write(w, protocols.InternalServerError)
+			return
+		}
+		keypairs = append(keypairs, KeyPair{kp.Address(), kp.Seed()})
+	}
+
+	response, err := json.Marshal(struct {
+		Keypairs []KeyPair `json:"keypairs"`
+	}{keypairs})
+	if err != nil {
+		log.WithFields(log.Fields{"err": err}).Error("Error marshalling random keypairs")
+		server.Write(w, protocols.InternalServerError)
+		return
+	}
+
+	log.WithFields(log.Fields{"count": count}).Info("Generated random keypairs")
+	w.Write(response)
+}
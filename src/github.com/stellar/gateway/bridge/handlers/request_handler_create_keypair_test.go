@@ -0,0 +1,68 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+	"github.com/stellar/gateway/bridge/config"
+	"github.com/stellar/gateway/net"
+	"github.com/stellar/gateway/test"
+	"github.com/stellar/go/keypair"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRequestHandlerKeypair(t *testing.T) {
+	requestHandler := RequestHandler{Config: &config.Config{}}
+
+	testServer := httptest.NewServer(http.HandlerFunc(requestHandler.Keypair))
+	defer testServer.Close()
+
+	Convey("Given admin keypair request", t, func() {
+		Convey("When count is invalid", func() {
+			params := url.Values{"count": {"101"}}
+
+			Convey("it should return error", func() {
+				statusCode, response := net.GetResponse(testServer, params)
+				responseString := strings.TrimSpace(string(response))
+				assert.Equal(t, 400, statusCode)
+				expected := test.StringToJSONMap(`{
+  "code": "invalid_parameter",
+  "error_code": 101,
+  "message": "Invalid parameter.",
+  "data": {
+    "name": "count"
+  }
+}`)
+				assert.Equal(t, expected, test.StringToJSONMap(responseString, "more_info"))
+			})
+		})
+
+		Convey("When count is valid", func() {
+			params := url.Values{"count": {"3"}}
+
+			Convey("it should return new keypairs", func() {
+				statusCode, response := net.GetResponse(testServer, params)
+				assert.Equal(t, 200, statusCode)
+
+				var body struct {
+					Keypairs []KeyPair `json:"keypairs"`
+				}
+				require.NoError(t, json.Unmarshal(response, &body))
+				require.Len(t, body.Keypairs, 3)
+
+				for _, pair := range body.Keypairs {
+					kp, err := keypair.Parse(pair.PrivateKey)
+					require.NoError(t, err)
+					assert.Equal(t, kp.Address(), pair.PublicKey)
+				}
+				assert.NotEqual(t, body.Keypairs[0].PublicKey, body.Keypairs[1].PublicKey)
+			})
+		})
+	})
+}
@@ -0,0 +1,48 @@
+package handlers
+
+import (
+	"net/http"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/stellar/gateway/protocols"
+	"github.com/stellar/gateway/protocols/bridge"
+	"github.com/stellar/gateway/server"
+)
+
+// Effects implements /effects endpoint
+func (rh *RequestHandler) Effects(w http.ResponseWriter, r *http.Request) {
+	request := &bridge.EffectsRequest{}
+	err := request.FromRequest(r)
+	if err != nil {
+		log.Error(err.Error())
+		server.Write(w, protocols.InvalidParameterError)
+		return
+	}
+
+	err = request.Validate()
+	if err != nil {
+		errorResponse := err.(*protocols.ErrorResponse)
+		log.WithFields(errorResponse.LogData).Error(errorResponse.Error())
+		server.Write(w, errorResponse)
+		return
+	}
+
+	effects, err := rh.Horizon.LoadTransactionEffects(request.Hash)
+	if err != nil {
+		log.WithFields(log.Fields{"hash": request.Hash, "err": err}).Error("Error loading transaction effects")
+		rh.writeHorizonError(w, err)
+		return
+	}
+
+	if effects == nil {
+		server.Write(w, bridge.EffectsTransactionNotFound)
+		return
+	}
+
+	response := bridge.EffectsResponse{Hash: request.Hash, Effects: make([]bridge.Effect, len(effects))}
+	for i, effect := range effects {
+		response.Effects[i] = bridge.NewEffect(effect)
+	}
+
+	server.Write(w, response)
+}
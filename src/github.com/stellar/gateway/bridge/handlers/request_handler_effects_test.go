@@ -0,0 +1,168 @@
+package handlers
+
+import (
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	. "github.com/smartystreets/goconvey/convey"
+	"github.com/stellar/gateway/horizon"
+	"github.com/stellar/gateway/mocks"
+	"github.com/stellar/gateway/net"
+	"github.com/stellar/gateway/test"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRequestHandlerEffects(t *testing.T) {
+	mockHorizon := new(mocks.MockHorizon)
+	requestHandler := RequestHandler{Horizon: mockHorizon}
+
+	testServer := httptest.NewServer(http.HandlerFunc(requestHandler.Effects))
+	defer testServer.Close()
+
+	rawErrorsServer := httptest.NewServer(http.HandlerFunc(requestHandler.WithRawErrors().Effects))
+	defer rawErrorsServer.Close()
+
+	hash := "6a0049b44e0d0341bd52f131c74383e6ccd2b74b92c829c990994d24bbfcfa7a"
+
+	Convey("Given effects request", t, func() {
+		Convey("When hash is invalid", func() {
+			params := url.Values{"hash": {"abc"}}
+
+			Convey("it should return error", func() {
+				statusCode, response := net.GetResponse(testServer, params)
+				responseString := strings.TrimSpace(string(response))
+				assert.Equal(t, 400, statusCode)
+				expected := test.StringToJSONMap(`{
+  "code": "invalid_parameter",
+  "error_code": 101,
+  "message": "Invalid parameter.",
+  "more_info": "Hash must be 64 hex characters.",
+  "data": {
+    "name": "hash"
+  }
+}`)
+				assert.Equal(t, expected, test.StringToJSONMap(responseString))
+			})
+		})
+
+		Convey("When transaction does not exist", func() {
+			mockHorizon.On("LoadTransactionEffects", hash).Return([]horizon.EffectResponse(nil), nil).Once()
+
+			Convey("it should return error", func() {
+				statusCode, response := net.GetResponse(testServer, url.Values{"hash": {hash}})
+				responseString := strings.TrimSpace(string(response))
+				assert.Equal(t, 404, statusCode)
+				expected := test.StringToJSONMap(`{
+  "code": "transaction_not_found",
+  "error_code": 600,
+  "message": "Transaction not found."
+}`)
+				assert.Equal(t, expected, test.StringToJSONMap(responseString))
+			})
+		})
+
+		Convey("When Horizon fails", func() {
+			mockHorizon.On("LoadTransactionEffects", hash).Return([]horizon.EffectResponse(nil), errors.New("Horizon error")).Once()
+
+			Convey("it should return error", func() {
+				statusCode, _ := net.GetResponse(testServer, url.Values{"hash": {hash}})
+				assert.Equal(t, 500, statusCode)
+			})
+		})
+
+		Convey("When Horizon responds with error and raw errors are requested", func() {
+			problem := `{"type": "https://stellar.org/horizon-errors/server_error", "title": "Internal Server Error", "status": 500}`
+			mockHorizon.On("LoadTransactionEffects", hash).Return([]horizon.EffectResponse(nil), &horizon.Error{Status: 500, Problem: []byte(problem)}).Once()
+
+			Convey("it should return error with Horizon error document", func() {
+				statusCode, response := net.GetResponse(rawErrorsServer, url.Values{"hash": {hash}})
+				responseString := strings.TrimSpace(string(response))
+				assert.Equal(t, 500, statusCode)
+				expected := test.StringToJSONMap(`{
+  "code": "internal_server_error",
+  "error_code": 100,
+  "message": "Internal Server Error, please try again.",
+  "horizon_error": {
+    "type": "https://stellar.org/horizon-errors/server_error",
+    "title": "Internal Server Error",
+    "status": 500
+  }
+}`)
+				assert.Equal(t, expected, test.StringToJSONMap(responseString))
+			})
+		})
+
+		Convey("When Horizon rate limits the bridge", func() {
+			err := &url.Error{Op: "Get", URL: "https://horizon.stellar.org", Err: &horizon.RateLimitedError{RetryAfter: 1500 * time.Millisecond}}
+			mockHorizon.On("LoadTransactionEffects", hash).Return([]horizon.EffectResponse(nil), err).Once()
+
+			Convey("it should return error with retry hint", func() {
+				resp, err := http.PostForm(testServer.URL, url.Values{"hash": {hash}})
+				require.NoError(t, err)
+				defer resp.Body.Close()
+				response, _ := ioutil.ReadAll(resp.Body)
+
+				assert.Equal(t, 503, resp.StatusCode)
+				assert.Equal(t, "2", resp.Header.Get("Retry-After"))
+				expected := test.StringToJSONMap(`{
+  "code": "horizon_rate_limited",
+  "error_code": 105,
+  "message": "Horizon server is rate limiting requests, please try again later.",
+  "data": {
+    "retry_after": 2
+  }
+}`)
+				assert.Equal(t, expected, test.StringToJSONMap(string(response)))
+			})
+		})
+
+		Convey("When transaction exists", func() {
+			mockHorizon.On("LoadTransactionEffects", hash).Return([]horizon.EffectResponse{
+				{ID: "0000000012884905985-0000000001", Type: "account_created", Account: "GDSIKW43UA6JTOA47WVEBCZ4MYC74M3GNKNXTVDXFHXYYTNO5GGVN632", StartingBalance: "20.0000000"},
+				{ID: "0000000012884905985-0000000002", Type: "account_debited", Account: "GCF3WVYTHF75PEG6622G5G6KU26GOSDQPDHSCJ3DQD7VONH4EYVDOGKJ", Amount: "20.0000000"},
+				{ID: "0000000012884905986-0000000001", Type: "account_credited", Account: "GDSIKW43UA6JTOA47WVEBCZ4MYC74M3GNKNXTVDXFHXYYTNO5GGVN632", Amount: "5.0000000", AssetType: "credit_alphanum4", AssetCode: "USD", AssetIssuer: "GBDOSO3K4JTGSWJSIHXAOFIBMAABVM3YK3FI6VJPKIHHM56XAFIUCGD6"},
+			}, nil).Once()
+
+			Convey("it should return normalized effects", func() {
+				statusCode, response := net.GetResponse(testServer, url.Values{"hash": {hash}})
+				responseString := strings.TrimSpace(string(response))
+				assert.Equal(t, 200, statusCode)
+				expected := test.StringToJSONMap(`{
+  "hash": "6a0049b44e0d0341bd52f131c74383e6ccd2b74b92c829c990994d24bbfcfa7a",
+  "effects": [
+    {
+      "id": "0000000012884905985-0000000001",
+      "type": "account_created",
+      "account": "GDSIKW43UA6JTOA47WVEBCZ4MYC74M3GNKNXTVDXFHXYYTNO5GGVN632",
+      "amount": "20.0000000",
+      "asset_type": "native"
+    },
+    {
+      "id": "0000000012884905985-0000000002",
+      "type": "account_debited",
+      "account": "GCF3WVYTHF75PEG6622G5G6KU26GOSDQPDHSCJ3DQD7VONH4EYVDOGKJ",
+      "amount": "20.0000000"
+    },
+    {
+      "id": "0000000012884905986-0000000001",
+      "type": "account_credited",
+      "account": "GDSIKW43UA6JTOA47WVEBCZ4MYC74M3GNKNXTVDXFHXYYTNO5GGVN632",
+      "amount": "5.0000000",
+      "asset_type": "credit_alphanum4",
+      "asset_code": "USD",
+      "asset_issuer": "GBDOSO3K4JTGSWJSIHXAOFIBMAABVM3YK3FI6VJPKIHHM56XAFIUCGD6"
+    }
+  ]
+}`)
+				assert.Equal(t, expected, test.StringToJSONMap(responseString))
+			})
+		})
+	})
+}
@@ -0,0 +1,62 @@
+package handlers
+
+import (
+	"net/http"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/stellar/gateway/external"
+	"github.com/stellar/gateway/protocols"
+	"github.com/stellar/gateway/protocols/bridge"
+	"github.com/stellar/gateway/server"
+	"github.com/stellar/go/support/errors"
+)
+
+// Federation implements /federation endpoint. It resolves a Stellar address to the account ID
+// and memo without sending a payment.
+func (rh *RequestHandler) Federation(w http.ResponseWriter, r *http.Request) {
+	request := &bridge.FederationRequest{}
+	err := request.FromRequest(r)
+	if err != nil {
+		log.Error(err.Error())
+		server.Write(w, protocols.InvalidParameterError)
+		return
+	}
+
+	err = request.Validate()
+	if err != nil {
+		errorResponse := err.(*protocols.ErrorResponse)
+		log.WithFields(errorResponse.LogData).Error(errorResponse.Error())
+		server.Write(w, errorResponse)
+		return
+	}
+
+	nameResponse, err := rh.FederationResolver.LookupByAddress(request.Address)
+	if err != nil {
+		log.WithFields(log.Fields{"address": request.Address, "err": err}).Print("Cannot resolve address")
+		server.Write(w, federationLookupError(err))
+		return
+	}
+
+	if !protocols.IsValidAccountID(nameResponse.AccountID) {
+		log.WithFields(log.Fields{"address": request.Address, "account_id": nameResponse.AccountID}).Print("Invalid account ID in federation response")
+		server.Write(w, bridge.PaymentCannotResolveDestination)
+		return
+	}
+
+	server.Write(w, bridge.FederationResponse{
+		Address:   request.Address,
+		AccountID: nameResponse.AccountID,
+		MemoType:  nameResponse.MemoType,
+		Memo:      nameResponse.Memo.Value,
+	})
+}
+
+// federationLookupError returns the error response of a failed federation lookup:
+// PaymentFederationDiscoveryFailed when stellar.toml file of the domain cannot be fetched,
+// PaymentCannotResolveDestination otherwise
+func federationLookupError(err error) *protocols.ErrorResponse {
+	if discoveryErr, ok := errors.Cause(err).(*external.StellarTomlDiscoveryError); ok {
+		return bridge.NewPaymentFederationDiscoveryFailedError(discoveryErr.Domain)
+	}
+	return bridge.PaymentCannotResolveDestination
+}
@@ -0,0 +1,112 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+	"github.com/stellar/gateway/external"
+	"github.com/stellar/gateway/mocks"
+	"github.com/stellar/gateway/net"
+	"github.com/stellar/gateway/test"
+	"github.com/stellar/go/protocols/federation"
+	goerrors "github.com/stellar/go/support/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRequestHandlerFederation(t *testing.T) {
+	mockFederationResolver := new(mocks.MockFederationResolver)
+	requestHandler := RequestHandler{FederationResolver: mockFederationResolver}
+
+	testServer := httptest.NewServer(http.HandlerFunc(requestHandler.Federation))
+	defer testServer.Close()
+
+	Convey("Given federation request", t, func() {
+		Convey("When address is invalid", func() {
+			params := url.Values{"address": {"GDSIKW43UA6JTOA47WVEBCZ4MYC74M3GNKNXTVDXFHXYYTNO5GGVN632"}}
+
+			Convey("it should return error", func() {
+				statusCode, response := net.GetResponse(testServer, params)
+				responseString := strings.TrimSpace(string(response))
+				assert.Equal(t, 400, statusCode)
+				expected := test.StringToJSONMap(`{
+  "code": "invalid_parameter",
+  "error_code": 101,
+  "message": "Invalid parameter.",
+  "more_info": "Address must be a Stellar address (like bob*stellar.org).",
+  "data": {
+    "name": "address"
+  }
+}`)
+				assert.Equal(t, expected, test.StringToJSONMap(responseString))
+			})
+		})
+
+		Convey("When address cannot be resolved", func() {
+			mockFederationResolver.On("LookupByAddress", "alice*stellar.org").Return(
+				&federation.NameResponse{},
+				errors.New("stellar.toml response status code indicates error"),
+			).Once()
+
+			Convey("it should return error", func() {
+				statusCode, response := net.GetResponse(testServer, url.Values{"address": {"alice*stellar.org"}})
+				responseString := strings.TrimSpace(string(response))
+				assert.Equal(t, 400, statusCode)
+				expected := test.StringToJSONMap(`{
+  "code": "cannot_resolve_destination",
+  "error_code": 300,
+  "message": "Cannot resolve federated Stellar address."
+}`)
+				assert.Equal(t, expected, test.StringToJSONMap(responseString))
+			})
+		})
+
+		Convey("When stellar.toml of the domain cannot be fetched", func() {
+			mockFederationResolver.On("LookupByAddress", "carol*stellar.org").Return(
+				&federation.NameResponse{},
+				goerrors.Wrap(&external.StellarTomlDiscoveryError{Domain: "stellar.org", Err: errors.New("connection refused")}, "get stellar.toml failed"),
+			).Once()
+
+			Convey("it should return discovery error", func() {
+				statusCode, response := net.GetResponse(testServer, url.Values{"address": {"carol*stellar.org"}})
+				responseString := strings.TrimSpace(string(response))
+				assert.Equal(t, 502, statusCode)
+				expected := test.StringToJSONMap(`{
+  "code": "federation_discovery_failed",
+  "error_code": 317,
+  "message": "Cannot fetch stellar.toml file of the federation domain. Repeat your request later.",
+  "data": {
+    "domain": "stellar.org"
+  }
+}`)
+				assert.Equal(t, expected, test.StringToJSONMap(responseString))
+			})
+		})
+
+		Convey("When address is resolved", func() {
+			nameResponse := &federation.NameResponse{
+				AccountID: "GDSIKW43UA6JTOA47WVEBCZ4MYC74M3GNKNXTVDXFHXYYTNO5GGVN632",
+				MemoType:  "id",
+			}
+			nameResponse.Memo.Value = "123"
+			mockFederationResolver.On("LookupByAddress", "bob*stellar.org").Return(nameResponse, nil).Once()
+
+			Convey("it should return account ID and memo", func() {
+				statusCode, response := net.GetResponse(testServer, url.Values{"address": {"bob*stellar.org"}})
+				responseString := strings.TrimSpace(string(response))
+				assert.Equal(t, 200, statusCode)
+				expected := test.StringToJSONMap(`{
+  "address": "bob*stellar.org",
+  "account_id": "GDSIKW43UA6JTOA47WVEBCZ4MYC74M3GNKNXTVDXFHXYYTNO5GGVN632",
+  "memo_type": "id",
+  "memo": "123"
+}`)
+				assert.Equal(t, expected, test.StringToJSONMap(responseString))
+			})
+		})
+	})
+}
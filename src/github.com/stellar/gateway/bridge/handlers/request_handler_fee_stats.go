@@ -0,0 +1,106 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/stellar/gateway/horizon"
+	"github.com/stellar/gateway/protocols"
+	"github.com/stellar/gateway/protocols/bridge"
+	"github.com/stellar/gateway/server"
+)
+
+// FeeStats implements /fee-stats endpoint. It returns percentiles of fees per operation of the last
+// ledgers loaded from Horizon (cached for `fee_stats.cache_ttl`) and a fee recommended for the
+// requested confidence, which clients can use as `per_op_fee` of batches or `submission.max_base_fee`
+// during congestion.
+func (rh *RequestHandler) FeeStats(w http.ResponseWriter, r *http.Request) {
+	request := &bridge.FeeStatsRequest{}
+	err := request.FromRequest(r)
+	if err != nil {
+		log.Error(err.Error())
+		server.Write(w, protocols.InvalidParameterError)
+		return
+	}
+
+	err = request.Validate()
+	if err != nil {
+		errorResponse := err.(*protocols.ErrorResponse)
+		log.WithFields(errorResponse.LogData).Error(errorResponse.Error())
+		server.Write(w, errorResponse)
+		return
+	}
+
+	confidence := request.Confidence
+	if confidence == "" {
+		confidence = rh.Config.FeeStats.Confidence
+	}
+
+	stats, err := rh.FeeStatsCache.FeeStats()
+	if err != nil {
+		log.WithFields(log.Fields{"err": err}).Error("Error loading fee stats")
+		rh.writeHorizonError(w, err)
+		return
+	}
+
+	response, err := feeStatsResponse(stats, confidence)
+	if err != nil {
+		log.WithFields(log.Fields{"err": err}).Error("Invalid fee stats returned by Horizon")
+		server.Write(w, protocols.InternalServerError)
+		return
+	}
+
+	server.Write(w, response)
+}
+
+// feeStatsResponse converts fee stats returned by Horizon to bridge.FeeStatsResponse
+func feeStatsResponse(stats horizon.FeeStatsResponse, confidence string) (response bridge.FeeStatsResponse, err error) {
+	response.LastLedger, err = strconv.ParseInt(stats.LastLedger, 10, 64)
+	if err != nil {
+		return
+	}
+
+	response.BaseFee, err = strconv.ParseInt(stats.LastLedgerBaseFee, 10, 64)
+	if err != nil {
+		return
+	}
+
+	response.FeeCharged, err = feePercentiles(stats.FeeCharged)
+	if err != nil {
+		return
+	}
+
+	response.MaxFee, err = feePercentiles(stats.MaxFee)
+	if err != nil {
+		return
+	}
+
+	response.LedgerCapacityUsage = stats.LedgerCapacityUsage
+	response.Confidence = confidence
+	response.RecommendedFee = response.MaxFee.Get(confidence)
+	if response.RecommendedFee < response.BaseFee {
+		response.RecommendedFee = response.BaseFee
+	}
+	return
+}
+
+func feePercentiles(distribution horizon.FeeDistribution) (percentiles bridge.FeePercentiles, err error) {
+	values := []struct {
+		value  string
+		target *int64
+	}{
+		{distribution.P10, &percentiles.P10},
+		{distribution.P50, &percentiles.P50},
+		{distribution.P90, &percentiles.P90},
+		{distribution.P99, &percentiles.P99},
+	}
+
+	for _, v := range values {
+		*v.target, err = strconv.ParseInt(v.value, 10, 64)
+		if err != nil {
+			return
+		}
+	}
+	return
+}
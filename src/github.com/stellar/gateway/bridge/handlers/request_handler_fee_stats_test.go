@@ -0,0 +1,104 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	. "github.com/smartystreets/goconvey/convey"
+	"github.com/stellar/gateway/bridge/config"
+	"github.com/stellar/gateway/horizon"
+	"github.com/stellar/gateway/mocks"
+	"github.com/stellar/gateway/net"
+	"github.com/stellar/gateway/test"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRequestHandlerFeeStats(t *testing.T) {
+	c := &config.Config{FeeStats: config.FeeStats{Confidence: "p90"}}
+	mockHorizon := new(mocks.MockHorizon)
+	requestHandler := RequestHandler{
+		Config:        c,
+		Horizon:       mockHorizon,
+		FeeStatsCache: horizon.NewFeeStatsCache(mockHorizon, 0, time.Now),
+	}
+
+	testServer := httptest.NewServer(http.HandlerFunc(requestHandler.FeeStats))
+	defer testServer.Close()
+
+	stats := horizon.FeeStatsResponse{
+		LastLedger:          "22606298",
+		LastLedgerBaseFee:   "100",
+		LedgerCapacityUsage: "0.97",
+		FeeCharged:          horizon.FeeDistribution{P10: "100", P50: "200", P90: "1000", P99: "3000"},
+		MaxFee:              horizon.FeeDistribution{P10: "50", P50: "500", P90: "2000", P99: "10000"},
+	}
+
+	Convey("Given fee stats request", t, func() {
+		Convey("When confidence is invalid", func() {
+			params := url.Values{"confidence": {"p75"}}
+
+			Convey("it should return error", func() {
+				statusCode, response := net.GetResponse(testServer, params)
+				responseString := strings.TrimSpace(string(response))
+				assert.Equal(t, 400, statusCode)
+				expected := test.StringToJSONMap(`{
+  "code": "invalid_parameter",
+  "error_code": 101,
+  "message": "Invalid parameter.",
+  "more_info": "Confidence must be one of: p10, p50, p90, p99.",
+  "data": {
+    "name": "confidence"
+  }
+}`)
+				assert.Equal(t, expected, test.StringToJSONMap(responseString))
+			})
+		})
+
+		Convey("When confidence is not sent", func() {
+			mockHorizon.On("LoadFeeStats").Return(stats, nil).Once()
+
+			Convey("it should recommend fee for configured confidence", func() {
+				statusCode, response := net.GetResponse(testServer, url.Values{})
+				responseString := strings.TrimSpace(string(response))
+				assert.Equal(t, 200, statusCode)
+				expected := test.StringToJSONMap(`{
+  "last_ledger": 22606298,
+  "base_fee": 100,
+  "ledger_capacity_usage": "0.97",
+  "fee_charged": {"p10": 100, "p50": 200, "p90": 1000, "p99": 3000},
+  "max_fee": {"p10": 50, "p50": 500, "p90": 2000, "p99": 10000},
+  "confidence": "p90",
+  "recommended_fee": 2000
+}`)
+				assert.Equal(t, expected, test.StringToJSONMap(responseString))
+			})
+		})
+
+		Convey("When percentile is lower than base fee", func() {
+			mockHorizon.On("LoadFeeStats").Return(stats, nil).Once()
+
+			Convey("it should recommend base fee", func() {
+				statusCode, response := net.GetResponse(testServer, url.Values{"confidence": {"p10"}})
+				responseString := strings.TrimSpace(string(response))
+				assert.Equal(t, 200, statusCode)
+				responseMap := test.StringToJSONMap(responseString)
+				assert.Equal(t, "p10", responseMap["confidence"])
+				assert.Equal(t, float64(100), responseMap["recommended_fee"])
+			})
+		})
+
+		Convey("When Horizon responds with error", func() {
+			mockHorizon.On("LoadFeeStats").Return(horizon.FeeStatsResponse{}, errors.New("connection refused")).Once()
+
+			Convey("it should return error", func() {
+				statusCode, _ := net.GetResponse(testServer, url.Values{})
+				assert.Equal(t, 500, statusCode)
+			})
+		})
+	})
+}
@@ -0,0 +1,47 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/stellar/gateway/protocols"
+	"github.com/stellar/gateway/protocols/bridge"
+	"github.com/stellar/gateway/server"
+)
+
+// Health implements /health endpoint. It keeps working when the server is paused for maintenance.
+func (rh *RequestHandler) Health(w http.ResponseWriter, r *http.Request) {
+	response := bridge.HealthResponse{Status: "ok"}
+	if rh.Maintenance.Paused() {
+		response.Status = "paused"
+		response.Paused = true
+	}
+
+	server.Write(w, response)
+}
+
+// AdminMaintenance implements /admin/maintenance endpoint. POST requests pause (`paused=true`) or
+// resume (`paused=false`) endpoints changing state, like /payment. Both GET and POST requests
+// return the current state.
+func (rh *RequestHandler) AdminMaintenance(w http.ResponseWriter, r *http.Request) {
+	if r.Method == http.MethodPost {
+		value := r.PostFormValue("paused")
+		if value == "" {
+			server.Write(w, protocols.NewMissingParameter("paused"))
+			return
+		}
+
+		paused, err := strconv.ParseBool(value)
+		if err != nil {
+			log.WithFields(log.Fields{"paused": value}).Print("Invalid paused param")
+			server.Write(w, protocols.NewInvalidParameterError("paused", value, "Paused must be `true` or `false`."))
+			return
+		}
+
+		rh.Maintenance.SetPaused(paused)
+		log.WithFields(log.Fields{"paused": paused}).Warn("Maintenance state changed")
+	}
+
+	server.Write(w, bridge.MaintenanceResponse{Paused: rh.Maintenance.Paused()})
+}
@@ -0,0 +1,72 @@
+package handlers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+	"github.com/stellar/gateway/bridge/config"
+	"github.com/stellar/gateway/net"
+	"github.com/stellar/gateway/server"
+	"github.com/stellar/gateway/test"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRequestHandlerMaintenance(t *testing.T) {
+	requestHandler := RequestHandler{Config: &config.Config{}, Maintenance: &server.Maintenance{}}
+	adminServer := httptest.NewServer(http.HandlerFunc(requestHandler.AdminMaintenance))
+	defer adminServer.Close()
+	healthServer := httptest.NewServer(http.HandlerFunc(requestHandler.Health))
+	defer healthServer.Close()
+
+	Convey("Given admin maintenance request", t, func() {
+		Reset(func() {
+			requestHandler.Maintenance.SetPaused(false)
+		})
+
+		Convey("When paused param is invalid", func() {
+			Convey("it should return error", func() {
+				statusCode, response := net.GetResponse(adminServer, url.Values{"paused": {"maybe"}})
+				assert.Equal(t, 400, statusCode)
+				expected := test.StringToJSONMap(`{
+  "code": "invalid_parameter",
+  "error_code": 101,
+  "message": "Invalid parameter.",
+  "data": {
+    "name": "paused"
+  }
+}`)
+				assert.Equal(t, expected, test.StringToJSONMap(strings.TrimSpace(string(response)), "more_info"))
+				assert.False(t, requestHandler.Maintenance.Paused())
+			})
+		})
+
+		Convey("When server is paused", func() {
+			statusCode, response := net.GetResponse(adminServer, url.Values{"paused": {"true"}})
+
+			Convey("it should return paused state", func() {
+				assert.Equal(t, 200, statusCode)
+				assert.Equal(t, test.StringToJSONMap(`{"paused": true}`), test.StringToJSONMap(string(response)))
+				assert.True(t, requestHandler.Maintenance.Paused())
+			})
+
+			Convey("health endpoint should report it", func() {
+				statusCode, response := net.GetResponse(healthServer, url.Values{})
+				assert.Equal(t, 200, statusCode)
+				assert.Equal(t, test.StringToJSONMap(`{"status": "paused", "paused": true}`), test.StringToJSONMap(string(response)))
+			})
+
+			Convey("and then resumed health endpoint should report it", func() {
+				statusCode, _ = net.GetResponse(adminServer, url.Values{"paused": {"false"}})
+				assert.Equal(t, 200, statusCode)
+
+				statusCode, response := net.GetResponse(healthServer, url.Values{})
+				assert.Equal(t, 200, statusCode)
+				assert.Equal(t, test.StringToJSONMap(`{"status": "ok", "paused": false}`), test.StringToJSONMap(string(response)))
+			})
+		})
+	})
+}
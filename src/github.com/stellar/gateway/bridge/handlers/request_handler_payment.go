@@ -1,30 +1,51 @@
 package handlers
 
 import (
+	"crypto/sha256"
 	"encoding/base64"
 	"encoding/hex"
 	"encoding/json"
+	"errors"
 	log "github.com/sirupsen/logrus"
 	"io/ioutil"
 	"net/http"
 	"strconv"
 	"strings"
+	"time"
 
+	"github.com/stellar/gateway/db/entities"
 	"github.com/stellar/gateway/horizon"
+	"github.com/stellar/gateway/net"
 	"github.com/stellar/gateway/protocols"
 	"github.com/stellar/gateway/protocols/bridge"
 	callback "github.com/stellar/gateway/protocols/compliance"
+	"github.com/stellar/gateway/ratelimit"
 	"github.com/stellar/gateway/server"
 	"github.com/stellar/go/address"
 	"github.com/stellar/go/amount"
 	b "github.com/stellar/go/build"
+	"github.com/stellar/go/keypair"
 	"github.com/stellar/go/protocols/compliance"
 	"github.com/stellar/go/protocols/federation"
+	"github.com/stellar/go/strkey"
 	"github.com/stellar/go/xdr"
 )
 
 // Payment implements /payment endpoint
 func (rh *RequestHandler) Payment(w http.ResponseWriter, r *http.Request) {
+	start := time.Now()
+	recorder := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+	rh.payment(recorder, r)
+	rh.observePayment(recorder.status, time.Since(start))
+	memoType := r.PostFormValue("memo_type")
+	if recorder.memoType != nil {
+		memoType = *recorder.memoType
+	}
+	rh.observePaymentMemo(memoType, recorder)
+	rh.publishPaymentEvent(r, recorder)
+}
+
+func (rh *RequestHandler) payment(w http.ResponseWriter, r *http.Request) {
 	request := &bridge.PaymentRequest{}
 	err := request.FromRequest(r)
 	if err != nil {
@@ -33,6 +54,28 @@ func (rh *RequestHandler) Payment(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// Events are published with params resolved while the payment is processed (ex. `uri`)
+	if recorder, ok := w.(*statusRecorder); ok {
+		recorder.request = request
+	}
+
+	if request.URI != "" {
+		err = rh.applyPaymentURI(request)
+		if err != nil {
+			errorResponse := err.(*protocols.ErrorResponse)
+			log.WithFields(errorResponse.LogData).Error(errorResponse.Error())
+			server.Write(w, errorResponse)
+			return
+		}
+	}
+
+	// Params read by middlewares and RawErrorsRequested are not fields of the request
+	errorResponse := rh.checkUnknownParams(request.UnknownParams(request, "apiKey", "include_raw_error"))
+	if errorResponse != nil {
+		server.Write(w, errorResponse)
+		return
+	}
+
 	err = request.Validate()
 	if err != nil {
 		errorResponse := err.(*protocols.ErrorResponse)
@@ -41,22 +84,224 @@ func (rh *RequestHandler) Payment(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	errorResponse = rh.checkAmount(request.Amount, request.Operation == "create_account")
+	if errorResponse != nil {
+		log.WithFields(log.Fields{"amount": request.Amount, "operation": request.Operation}).Print(errorResponse.Error())
+		server.Write(w, errorResponse)
+		return
+	}
+
+	errorResponse = rh.checkAssetDecimals("amount", request.AssetCode, request.AssetIssuer, request.Amount)
+	if errorResponse != nil {
+		log.WithFields(errorResponse.LogData).Print(errorResponse.Error())
+		server.Write(w, errorResponse)
+		return
+	}
+
+	assets := []protocols.Asset{{Code: request.AssetCode, Issuer: request.AssetIssuer}}
+	if request.SendMax != "" {
+		assets = append(assets, protocols.Asset{Code: request.SendAssetCode, Issuer: request.SendAssetIssuer})
+		assets = append(assets, request.Path...)
+	}
+	errorResponse = rh.checkAssetsAllowed(assets...)
+	if errorResponse != nil {
+		log.WithFields(errorResponse.Data).Print(errorResponse.Error())
+		server.Write(w, errorResponse)
+		return
+	}
+
+	// When bearer tokens are configured source account is determined by the token only
+	if len(rh.Config.AuthTokens) > 0 {
+		if request.Source != "" {
+			log.Print("source param sent when bearer token authentication is enabled")
+			server.Write(w, protocols.NewInvalidParameterError("source", "", "Source param is not accepted. Use `Authorization: Bearer` header instead."))
+			return
+		}
+
+		seed, ok := rh.seedFromAuthorization(r)
+		if !ok {
+			log.Print("Missing or invalid bearer token")
+			server.Write(w, protocols.UnauthorizedError)
+			return
+		}
+		request.Source = seed
+	}
+
+	if request.Source == "" {
+		request.Source = rh.assetSeed(request.AssetCode, request.AssetIssuer)
+	}
+
 	if request.Source == "" {
 		request.Source = rh.Config.Accounts.BaseSeed
 	}
 
+	// Path payments send send_asset from the source account
+	issuerParam, sent := "asset_issuer", protocols.Asset{Code: request.AssetCode, Issuer: request.AssetIssuer}
+	if request.SendMax != "" {
+		issuerParam, sent = "send_asset_issuer", protocols.Asset{Code: request.SendAssetCode, Issuer: request.SendAssetIssuer}
+	}
+	errorResponse = rh.checkIssuance(issuerParam, request.Source, sent)
+	if errorResponse != nil {
+		log.WithFields(errorResponse.Data).Print(errorResponse.Error())
+		server.Write(w, errorResponse)
+		return
+	}
+
+	// Payments matching compliance rules must go through compliance server even without extra memo
+	complianceForced := rh.complianceRequired(request.AssetCode, request.AssetIssuer, request.Amount)
+	if complianceForced {
+		request.UseCompliance = true
+	}
+
+	if complianceForced || (rh.Config.Compliance != "" && (request.UseCompliance || request.ExtraMemo != "")) {
+		errorResponse := rh.resolveSender(request, complianceForced)
+		if errorResponse != nil {
+			log.WithFields(errorResponse.LogData).Print(errorResponse.Error())
+			server.Write(w, errorResponse)
+			return
+		}
+	}
+
+	// Extra memo is sent to the compliance server only so it would be lost otherwise
+	if request.ExtraMemo != "" && rh.Config.Compliance == "" {
+		log.Print("extra_memo sent but compliance server is not configured")
+		server.Write(w, bridge.PaymentComplianceNotConfigured)
+		return
+	}
+
+	errorResponse = rh.checkRateLimit(map[ratelimit.Asset]int{
+		{Code: request.AssetCode, Issuer: request.AssetIssuer}: 1,
+	})
+	if errorResponse != nil {
+		log.WithFields(errorResponse.Data).Print(errorResponse.Error())
+		server.Write(w, errorResponse)
+		return
+	}
+
 	// Will use compliance if compliance server is connected and:
 	// * User passed extra memo OR
 	// * User explicitly wants to use compliance protocol
 	if rh.Config.Compliance != "" &&
 		(request.ExtraMemo != "" || (request.ExtraMemo == "" && request.UseCompliance)) {
+		// Transaction is built by the compliance server
+		if request.DataName != "" {
+			log.Print("data_name sent with compliance payment")
+			server.Write(w, protocols.NewInvalidParameterError("data_name", request.DataName, "Data entries cannot be sent using compliance protocol."))
+			return
+		}
+
+		if request.AutoTrust {
+			log.Print("auto_trust sent with compliance payment")
+			server.Write(w, protocols.NewInvalidParameterError("auto_trust", "true", "Trustline cannot be added using compliance protocol."))
+			return
+		}
+
+		if request.SequenceNumber != "" {
+			log.Print("sequence_number sent with compliance payment")
+			server.Write(w, protocols.NewInvalidParameterError("sequence_number", request.SequenceNumber, "Sequence number cannot be set using compliance protocol."))
+			return
+		}
+
+		if request.Signer != "" {
+			log.Print("signer sent with compliance payment")
+			server.Write(w, protocols.NewInvalidParameterError("signer", "", "Separate signer cannot be used with compliance protocol."))
+			return
+		}
+
+		// Compliance protocol always attaches a memo hash to the transaction
+		if rh.Config.ForbidMemo {
+			log.Print("Compliance payment requested but memos are forbidden")
+			server.Write(w, bridge.PaymentMemoNotAllowed)
+			return
+		}
+
+		if !rh.allowedMemoType("hash") {
+			log.Print("Compliance payment requested but hash memos are not allowed")
+			server.Write(w, bridge.NewPaymentMemoTypeForbiddenError(rh.Config.AllowedMemoTypes))
+			return
+		}
 		rh.complianceProtocolPayment(w, request)
 	} else {
 		rh.standardPayment(w, request)
 	}
 }
 
+// resolveSender sets sender of a compliance payment sent without `sender` param according to
+// `compliance_sender_policy` config param. Without the policy `compliance_sender` is used for payments
+// forced to use compliance protocol by `compliance_rules` only.
+func (rh *RequestHandler) resolveSender(request *bridge.PaymentRequest, complianceForced bool) *protocols.ErrorResponse {
+	if request.Sender != "" {
+		return nil
+	}
+
+	switch rh.Config.ComplianceSenderPolicy {
+	case "require":
+		return protocols.NewMissingParameter("sender")
+	case "default":
+		request.Sender = rh.Config.ComplianceSender
+	case "home_domain":
+		kp, err := keypair.Parse(request.Source)
+		if err != nil {
+			return bridge.PaymentCannotResolveSender
+		}
+
+		// Reverse federation lookup at the federation server of the account home domain
+		response, err := rh.FederationResolver.LookupByAccountID(kp.Address())
+		if err != nil || response.Address == "" {
+			log.WithFields(log.Fields{"source": kp.Address(), "err": err}).Print("Cannot resolve sender from home domain of the source account")
+			return bridge.PaymentCannotResolveSender
+		}
+		request.Sender = response.Address
+	default:
+		if complianceForced {
+			request.Sender = rh.Config.ComplianceSender
+			if request.Sender == "" {
+				log.Print("Payment requires compliance protocol but sender is unknown")
+				return protocols.NewMissingParameter("sender")
+			}
+		}
+	}
+	return nil
+}
+
+// errComplianceUnavailable is returned by sendComplianceProtocolPayment when compliance server
+// cannot be reached or responds with 5xx status code
+var errComplianceUnavailable = errors.New("Compliance server unavailable")
+
+// errComplianceRetry is returned by sendComplianceProtocolPayment when compliance server responds
+// with one of `compliance_queue.retry_status_codes`
+var errComplianceRetry = errors.New("Transient error response from compliance server")
+
+// errComplianceSubmission is returned by sendComplianceProtocolPayment when the transaction built by
+// compliance server cannot be submitted. It may have reached the network anyway (ex. Horizon timeout).
+var errComplianceSubmission = errors.New("Error submitting compliance transaction")
+
 func (rh *RequestHandler) complianceProtocolPayment(w http.ResponseWriter, request *bridge.PaymentRequest) {
+	response, err := rh.sendComplianceProtocolPayment(request)
+	if rh.Config.ComplianceQueue.Enabled {
+		if err == errComplianceUnavailable || err == errComplianceRetry {
+			rh.queueComplianceProtocolPayment(w, request, bridge.PaymentQueued)
+			return
+		}
+
+		// Pending payments are queued only when they can be, otherwise client repeats the request
+		if rh.Config.ComplianceQueue.QueuePending && isPaymentPending(response) && rh.queuedPaymentSource(request) != "" {
+			rh.queueComplianceProtocolPayment(w, request, bridge.PaymentPendingQueued)
+			return
+		}
+	}
+
+	if err != nil {
+		server.Write(w, protocols.InternalServerError)
+		return
+	}
+
+	server.Write(w, response)
+}
+
+// sendComplianceProtocolPayment sends payment using compliance protocol. Error is returned only when
+// payment cannot be processed, pending and denied responses are returned as server.Response.
+func (rh *RequestHandler) sendComplianceProtocolPayment(request *bridge.PaymentRequest) (server.Response, error) {
 	var paymentID *string
 
 	if request.ID != "" {
@@ -72,16 +317,14 @@ func (rh *RequestHandler) complianceProtocolPayment(w http.ResponseWriter, reque
 	)
 	if err != nil {
 		log.WithFields(log.Fields{"err": err}).Error("Error sending request to compliance server")
-		server.Write(w, protocols.InternalServerError)
-		return
+		return nil, errComplianceUnavailable
 	}
 
 	defer resp.Body.Close()
-	body, err := ioutil.ReadAll(resp.Body)
+	body, err := ioutil.ReadAll(net.LimitReadCloser(resp.Body, int64(rh.Config.ComplianceMaxResponseSize)))
 	if err != nil {
-		log.Error("Error reading compliance server response")
-		server.Write(w, protocols.InternalServerError)
-		return
+		log.WithFields(log.Fields{"err": err}).Error("Error reading compliance server response")
+		return nil, err
 	}
 
 	if resp.StatusCode != 200 {
@@ -89,52 +332,260 @@ func (rh *RequestHandler) complianceProtocolPayment(w http.ResponseWriter, reque
 			"status": resp.StatusCode,
 			"body":   string(body),
 		}).Error("Error response from compliance server")
-		server.Write(w, protocols.InternalServerError)
-		return
+		for _, code := range rh.Config.ComplianceQueue.RetryStatusCodes {
+			if resp.StatusCode == code {
+				return nil, errComplianceRetry
+			}
+		}
+		if resp.StatusCode >= 500 {
+			return nil, errComplianceUnavailable
+		}
+		return nil, errors.New("Error response from compliance server")
 	}
 
 	var callbackSendResponse callback.SendResponse
 	err = json.Unmarshal(body, &callbackSendResponse)
 	if err != nil {
 		log.Error("Error unmarshalling from compliance server")
-		server.Write(w, protocols.InternalServerError)
-		return
+		return nil, err
 	}
 
 	if callbackSendResponse.AuthResponse.InfoStatus == compliance.AuthStatusPending ||
 		callbackSendResponse.AuthResponse.TxStatus == compliance.AuthStatusPending {
 		log.WithFields(log.Fields{"response": callbackSendResponse}).Info("Compliance response pending")
-		server.Write(w, bridge.NewPaymentPendingError(callbackSendResponse.AuthResponse.Pending))
-		return
+		return bridge.NewPaymentPendingError(callbackSendResponse.AuthResponse.Pending), nil
 	}
 
 	if callbackSendResponse.AuthResponse.InfoStatus == compliance.AuthStatusDenied ||
 		callbackSendResponse.AuthResponse.TxStatus == compliance.AuthStatusDenied {
 		log.WithFields(log.Fields{"response": callbackSendResponse}).Info("Compliance response denied")
-		server.Write(w, bridge.PaymentDenied)
-		return
+		return bridge.PaymentDenied, nil
 	}
 
 	var tx xdr.Transaction
 	err = xdr.SafeUnmarshalBase64(callbackSendResponse.TransactionXdr, &tx)
 	if err != nil {
 		log.Error("Error unmarshalling transaction returned by compliance server")
-		server.Write(w, protocols.InternalServerError)
-		return
+		return nil, err
+	}
+
+	errorResponse := rh.attachComplianceMemo(request, &callbackSendResponse, &tx)
+	if errorResponse == nil {
+		errorResponse = rh.checkComplianceTransaction(request, &tx)
+	}
+	if errorResponse != nil {
+		log.WithFields(log.Fields{"tx": callbackSendResponse.TransactionXdr}).WithFields(errorResponse.Data).Error(errorResponse.Error())
+		return errorResponse, nil
+	}
+	request.DestinationAccountID = paymentDestination(&tx)
+
+	// Floors of the source of the transaction built by compliance server, it pays the fee
+	errorResponse = rh.checkBalanceFloors(paymentSpending(tx.SourceAccount.Address(), request, len(tx.Operations)))
+	if errorResponse != nil {
+		log.WithFields(errorResponse.Data).Print(errorResponse.Error())
+		return errorResponse, nil
+	}
+
+	if requestExpired(request.HTTPRequest) {
+		log.Print("Request deadline exceeded, transaction not submitted")
+		return protocols.RequestTimeoutError, nil
 	}
 
 	submitResponse, err := rh.TransactionSubmitter.SignAndSubmitRawTransaction(paymentID, request.Source, &tx)
 	if err != nil {
 		log.WithFields(log.Fields{"error": err}).Error("Error submitting transaction")
+		return nil, errComplianceSubmission
+	}
+
+	return rh.submitterResponse(withPaymentMemo(rh.withPaymentAmount(submitResponse, request), tx.Memo), request.IncludeMeta), nil
+}
+
+// attachComplianceMemo attaches memo returned by compliance server separately from the transaction to it
+// when the transaction has no memo. The memo must match the memo of the transaction if it has one and the
+// hash memo sent in the request, if any.
+func (rh *RequestHandler) attachComplianceMemo(request *bridge.PaymentRequest, response *callback.SendResponse, tx *xdr.Transaction) *protocols.ErrorResponse {
+	if response.Memo == "" {
+		return nil
+	}
+
+	hash, err := response.MemoHash()
+	if err != nil {
+		log.WithFields(log.Fields{"memo": response.Memo, "err": err}).Print("Cannot decode memo returned by compliance server")
+		return bridge.NewPaymentComplianceResponseMismatchError("memo")
+	}
+
+	if request.MemoType == "hash" && !strings.EqualFold(request.Memo, hex.EncodeToString(hash[:])) {
+		return bridge.NewPaymentComplianceResponseMismatchError("memo")
+	}
+
+	switch tx.Memo.Type {
+	case xdr.MemoTypeMemoNone:
+		tx.Memo, err = xdr.NewMemo(xdr.MemoTypeMemoHash, xdr.Hash(hash))
+		if err != nil {
+			return bridge.NewPaymentComplianceResponseMismatchError("memo")
+		}
+	case xdr.MemoTypeMemoHash:
+		if *tx.Memo.Hash != xdr.Hash(hash) {
+			return bridge.NewPaymentComplianceResponseMismatchError("memo")
+		}
+	default:
+		return bridge.NewPaymentComplianceResponseMismatchError("memo")
+	}
+
+	return nil
+}
+
+// checkComplianceTransaction checks if the transaction built by compliance server sends exactly the payment
+// requested, so a compromised or broken compliance server cannot change it. Federation addresses are resolved
+// and compared with the destination only when `compliance_check` is `strict`.
+func (rh *RequestHandler) checkComplianceTransaction(request *bridge.PaymentRequest, tx *xdr.Transaction) *protocols.ErrorResponse {
+	if rh.Config.ComplianceCheck == "none" {
+		return nil
+	}
+
+	if tx.SourceAccount.Address() != keypair.MustParse(request.Source).Address() {
+		return bridge.NewPaymentComplianceResponseMismatchError("source")
+	}
+
+	if tx.Memo.Type != xdr.MemoTypeMemoHash {
+		return bridge.NewPaymentComplianceResponseMismatchError("memo")
+	}
+
+	if len(tx.Operations) != 1 || tx.Operations[0].SourceAccount != nil {
+		return bridge.NewPaymentComplianceResponseMismatchError("operations")
+	}
+
+	var destination xdr.AccountId
+	var destAsset xdr.Asset
+	var destAmount xdr.Int64
+
+	body := tx.Operations[0].Body
+	switch {
+	case body.Type == xdr.OperationTypePayment && request.SendMax == "":
+		destination, destAsset, destAmount = body.PaymentOp.Destination, body.PaymentOp.Asset, body.PaymentOp.Amount
+	case body.Type == xdr.OperationTypePathPayment && request.SendMax != "":
+		op := body.PathPaymentOp
+		destination, destAsset, destAmount = op.Destination, op.DestAsset, op.DestAmount
+
+		sendMax, err := amount.Parse(request.SendMax)
+		if err != nil || op.SendMax != sendMax {
+			return bridge.NewPaymentComplianceResponseMismatchError("send_max")
+		}
+
+		if !rh.assetMatches(op.SendAsset, request.SendAssetCode, request.SendAssetIssuer) {
+			return bridge.NewPaymentComplianceResponseMismatchError("send_asset")
+		}
+	default:
+		return bridge.NewPaymentComplianceResponseMismatchError("operations")
+	}
+
+	requestAmount, err := amount.Parse(request.Amount)
+	if err != nil || destAmount != requestAmount {
+		return bridge.NewPaymentComplianceResponseMismatchError("amount")
+	}
+
+	if !rh.assetMatches(destAsset, request.AssetCode, request.AssetIssuer) {
+		return bridge.NewPaymentComplianceResponseMismatchError("asset")
+	}
+
+	var accountID string
+	if request.ForwardDestination == nil && protocols.IsValidAccountID(request.Destination) {
+		accountID = request.Destination
+	} else if rh.Config.ComplianceCheck == "strict" {
+		var nameResponse *federation.NameResponse
+		if request.ForwardDestination == nil {
+			nameResponse, err = rh.FederationResolver.LookupByAddress(request.Destination)
+		} else {
+			nameResponse, err = rh.FederationResolver.ForwardRequest(request.ForwardDestination.Domain, request.ForwardDestination.Fields)
+		}
+		if err != nil {
+			log.WithFields(log.Fields{"destination": request.Destination, "err": err}).Print("Cannot resolve address")
+			return federationLookupError(err)
+		}
+		accountID = nameResponse.AccountID
+	}
+
+	if accountID != "" && destination.Address() != accountID {
+		return bridge.NewPaymentComplianceResponseMismatchError("destination")
+	}
+
+	return nil
+}
+
+// assetMatches returns true when asset is the asset with given code and issuer (XLM when both are empty)
+func (rh *RequestHandler) assetMatches(asset xdr.Asset, code, issuer string) bool {
+	expected, err := protocols.Asset{Code: code, Issuer: issuer}.ToBaseAsset().ToXDR()
+	return err == nil && asset.Equals(expected)
+}
+
+// isPaymentPending returns true if response is a pending response of compliance server
+func isPaymentPending(response server.Response) bool {
+	errorResponse, ok := response.(*protocols.ErrorResponse)
+	return ok && errorResponse.Code == bridge.PaymentPending.Code
+}
+
+// queuedPaymentSource returns source account ID of the payment if its seed is in the config or
+// empty string otherwise. Seeds are never stored in DB so only such payments can be queued.
+func (rh *RequestHandler) queuedPaymentSource(request *bridge.PaymentRequest) string {
+	source, err := keypair.Parse(request.Source)
+	if err != nil || rh.configuredSeed(source.Address()) != request.Source {
+		return ""
+	}
+	return source.Address()
+}
+
+// queueComplianceProtocolPayment saves the payment to be sent by ProcessComplianceQueue later and
+// writes queuedResponse with ID of the queued payment. Only payments sent from accounts with seeds
+// in the config can be queued.
+func (rh *RequestHandler) queueComplianceProtocolPayment(w http.ResponseWriter, request *bridge.PaymentRequest, queuedResponse *protocols.ErrorResponse) {
+	source := rh.queuedPaymentSource(request)
+	if source == "" {
+		log.Print("Cannot queue payment: source seed not found in config")
 		server.Write(w, protocols.InternalServerError)
 		return
 	}
 
-	rh.handleSubmitterResponse(w, submitResponse)
+	values := request.ToValues()
+	values.Del("source")
+
+	queuedPayment := &entities.QueuedPayment{
+		Status:   entities.QueuedPaymentStatusQueued,
+		Source:   source,
+		Request:  values.Encode(),
+		QueuedAt: time.Now(),
+	}
+
+	if request.ID != "" {
+		queuedPayment.PaymentID = &request.ID
+	}
+
+	err := rh.EntityManager.Persist(queuedPayment)
+	if err != nil {
+		log.WithFields(log.Fields{"err": err}).Error("Error persisting queued payment")
+		server.Write(w, protocols.InternalServerError)
+		return
+	}
+
+	log.WithFields(log.Fields{"id": request.ID, "code": queuedResponse.Code}).Info("Payment queued")
+
+	if queuedPayment.ID != nil {
+		response := *queuedResponse
+		response.Data = map[string]interface{}{"queued_payment_id": *queuedPayment.ID}
+		queuedResponse = &response
+	}
+	server.Write(w, queuedResponse)
 }
 
 func (rh *RequestHandler) standardPayment(w http.ResponseWriter, request *bridge.PaymentRequest) {
 	var paymentID *string
+	requestHash := paymentRequestHash(request)
+
+	submit := rh.TransactionSubmitter.SubmitTransaction
+	resubmit := rh.TransactionSubmitter.ResubmitTransaction
+	if rh.asyncSubmission(request.SubmissionMode) {
+		submit = rh.TransactionSubmitter.SubmitTransactionAsync
+		resubmit = rh.TransactionSubmitter.ResubmitTransactionAsync
+	}
 
 	if request.ID != "" {
 		sentTransaction, err := rh.Repository.GetSentTransactionByPaymentID(request.ID)
@@ -146,16 +597,36 @@ func (rh *RequestHandler) standardPayment(w http.ResponseWriter, request *bridge
 
 		if sentTransaction == nil {
 			paymentID = &request.ID
+		} else if sentTransaction.RequestHash != nil && *sentTransaction.RequestHash != requestHash {
+			log.WithFields(log.Fields{"paymentID": request.ID}).Print("Payment ID reused with different params")
+			server.Write(w, bridge.PaymentIdempotencyKeyConflict)
+			return
 		} else {
 			log.WithFields(log.Fields{"paymentID": request.ID, "tx": sentTransaction.EnvelopeXdr}).Info("Transaction with given ID already exists, resubmitting...")
-			submitResponse, err := rh.Horizon.SubmitTransaction(sentTransaction.EnvelopeXdr)
+			submitResponse, err := resubmit(sentTransaction.EnvelopeXdr)
 			if err != nil {
 				log.WithFields(log.Fields{"error": err}).Error("Error submitting transaction")
-				server.Write(w, protocols.InternalServerError)
+				rh.writeHorizonError(w, err)
 				return
 			}
 
-			rh.handleSubmitterResponse(w, submitResponse)
+			if request.WaitForConfirmation {
+				var errorResponse *protocols.ErrorResponse
+				submitResponse, errorResponse = rh.confirmTransaction(submitResponse)
+				if errorResponse != nil {
+					log.WithFields(errorResponse.LogData).Print(errorResponse.Error())
+					server.Write(w, errorResponse)
+					return
+				}
+			}
+
+			// Memo of the stored envelope, it may have been returned by federation or compliance server
+			var envelope xdr.TransactionEnvelope
+			if xdr.SafeUnmarshalBase64(sentTransaction.EnvelopeXdr, &envelope) == nil {
+				submitResponse = withPaymentMemo(submitResponse, envelope.Tx.Memo)
+			}
+
+			rh.handleSubmitterResponse(w, rh.withPaymentAmount(submitResponse, request), request.IncludeMeta)
 			return
 		}
 	}
@@ -171,7 +642,7 @@ func (rh *RequestHandler) standardPayment(w http.ResponseWriter, request *bridge
 			destinationObject, err = rh.FederationResolver.LookupByAddress(request.Destination)
 			if err != nil {
 				log.WithFields(log.Fields{"destination": request.Destination, "err": err}).Print("Cannot resolve address")
-				server.Write(w, bridge.PaymentCannotResolveDestination)
+				server.Write(w, federationLookupError(err))
 				return
 			}
 		}
@@ -179,16 +650,55 @@ func (rh *RequestHandler) standardPayment(w http.ResponseWriter, request *bridge
 		destinationObject, err = rh.FederationResolver.ForwardRequest(request.ForwardDestination.Domain, request.ForwardDestination.Fields)
 		if err != nil {
 			log.WithFields(log.Fields{"destination": request.Destination, "err": err}).Print("Cannot resolve address")
-			server.Write(w, bridge.PaymentCannotResolveDestination)
+			server.Write(w, federationLookupError(err))
 			return
 		}
 	}
 
-	if !protocols.IsValidAccountID(destinationObject.AccountID) {
+	err = protocols.CheckKey(destinationObject.AccountID, strkey.VersionByteAccountID)
+	if err != nil {
 		log.WithFields(log.Fields{"AccountId": destinationObject.AccountID}).Print("Invalid AccountId in destination")
-		server.Write(w, protocols.NewInvalidParameterError("destination", request.Destination, "Destination public key must start with `G`."))
+		server.Write(w, protocols.NewInvalidParameterError("destination", request.Destination, err.Error()))
 		return
 	}
+	request.DestinationAccountID = destinationObject.AccountID
+
+	var signers []string
+	if request.Signer != "" {
+		errorResponse := rh.checkSigner(request.Source, request.Signer)
+		if errorResponse != nil {
+			log.WithFields(errorResponse.LogData).Print(errorResponse.Error())
+			server.Write(w, errorResponse)
+			return
+		}
+		signers = append(signers, request.Signer)
+	}
+
+	// change_trust operation sent before the payment when destination does not trust the asset yet
+	var trustOperation *b.ChangeTrustBuilder
+	var errorResponse *protocols.ErrorResponse
+	if request.AutoTrust {
+		trustOperation, errorResponse = rh.autoTrustOperation(destinationObject.AccountID, request)
+		if errorResponse != nil {
+			log.WithFields(errorResponse.LogData).Print(errorResponse.Error())
+			server.Write(w, errorResponse)
+			return
+		}
+
+		if trustOperation != nil {
+			signers = append(signers, request.DestinationSeed)
+		}
+	}
+
+	// Trustline added in the same transaction cannot be checked
+	if trustOperation == nil {
+		errorResponse = rh.checkDestinationAuthorization(destinationObject.AccountID, request.AssetCode, request.AssetIssuer)
+		if errorResponse != nil {
+			log.WithFields(log.Fields{"destination": destinationObject.AccountID, "asset_code": request.AssetCode}).Print(errorResponse.Error())
+			server.Write(w, errorResponse)
+			return
+		}
+	}
 
 	var payWithMutator *b.PayWithPath
 
@@ -211,6 +721,9 @@ func (rh *RequestHandler) standardPayment(w http.ResponseWriter, request *bridge
 	}
 
 	var operationBuilder interface{}
+	// payment operation sent when create_account fails because destination has just been created
+	var fallbackOperation interface{}
+	var createAccount bool
 
 	if request.AssetCode != "" && request.AssetIssuer != "" {
 		mutators := []interface{}{
@@ -223,6 +736,9 @@ func (rh *RequestHandler) standardPayment(w http.ResponseWriter, request *bridge
 		}
 
 		operationBuilder = b.Payment(mutators...)
+		if trustOperation != nil {
+			operationBuilder = bridge.Operations{*trustOperation, operationBuilder.(b.PaymentBuilder)}
+		}
 	} else {
 		mutators := []interface{}{
 			b.Destination{destinationObject.AccountID},
@@ -233,13 +749,34 @@ func (rh *RequestHandler) standardPayment(w http.ResponseWriter, request *bridge
 			mutators = append(mutators, *payWithMutator)
 		}
 
-		// Check if destination account exist
-		_, err = rh.Horizon.LoadAccount(destinationObject.AccountID)
-		if err != nil {
-			log.WithFields(log.Fields{"error": err}).Error("Error loading account")
-			operationBuilder = b.CreateAccount(mutators...)
-		} else {
+		switch request.Operation {
+		case "payment":
 			operationBuilder = b.Payment(mutators...)
+		case "create_account":
+			operationBuilder = b.CreateAccount(mutators...)
+			createAccount = true
+		default:
+			// Check if destination account exist
+			_, err = rh.Horizon.LoadAccount(destinationObject.AccountID)
+			if err == horizon.ErrAccountNotFound {
+				errorResponse = rh.checkAmount(request.Amount, true)
+				if errorResponse != nil {
+					log.WithFields(log.Fields{"destination": destinationObject.AccountID}).Print("Cannot create account with zero starting balance")
+					server.Write(w, errorResponse)
+					return
+				}
+				operationBuilder = b.CreateAccount(mutators...)
+				createAccount = true
+				if rh.Config.RetryCreateAccount {
+					fallbackOperation = b.Payment(mutators...)
+				}
+			} else if err != nil {
+				log.WithFields(log.Fields{"error": err}).Error("Error loading account")
+				rh.writeHorizonError(w, err)
+				return
+			} else {
+				operationBuilder = b.Payment(mutators...)
+			}
 		}
 	}
 
@@ -256,8 +793,43 @@ func (rh *RequestHandler) standardPayment(w http.ResponseWriter, request *bridge
 		memoType = destinationObject.MemoType
 		memo = destinationObject.Memo.Value
 	}
+	recordMemoType(w, memoType)
+
+	if rh.Config.ForbidMemo && memoType != "" {
+		log.WithFields(log.Fields{"memo_type": memoType, "memo": memo}).Print("Memo is not allowed")
+		server.Write(w, bridge.PaymentMemoNotAllowed)
+		return
+	}
+
+	if !rh.allowedMemoType(memoType) {
+		log.WithFields(log.Fields{"memo_type": memoType}).Print("Memo type is not allowed")
+		server.Write(w, bridge.NewPaymentMemoTypeForbiddenError(rh.Config.AllowedMemoTypes))
+		return
+	}
+
+	destination := request.Destination
+	domain := destinationDomain(request.Destination)
+	if request.ForwardDestination != nil {
+		destination = request.ForwardDestination.Domain
+		domain = request.ForwardDestination.Domain
+	}
+
+	errorResponse = rh.checkMemoType(destination, domain, destinationObject.AccountID, memoType)
+	if errorResponse != nil {
+		log.WithFields(log.Fields{"destination": destination, "memo_type": memoType}).Print(errorResponse.Error())
+		server.Write(w, errorResponse)
+		return
+	}
+
+	errorResponse = rh.checkMemoRequired(destination, destinationObject.AccountID, memoType)
+	if errorResponse != nil {
+		log.WithFields(log.Fields{"destination": destination}).Print(errorResponse.Error())
+		server.Write(w, errorResponse)
+		return
+	}
 
 	var memoMutator interface{}
+	var txMemo xdr.Memo
 	switch {
 	case memoType == "":
 		break
@@ -269,8 +841,10 @@ func (rh *RequestHandler) standardPayment(w http.ResponseWriter, request *bridge
 			return
 		}
 		memoMutator = b.MemoID{id}
+		txMemo, _ = xdr.NewMemo(xdr.MemoTypeMemoId, xdr.Uint64(id))
 	case memoType == "text":
 		memoMutator = b.MemoText{memo}
+		txMemo, _ = xdr.NewMemo(xdr.MemoTypeMemoText, memo)
 	case memoType == "hash":
 		memoBytes, err := hex.DecodeString(memo)
 		if err != nil || len(memoBytes) != 32 {
@@ -282,28 +856,293 @@ func (rh *RequestHandler) standardPayment(w http.ResponseWriter, request *bridge
 		copy(b32[:], memoBytes[0:32])
 		hash := xdr.Hash(b32)
 		memoMutator = b.MemoHash{hash}
+		txMemo, _ = xdr.NewMemo(xdr.MemoTypeMemoHash, hash)
 	default:
 		log.Print("Not supported memo type: ", memoType)
 		server.Write(w, protocols.NewInvalidParameterError("memo", request.Memo, "Memo type not supported"))
 		return
 	}
 
-	submitResponse, err := rh.TransactionSubmitter.SubmitTransaction(paymentID, request.Source, operationBuilder, memoMutator)
+	if request.DataName != "" {
+		operationBuilder = withDataEntry(operationBuilder, request)
+		if fallbackOperation != nil {
+			fallbackOperation = withDataEntry(fallbackOperation, request)
+		}
+	}
+
+	if request.SequenceNumber != "" {
+		operationBuilder = withSequence(operationBuilder, request.SequenceNumber)
+		// Failed create_account transaction consumes the sequence number so it cannot be resent as payment
+		fallbackOperation = nil
+	}
+
+	operations := 1
+	if trustOperation != nil {
+		operations++
+	}
+	if request.DataName != "" {
+		operations++
+	}
+
+	// Invalid (or missing) source is reported by the submitter
+	if sourceKeypair, err := keypair.Parse(request.Source); err == nil {
+		errorResponse = rh.checkBalanceFloors(paymentSpending(sourceKeypair.Address(), request, operations))
+		if errorResponse != nil {
+			log.WithFields(log.Fields{"destination": destinationObject.AccountID}).WithFields(errorResponse.Data).Print(errorResponse.Error())
+			server.Write(w, errorResponse)
+			return
+		}
+	}
+
+	if createAccount && rh.Config.CheckCreateAccountBalance {
+		sourceKeypair, _ := keypair.Parse(request.Source)
+		errorResponse = rh.checkCreateAccountBalance(sourceKeypair.Address(), request, operations)
+		if errorResponse != nil {
+			log.WithFields(log.Fields{"destination": destinationObject.AccountID}).WithFields(errorResponse.Data).Print(errorResponse.Error())
+			server.Write(w, errorResponse)
+			return
+		}
+	}
+
+	if rh.Config.SimulatePayments {
+		sourceKeypair, _ := keypair.Parse(request.Source)
+		errorResponse = rh.simulatePayment(sourceKeypair.Address(), destinationObject.AccountID, request, operations, trustOperation != nil)
+		if errorResponse != nil {
+			log.WithFields(log.Fields{"destination": destinationObject.AccountID, "asset_code": request.AssetCode}).Print("Payment simulation failed: " + errorResponse.Error())
+			server.Write(w, errorResponse)
+			return
+		}
+	}
+
+	if requestExpired(request.HTTPRequest) {
+		log.Print("Request deadline exceeded, transaction not submitted")
+		server.Write(w, protocols.RequestTimeoutError)
+		return
+	}
+
+	submitResponse, err := submit(paymentID, request.Source, operationBuilder, memoMutator, signers...)
+	rh.saveRequestHash(paymentID, requestHash)
 	if err != nil {
 		log.WithFields(log.Fields{"error": err}).Error("Error submitting transaction")
-		server.Write(w, protocols.InternalServerError)
+		rh.writeHorizonError(w, err)
+		return
+	}
+
+	if fallbackOperation != nil && bridge.CreateAccountAlreadyExists(submitResponse) && takeRetry(request.HTTPRequest) {
+		log.WithFields(log.Fields{"destination": destinationObject.AccountID}).Info("Destination account already exists, resending as payment")
+
+		err = rh.releasePaymentID(paymentID)
+		if err != nil {
+			log.WithFields(log.Fields{"err": err}).Error("Error releasing payment ID")
+			server.Write(w, protocols.InternalServerError)
+			return
+		}
+
+		submitResponse, err = submit(paymentID, request.Source, fallbackOperation, memoMutator, signers...)
+		rh.saveRequestHash(paymentID, requestHash)
+		if err != nil {
+			log.WithFields(log.Fields{"error": err}).Error("Error submitting transaction")
+			rh.writeHorizonError(w, err)
+			return
+		}
+	}
+
+	// The submitter publishes the outcome of transactions accepted by async submission
+	if recorder, ok := w.(*statusRecorder); ok && submitResponse.Status != "" && submitResponse.Ledger == nil {
+		recorder.submittedAsync = true
+	}
+
+	if request.WaitForConfirmation {
+		submitResponse, errorResponse = rh.confirmTransaction(submitResponse)
+		if errorResponse != nil {
+			log.WithFields(errorResponse.LogData).Print(errorResponse.Error())
+			server.Write(w, errorResponse)
+			return
+		}
+	}
+
+	rh.handleSubmitterResponse(w, withPaymentMemo(rh.withPaymentAmount(submitResponse, request), txMemo), request.IncludeMeta)
+}
+
+// withDataEntry adds manage_data operation setting `data_name` entry of the source account to the payment operation
+func withDataEntry(operation interface{}, request *bridge.PaymentRequest) bridge.Operations {
+	return bridge.Operations{
+		operation.(b.TransactionMutator),
+		b.SetData(request.DataName, []byte(request.DataValue)),
+	}
+}
+
+// withSequence sets the transaction sequence number to `sequence_number` param (validated before)
+// so the submitter does not use the current one of the source account
+func withSequence(operation interface{}, sequenceNumber string) bridge.Operations {
+	sequence, _ := strconv.ParseUint(sequenceNumber, 10, 64)
+	return bridge.Operations{
+		b.Sequence{sequence},
+		operation.(b.TransactionMutator),
+	}
+}
+
+// autoTrustOperation returns change_trust operation adding the trustline of the sent asset to the destination
+// (see `auto_trust` param) or nil if the destination already trusts the asset or cannot be loaded.
+func (rh *RequestHandler) autoTrustOperation(destination string, request *bridge.PaymentRequest) (*b.ChangeTrustBuilder, *protocols.ErrorResponse) {
+	if keypair.MustParse(request.DestinationSeed).Address() != destination {
+		return nil, protocols.NewInvalidParameterError("destination_seed", "", "Destination seed does not match destination.")
+	}
+
+	destinationAccount, err := rh.Horizon.LoadAccount(destination)
+	if err != nil {
+		log.WithFields(log.Fields{"destination": destination, "err": err}).Print("Cannot load destination account, trustline not added")
+		return nil, nil
+	}
+
+	if destinationAccount.Balance(request.AssetCode, request.AssetIssuer) != nil {
+		return nil, nil
+	}
+
+	trust := b.Trust(request.AssetCode, request.AssetIssuer, b.SourceAccount{destination})
+	return &trust, nil
+}
+
+// checkAmount returns PaymentInvalidAmount when value is zero (amounts smaller than one stroop are
+// rounded to zero by the builder) and zero amount payments are not allowed (see `allow_zero_amount`
+// config param) or createAccount is true, as accounts cannot be created with zero starting balance.
+// value must be validated before.
+func (rh *RequestHandler) checkAmount(value string, createAccount bool) *protocols.ErrorResponse {
+	parsed, err := amount.Parse(value)
+	if err != nil || parsed != 0 {
+		return nil
+	}
+
+	if createAccount || !rh.Config.AllowZeroAmount {
+		return bridge.PaymentInvalidAmount
+	}
+	return nil
+}
+
+// releasePaymentID detaches the payment ID from a failed transaction so it can be used by a new one
+func (rh *RequestHandler) releasePaymentID(paymentID *string) error {
+	if paymentID == nil {
+		return nil
+	}
+
+	sentTransaction, err := rh.Repository.GetSentTransactionByPaymentID(*paymentID)
+	if err != nil || sentTransaction == nil {
+		return err
+	}
+
+	sentTransaction.PaymentID = nil
+	return rh.EntityManager.Persist(sentTransaction)
+}
+
+// paymentRequestHash returns hex encoded SHA-256 hash of normalized params of the payment request.
+// Params that do not change the sent transaction are ignored and secrets are replaced with addresses.
+func paymentRequestHash(request *bridge.PaymentRequest) string {
+	values := request.ToValues()
+	values.Del("include_meta")
+	values.Del("wait_for_confirmation")
+	values.Del("submission_mode")
+
+	for _, name := range []string{"source", "signer", "destination_seed"} {
+		if kp, err := keypair.Parse(values.Get(name)); err == nil {
+			values.Set(name, kp.Address())
+		}
+	}
+
+	for _, name := range []string{"amount", "send_max"} {
+		if value, err := amount.Parse(values.Get(name)); err == nil {
+			values.Set(name, amount.String(value))
+		}
+	}
+
+	hash := sha256.Sum256([]byte(values.Encode()))
+	return hex.EncodeToString(hash[:])
+}
+
+// saveRequestHash stores the payment request hash in the transaction sent with paymentID so reuse
+// of the ID with different params can be detected. Errors are only logged as the transaction
+// has already been submitted.
+func (rh *RequestHandler) saveRequestHash(paymentID *string, hash string) {
+	if paymentID == nil {
 		return
 	}
 
-	rh.handleSubmitterResponse(w, submitResponse)
+	sentTransaction, err := rh.Repository.GetSentTransactionByPaymentID(*paymentID)
+	if err != nil || sentTransaction == nil {
+		log.WithFields(log.Fields{"paymentID": *paymentID, "err": err}).Warn("Cannot load sent transaction, request hash not saved")
+		return
+	}
+
+	sentTransaction.RequestHash = &hash
+	err = rh.EntityManager.Persist(sentTransaction)
+	if err != nil {
+		log.WithFields(log.Fields{"paymentID": *paymentID, "err": err}).Warn("Error saving request hash")
+	}
 }
 
-func (rh *RequestHandler) handleSubmitterResponse(w http.ResponseWriter, response horizon.SubmitTransactionResponse) {
-	errorResponse := bridge.ErrorFromHorizonResponse(response)
+// withPaymentAmount adds amount of the payment to the response, in stroops and formatted using
+// `display_decimals` of the asset
+func (rh *RequestHandler) withPaymentAmount(response horizon.SubmitTransactionResponse, request *bridge.PaymentRequest) horizon.SubmitTransactionResponse {
+	value, err := amount.Parse(request.Amount)
+	if err != nil {
+		return response
+	}
+
+	response.Amount = rh.formatAssetAmount(request.AssetCode, request.AssetIssuer, value)
+	response.AmountStroops = strconv.FormatInt(int64(value), 10)
+	return response
+}
+
+// paymentDestination returns destination of the first payment or path payment operation of tx, empty
+// when there is none
+func paymentDestination(tx *xdr.Transaction) string {
+	for _, operation := range tx.Operations {
+		switch operation.Body.Type {
+		case xdr.OperationTypePayment:
+			return operation.Body.PaymentOp.Destination.Address()
+		case xdr.OperationTypePathPayment:
+			return operation.Body.PathPaymentOp.Destination.Address()
+		}
+	}
+	return ""
+}
+
+// withPaymentMemo adds type and value of the memo attached to the transaction to the response, no
+// matter if it was sent in the request or returned by federation or compliance server
+func withPaymentMemo(response horizon.SubmitTransactionResponse, memo xdr.Memo) horizon.SubmitTransactionResponse {
+	switch memo.Type {
+	case xdr.MemoTypeMemoId:
+		response.MemoType = "id"
+		response.Memo = strconv.FormatUint(uint64(memo.MustId()), 10)
+	case xdr.MemoTypeMemoText:
+		response.MemoType = "text"
+		response.Memo = memo.MustText()
+	case xdr.MemoTypeMemoHash:
+		hash := memo.MustHash()
+		response.MemoType = "hash"
+		response.Memo = hex.EncodeToString(hash[:])
+	case xdr.MemoTypeMemoReturn:
+		hash := memo.MustRetHash()
+		response.MemoType = "return"
+		response.Memo = hex.EncodeToString(hash[:])
+	}
+	return response
+}
+
+func (rh *RequestHandler) handleSubmitterResponse(w http.ResponseWriter, response horizon.SubmitTransactionResponse, includeMeta bool) {
+	server.Write(w, rh.submitterResponse(response, includeMeta))
+}
+
+// submitterResponse converts transaction submitter response into error or success response.
+// result_meta_xdr is returned only when includeMeta is true.
+func (rh *RequestHandler) submitterResponse(response horizon.SubmitTransactionResponse, includeMeta bool) server.Response {
+	errorResponse := rh.errorFromHorizonResponse(response)
 	if errorResponse != nil {
 		log.WithFields(errorResponse.LogData).Error(errorResponse.Error())
-		server.Write(w, errorResponse)
-		return
+		return errorResponse
+	}
+
+	if !includeMeta {
+		response.ResultMetaXdr = nil
 	}
 
 	// Path payment send amount
@@ -314,13 +1153,25 @@ func (rh *RequestHandler) handleSubmitterResponse(w http.ResponseWriter, respons
 		_, err := xdr.Unmarshal(b64r, &transactionResult)
 
 		if err == nil && transactionResult.Result.Code == xdr.TransactionResultCodeTxSuccess {
-			operationResult := (*transactionResult.Result.Results)[0]
-			if operationResult.Tr.PathPaymentResult != nil {
-				sendAmount := operationResult.Tr.PathPaymentResult.SendAmount()
-				response.SendAmount = amount.String(sendAmount)
+			response.Fee = rh.formatAssetAmount("", "", transactionResult.FeeCharged)
+			response.FeeStroops = strconv.FormatInt(int64(transactionResult.FeeCharged), 10)
+
+			if result := pathPaymentResult(*transactionResult.Result.Results); result != nil {
+				response.SendAmount = amount.String(result.SendAmount())
 			}
 		}
 	}
 
-	server.Write(w, &response)
+	return &response
+}
+
+// pathPaymentResult returns the result of the first path payment operation or nil if there is none.
+// Path payments are not always the first operation, ex. change_trust is sent before them (see `auto_trust`).
+func pathPaymentResult(results []xdr.OperationResult) *xdr.PathPaymentResult {
+	for _, result := range results {
+		if result.Tr != nil && result.Tr.Type == xdr.OperationTypePathPayment {
+			return result.Tr.PathPaymentResult
+		}
+	}
+	return nil
 }
@@ -0,0 +1,104 @@
+package handlers
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+
+	"github.com/BurntSushi/toml"
+	log "github.com/sirupsen/logrus"
+	"github.com/stellar/gateway/protocols"
+	"github.com/stellar/gateway/protocols/bridge"
+	"github.com/stellar/go/clients/stellartoml"
+)
+
+// applyPaymentURI populates request fields with values of SEP-7 payment URI sent in `uri` param.
+// Fields sent in the request must match the URI, fields missing in the URI (ex. amount) are kept.
+func (rh *RequestHandler) applyPaymentURI(request *bridge.PaymentRequest) error {
+	uri, err := bridge.ParsePaymentURI(request.URI)
+	if err != nil {
+		return err
+	}
+
+	if uri.NetworkPassphrase != "" && uri.NetworkPassphrase != rh.Config.NetworkPassphrase {
+		return protocols.NewInvalidParameterError("uri", request.URI, "URI network_passphrase does not match bridge network.")
+	}
+
+	if uri.Signature != nil {
+		if uri.OriginDomain == "" {
+			return protocols.NewInvalidParameterError("uri", request.URI, "Signed URI must contain origin_domain.")
+		}
+
+		signingKey, err := rh.uriRequestSigningKey(uri.OriginDomain)
+		if err != nil {
+			log.WithFields(log.Fields{"err": err, "origin_domain": uri.OriginDomain}).Print("Cannot load URI_REQUEST_SIGNING_KEY")
+			return protocols.NewInvalidParameterError("uri", request.URI, "Cannot load URI_REQUEST_SIGNING_KEY of origin_domain.")
+		}
+
+		err = uri.Verify(signingKey)
+		if err != nil {
+			return protocols.NewInvalidParameterError("uri", request.URI, "URI signature is invalid.")
+		}
+	}
+
+	fields := []struct {
+		name     string
+		value    string
+		uriValue string
+		field    *string
+	}{
+		{"destination", request.Destination, uri.Destination, &request.Destination},
+		{"amount", request.Amount, uri.Amount, &request.Amount},
+		{"asset_code", request.AssetCode, uri.AssetCode, &request.AssetCode},
+		{"asset_issuer", request.AssetIssuer, uri.AssetIssuer, &request.AssetIssuer},
+		{"memo_type", request.MemoType, uri.MemoType, &request.MemoType},
+		{"memo", request.Memo, uri.Memo, &request.Memo},
+	}
+
+	for _, f := range fields {
+		if f.uriValue == "" {
+			continue
+		}
+
+		if f.value != "" && f.value != f.uriValue {
+			return protocols.NewInvalidParameterError(f.name, f.value, "Value does not match the value in uri.")
+		}
+
+		*f.field = f.uriValue
+		// Required params are checked in the form
+		request.HTTPRequest.PostForm.Set(f.name, f.uriValue)
+	}
+
+	// Values are already in the request, stored and resubmitted requests must not be verified again
+	request.URI = ""
+	return nil
+}
+
+// uriRequestSigningKey loads `URI_REQUEST_SIGNING_KEY` from stellar.toml of a given domain
+func (rh *RequestHandler) uriRequestSigningKey(domain string) (string, error) {
+	resp, err := rh.Client.Get("https://" + domain + stellartoml.WellKnownPath)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return "", errors.New("stellar.toml response status: " + strconv.Itoa(resp.StatusCode))
+	}
+
+	var stellarToml struct {
+		URIRequestSigningKey string `toml:"URI_REQUEST_SIGNING_KEY"`
+	}
+
+	_, err = toml.DecodeReader(io.LimitReader(resp.Body, stellartoml.StellarTomlMaxSize), &stellarToml)
+	if err != nil {
+		return "", err
+	}
+
+	if stellarToml.URIRequestSigningKey == "" {
+		return "", errors.New("URI_REQUEST_SIGNING_KEY not found")
+	}
+
+	return stellarToml.URIRequestSigningKey, nil
+}
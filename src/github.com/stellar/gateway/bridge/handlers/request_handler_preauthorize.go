@@ -0,0 +1,104 @@
+package handlers
+
+import (
+	"encoding/hex"
+	"net/http"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/stellar/gateway/protocols"
+	"github.com/stellar/gateway/protocols/bridge"
+	"github.com/stellar/gateway/server"
+	"github.com/stellar/gateway/submitter"
+	b "github.com/stellar/go/build"
+	"github.com/stellar/go/strkey"
+)
+
+// Preauthorize implements /preauthorize endpoint. It adds the hash of the given transaction as
+// a pre-authorized transaction signer of the source account (or, when `weight` is zero, removes
+// it) so the transaction can be submitted later without other signatures.
+func (rh *RequestHandler) Preauthorize(w http.ResponseWriter, r *http.Request) {
+	request := &bridge.PreauthorizeRequest{}
+	err := request.FromRequest(r)
+	if err != nil {
+		log.Error(err.Error())
+		server.Write(w, protocols.InvalidParameterError)
+		return
+	}
+
+	err = request.Validate()
+	if err != nil {
+		errorResponse := err.(*protocols.ErrorResponse)
+		log.WithFields(errorResponse.LogData).Error(errorResponse.Error())
+		server.Write(w, errorResponse)
+		return
+	}
+
+	// When bearer tokens are configured source account is determined by the token only
+	if len(rh.Config.AuthTokens) > 0 {
+		if request.Source != "" {
+			log.Print("source param sent when bearer token authentication is enabled")
+			server.Write(w, protocols.NewInvalidParameterError("source", "", "Source param is not accepted. Use `Authorization: Bearer` header instead."))
+			return
+		}
+
+		seed, ok := rh.seedFromAuthorization(r)
+		if !ok {
+			log.Print("Missing or invalid bearer token")
+			server.Write(w, protocols.UnauthorizedError)
+			return
+		}
+		request.Source = seed
+	}
+
+	if request.Source == "" {
+		request.Source = rh.Config.Accounts.BaseSeed
+	}
+
+	// Checked again because source may come from the bearer token or base_seed
+	if request.Source != "" {
+		err = request.CheckSourceAccount(request.Source)
+		if err != nil {
+			errorResponse := err.(*protocols.ErrorResponse)
+			log.WithFields(errorResponse.LogData).Error(errorResponse.Error())
+			server.Write(w, errorResponse)
+			return
+		}
+	}
+
+	tx := request.Transaction()
+	hash, err := submitter.TransactionHash(&tx, rh.Config.NetworkPassphrase)
+	if err != nil {
+		log.WithFields(log.Fields{"err": err}).Error("Error calculating transaction hash")
+		server.Write(w, protocols.InternalServerError)
+		return
+	}
+
+	signer, err := strkey.Encode(strkey.VersionByteHashTx, hash[:])
+	if err != nil {
+		log.WithFields(log.Fields{"err": err}).Error("Error encoding pre-authorized transaction signer")
+		server.Write(w, protocols.InternalServerError)
+		return
+	}
+
+	operation := b.SetOptions(b.AddSigner(signer, request.SignerWeight()))
+
+	submitResponse, err := rh.TransactionSubmitter.SubmitTransaction(nil, request.Source, operation, nil)
+	if err != nil {
+		log.WithFields(log.Fields{"err": err}).Error("Error submitting transaction")
+		rh.writeHorizonError(w, err)
+		return
+	}
+
+	errorResponse := rh.errorFromHorizonResponse(submitResponse)
+	if errorResponse != nil {
+		log.WithFields(errorResponse.LogData).Error(errorResponse.Error())
+		server.Write(w, errorResponse)
+		return
+	}
+
+	server.Write(w, &bridge.PreauthorizeResponse{
+		Hash:        hex.EncodeToString(hash[:]),
+		Signer:      signer,
+		Transaction: submitResponse,
+	})
+}
@@ -0,0 +1,199 @@
+package handlers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+	"github.com/stellar/gateway/bridge/config"
+	"github.com/stellar/gateway/horizon"
+	"github.com/stellar/gateway/mocks"
+	"github.com/stellar/gateway/net"
+	"github.com/stellar/gateway/test"
+	b "github.com/stellar/go/build"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestRequestHandlerPreauthorize(t *testing.T) {
+	mockTransactionSubmitter := new(mocks.MockTransactionSubmitter)
+
+	config := config.Config{
+		NetworkPassphrase: "Test SDF Network ; September 2015",
+		Accounts: config.Accounts{
+			BaseSeed: "SDWLS4G3XCNIYPKXJWWGGJT6UDY63WV6PEFTWP7JZMQB4RE7EUJQN5XM",
+		},
+	}
+
+	requestHandler := RequestHandler{Config: &config, TransactionSubmitter: mockTransactionSubmitter}
+	testServer := httptest.NewServer(http.HandlerFunc(requestHandler.Preauthorize))
+	defer testServer.Close()
+
+	// Transaction built by /builder (see request_handler_builder_test.go) sent from the base_seed account
+	envelope := "AAAAAIu7VxM5f9eQ3va0bpvKprxnSHB4zyEnY4D/VzT8Jio3AAAAZAAAAAAAAAB7AAAAAAAAAAAAAAABAAAAAAAAAAAAAAAAnEM7m3lksnFftHMGxdt6HTitUQSfvVvjk8JfduWfK+cAAAAAHc1lAAAAAAAAAAAA"
+	// Built by /builder, sent from other account
+	otherEnvelope := "AAAAAGySS3ZylffFaVZqZD6lNCUjCizHz7MLPwkN7Mxh4XN5AAAAZAAAAAAAAAB7AAAAAAAAAAAAAAABAAAAAAAAAAAAAAAAnEM7m3lksnFftHMGxdt6HTitUQSfvVvjk8JfduWfK+cAAAAAHc1lAAAAAAAAAAABn420/AAAAECXY+neSolhAeHUXf+UrOV6PjeJnvLM/HqjOlOEWD3hmu/z9aBksDu9zqa26jS14eMpZzq8sofnnvt248FUO+cP"
+	// Hash of the envelope transaction on the test network
+	signer := "TBN2K4CIE6PVAQNGZ7O56FF2PCME7Q7IPO2UVMW77R472WCOFFEUIXSO"
+
+	var ledger uint64
+	ledger = 1988728
+	successResponse := horizon.SubmitTransactionResponse{Hash: "6a0049b44e0d0341bd52f131c74383e6ccd2b74b92c829c990994d24bbfcfa7a", Ledger: &ledger}
+
+	Convey("Given preauthorize request", t, func() {
+		params := url.Values{"tx": {envelope}}
+
+		Convey("When tx is invalid", func() {
+			params.Set("tx", "AAAA")
+
+			Convey("it should return error", func() {
+				statusCode, response := net.GetResponse(testServer, params)
+				responseString := strings.TrimSpace(string(response))
+				assert.Equal(t, 400, statusCode)
+				expected := test.StringToJSONMap(`{
+  "code": "invalid_parameter",
+  "error_code": 101,
+  "message": "Invalid parameter.",
+  "more_info": "Transaction envelope must be a base64 encoded XDR.",
+  "data": {
+    "name": "tx"
+  }
+}`)
+				assert.Equal(t, expected, test.StringToJSONMap(responseString))
+			})
+		})
+
+		Convey("When weight is invalid", func() {
+			params.Set("weight", "256")
+
+			Convey("it should return error", func() {
+				statusCode, response := net.GetResponse(testServer, params)
+				responseString := strings.TrimSpace(string(response))
+				assert.Equal(t, 400, statusCode)
+				expected := test.StringToJSONMap(`{
+  "code": "invalid_parameter",
+  "error_code": 101,
+  "message": "Invalid parameter.",
+  "more_info": "Weight must be an integer between 0 and 255.",
+  "data": {
+    "name": "weight"
+  }
+}`)
+				assert.Equal(t, expected, test.StringToJSONMap(responseString))
+			})
+		})
+
+		Convey("When tx is not sent from source", func() {
+			params.Set("source", "SBKKWO3ZVDDEHDJILGHPHCJCFD2GNUAYIUDMRAS326HLUEQ7ZFXWIGQK")
+
+			Convey("it should return error", func() {
+				statusCode, response := net.GetResponse(testServer, params)
+				responseString := strings.TrimSpace(string(response))
+				assert.Equal(t, 400, statusCode)
+				expected := test.StringToJSONMap(`{
+  "code": "invalid_parameter",
+  "error_code": 101,
+  "message": "Invalid parameter.",
+  "more_info": "Transaction source account must be the account the signer is added to.",
+  "data": {
+    "name": "tx"
+  }
+}`)
+				assert.Equal(t, expected, test.StringToJSONMap(responseString))
+			})
+		})
+
+		Convey("When tx is not sent from base seed account", func() {
+			params.Set("tx", otherEnvelope)
+
+			Convey("it should return error", func() {
+				statusCode, response := net.GetResponse(testServer, params)
+				responseString := strings.TrimSpace(string(response))
+				assert.Equal(t, 400, statusCode)
+				expected := test.StringToJSONMap(`{
+  "code": "invalid_parameter",
+  "error_code": 101,
+  "message": "Invalid parameter.",
+  "more_info": "Transaction source account must be the account the signer is added to.",
+  "data": {
+    "name": "tx"
+  }
+}`)
+				assert.Equal(t, expected, test.StringToJSONMap(responseString))
+				assert.Equal(t, 0, len(mockTransactionSubmitter.Calls))
+			})
+		})
+
+		Convey("When params are valid", func() {
+			mockTransactionSubmitter.On(
+				"SubmitTransaction",
+				(*string)(nil),
+				"SDWLS4G3XCNIYPKXJWWGGJT6UDY63WV6PEFTWP7JZMQB4RE7EUJQN5XM",
+				b.SetOptions(b.AddSigner(signer, 1)),
+				nil,
+			).Return(successResponse, nil).Once()
+
+			Convey("it should add the transaction hash signer", func() {
+				statusCode, response := net.GetResponse(testServer, params)
+				responseString := strings.TrimSpace(string(response))
+				assert.Equal(t, 200, statusCode)
+				expected := test.StringToJSONMap(`{
+  "hash": "5ba57048279f5041a6cfdddf14ba78984fc3e87bb54ab2dffc79fd584e294944",
+  "signer": "TBN2K4CIE6PVAQNGZ7O56FF2PCME7Q7IPO2UVMW77R472WCOFFEUIXSO",
+  "transaction": {
+    "hash": "6a0049b44e0d0341bd52f131c74383e6ccd2b74b92c829c990994d24bbfcfa7a",
+    "ledger": 1988728
+  }
+}`)
+				assert.Equal(t, expected, test.StringToJSONMap(responseString))
+			})
+		})
+
+		Convey("When weight is zero", func() {
+			params.Set("weight", "0")
+
+			mockTransactionSubmitter.On(
+				"SubmitTransaction",
+				(*string)(nil),
+				"SDWLS4G3XCNIYPKXJWWGGJT6UDY63WV6PEFTWP7JZMQB4RE7EUJQN5XM",
+				b.SetOptions(b.RemoveSigner(signer)),
+				nil,
+			).Return(successResponse, nil).Once()
+
+			Convey("it should remove the signer", func() {
+				statusCode, _ := net.GetResponse(testServer, params)
+				assert.Equal(t, 200, statusCode)
+			})
+		})
+
+		Convey("When account has too many signers", func() {
+			mockTransactionSubmitter.On(
+				"SubmitTransaction",
+				(*string)(nil),
+				"SDWLS4G3XCNIYPKXJWWGGJT6UDY63WV6PEFTWP7JZMQB4RE7EUJQN5XM",
+				mock.AnythingOfType("build.SetOptionsBuilder"),
+				nil,
+			).Return(horizon.SubmitTransactionResponse{
+				Extras: &horizon.SubmitTransactionResponseExtras{
+					// op_too_many_signers
+					ResultXdr: "AAAAAAAAAGT/////AAAAAQAAAAAAAAAF/////gAAAAA=",
+				},
+			}, nil).Once()
+
+			Convey("it should return error", func() {
+				statusCode, response := net.GetResponse(testServer, params)
+				responseString := strings.TrimSpace(string(response))
+				assert.Equal(t, 400, statusCode)
+				expected := test.StringToJSONMap(`{
+  "code": "set_options_too_many_signers",
+  "error_code": 901,
+  "message": "Account already has the maximum number of signers."
+}`)
+				assert.Equal(t, expected, test.StringToJSONMap(responseString))
+			})
+		})
+	})
+}
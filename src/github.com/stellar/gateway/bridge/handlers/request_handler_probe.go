@@ -0,0 +1,66 @@
+package handlers
+
+import (
+	"net/http"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/stellar/gateway/protocols"
+	"github.com/stellar/gateway/protocols/bridge"
+	"github.com/stellar/gateway/server"
+	b "github.com/stellar/go/build"
+	"github.com/stellar/go/keypair"
+)
+
+// probeAmount is the smallest amount of XLM, sent by the probe account to itself
+const probeAmount = "0.0000001"
+
+// AdminProbe implements /admin/probe endpoint. It submits a minimal transaction (a self-payment of
+// the smallest XLM amount) from `account_id` (default: `accounts.base_seed` account) and returns the
+// result and the time it took. The account pays a transaction fee and its sequence number is bumped.
+func (rh *RequestHandler) AdminProbe(w http.ResponseWriter, r *http.Request) {
+	seed := rh.Config.Accounts.BaseSeed
+	if accountID := r.PostFormValue("account_id"); accountID != "" {
+		seed = rh.configuredSeed(accountID)
+		if seed == "" {
+			log.WithFields(log.Fields{"account_id": accountID}).Print("Probe account is not configured")
+			server.Write(w, protocols.NewInvalidParameterError("account_id", accountID, "Account must be one of the accounts configured in the bridge."))
+			return
+		}
+	}
+
+	if seed == "" {
+		server.Write(w, protocols.NewMissingParameter("account_id"))
+		return
+	}
+
+	accountID := keypair.MustParse(seed).Address()
+	operation := b.Payment(
+		b.Destination{accountID},
+		b.NativeAmount{probeAmount},
+	)
+
+	start := time.Now()
+	submitResponse, err := rh.TransactionSubmitter.SubmitTransaction(nil, seed, operation, nil)
+	duration := time.Since(start)
+	if err != nil {
+		log.WithFields(log.Fields{"err": err}).Error("Error submitting probe transaction")
+		rh.writeHorizonError(w, err)
+		return
+	}
+
+	response := bridge.ProbeResponse{
+		AccountID:  accountID,
+		Hash:       submitResponse.Hash,
+		Ledger:     submitResponse.Ledger,
+		Result:     "success",
+		DurationMs: int64(duration / time.Millisecond),
+	}
+
+	if errorResponse := bridge.ErrorFromHorizonResponse(submitResponse); errorResponse != nil {
+		response.Result = errorResponse.Code
+	}
+
+	log.WithFields(log.Fields{"account_id": accountID, "result": response.Result, "duration": duration}).Info("Probe transaction submitted")
+	server.Write(w, response)
+}
@@ -0,0 +1,112 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+	"github.com/stellar/gateway/bridge/config"
+	"github.com/stellar/gateway/horizon"
+	"github.com/stellar/gateway/mocks"
+	"github.com/stellar/gateway/net"
+	"github.com/stellar/gateway/protocols/bridge"
+	"github.com/stellar/gateway/test"
+	b "github.com/stellar/go/build"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRequestHandlerAdminProbe(t *testing.T) {
+	mockTransactionSubmitter := new(mocks.MockTransactionSubmitter)
+
+	config := config.Config{
+		Accounts: config.Accounts{
+			// GCF3WVYTHF75PEG6622G5G6KU26GOSDQPDHSCJ3DQD7VONH4EYVDOGKJ
+			BaseSeed: "SDWLS4G3XCNIYPKXJWWGGJT6UDY63WV6PEFTWP7JZMQB4RE7EUJQN5XM",
+		},
+	}
+
+	requestHandler := RequestHandler{Config: &config, TransactionSubmitter: mockTransactionSubmitter}
+	testServer := httptest.NewServer(http.HandlerFunc(requestHandler.AdminProbe))
+	defer testServer.Close()
+
+	Convey("Given admin probe request", t, func() {
+		Convey("When account is not configured", func() {
+			params := url.Values{"account_id": {"GDSIKW43UA6JTOA47WVEBCZ4MYC74M3GNKNXTVDXFHXYYTNO5GGVN632"}}
+
+			Convey("it should return error", func() {
+				statusCode, response := net.GetResponse(testServer, params)
+				responseString := strings.TrimSpace(string(response))
+				assert.Equal(t, 400, statusCode)
+				expected := test.StringToJSONMap(`{
+  "code": "invalid_parameter",
+  "error_code": 101,
+  "message": "Invalid parameter.",
+  "more_info": "Account must be one of the accounts configured in the bridge.",
+  "data": {
+    "name": "account_id"
+  }
+}`)
+				assert.Equal(t, expected, test.StringToJSONMap(responseString))
+			})
+		})
+
+		Convey("When transaction succeeds", func() {
+			var ledger uint64
+			ledger = 1988728
+			mockTransactionSubmitter.On(
+				"SubmitTransaction",
+				(*string)(nil),
+				"SDWLS4G3XCNIYPKXJWWGGJT6UDY63WV6PEFTWP7JZMQB4RE7EUJQN5XM",
+				mock.AnythingOfType("build.PaymentBuilder"),
+				nil,
+			).Run(func(args mock.Arguments) {
+				payment := args.Get(2).(b.PaymentBuilder)
+				assert.Equal(t, "GCF3WVYTHF75PEG6622G5G6KU26GOSDQPDHSCJ3DQD7VONH4EYVDOGKJ", payment.P.Destination.Address())
+				assert.Equal(t, int64(1), int64(payment.P.Amount))
+			}).Return(horizon.SubmitTransactionResponse{Hash: "6a0049b44e0d0341bd52f131c74383e6ccd2b74b92c829c990994d24bbfcfa7a", Ledger: &ledger}, nil).Once()
+
+			Convey("it should return success", func() {
+				statusCode, response := net.GetResponse(testServer, url.Values{})
+				assert.Equal(t, 200, statusCode)
+
+				var probe bridge.ProbeResponse
+				require.NoError(t, json.Unmarshal(response, &probe))
+				assert.Equal(t, "GCF3WVYTHF75PEG6622G5G6KU26GOSDQPDHSCJ3DQD7VONH4EYVDOGKJ", probe.AccountID)
+				assert.Equal(t, "success", probe.Result)
+				assert.Equal(t, "6a0049b44e0d0341bd52f131c74383e6ccd2b74b92c829c990994d24bbfcfa7a", probe.Hash)
+				require.NotNil(t, probe.Ledger)
+				assert.Equal(t, ledger, *probe.Ledger)
+			})
+		})
+
+		Convey("When transaction fails", func() {
+			mockTransactionSubmitter.On(
+				"SubmitTransaction",
+				(*string)(nil),
+				"SDWLS4G3XCNIYPKXJWWGGJT6UDY63WV6PEFTWP7JZMQB4RE7EUJQN5XM",
+				mock.AnythingOfType("build.PaymentBuilder"),
+				nil,
+			).Return(horizon.SubmitTransactionResponse{
+				Extras: &horizon.SubmitTransactionResponseExtras{
+					ResultXdr: "AAAAAAAAAAD////7AAAAAA==", // tx_bad_seq
+				},
+			}, nil).Once()
+
+			Convey("it should return error code as result", func() {
+				statusCode, response := net.GetResponse(testServer, url.Values{})
+				assert.Equal(t, 200, statusCode)
+
+				var probe bridge.ProbeResponse
+				require.NoError(t, json.Unmarshal(response, &probe))
+				assert.Equal(t, "transaction_bad_seq", probe.Result)
+				assert.Nil(t, probe.Ledger)
+			})
+		})
+	})
+}
@@ -0,0 +1,71 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/stellar/gateway/protocols"
+	"github.com/stellar/gateway/protocols/bridge"
+	"github.com/stellar/gateway/server"
+	"github.com/stellar/go/keypair"
+)
+
+// ReserveSequence implements /reserve-sequence endpoint. It reserves the next sequence number of the
+// source account for a transaction built and signed outside of the bridge. The bridge never sends
+// a transaction with a reserved sequence number. Reserved sequence numbers that are not used within
+// `submission.sequence_reservation_ttl` seconds are reclaimed.
+func (rh *RequestHandler) ReserveSequence(w http.ResponseWriter, r *http.Request) {
+	request := &bridge.ReserveSequenceRequest{}
+	err := request.FromRequest(r)
+	if err != nil {
+		log.Error(err.Error())
+		server.Write(w, protocols.InvalidParameterError)
+		return
+	}
+
+	err = request.Validate()
+	if err != nil {
+		errorResponse := err.(*protocols.ErrorResponse)
+		log.WithFields(errorResponse.LogData).Error(errorResponse.Error())
+		server.Write(w, errorResponse)
+		return
+	}
+
+	// When bearer tokens are configured source account is determined by the token only
+	if len(rh.Config.AuthTokens) > 0 {
+		if request.Source != "" {
+			log.Print("source param sent when bearer token authentication is enabled")
+			server.Write(w, protocols.NewInvalidParameterError("source", "", "Source param is not accepted. Use `Authorization: Bearer` header instead."))
+			return
+		}
+
+		seed, ok := rh.seedFromAuthorization(r)
+		if !ok {
+			log.Print("Missing or invalid bearer token")
+			server.Write(w, protocols.UnauthorizedError)
+			return
+		}
+		request.Source = seed
+	}
+
+	if request.Source == "" {
+		request.Source = rh.Config.Accounts.BaseSeed
+	}
+
+	ttl := time.Duration(rh.Config.Submission.SequenceReservationTTL) * time.Second
+	sequence, expiresAt, err := rh.TransactionSubmitter.ReserveSequence(request.Source, ttl)
+	if err != nil {
+		log.WithFields(log.Fields{"err": err}).Error("Error reserving sequence number")
+		rh.writeHorizonError(w, err)
+		return
+	}
+
+	kp, _ := keypair.Parse(request.Source)
+	server.Write(w, &bridge.ReserveSequenceResponse{
+		AccountID:      kp.Address(),
+		SequenceNumber: strconv.FormatUint(sequence, 10),
+		ExpiresAt:      expiresAt,
+	})
+}
@@ -0,0 +1,117 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	. "github.com/smartystreets/goconvey/convey"
+	"github.com/stellar/gateway/bridge/config"
+	"github.com/stellar/gateway/mocks"
+	"github.com/stellar/gateway/net"
+	"github.com/stellar/gateway/test"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRequestHandlerReserveSequence(t *testing.T) {
+	mockTransactionSubmitter := new(mocks.MockTransactionSubmitter)
+
+	config := config.Config{
+		NetworkPassphrase: "Test SDF Network ; September 2015",
+		Accounts: config.Accounts{
+			// GCF3WVYTHF75PEG6622G5G6KU26GOSDQPDHSCJ3DQD7VONH4EYVDOGKJ
+			BaseSeed: "SDWLS4G3XCNIYPKXJWWGGJT6UDY63WV6PEFTWP7JZMQB4RE7EUJQN5XM",
+		},
+		Submission: config.Submission{
+			SequenceReservationTTL: 60,
+		},
+	}
+
+	requestHandler := RequestHandler{Config: &config, TransactionSubmitter: mockTransactionSubmitter}
+	testServer := httptest.NewServer(http.HandlerFunc(requestHandler.ReserveSequence))
+	defer testServer.Close()
+
+	expiresAt := time.Date(2018, 1, 2, 15, 4, 5, 0, time.UTC)
+
+	Convey("Given reserve sequence request", t, func() {
+		Convey("When source is invalid", func() {
+			params := url.Values{"source": {"GCF3WVYTHF75PEG6622G5G6KU26GOSDQPDHSCJ3DQD7VONH4EYVDOGKK"}}
+
+			Convey("it should return error", func() {
+				statusCode, response := net.GetResponse(testServer, params)
+				responseString := strings.TrimSpace(string(response))
+				assert.Equal(t, 400, statusCode)
+				expected := test.StringToJSONMap(`{
+  "code": "invalid_parameter",
+  "error_code": 101,
+  "message": "Invalid parameter.",
+  "data": {
+    "name": "source"
+  }
+}`)
+				assert.Equal(t, expected, test.StringToJSONMap(responseString, "more_info"))
+			})
+		})
+
+		Convey("When source is an account ID", func() {
+			params := url.Values{"source": {"GAPCT362RATBUJ37RN2MOKQIZLHSJMO33MMCSRUXTTHIGVDYWOFG5HDS"}}
+
+			mockTransactionSubmitter.On(
+				"ReserveSequence",
+				"GAPCT362RATBUJ37RN2MOKQIZLHSJMO33MMCSRUXTTHIGVDYWOFG5HDS",
+				time.Minute,
+			).Return(uint64(10372672437354497), expiresAt, nil).Once()
+
+			Convey("it should return reserved sequence number", func() {
+				statusCode, response := net.GetResponse(testServer, params)
+				responseString := strings.TrimSpace(string(response))
+				assert.Equal(t, 200, statusCode)
+				expected := test.StringToJSONMap(`{
+  "account_id": "GAPCT362RATBUJ37RN2MOKQIZLHSJMO33MMCSRUXTTHIGVDYWOFG5HDS",
+  "sequence_number": "10372672437354497",
+  "expires_at": "2018-01-02T15:04:05Z"
+}`)
+				assert.Equal(t, expected, test.StringToJSONMap(responseString))
+			})
+		})
+
+		Convey("When source is not sent", func() {
+			Convey("and sequence number is reserved", func() {
+				mockTransactionSubmitter.On(
+					"ReserveSequence",
+					"SDWLS4G3XCNIYPKXJWWGGJT6UDY63WV6PEFTWP7JZMQB4RE7EUJQN5XM",
+					time.Minute,
+				).Return(uint64(124), expiresAt, nil).Once()
+
+				Convey("it should reserve sequence number of the base account", func() {
+					statusCode, response := net.GetResponse(testServer, url.Values{})
+					responseString := strings.TrimSpace(string(response))
+					assert.Equal(t, 200, statusCode)
+					expected := test.StringToJSONMap(`{
+  "account_id": "GCF3WVYTHF75PEG6622G5G6KU26GOSDQPDHSCJ3DQD7VONH4EYVDOGKJ",
+  "sequence_number": "124",
+  "expires_at": "2018-01-02T15:04:05Z"
+}`)
+					assert.Equal(t, expected, test.StringToJSONMap(responseString))
+				})
+			})
+
+			Convey("and account cannot be loaded", func() {
+				mockTransactionSubmitter.On(
+					"ReserveSequence",
+					"SDWLS4G3XCNIYPKXJWWGGJT6UDY63WV6PEFTWP7JZMQB4RE7EUJQN5XM",
+					time.Minute,
+				).Return(uint64(0), time.Time{}, errors.New("Horizon error")).Once()
+
+				Convey("it should return error", func() {
+					statusCode, _ := net.GetResponse(testServer, url.Values{})
+					assert.Equal(t, 500, statusCode)
+				})
+			})
+		})
+	})
+}
@@ -0,0 +1,179 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/stellar/gateway/horizon"
+	"github.com/stellar/gateway/protocols"
+	"github.com/stellar/gateway/protocols/bridge"
+	"github.com/stellar/gateway/server"
+	"github.com/stellar/go/keypair"
+	"github.com/stellar/go/network"
+	"github.com/stellar/go/xdr"
+)
+
+// Sign implements /sign endpoint. It appends signatures of `signers` to a transaction envelope
+// built and possibly signed by another party (multisig) and optionally submits it.
+func (rh *RequestHandler) Sign(w http.ResponseWriter, r *http.Request) {
+	var request bridge.SignRequest
+
+	decoder := json.NewDecoder(r.Body)
+	err := decoder.Decode(&request)
+	if err != nil {
+		log.WithFields(log.Fields{"err": err}).Error("Error decoding request")
+		server.Write(w, protocols.NewInvalidParameterError("", "", "Request body is not a valid JSON"))
+		return
+	}
+
+	err = request.Validate()
+	if err != nil {
+		errorResponse := err.(*protocols.ErrorResponse)
+		log.WithFields(errorResponse.LogData).Error(errorResponse.Error())
+		server.Write(w, errorResponse)
+		return
+	}
+
+	// When bearer tokens are configured transaction is signed using a seed assigned to the token
+	if len(rh.Config.AuthTokens) > 0 {
+		if len(request.Signers) > 0 {
+			log.Print("signers param sent when bearer token authentication is enabled")
+			server.Write(w, protocols.NewInvalidParameterError("signers", "", "Signers param is not accepted. Use `Authorization: Bearer` header instead."))
+			return
+		}
+
+		seed, ok := rh.seedFromAuthorization(r)
+		if !ok {
+			log.Print("Missing or invalid bearer token")
+			server.Write(w, protocols.UnauthorizedError)
+			return
+		}
+		request.Signers = []string{seed}
+	}
+
+	if len(request.Signers) == 0 {
+		server.Write(w, protocols.NewMissingParameter("signers"))
+		return
+	}
+
+	var envelope xdr.TransactionEnvelope
+	// Validated already
+	xdr.SafeUnmarshalBase64(request.TransactionEnvelope, &envelope)
+
+	hash, err := network.HashTransaction(&envelope.Tx, rh.Config.NetworkPassphrase)
+	if err != nil {
+		log.WithFields(log.Fields{"err": err}).Error("Error calculating transaction hash")
+		server.Write(w, protocols.InternalServerError)
+		return
+	}
+
+	// Signers cannot be checked without the accounts so nothing is signed then
+	accounts, err := rh.loadTransactionAccounts(envelope.Tx)
+	if err == horizon.ErrAccountNotFound {
+		server.Write(w, bridge.AccountNotFound)
+		return
+	} else if err != nil {
+		rh.writeHorizonError(w, err)
+		return
+	}
+
+	for i, signer := range request.Signers {
+		kp := keypair.MustParse(signer).(*keypair.Full)
+
+		if !isSignerOfAny(accounts, kp.Address()) {
+			errorResponse := protocols.NewInvalidParameterError(
+				"signers["+strconv.Itoa(i)+"]",
+				"",
+				"Signer is not a signer of the transaction source or operation source accounts.",
+				map[string]interface{}{"signer": kp.Address()},
+			)
+			log.WithFields(errorResponse.LogData).Print(errorResponse.Error())
+			server.Write(w, errorResponse)
+			return
+		}
+
+		signature, err := kp.SignDecorated(hash[:])
+		if err != nil {
+			log.WithFields(log.Fields{"err": err}).Error("Error signing transaction")
+			server.Write(w, protocols.InternalServerError)
+			return
+		}
+
+		// Signatures are deterministic, signing the same envelope twice adds nothing
+		if hasSignature(envelope.Signatures, signature) {
+			continue
+		}
+
+		if len(envelope.Signatures) == bridge.MaxSignaturesPerTransaction {
+			server.Write(w, protocols.NewInvalidParameterError("signers", "", "Transaction cannot have more than 20 signatures."))
+			return
+		}
+		envelope.Signatures = append(envelope.Signatures, signature)
+	}
+
+	txeB64, err := xdr.MarshalBase64(envelope)
+	if err != nil {
+		log.WithFields(log.Fields{"err": err}).Error("Error encoding transaction envelope")
+		server.Write(w, protocols.InternalServerError)
+		return
+	}
+
+	if !request.Submit {
+		server.Write(w, &bridge.BuilderResponse{TransactionEnvelope: txeB64, Hash: hex.EncodeToString(hash[:])})
+		return
+	}
+
+	rh.submitEnvelope(w, txeB64, request.IncludeMeta, request.WaitForConfirmation, rh.asyncSubmission(request.SubmissionMode))
+}
+
+// loadTransactionAccounts loads transaction source and operation source accounts. It returns an error
+// when any of them cannot be loaded.
+func (rh *RequestHandler) loadTransactionAccounts(tx xdr.Transaction) ([]horizon.AccountResponse, error) {
+	accountIDs := []string{tx.SourceAccount.Address()}
+	for _, operation := range tx.Operations {
+		if operation.SourceAccount != nil {
+			accountIDs = append(accountIDs, operation.SourceAccount.Address())
+		}
+	}
+
+	var accounts []horizon.AccountResponse
+	loaded := make(map[string]bool)
+	for _, accountID := range accountIDs {
+		if loaded[accountID] {
+			continue
+		}
+		loaded[accountID] = true
+
+		account, err := rh.Horizon.LoadAccount(accountID)
+		if err != nil {
+			log.WithFields(log.Fields{"account_id": accountID, "err": err}).Error("Cannot load account, signers cannot be checked")
+			return nil, err
+		}
+		accounts = append(accounts, account)
+	}
+	return accounts, nil
+}
+
+// isSignerOfAny returns true when publicKey is a signer of at least one of the accounts
+func isSignerOfAny(accounts []horizon.AccountResponse, publicKey string) bool {
+	for _, account := range accounts {
+		if account.SignerWeight(publicKey) > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// hasSignature returns true when signatures already contain the given signature
+func hasSignature(signatures []xdr.DecoratedSignature, signature xdr.DecoratedSignature) bool {
+	for _, s := range signatures {
+		if s.Hint == signature.Hint && bytes.Equal(s.Signature, signature.Signature) {
+			return true
+		}
+	}
+	return false
+}
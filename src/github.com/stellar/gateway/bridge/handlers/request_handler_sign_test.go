@@ -0,0 +1,171 @@
+package handlers
+
+import (
+	"encoding/hex"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+	"github.com/stellar/gateway/bridge/config"
+	"github.com/stellar/gateway/horizon"
+	"github.com/stellar/gateway/mocks"
+	"github.com/stellar/gateway/net"
+	"github.com/stellar/gateway/test"
+	b "github.com/stellar/go/build"
+	"github.com/stellar/go/keypair"
+	"github.com/stellar/go/network"
+	"github.com/stellar/go/xdr"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRequestHandlerSign(t *testing.T) {
+	c := &config.Config{NetworkPassphrase: "Test SDF Network ; September 2015"}
+
+	mockHorizon := new(mocks.MockHorizon)
+	mockTransactionSubmitter := new(mocks.MockTransactionSubmitter)
+
+	requestHandler := RequestHandler{
+		Config:               c,
+		Horizon:              mockHorizon,
+		TransactionSubmitter: mockTransactionSubmitter,
+	}
+
+	testServer := httptest.NewServer(http.HandlerFunc(requestHandler.Sign))
+	defer testServer.Close()
+
+	source := keypair.MustParse("SDWLS4G3XCNIYPKXJWWGGJT6UDY63WV6PEFTWP7JZMQB4RE7EUJQN5XM").(*keypair.Full)
+	cosigner := keypair.MustParse("SBKKWO3ZVDDEHDJILGHPHCJCFD2GNUAYIUDMRAS326HLUEQ7ZFXWIGQK").(*keypair.Full)
+
+	tx, err := b.Transaction(
+		b.SourceAccount{source.Address()},
+		b.Sequence{124},
+		b.Network{c.NetworkPassphrase},
+		b.Payment(
+			b.Destination{"GAPCT362RATBUJ37RN2MOKQIZLHSJMO33MMCSRUXTTHIGVDYWOFG5HDS"},
+			b.NativeAmount{"1"},
+		),
+	)
+	require.NoError(t, err)
+	txe, err := tx.Sign(source.Seed())
+	require.NoError(t, err)
+	envelope, err := txe.Base64()
+	require.NoError(t, err)
+	hash, err := network.HashTransaction(&txe.E.Tx, c.NetworkPassphrase)
+	require.NoError(t, err)
+
+	sourceAccount := horizon.AccountResponse{
+		AccountID: source.Address(),
+		Signers: []horizon.Signer{
+			{PublicKey: source.Address(), Weight: 1},
+			{PublicKey: cosigner.Address(), Weight: 1},
+		},
+	}
+
+	Convey("Given sign request", t, func() {
+		Convey("When signer is invalid", func() {
+			Convey("it should return error", func() {
+				statusCode, response := net.JSONGetResponse(testServer, map[string]interface{}{"tx": envelope, "signers": []string{"GAPCT362RATBUJ37RN2MOKQIZLHSJMO33MMCSRUXTTHIGVDYWOFG5HDS"}})
+				assert.Equal(t, 400, statusCode)
+				expected := test.StringToJSONMap(`{
+  "code": "invalid_parameter",
+  "error_code": 101,
+  "message": "Invalid parameter.",
+  "more_info": "Key must be a secret seed (starting with ` + "`S`" + `).",
+  "data": {
+    "name": "signers[0]"
+  }
+}`)
+				assert.Equal(t, expected, test.StringToJSONMap(strings.TrimSpace(string(response))))
+			})
+		})
+
+		Convey("When signer is not a signer of the source account", func() {
+			account := sourceAccount
+			account.Signers = account.Signers[:1]
+			mockHorizon.On("LoadAccount", source.Address()).Return(account, nil).Once()
+
+			Convey("it should return error", func() {
+				statusCode, response := net.JSONGetResponse(testServer, map[string]interface{}{"tx": envelope, "signers": []string{cosigner.Seed()}})
+				assert.Equal(t, 400, statusCode)
+				expected := test.StringToJSONMap(`{
+  "code": "invalid_parameter",
+  "error_code": 101,
+  "message": "Invalid parameter.",
+  "more_info": "Signer is not a signer of the transaction source or operation source accounts.",
+  "data": {
+    "name": "signers[0]"
+  }
+}`)
+				assert.Equal(t, expected, test.StringToJSONMap(strings.TrimSpace(string(response))))
+			})
+		})
+
+		Convey("When signers are valid", func() {
+			mockHorizon.On("LoadAccount", source.Address()).Return(sourceAccount, nil).Once()
+
+			Convey("it should append new signatures only", func() {
+				statusCode, response := net.JSONGetResponse(testServer, map[string]interface{}{"tx": envelope, "signers": []string{source.Seed(), cosigner.Seed()}})
+				assert.Equal(t, 200, statusCode)
+
+				responseMap := test.StringToJSONMap(strings.TrimSpace(string(response)))
+				assert.Equal(t, hex.EncodeToString(hash[:]), responseMap["hash"])
+
+				var signed xdr.TransactionEnvelope
+				require.NoError(t, xdr.SafeUnmarshalBase64(responseMap["transaction_envelope"].(string), &signed))
+				require.Len(t, signed.Signatures, 2)
+				assert.Equal(t, txe.E.Signatures[0], signed.Signatures[0])
+				assert.Equal(t, cosigner.Hint(), [4]byte(signed.Signatures[1].Hint))
+				assert.NoError(t, cosigner.Verify(hash[:], signed.Signatures[1].Signature))
+			})
+		})
+
+		Convey("When source account cannot be loaded", func() {
+			mockHorizon.On("LoadAccount", source.Address()).Return(horizon.AccountResponse{}, errors.New("connection refused")).Once()
+
+			Convey("it should not sign the transaction", func() {
+				statusCode, response := net.JSONGetResponse(testServer, map[string]interface{}{"tx": envelope, "signers": []string{cosigner.Seed()}})
+				assert.Equal(t, 500, statusCode)
+				assert.Equal(t, "internal_server_error", test.StringToJSONMap(strings.TrimSpace(string(response)))["code"])
+			})
+		})
+
+		Convey("When source account does not exist", func() {
+			mockHorizon.On("LoadAccount", source.Address()).Return(horizon.AccountResponse{}, horizon.ErrAccountNotFound).Once()
+
+			Convey("it should not sign the transaction", func() {
+				statusCode, response := net.JSONGetResponse(testServer, map[string]interface{}{"tx": envelope, "signers": []string{cosigner.Seed()}})
+				assert.Equal(t, 404, statusCode)
+				assert.Equal(t, "account_not_found", test.StringToJSONMap(strings.TrimSpace(string(response)))["code"])
+			})
+		})
+
+		Convey("When transaction is submitted", func() {
+			mockHorizon.On("LoadAccount", source.Address()).Return(sourceAccount, nil).Once()
+
+			var ledger uint64 = 1988727
+			mockTransactionSubmitter.On("ResubmitTransaction", mock.AnythingOfType("string")).Run(func(args mock.Arguments) {
+				var submitted xdr.TransactionEnvelope
+				require.NoError(t, xdr.SafeUnmarshalBase64(args.String(0), &submitted))
+				assert.Len(t, submitted.Signatures, 2)
+			}).Return(
+				horizon.SubmitTransactionResponse{Hash: "a", Ledger: &ledger},
+				nil,
+			).Once()
+
+			Convey("it should return submission result", func() {
+				statusCode, response := net.JSONGetResponse(testServer, map[string]interface{}{"tx": envelope, "signers": []string{cosigner.Seed()}, "submit": true})
+				assert.Equal(t, 200, statusCode)
+				expected := test.StringToJSONMap(`{
+  "hash": "a",
+  "ledger": 1988727
+}`)
+				assert.Equal(t, expected, test.StringToJSONMap(strings.TrimSpace(string(response))))
+			})
+		})
+	})
+}
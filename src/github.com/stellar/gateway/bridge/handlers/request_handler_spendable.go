@@ -0,0 +1,145 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/stellar/gateway/horizon"
+	"github.com/stellar/gateway/protocols"
+	"github.com/stellar/gateway/protocols/bridge"
+	"github.com/stellar/gateway/server"
+	"github.com/stellar/go/address"
+	"github.com/stellar/go/amount"
+	"github.com/stellar/go/strkey"
+	"github.com/stellar/go/xdr"
+	"github.com/zenazn/goji/web"
+)
+
+// AccountSpendable implements /account/:address/spendable endpoint. It returns the maximum amount
+// of XLM the account can send: its native balance minus the minimum balance, selling liabilities
+// and `spendable.fee_buffer`. Limits and receiving capacity of the account's trustlines are
+// returned too.
+func (rh *RequestHandler) AccountSpendable(c web.C, w http.ResponseWriter, r *http.Request) {
+	accountID := c.URLParams["address"]
+	if _, _, err := address.Split(accountID); err == nil {
+		nameResponse, err := rh.FederationResolver.LookupByAddress(accountID)
+		if err != nil {
+			log.WithFields(log.Fields{"address": accountID, "err": err}).Print("Cannot resolve address")
+			server.Write(w, federationLookupError(err))
+			return
+		}
+		accountID = nameResponse.AccountID
+	}
+
+	err := protocols.CheckKey(accountID, strkey.VersionByteAccountID)
+	if err != nil {
+		server.Write(w, protocols.NewInvalidParameterError("address", c.URLParams["address"], err.Error()))
+		return
+	}
+
+	account, err := rh.Horizon.LoadAccount(accountID)
+	if err == horizon.ErrAccountNotFound {
+		server.Write(w, bridge.AccountNotFound)
+		return
+	} else if err != nil {
+		log.WithFields(log.Fields{"account_id": accountID, "err": err}).Error("Error loading account")
+		rh.writeHorizonError(w, err)
+		return
+	}
+
+	baseReserve, err := rh.baseReserve()
+	if err != nil {
+		log.WithFields(log.Fields{"err": err}).Error("Error loading base reserve")
+		rh.writeHorizonError(w, err)
+		return
+	}
+
+	var feeBuffer xdr.Int64
+	if rh.Config.Spendable.FeeBuffer != "" {
+		feeBuffer = amount.MustParse(rh.Config.Spendable.FeeBuffer)
+	}
+
+	var balance, sellingLiabilities xdr.Int64
+	if native := account.Balance("", ""); native != nil {
+		balance, err = amount.Parse(native.Balance)
+		if err != nil {
+			log.WithFields(log.Fields{"balance": native.Balance, "err": err}).Error("Error parsing native balance")
+			server.Write(w, protocols.InternalServerError)
+			return
+		}
+
+		if native.SellingLiabilities != "" {
+			sellingLiabilities, err = amount.Parse(native.SellingLiabilities)
+			if err != nil {
+				log.WithFields(log.Fields{"selling_liabilities": native.SellingLiabilities, "err": err}).Error("Error parsing selling liabilities")
+				server.Write(w, protocols.InternalServerError)
+				return
+			}
+		}
+	}
+
+	minimumBalance := account.MinimumBalance(baseReserve)
+	spendable := balance - minimumBalance - sellingLiabilities - feeBuffer
+	if spendable < 0 {
+		spendable = 0
+	}
+
+	trustlines := []bridge.SpendableTrustline{}
+	for _, b := range account.Balances {
+		if b.AssetType == "native" {
+			continue
+		}
+
+		capacity, err := b.Capacity()
+		if err != nil {
+			log.WithFields(log.Fields{"asset_code": b.AssetCode, "asset_issuer": b.AssetIssuer, "err": err}).Error("Error calculating trustline capacity")
+			server.Write(w, protocols.InternalServerError)
+			return
+		}
+
+		buyingLiabilities := b.BuyingLiabilities
+		if buyingLiabilities == "" {
+			buyingLiabilities = amount.String(0)
+		}
+
+		trustlines = append(trustlines, bridge.SpendableTrustline{
+			AssetType:         b.AssetType,
+			AssetCode:         b.AssetCode,
+			AssetIssuer:       b.AssetIssuer,
+			Balance:           b.Balance,
+			Limit:             b.Limit,
+			BuyingLiabilities: buyingLiabilities,
+			Capacity:          amount.String(capacity),
+		})
+	}
+
+	server.Write(w, bridge.SpendableResponse{
+		AccountID:          accountID,
+		Balance:            amount.String(balance),
+		BaseReserve:        amount.String(baseReserve),
+		SubentryCount:      account.SubentryCount,
+		MinimumBalance:     amount.String(minimumBalance),
+		SellingLiabilities: amount.String(sellingLiabilities),
+		FeeBuffer:          amount.String(feeBuffer),
+		Spendable:          amount.String(spendable),
+		Trustlines:         trustlines,
+	})
+}
+
+// baseReserve returns `spendable.base_reserve` or, when not set, the base reserve of the latest ledger
+func (rh *RequestHandler) baseReserve() (xdr.Int64, error) {
+	if rh.Config.Spendable.BaseReserve != "" {
+		// Validated in config
+		return amount.MustParse(rh.Config.Spendable.BaseReserve), nil
+	}
+
+	ledger, err := rh.Horizon.LoadLatestLedger()
+	if err != nil {
+		return 0, err
+	}
+	if ledger.BaseReserveInStroops <= 0 {
+		return 0, errors.New("Invalid base reserve of the latest ledger")
+	}
+	return ledger.BaseReserveInStroops, nil
+}
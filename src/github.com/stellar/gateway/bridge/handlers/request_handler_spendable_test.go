@@ -0,0 +1,139 @@
+package handlers
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+	"github.com/stellar/gateway/bridge/config"
+	"github.com/stellar/gateway/horizon"
+	"github.com/stellar/gateway/mocks"
+	"github.com/stellar/gateway/test"
+	"github.com/stellar/go/protocols/federation"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/zenazn/goji/web"
+)
+
+func TestRequestHandlerAccountSpendable(t *testing.T) {
+	c := &config.Config{}
+	mockHorizon := new(mocks.MockHorizon)
+	mockFederationResolver := new(mocks.MockFederationResolver)
+	requestHandler := RequestHandler{
+		Config:             c,
+		Horizon:            mockHorizon,
+		FederationResolver: mockFederationResolver,
+	}
+
+	mux := web.New()
+	mux.Get("/account/:address/spendable", requestHandler.AccountSpendable)
+	testServer := httptest.NewServer(mux)
+	defer testServer.Close()
+
+	accountID := "GAPCT362RATBUJ37RN2MOKQIZLHSJMO33MMCSRUXTTHIGVDYWOFG5HDS"
+	account := horizon.AccountResponse{
+		AccountID:     accountID,
+		SubentryCount: 3,
+		Balances: []horizon.Balance{
+			{Balance: "100.0000000", Limit: "1000.0000000", BuyingLiabilities: "50.0000000", AssetType: "credit_alphanum4", AssetCode: "USD", AssetIssuer: "GDSIKW43UA6JTOA47WVEBCZ4MYC74M3GNKNXTVDXFHXYYTNO5GGVN632"},
+			{Balance: "922337203685.4775807", Limit: "922337203685.4775807", AssetType: "credit_alphanum4", AssetCode: "EUR", AssetIssuer: "GDSIKW43UA6JTOA47WVEBCZ4MYC74M3GNKNXTVDXFHXYYTNO5GGVN632"},
+			{Balance: "10.0000000", AssetType: "native", SellingLiabilities: "1.5000000"},
+		},
+	}
+
+	get := func(address string) (int, map[string]interface{}) {
+		resp, err := http.Get(testServer.URL + "/account/" + address + "/spendable")
+		require.NoError(t, err)
+		defer resp.Body.Close()
+		body, err := ioutil.ReadAll(resp.Body)
+		require.NoError(t, err)
+		return resp.StatusCode, test.StringToJSONMap(strings.TrimSpace(string(body)))
+	}
+
+	Convey("Given spendable request", t, func() {
+		c.Spendable = config.Spendable{FeeBuffer: "0.01"}
+
+		Convey("When base reserve is loaded from Horizon", func() {
+			mockHorizon.On("LoadAccount", accountID).Return(account, nil).Once()
+			mockHorizon.On("LoadLatestLedger").Return(horizon.LedgerResponse{BaseReserveInStroops: 5000000}, nil).Once()
+
+			Convey("it should return spendable balance", func() {
+				statusCode, response := get(accountID)
+				assert.Equal(t, 200, statusCode)
+				expected := test.StringToJSONMap(`{
+  "account_id": "GAPCT362RATBUJ37RN2MOKQIZLHSJMO33MMCSRUXTTHIGVDYWOFG5HDS",
+  "balance": "10.0000000",
+  "base_reserve": "0.5000000",
+  "subentry_count": 3,
+  "minimum_balance": "2.5000000",
+  "selling_liabilities": "1.5000000",
+  "fee_buffer": "0.0100000",
+  "spendable": "5.9900000",
+  "trustlines": [
+    {
+      "asset_type": "credit_alphanum4",
+      "asset_code": "USD",
+      "asset_issuer": "GDSIKW43UA6JTOA47WVEBCZ4MYC74M3GNKNXTVDXFHXYYTNO5GGVN632",
+      "balance": "100.0000000",
+      "limit": "1000.0000000",
+      "buying_liabilities": "50.0000000",
+      "capacity": "850.0000000"
+    },
+    {
+      "asset_type": "credit_alphanum4",
+      "asset_code": "EUR",
+      "asset_issuer": "GDSIKW43UA6JTOA47WVEBCZ4MYC74M3GNKNXTVDXFHXYYTNO5GGVN632",
+      "balance": "922337203685.4775807",
+      "limit": "922337203685.4775807",
+      "buying_liabilities": "0.0000000",
+      "capacity": "0.0000000"
+    }
+  ]
+}`)
+				assert.Equal(t, expected, response)
+			})
+		})
+
+		Convey("When base reserve is configured and address is federated", func() {
+			c.Spendable.BaseReserve = "5"
+			mockFederationResolver.On("LookupByAddress", "alice*stellar.org").Return(
+				&federation.NameResponse{AccountID: accountID},
+				nil,
+			).Once()
+			mockHorizon.On("LoadAccount", accountID).Return(account, nil).Once()
+
+			Convey("it should never return negative spendable balance", func() {
+				statusCode, response := get("alice*stellar.org")
+				assert.Equal(t, 200, statusCode)
+				assert.Equal(t, "25.0000000", response["minimum_balance"])
+				assert.Equal(t, "0.0000000", response["spendable"])
+			})
+		})
+
+		Convey("When account does not exist", func() {
+			mockHorizon.On("LoadAccount", accountID).Return(horizon.AccountResponse{}, horizon.ErrAccountNotFound).Once()
+
+			Convey("it should return error", func() {
+				statusCode, response := get(accountID)
+				assert.Equal(t, 404, statusCode)
+				expected := test.StringToJSONMap(`{
+  "code": "account_not_found",
+  "error_code": 800,
+  "message": "Account not found."
+}`)
+				assert.Equal(t, expected, response)
+			})
+		})
+
+		Convey("When address is invalid", func() {
+			Convey("it should return error", func() {
+				statusCode, response := get("GABC")
+				assert.Equal(t, 400, statusCode)
+				assert.Equal(t, "invalid_parameter", response["code"])
+			})
+		})
+	})
+}
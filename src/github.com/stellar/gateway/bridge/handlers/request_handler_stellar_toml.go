@@ -0,0 +1,54 @@
+package handlers
+
+import (
+	"net/http"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/stellar/gateway/protocols"
+	"github.com/stellar/gateway/protocols/bridge"
+	"github.com/stellar/gateway/server"
+)
+
+// StellarToml implements /stellar-toml endpoint. It fetches stellar.toml file of the domain (ex. of
+// an asset issuer being onboarded) and returns its federation server, auth server, signing key and
+// currencies along with problems found in the file. Results are cached for
+// `stellar_toml_cache_ttl` seconds.
+func (rh *RequestHandler) StellarToml(w http.ResponseWriter, r *http.Request) {
+	request := &bridge.StellarTomlRequest{}
+	err := request.FromRequest(r)
+	if err != nil {
+		log.Error(err.Error())
+		server.Write(w, protocols.InvalidParameterError)
+		return
+	}
+
+	err = request.Validate()
+	if err != nil {
+		errorResponse := err.(*protocols.ErrorResponse)
+		log.WithFields(errorResponse.LogData).Error(errorResponse.Error())
+		server.Write(w, errorResponse)
+		return
+	}
+
+	check := rh.StellarTomlChecker.Check(request.Domain)
+	response := bridge.StellarTomlResponse{
+		Domain: request.Domain,
+		Valid:  len(check.Errors) == 0,
+		Errors: check.Errors,
+	}
+
+	if check.Toml != nil {
+		response.FederationServer = check.Toml.FederationServer
+		response.AuthServer = check.Toml.AuthServer
+		response.SigningKey = check.Toml.SigningKey
+		for _, currency := range check.Toml.Currencies {
+			response.Currencies = append(response.Currencies, bridge.StellarTomlCurrency{Code: currency.Code, Issuer: currency.Issuer})
+		}
+	}
+
+	if !response.Valid {
+		log.WithFields(log.Fields{"domain": request.Domain, "errors": check.Errors}).Print("Invalid stellar.toml")
+	}
+
+	server.Write(w, response)
+}
@@ -0,0 +1,99 @@
+package handlers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	. "github.com/smartystreets/goconvey/convey"
+	"github.com/stellar/gateway/bridge/config"
+	"github.com/stellar/gateway/external"
+	"github.com/stellar/gateway/mocks"
+	"github.com/stellar/gateway/net"
+	"github.com/stellar/gateway/test"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRequestHandlerStellarToml(t *testing.T) {
+	mockHTTPClient := new(mocks.MockHTTPClient)
+
+	requestHandler := RequestHandler{
+		Config:             &config.Config{},
+		StellarTomlChecker: external.NewStellarTomlChecker(mockHTTPClient, time.Minute, time.Now),
+	}
+	testServer := httptest.NewServer(http.HandlerFunc(requestHandler.StellarToml))
+	defer testServer.Close()
+
+	Convey("Given stellar.toml request", t, func() {
+		Convey("When domain is invalid", func() {
+			params := url.Values{"domain": {"stellar.org/.well-known"}}
+
+			Convey("it should return error", func() {
+				statusCode, response := net.GetResponse(testServer, params)
+				responseString := strings.TrimSpace(string(response))
+				assert.Equal(t, 400, statusCode)
+				expected := test.StringToJSONMap(`{
+  "code": "invalid_parameter",
+  "error_code": 101,
+  "message": "Invalid parameter.",
+  "data": {
+    "name": "domain"
+  }
+}`)
+				assert.Equal(t, expected, test.StringToJSONMap(responseString, "more_info"))
+			})
+		})
+
+		Convey("When stellar.toml is valid", func() {
+			mockHTTPClient.On("Get", "https://valid.example.com/.well-known/stellar.toml").Return(
+				net.BuildHTTPResponse(200, `
+FEDERATION_SERVER = "https://valid.example.com/federation"
+SIGNING_KEY = "GBDOSO3K4JTGSWJSIHXAOFIBMAABVM3YK3FI6VJPKIHHM56XAFIUCGD6"
+
+[[CURRENCIES]]
+code = "USD"
+issuer = "GBDOSO3K4JTGSWJSIHXAOFIBMAABVM3YK3FI6VJPKIHHM56XAFIUCGD6"
+`),
+				nil,
+			).Once()
+
+			Convey("it should return its fields", func() {
+				statusCode, response := net.GetResponse(testServer, url.Values{"domain": {"valid.example.com"}})
+				responseString := strings.TrimSpace(string(response))
+				assert.Equal(t, 200, statusCode)
+				expected := test.StringToJSONMap(`{
+  "domain": "valid.example.com",
+  "valid": true,
+  "federation_server": "https://valid.example.com/federation",
+  "signing_key": "GBDOSO3K4JTGSWJSIHXAOFIBMAABVM3YK3FI6VJPKIHHM56XAFIUCGD6",
+  "currencies": [
+    {"code": "USD", "issuer": "GBDOSO3K4JTGSWJSIHXAOFIBMAABVM3YK3FI6VJPKIHHM56XAFIUCGD6"}
+  ]
+}`)
+				assert.Equal(t, expected, test.StringToJSONMap(responseString))
+			})
+		})
+
+		Convey("When stellar.toml is unreachable", func() {
+			mockHTTPClient.On("Get", "https://missing.example.com/.well-known/stellar.toml").Return(
+				net.BuildHTTPResponse(404, "Not Found"),
+				nil,
+			).Once()
+
+			Convey("it should return validation errors", func() {
+				statusCode, response := net.GetResponse(testServer, url.Values{"domain": {"missing.example.com"}})
+				responseString := strings.TrimSpace(string(response))
+				assert.Equal(t, 200, statusCode)
+				expected := test.StringToJSONMap(`{
+  "domain": "missing.example.com",
+  "valid": false,
+  "errors": ["stellar.toml is unreachable: HTTP status 404"]
+}`)
+				assert.Equal(t, expected, test.StringToJSONMap(responseString))
+			})
+		})
+	})
+}
@@ -0,0 +1,61 @@
+package handlers
+
+import (
+	"net/http"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/stellar/gateway/protocols"
+	"github.com/stellar/gateway/protocols/bridge"
+	"github.com/stellar/gateway/server"
+)
+
+// Submit implements /submit endpoint. It submits a transaction signed elsewhere (ex. built using
+// /builder on an offline machine). Transactions already in the ledger are not submitted again so
+// the request can be safely repeated.
+func (rh *RequestHandler) Submit(w http.ResponseWriter, r *http.Request) {
+	request := &bridge.SubmitRequest{}
+	err := request.FromRequest(r)
+	if err != nil {
+		log.Error(err.Error())
+		server.Write(w, protocols.InvalidParameterError)
+		return
+	}
+
+	err = request.Validate()
+	if err != nil {
+		errorResponse := err.(*protocols.ErrorResponse)
+		log.WithFields(errorResponse.LogData).Error(errorResponse.Error())
+		server.Write(w, errorResponse)
+		return
+	}
+
+	rh.submitEnvelope(w, request.TransactionEnvelope, request.IncludeMeta, request.WaitForConfirmation, rh.asyncSubmission(request.SubmissionMode))
+}
+
+// submitEnvelope submits a signed transaction envelope and writes the result. When async is true
+// the transaction is submitted to Horizon async submission endpoint.
+func (rh *RequestHandler) submitEnvelope(w http.ResponseWriter, envelope string, includeMeta, waitForConfirmation, async bool) {
+	resubmit := rh.TransactionSubmitter.ResubmitTransaction
+	if async {
+		resubmit = rh.TransactionSubmitter.ResubmitTransactionAsync
+	}
+
+	submitResponse, err := resubmit(envelope)
+	if err != nil {
+		log.WithFields(log.Fields{"err": err}).Error("Error submitting transaction")
+		rh.writeHorizonError(w, err)
+		return
+	}
+
+	if waitForConfirmation {
+		var errorResponse *protocols.ErrorResponse
+		submitResponse, errorResponse = rh.confirmTransaction(submitResponse)
+		if errorResponse != nil {
+			log.WithFields(errorResponse.LogData).Print(errorResponse.Error())
+			server.Write(w, errorResponse)
+			return
+		}
+	}
+
+	rh.handleSubmitterResponse(w, submitResponse, includeMeta)
+}
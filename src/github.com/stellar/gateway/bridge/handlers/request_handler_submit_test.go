@@ -0,0 +1,335 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+	"github.com/stellar/gateway/bridge/config"
+	"github.com/stellar/gateway/horizon"
+	"github.com/stellar/gateway/mocks"
+	"github.com/stellar/gateway/net"
+	"github.com/stellar/gateway/test"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRequestHandlerSubmit(t *testing.T) {
+	c := &config.Config{NetworkPassphrase: "Test SDF Network ; September 2015"}
+
+	mockHorizon := new(mocks.MockHorizon)
+	mockTransactionSubmitter := new(mocks.MockTransactionSubmitter)
+
+	requestHandler := RequestHandler{
+		Config:               c,
+		Horizon:              mockHorizon,
+		TransactionSubmitter: mockTransactionSubmitter,
+	}
+
+	testServer := httptest.NewServer(http.HandlerFunc(requestHandler.Submit))
+	defer testServer.Close()
+
+	rawErrorsServer := httptest.NewServer(http.HandlerFunc(requestHandler.WithRawErrors().Submit))
+	defer rawErrorsServer.Close()
+
+	// Built by /builder, see request_handler_builder_test.go
+	envelope := "AAAAAGySS3ZylffFaVZqZD6lNCUjCizHz7MLPwkN7Mxh4XN5AAAAZAAAAAAAAAB7AAAAAAAAAAAAAAABAAAAAAAAAAAAAAAAnEM7m3lksnFftHMGxdt6HTitUQSfvVvjk8JfduWfK+cAAAAAHc1lAAAAAAAAAAABn420/AAAAECXY+neSolhAeHUXf+UrOV6PjeJnvLM/HqjOlOEWD3hmu/z9aBksDu9zqa26jS14eMpZzq8sofnnvt248FUO+cP"
+
+	Convey("Given submit request", t, func() {
+		Convey("When tx is missing", func() {
+			Convey("it should return error", func() {
+				statusCode, response := net.GetResponse(testServer, url.Values{})
+				responseString := strings.TrimSpace(string(response))
+				assert.Equal(t, 400, statusCode)
+				expected := test.StringToJSONMap(`{
+  "code": "missing_parameter",
+  "error_code": 102,
+  "message": "Required parameter is missing.",
+  "data": {
+    "name": "tx"
+  }
+}`)
+				assert.Equal(t, expected, test.StringToJSONMap(responseString))
+			})
+		})
+
+		Convey("When tx is not base64", func() {
+			Convey("it should return error", func() {
+				statusCode, response := net.GetResponse(testServer, url.Values{"tx": {"AAA!"}})
+				responseString := strings.TrimSpace(string(response))
+				assert.Equal(t, 400, statusCode)
+				expected := test.StringToJSONMap(`{
+  "code": "submit_invalid_envelope",
+  "error_code": 1000,
+  "message": "Transaction envelope is malformed.",
+  "data": {
+    "name": "tx",
+    "reason": "base64",
+    "error": "illegal base64 data at input byte 3"
+  }
+}`)
+				assert.Equal(t, expected, test.StringToJSONMap(responseString))
+			})
+		})
+
+		Convey("When tx is not a transaction envelope", func() {
+			Convey("it should return error", func() {
+				statusCode, response := net.GetResponse(testServer, url.Values{"tx": {"AAAA"}})
+				responseString := strings.TrimSpace(string(response))
+				assert.Equal(t, 400, statusCode)
+				assert.Equal(t, "submit_invalid_envelope", test.StringToJSONMap(responseString)["code"])
+				data := test.StringToJSONMap(responseString)["data"].(map[string]interface{})
+				assert.Equal(t, "tx", data["name"])
+				assert.Equal(t, "xdr", data["reason"])
+				assert.NotEmpty(t, data["error"])
+			})
+		})
+
+		Convey("When transaction succeeds", func() {
+			var ledger uint64 = 1988727
+			mockTransactionSubmitter.On("ResubmitTransaction", envelope).Return(
+				horizon.SubmitTransactionResponse{Hash: "8376a64d4d0d116be143c00ed6181d8bb64ae74c9ba7b773277a6926e7a20bed", Ledger: &ledger},
+				nil,
+			).Once()
+
+			Convey("it should return transaction hash and ledger", func() {
+				statusCode, response := net.GetResponse(testServer, url.Values{"tx": {envelope}})
+				responseString := strings.TrimSpace(string(response))
+				assert.Equal(t, 200, statusCode)
+				expected := test.StringToJSONMap(`{
+  "hash": "8376a64d4d0d116be143c00ed6181d8bb64ae74c9ba7b773277a6926e7a20bed",
+  "ledger": 1988727
+}`)
+				assert.Equal(t, expected, test.StringToJSONMap(responseString))
+			})
+		})
+
+		Convey("When transaction fails", func() {
+			mockTransactionSubmitter.On("ResubmitTransaction", envelope).Return(
+				horizon.SubmitTransactionResponse{
+					Extras: &horizon.SubmitTransactionResponseExtras{
+						EnvelopeXdr: envelope,
+						ResultXdr:   "AAAAAAAAAAD////7AAAAAA==", // tx_bad_seq
+					},
+				},
+				nil,
+			).Once()
+
+			Convey("it should return error", func() {
+				statusCode, response := net.GetResponse(testServer, url.Values{"tx": {envelope}})
+				responseString := strings.TrimSpace(string(response))
+				assert.Equal(t, 400, statusCode)
+				expected := test.StringToJSONMap(`{
+  "code": "transaction_bad_seq",
+  "error_code": 200,
+  "message": "Bad Sequence. Please, try again."
+}`)
+				assert.Equal(t, expected, test.StringToJSONMap(responseString))
+			})
+		})
+
+		Convey("When transaction fails and raw errors are requested", func() {
+			problem := `{"type": "https://stellar.org/horizon-errors/transaction_failed", "title": "Transaction Failed", "status": 400, "extras": {"result_codes": {"transaction": "tx_bad_seq"}}}`
+			mockTransactionSubmitter.On("ResubmitTransaction", envelope).Return(
+				horizon.SubmitTransactionResponse{
+					Extras: &horizon.SubmitTransactionResponseExtras{
+						EnvelopeXdr: envelope,
+						ResultXdr:   "AAAAAAAAAAD////7AAAAAA==", // tx_bad_seq
+					},
+					RawError: []byte(problem),
+				},
+				nil,
+			).Twice()
+
+			Convey("it should return error with Horizon error document", func() {
+				statusCode, response := net.GetResponse(rawErrorsServer, url.Values{"tx": {envelope}})
+				responseString := strings.TrimSpace(string(response))
+				assert.Equal(t, 400, statusCode)
+				expected := test.StringToJSONMap(`{
+  "code": "transaction_bad_seq",
+  "error_code": 200,
+  "message": "Bad Sequence. Please, try again.",
+  "horizon_error": {
+    "type": "https://stellar.org/horizon-errors/transaction_failed",
+    "title": "Transaction Failed",
+    "status": 400,
+    "extras": {
+      "result_codes": {
+        "transaction": "tx_bad_seq"
+      }
+    }
+  }
+}`)
+				assert.Equal(t, expected, test.StringToJSONMap(responseString))
+
+				// Not included unless requested
+				_, response = net.GetResponse(testServer, url.Values{"tx": {envelope}})
+				assert.NotContains(t, string(response), "horizon_error")
+			})
+		})
+
+		Convey("When transaction fee is too small", func() {
+			mockTransactionSubmitter.On("ResubmitTransaction", envelope).Return(
+				horizon.SubmitTransactionResponse{
+					Extras: &horizon.SubmitTransactionResponseExtras{
+						EnvelopeXdr: envelope,
+						ResultXdr:   "AAAAAAAAAAD////3AAAAAA==", // tx_insufficient_fee
+					},
+				},
+				nil,
+			).Once()
+			mockHorizon.On("LoadLatestLedger").Return(horizon.LedgerResponse{BaseFeeInStroops: 200}, nil).Once()
+
+			Convey("it should return error with the current base fee", func() {
+				statusCode, response := net.GetResponse(testServer, url.Values{"tx": {envelope}})
+				responseString := strings.TrimSpace(string(response))
+				assert.Equal(t, 400, statusCode)
+				expected := test.StringToJSONMap(`{
+  "code": "transaction_insufficient_fee",
+  "error_code": 204,
+  "message": "Transaction fee is too small.",
+  "data": {
+    "base_fee": 200
+  }
+}`)
+				assert.Equal(t, expected, test.StringToJSONMap(responseString))
+			})
+		})
+
+		Convey("When transaction result is unknown", func() {
+			hash := "8376a64d4d0d116be143c00ed6181d8bb64ae74c9ba7b773277a6926e7a20bed"
+			params := url.Values{"tx": {envelope}, "wait_for_confirmation": {"true"}}
+
+			// Horizon timed out waiting for the ledger
+			mockTransactionSubmitter.On("ResubmitTransaction", envelope).Return(
+				horizon.SubmitTransactionResponse{Hash: hash},
+				nil,
+			).Once()
+
+			Convey("and transaction is found in a ledger", func() {
+				mockHorizon.On("LoadTransaction", hash).Return(
+					&horizon.TransactionResponse{Hash: hash, Ledger: 1988727, ResultXdr: "AAAAAAAAAGQAAAAAAAAAAQAAAAAAAAAAAAAAAAAAAAA="},
+					nil,
+				).Once()
+
+				Convey("it should return confirmed result", func() {
+					statusCode, response := net.GetResponse(testServer, params)
+					responseString := strings.TrimSpace(string(response))
+					assert.Equal(t, 200, statusCode)
+					expected := test.StringToJSONMap(`{
+  "fee": "0.0000100",
+  "fee_stroops": "100",
+  "hash": "8376a64d4d0d116be143c00ed6181d8bb64ae74c9ba7b773277a6926e7a20bed",
+  "result_xdr": "AAAAAAAAAGQAAAAAAAAAAQAAAAAAAAAAAAAAAAAAAAA=",
+  "ledger": 1988727
+}`)
+					assert.Equal(t, expected, test.StringToJSONMap(responseString))
+				})
+			})
+
+			// confirmation_timeout is 0 so Horizon is polled once
+			Convey("and transaction is not found before timeout", func() {
+				mockHorizon.On("LoadTransaction", hash).Return(
+					(*horizon.TransactionResponse)(nil),
+					nil,
+				).Once()
+
+				Convey("it should return error", func() {
+					statusCode, response := net.GetResponse(testServer, params)
+					responseString := strings.TrimSpace(string(response))
+					assert.Equal(t, 202, statusCode)
+					expected := test.StringToJSONMap(`{
+  "code": "transaction_not_confirmed",
+  "error_code": 206,
+  "message": "Transaction has been submitted but is not in a ledger yet. Check its status later.",
+  "data": {
+    "hash": "8376a64d4d0d116be143c00ed6181d8bb64ae74c9ba7b773277a6926e7a20bed"
+  }
+}`)
+					assert.Equal(t, expected, test.StringToJSONMap(responseString))
+				})
+			})
+		})
+
+		Convey("When submission_mode is invalid", func() {
+			Convey("it should return error", func() {
+				statusCode, response := net.GetResponse(testServer, url.Values{"tx": {envelope}, "submission_mode": {"later"}})
+				responseString := strings.TrimSpace(string(response))
+				assert.Equal(t, 400, statusCode)
+				expected := test.StringToJSONMap(`{
+  "code": "invalid_parameter",
+  "error_code": 101,
+  "message": "Invalid parameter.",
+  "more_info": "Submission mode must be ` + "`sync` or `async`" + `.",
+  "data": {
+    "name": "submission_mode"
+  }
+}`)
+				assert.Equal(t, expected, test.StringToJSONMap(responseString))
+			})
+		})
+
+		Convey("When transaction is submitted asynchronously", func() {
+			hash := "8376a64d4d0d116be143c00ed6181d8bb64ae74c9ba7b773277a6926e7a20bed"
+			mockTransactionSubmitter.On("ResubmitTransactionAsync", envelope).Return(
+				horizon.SubmitTransactionResponse{Hash: hash, Status: horizon.AsyncStatusPending},
+				nil,
+			).Once()
+
+			expected := test.StringToJSONMap(`{
+  "hash": "8376a64d4d0d116be143c00ed6181d8bb64ae74c9ba7b773277a6926e7a20bed",
+  "ledger": null,
+  "tx_status": "PENDING"
+}`)
+
+			Convey("by submission_mode param it should return pending status", func() {
+				statusCode, response := net.GetResponse(testServer, url.Values{"tx": {envelope}, "submission_mode": {"async"}})
+				responseString := strings.TrimSpace(string(response))
+				assert.Equal(t, 202, statusCode)
+				assert.Equal(t, expected, test.StringToJSONMap(responseString))
+			})
+
+			Convey("by submission.mode config param it should return pending status", func() {
+				c.Submission.Mode = "async"
+				defer func() { c.Submission.Mode = "" }()
+
+				statusCode, response := net.GetResponse(testServer, url.Values{"tx": {envelope}})
+				responseString := strings.TrimSpace(string(response))
+				assert.Equal(t, 202, statusCode)
+				assert.Equal(t, expected, test.StringToJSONMap(responseString))
+			})
+		})
+
+		Convey("When submission_mode param overrides async config", func() {
+			c.Submission.Mode = "async"
+			defer func() { c.Submission.Mode = "" }()
+
+			var ledger uint64 = 1988727
+			mockTransactionSubmitter.On("ResubmitTransaction", envelope).Return(
+				horizon.SubmitTransactionResponse{Hash: "8376a64d4d0d116be143c00ed6181d8bb64ae74c9ba7b773277a6926e7a20bed", Ledger: &ledger},
+				nil,
+			).Once()
+
+			Convey("it should submit transaction synchronously", func() {
+				statusCode, _ := net.GetResponse(testServer, url.Values{"tx": {envelope}, "submission_mode": {"sync"}})
+				assert.Equal(t, 200, statusCode)
+				mockTransactionSubmitter.AssertExpectations(t)
+			})
+		})
+
+		Convey("When Horizon cannot be reached", func() {
+			mockTransactionSubmitter.On("ResubmitTransaction", envelope).Return(
+				horizon.SubmitTransactionResponse{},
+				errors.New("connection refused"),
+			).Once()
+
+			Convey("it should return error", func() {
+				statusCode, _ := net.GetResponse(testServer, url.Values{"tx": {envelope}})
+				assert.Equal(t, 500, statusCode)
+			})
+		})
+	})
+}
@@ -0,0 +1,55 @@
+package handlers
+
+import (
+	"encoding/hex"
+	"net/http"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/stellar/gateway/protocols"
+	"github.com/stellar/gateway/protocols/bridge"
+	"github.com/stellar/gateway/server"
+	"github.com/stellar/go/network"
+	"github.com/stellar/go/xdr"
+)
+
+// TransactionHash implements /transaction-hash endpoint. It returns the hash of a transaction
+// signed by its signers on the given network, the same hash /sign and the transaction submitter
+// sign, so clients can verify their own signatures. Nothing is signed or submitted.
+func (rh *RequestHandler) TransactionHash(w http.ResponseWriter, r *http.Request) {
+	request := &bridge.TransactionHashRequest{}
+	err := request.FromRequest(r)
+	if err != nil {
+		log.Error(err.Error())
+		server.Write(w, protocols.InvalidParameterError)
+		return
+	}
+
+	err = request.Validate()
+	if err != nil {
+		errorResponse := err.(*protocols.ErrorResponse)
+		log.WithFields(errorResponse.LogData).Error(errorResponse.Error())
+		server.Write(w, errorResponse)
+		return
+	}
+
+	passphrase := request.NetworkPassphrase
+	if passphrase == "" {
+		passphrase = rh.Config.NetworkPassphrase
+	}
+
+	var envelope xdr.TransactionEnvelope
+	// Validated already
+	xdr.SafeUnmarshalBase64(request.TransactionEnvelope, &envelope)
+
+	hash, err := network.HashTransaction(&envelope.Tx, passphrase)
+	if err != nil {
+		log.WithFields(log.Fields{"err": err}).Error("Error calculating transaction hash")
+		server.Write(w, protocols.InternalServerError)
+		return
+	}
+
+	server.Write(w, bridge.TransactionHashResponse{
+		Hash:              hex.EncodeToString(hash[:]),
+		NetworkPassphrase: passphrase,
+	})
+}
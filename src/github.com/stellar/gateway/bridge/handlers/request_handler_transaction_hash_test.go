@@ -0,0 +1,101 @@
+package handlers
+
+import (
+	"encoding/hex"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+	"github.com/stellar/gateway/bridge/config"
+	"github.com/stellar/gateway/net"
+	"github.com/stellar/gateway/test"
+	b "github.com/stellar/go/build"
+	"github.com/stellar/go/keypair"
+	"github.com/stellar/go/network"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRequestHandlerTransactionHash(t *testing.T) {
+	c := &config.Config{NetworkPassphrase: network.TestNetworkPassphrase}
+	requestHandler := RequestHandler{Config: c}
+
+	testServer := httptest.NewServer(http.HandlerFunc(requestHandler.TransactionHash))
+	defer testServer.Close()
+
+	source := keypair.MustParse("SDWLS4G3XCNIYPKXJWWGGJT6UDY63WV6PEFTWP7JZMQB4RE7EUJQN5XM").(*keypair.Full)
+
+	tx, err := b.Transaction(
+		b.SourceAccount{source.Address()},
+		b.Sequence{124},
+		b.Network{network.PublicNetworkPassphrase},
+		b.Payment(
+			b.Destination{"GAPCT362RATBUJ37RN2MOKQIZLHSJMO33MMCSRUXTTHIGVDYWOFG5HDS"},
+			b.NativeAmount{"1"},
+		),
+	)
+	require.NoError(t, err)
+	txe, err := tx.Sign(source.Seed())
+	require.NoError(t, err)
+	envelope, err := txe.Base64()
+	require.NoError(t, err)
+
+	Convey("Given transaction hash request", t, func() {
+		Convey("When envelope is invalid", func() {
+			params := url.Values{"tx": {"AAAA"}}
+
+			Convey("it should return error", func() {
+				statusCode, response := net.GetResponse(testServer, params)
+				responseString := strings.TrimSpace(string(response))
+				assert.Equal(t, 400, statusCode)
+				expected := test.StringToJSONMap(`{
+  "code": "invalid_parameter",
+  "error_code": 101,
+  "message": "Invalid parameter.",
+  "more_info": "Transaction envelope must be a base64 encoded XDR.",
+  "data": {
+    "name": "tx"
+  }
+}`)
+				assert.Equal(t, expected, test.StringToJSONMap(responseString))
+			})
+		})
+
+		Convey("When network passphrase is sent", func() {
+			params := url.Values{"tx": {envelope}, "network_passphrase": {network.PublicNetworkPassphrase}}
+
+			Convey("it should return hash signed on that network", func() {
+				statusCode, response := net.GetResponse(testServer, params)
+				assert.Equal(t, 200, statusCode)
+				responseMap := test.StringToJSONMap(string(response))
+				assert.Equal(t, network.PublicNetworkPassphrase, responseMap["network_passphrase"])
+
+				hash, err := hex.DecodeString(responseMap["hash"].(string))
+				require.NoError(t, err)
+				assert.NoError(t, source.Verify(hash, txe.E.Signatures[0].Signature))
+			})
+		})
+
+		Convey("When network passphrase is not sent", func() {
+			params := url.Values{"tx": {envelope}}
+
+			Convey("it should return hash on the configured network", func() {
+				statusCode, response := net.GetResponse(testServer, params)
+				assert.Equal(t, 200, statusCode)
+				responseMap := test.StringToJSONMap(string(response))
+				assert.Equal(t, network.TestNetworkPassphrase, responseMap["network_passphrase"])
+
+				expected, err := network.HashTransaction(&txe.E.Tx, network.TestNetworkPassphrase)
+				require.NoError(t, err)
+				assert.Equal(t, hex.EncodeToString(expected[:]), responseMap["hash"])
+
+				hash, err := hex.DecodeString(responseMap["hash"].(string))
+				require.NoError(t, err)
+				assert.Error(t, source.Verify(hash, txe.E.Signatures[0].Signature))
+			})
+		})
+	})
+}
@@ -0,0 +1,240 @@
+package handlers
+
+import (
+	log "github.com/sirupsen/logrus"
+	"github.com/stellar/gateway/horizon"
+	"github.com/stellar/gateway/protocols"
+	"github.com/stellar/gateway/protocols/bridge"
+	"github.com/stellar/go/amount"
+	b "github.com/stellar/go/build"
+	"github.com/stellar/go/xdr"
+)
+
+// simulatePayment predicts the result of the payment sent from source to destination account (see
+// `simulate_payments` config param). It checks balances of both accounts, their trustlines and
+// trustline authorization and returns the error the transaction is expected to fail with or nil.
+// `operations` is the number of operations of the transaction and `trustAdded` is true when the
+// transaction adds the destination trustline. Amounts sent by path payments are not known before
+// submission so only the source trustline of the send asset is checked then. Nothing is checked
+// when accounts cannot be loaded, submission will fail with the right error then.
+func (rh *RequestHandler) simulatePayment(source, destination string, request *bridge.PaymentRequest, operations int, trustAdded bool) *protocols.ErrorResponse {
+	sourceAccount, err := rh.Horizon.LoadAccount(source)
+	if err == horizon.ErrAccountNotFound {
+		return bridge.PaymentSourceNotExist
+	} else if err != nil {
+		log.WithFields(log.Fields{"source": source, "err": err}).Print("Cannot load source account, skipping simulation")
+		return nil
+	}
+
+	baseReserve, err := rh.baseReserve()
+	if err != nil {
+		log.WithFields(log.Fields{"err": err}).Print("Cannot load base reserve, skipping simulation")
+		return nil
+	}
+
+	// Native balance left after paying the transaction fee
+	fee := xdr.Int64(b.DefaultBaseFee) * xdr.Int64(operations)
+	native := availableBalance(sourceAccount.Balance("", "")) - sourceAccount.MinimumBalance(baseReserve) - fee
+	if native < 0 {
+		return bridge.TransactionInsufficientBalance
+	}
+
+	// Zero when the amount is not known
+	var sendAmount xdr.Int64
+	sendCode, sendIssuer := request.AssetCode, request.AssetIssuer
+	if request.SendMax != "" {
+		sendCode, sendIssuer = request.SendAssetCode, request.SendAssetIssuer
+	} else {
+		// Validated in request
+		sendAmount, _ = amount.Parse(request.Amount)
+	}
+
+	switch {
+	case sendCode == "":
+		if sendAmount > native {
+			return bridge.PaymentUnderfunded
+		}
+	case sendIssuer != source:
+		balance := sourceAccount.Balance(sendCode, sendIssuer)
+		if balance == nil {
+			return bridge.PaymentSrcNoTrust
+		}
+		if balance.IsAuthorized != nil && !*balance.IsAuthorized {
+			return bridge.PaymentSrcNotAuthorized
+		}
+		if sendAmount > availableBalance(balance) {
+			return bridge.PaymentUnderfunded
+		}
+	}
+
+	return rh.simulateReceive(destination, request, baseReserve, trustAdded)
+}
+
+// simulateReceive checks if the destination account can receive the payment (see simulatePayment)
+func (rh *RequestHandler) simulateReceive(destination string, request *bridge.PaymentRequest, baseReserve xdr.Int64, trustAdded bool) *protocols.ErrorResponse {
+	receiveAmount, _ := amount.Parse(request.Amount)
+
+	destinationAccount, err := rh.Horizon.LoadAccount(destination)
+	if err == horizon.ErrAccountNotFound {
+		// Only create_account operation can send a payment to a new account
+		if request.AssetCode != "" || request.SendMax != "" || request.Operation == "payment" {
+			return bridge.PaymentNoDestination
+		}
+		if receiveAmount < 2*baseReserve {
+			return bridge.PaymentLowReserve
+		}
+		return nil
+	} else if err != nil {
+		log.WithFields(log.Fields{"destination": destination, "err": err}).Print("Cannot load destination account, skipping simulation")
+		return nil
+	}
+
+	if request.AssetCode == "" {
+		if request.Operation == "create_account" {
+			return bridge.PaymentAccountAlreadyExists
+		}
+		return nil
+	}
+
+	if trustAdded || destination == request.AssetIssuer {
+		return nil
+	}
+
+	balance := destinationAccount.Balance(request.AssetCode, request.AssetIssuer)
+	if balance == nil {
+		return bridge.PaymentNoTrust
+	}
+	if balance.IsAuthorized != nil && !*balance.IsAuthorized {
+		return bridge.PaymentNotAuthorized
+	}
+
+	current, _ := amount.Parse(balance.Balance)
+	limit, err := amount.Parse(balance.Limit)
+	if err == nil && receiveAmount > limit-current {
+		return bridge.PaymentLineFull
+	}
+	return nil
+}
+
+// checkCreateAccountBalance checks if the source account can create a new account with the payment amount
+// as starting balance (see `check_create_account_balance` config param). XLM balance of the source minus
+// selling liabilities must cover the starting balance, fee of the transaction with `operations` operations
+// and the minimum balance of the source. Nothing is checked when the source or base reserve cannot be
+// loaded, submission will fail with the right error then.
+func (rh *RequestHandler) checkCreateAccountBalance(source string, request *bridge.PaymentRequest, operations int) *protocols.ErrorResponse {
+	sourceAccount, err := rh.Horizon.LoadAccount(source)
+	if err == horizon.ErrAccountNotFound {
+		return bridge.PaymentSourceNotExist
+	} else if err != nil {
+		log.WithFields(log.Fields{"source": source, "err": err}).Print("Cannot load source account, skipping balance check")
+		return nil
+	}
+
+	baseReserve, err := rh.baseReserve()
+	if err != nil {
+		log.WithFields(log.Fields{"err": err}).Print("Cannot load base reserve, skipping balance check")
+		return nil
+	}
+
+	// Validated in request
+	startingBalance, _ := amount.Parse(request.Amount)
+	fee := xdr.Int64(b.DefaultBaseFee) * xdr.Int64(operations)
+
+	available := availableBalance(sourceAccount.Balance("", ""))
+	required := startingBalance + fee + sourceAccount.MinimumBalance(baseReserve)
+	if available < required {
+		return bridge.NewPaymentSourceUnderfundedError(amount.String(available), amount.String(required))
+	}
+	return nil
+}
+
+// accountAsset identifies a balance of an asset of an account
+type accountAsset struct {
+	AccountID string
+	Asset     protocols.Asset
+}
+
+// paymentSpending returns the amount of the asset sent by the source of the payment (`send_max` of
+// path payments) and the fee of the transaction with the given number of operations
+func paymentSpending(source string, request *bridge.PaymentRequest, operations int) map[accountAsset]xdr.Int64 {
+	sent := accountAsset{source, protocols.Asset{Code: request.AssetCode, Issuer: request.AssetIssuer}}
+	value := request.Amount
+	if request.SendMax != "" {
+		sent.Asset = protocols.Asset{Code: request.SendAssetCode, Issuer: request.SendAssetIssuer}
+		value = request.SendMax
+	}
+
+	spent := make(map[accountAsset]xdr.Int64)
+	// Validated in request
+	spent[sent], _ = amount.Parse(value)
+	spent[accountAsset{AccountID: source}] += xdr.Int64(b.DefaultBaseFee) * xdr.Int64(operations)
+	return spent
+}
+
+// checkBalanceFloors returns PaymentWouldBreachFloor error when an account would be left with less
+// than its `balance_floors` minimum balance of an asset after spending the given amounts (fees are
+// included in amounts of native asset). Only balances spent are checked and accounts that cannot be
+// loaded are skipped, submission will fail with the right error then.
+func (rh *RequestHandler) checkBalanceFloors(spent map[accountAsset]xdr.Int64) *protocols.ErrorResponse {
+	accounts := make(map[string]*horizon.AccountResponse)
+
+	for _, floor := range rh.Config.BalanceFloors {
+		asset := protocols.Asset{Code: floor.AssetCode, Issuer: floor.AssetIssuer}
+		value, ok := spent[accountAsset{floor.AccountID, asset}]
+		if !ok {
+			continue
+		}
+
+		account, loaded := accounts[floor.AccountID]
+		if !loaded {
+			response, err := rh.Horizon.LoadAccount(floor.AccountID)
+			if err != nil {
+				log.WithFields(log.Fields{"account_id": floor.AccountID, "err": err}).Print("Cannot load account, skipping balance floor check")
+			} else {
+				account = &response
+			}
+			accounts[floor.AccountID] = account
+		}
+
+		if account == nil {
+			continue
+		}
+
+		// Validated in config
+		minBalance, _ := amount.Parse(floor.MinBalance)
+		balance := availableBalance(account.Balance(floor.AssetCode, floor.AssetIssuer))
+		if balance-value < minBalance {
+			return bridge.NewPaymentWouldBreachFloorError(
+				floor.AccountID,
+				floor.AssetCode,
+				floor.AssetIssuer,
+				amount.String(balance),
+				amount.String(balance-value),
+				amount.String(minBalance),
+			)
+		}
+	}
+
+	return nil
+}
+
+// availableBalance returns the balance minus the amount reserved by offers selling it.
+// Zero is returned when the balance is nil or cannot be parsed.
+func availableBalance(balance *horizon.Balance) xdr.Int64 {
+	if balance == nil {
+		return 0
+	}
+
+	value, err := amount.Parse(balance.Balance)
+	if err != nil {
+		return 0
+	}
+
+	if balance.SellingLiabilities != "" {
+		liabilities, err := amount.Parse(balance.SellingLiabilities)
+		if err == nil {
+			value -= liabilities
+		}
+	}
+	return value
+}
@@ -0,0 +1,50 @@
+package bridge
+
+import (
+	"fmt"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/stellar/gateway/bridge/config"
+	"github.com/stellar/gateway/horizon"
+)
+
+// checkNetworkPassphrase compares `network_passphrase` with the network passphrase of Horizon server
+// according to `network_passphrase_check` param. Transactions signed for another network are rejected
+// so in `strict` mode a mismatch is an error and in `adopt` mode the Horizon passphrase replaces the
+// configured one. When Horizon cannot be reached the configured passphrase is used without checking.
+func checkNetworkPassphrase(h *horizon.Horizon, c *config.Config) error {
+	if c.NetworkPassphraseCheck == "none" {
+		return nil
+	}
+
+	root, err := h.LoadRoot()
+	if err == nil && root.NetworkPassphrase == "" {
+		err = fmt.Errorf("no network_passphrase in Horizon response")
+	}
+	if err != nil {
+		if c.NetworkPassphrase == "" {
+			return fmt.Errorf("Cannot load network passphrase from Horizon: %s", err)
+		}
+		log.WithField("err", err).Warn("Cannot load network passphrase from Horizon, network_passphrase not checked")
+		return nil
+	}
+
+	if root.NetworkPassphrase == c.NetworkPassphrase {
+		return nil
+	}
+
+	fields := log.Fields{"network_passphrase": c.NetworkPassphrase, "horizon_network_passphrase": root.NetworkPassphrase}
+	switch c.NetworkPassphraseCheck {
+	case "adopt":
+		if c.NetworkPassphrase != "" {
+			log.WithFields(fields).Warn("network_passphrase does not match Horizon network, using Horizon network passphrase")
+		}
+		c.NetworkPassphrase = root.NetworkPassphrase
+		return nil
+	case "warn":
+		log.WithFields(fields).Warn("network_passphrase does not match Horizon network, transactions will be rejected")
+		return nil
+	default:
+		return fmt.Errorf("network_passphrase %q does not match Horizon network passphrase %q", c.NetworkPassphrase, root.NetworkPassphrase)
+	}
+}
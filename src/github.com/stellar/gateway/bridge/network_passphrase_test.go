@@ -0,0 +1,82 @@
+package bridge
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+	"github.com/stellar/gateway/bridge/config"
+	"github.com/stellar/gateway/horizon"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCheckNetworkPassphrase(t *testing.T) {
+	var status int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(status)
+		w.Write([]byte(`{"horizon_version": "1.0.0", "network_passphrase": "Test SDF Network ; September 2015"}`))
+	}))
+	defer server.Close()
+
+	h := horizon.New(server.URL)
+	public := "Public Global Stellar Network ; September 2015"
+	testnet := "Test SDF Network ; September 2015"
+
+	Convey("checkNetworkPassphrase", t, func() {
+		status = 200
+		c := config.Config{NetworkPassphrase: public, NetworkPassphraseCheck: "strict"}
+
+		Convey("When passphrase matches", func() {
+			c.NetworkPassphrase = testnet
+			assert.NoError(t, checkNetworkPassphrase(&h, &c))
+		})
+
+		Convey("When passphrase does not match in strict mode", func() {
+			err := checkNetworkPassphrase(&h, &c)
+			assert.EqualError(t, err, `network_passphrase "Public Global Stellar Network ; September 2015" does not match Horizon network passphrase "Test SDF Network ; September 2015"`)
+		})
+
+		Convey("When passphrase does not match in warn mode", func() {
+			c.NetworkPassphraseCheck = "warn"
+			assert.NoError(t, checkNetworkPassphrase(&h, &c))
+			assert.Equal(t, public, c.NetworkPassphrase)
+		})
+
+		Convey("When passphrase does not match in adopt mode", func() {
+			c.NetworkPassphraseCheck = "adopt"
+			assert.NoError(t, checkNetworkPassphrase(&h, &c))
+			assert.Equal(t, testnet, c.NetworkPassphrase)
+		})
+
+		Convey("When passphrase is not set in adopt mode", func() {
+			c.NetworkPassphraseCheck = "adopt"
+			c.NetworkPassphrase = ""
+			assert.NoError(t, checkNetworkPassphrase(&h, &c))
+			assert.Equal(t, testnet, c.NetworkPassphrase)
+		})
+
+		Convey("When check is disabled", func() {
+			c.NetworkPassphraseCheck = "none"
+			assert.NoError(t, checkNetworkPassphrase(&h, &c))
+		})
+
+		Convey("When Horizon is unavailable", func() {
+			status = 500
+
+			Convey("it should use the configured passphrase", func() {
+				assert.NoError(t, checkNetworkPassphrase(&h, &c))
+				assert.Equal(t, public, c.NetworkPassphrase)
+			})
+
+			Convey("it should fail in adopt mode without passphrase", func() {
+				c.NetworkPassphraseCheck = "adopt"
+				c.NetworkPassphrase = ""
+				err := checkNetworkPassphrase(&h, &c)
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), "Cannot load network passphrase from Horizon")
+			})
+		})
+	})
+}
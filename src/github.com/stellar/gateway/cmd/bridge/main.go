@@ -2,6 +2,7 @@ package main
 
 import (
 	log "github.com/sirupsen/logrus"
+	"os"
 	"runtime"
 
 	"github.com/spf13/cobra"
@@ -33,20 +34,22 @@ func init() {
 	rootCmd.Flags().BoolVarP(&migrateFlag, "migrate-db", "", false, "migrate DB to the newest schema version")
 	rootCmd.Flags().StringVarP(&configFile, "config", "c", "bridge.cfg", "path to config file")
 	rootCmd.Flags().BoolVarP(&versionFlag, "version", "v", false, "displays bridge server version")
+	config.RegisterFlags(rootCmd.Flags())
 }
 
 func run(cmd *cobra.Command, args []string) {
-	viper.SetConfigFile(configFile)
-	viper.SetConfigType("toml")
-	err := viper.ReadInConfig()
-	if err != nil {
-		log.Fatal("Error reading "+configFile+" file: ", err)
+	v := viper.New()
+	v.SetConfigType("toml")
+	// Config file can be omitted when all params are set using environment variables and flags
+	if _, err := os.Stat(configFile); err == nil || cmd.Flags().Lookup("config").Changed {
+		v.SetConfigFile(configFile)
+		err = v.ReadInConfig()
+		if err != nil {
+			log.Fatal("Error reading "+configFile+" file: ", err)
+		}
 	}
 
-	var config config.Config
-	err = viper.Unmarshal(&config)
-
-	err = config.Validate()
+	config, err := config.Load(v, cmd.Flags(), os.Getenv)
 	if err != nil {
 		log.Fatal(err.Error())
 		return
@@ -82,6 +82,7 @@ func TestRequestHandlerAuth(t *testing.T) {
 			assert.Equal(t, 400, statusCode)
 			expected := test.StringToJSONMap(`{
 					  "code": "invalid_parameter",
+					  "error_code": 101,
 					  "message": "Invalid parameter."
 					}`)
 			assert.Equal(t, expected, test.StringToJSONMap(responseString, "more_info"))
@@ -98,6 +99,7 @@ func TestRequestHandlerAuth(t *testing.T) {
 			assert.Equal(t, 400, statusCode)
 			expected := test.StringToJSONMap(`{
 					  "code": "invalid_parameter",
+					  "error_code": 101,
 					  "message": "Invalid parameter."
 					}`)
 			assert.Equal(t, expected, test.StringToJSONMap(responseString, "more_info"))
@@ -146,6 +148,7 @@ func TestRequestHandlerAuth(t *testing.T) {
 			assert.Equal(t, 400, statusCode)
 			expected := test.StringToJSONMap(`{
 		  "code": "invalid_parameter",
+		  "error_code": 101,
 		  "message": "Invalid parameter.",
 		  "data": {
 		    "name": "data.sender"
@@ -210,6 +213,7 @@ func TestRequestHandlerAuth(t *testing.T) {
 			assert.Equal(t, 400, statusCode)
 			expected := test.StringToJSONMap(`{
   "code": "invalid_parameter",
+  "error_code": 101,
   "message": "Invalid parameter.",
   "data": {
     "name": "sig"
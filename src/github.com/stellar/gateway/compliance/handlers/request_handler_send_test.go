@@ -81,6 +81,7 @@ func TestRequestHandlerSend(t *testing.T) {
 			assert.Equal(t, 400, statusCode)
 			expected := test.StringToJSONMap(`{
 			  "code": "missing_parameter",
+			  "error_code": 102,
 			  "message": "Required parameter is missing.",
 			  "data": {
 			    "name": "id"
@@ -95,6 +96,7 @@ func TestRequestHandlerSend(t *testing.T) {
 			assert.Equal(t, 400, statusCode)
 			expected := test.StringToJSONMap(`{
 			  "code": "missing_parameter",
+			  "error_code": 102,
 			  "message": "Required parameter is missing.",
 			  "data": {
 			    "name": "source"
@@ -120,6 +122,7 @@ func TestRequestHandlerSend(t *testing.T) {
 			assert.Equal(t, 400, statusCode)
 			expected := test.StringToJSONMap(`{
 			  "code": "invalid_parameter",
+			  "error_code": 101,
 			  "message": "Invalid parameter.",
 			  "data": {
 			    "name": "source"
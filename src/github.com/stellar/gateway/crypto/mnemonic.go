@@ -0,0 +1,140 @@
+package crypto
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/binary"
+	"errors"
+	"strings"
+
+	"github.com/stellar/go/exp/crypto/derivation"
+	"github.com/stellar/go/keypair"
+)
+
+// mnemonicIterations is the number of PBKDF2 iterations used to compute a seed of a mnemonic (BIP-39)
+const mnemonicIterations = 2048
+
+var (
+	// ErrInvalidMnemonic is returned when a mnemonic has invalid number of words, unknown words
+	// or invalid checksum
+	ErrInvalidMnemonic = errors.New("Invalid mnemonic")
+	// ErrInvalidDerivationPath is returned when a derivation path is not in `m/44'/148'/0'` format
+	ErrInvalidDerivationPath = errors.New("Invalid derivation path")
+
+	mnemonicWordIndexes = buildMnemonicWordIndexes()
+)
+
+func buildMnemonicWordIndexes() map[string]int {
+	indexes := make(map[string]int)
+	for i, word := range strings.Fields(mnemonicWordlist) {
+		indexes[word] = i
+	}
+	return indexes
+}
+
+// IsDerivationPath returns true when value looks like a derivation path (ex. `m/44'/148'/0'`)
+// rather than a secret seed. It doesn't check if the path is valid.
+func IsDerivationPath(value string) bool {
+	return strings.HasPrefix(value, "m/")
+}
+
+// ValidateMnemonic checks if mnemonic is a valid BIP-39 mnemonic in English: it must have 12, 15,
+// 18, 21 or 24 words from the wordlist and a valid checksum.
+func ValidateMnemonic(mnemonic string) error {
+	words := strings.Fields(strings.ToLower(mnemonic))
+	if len(words) < 12 || len(words) > 24 || len(words)%3 != 0 {
+		return ErrInvalidMnemonic
+	}
+
+	// Each word encodes 11 bits: entropy followed by a checksum of 1 bit per 32 bits of entropy
+	bits := make([]byte, 0, len(words)*11)
+	for _, word := range words {
+		index, ok := mnemonicWordIndexes[word]
+		if !ok {
+			return ErrInvalidMnemonic
+		}
+		for i := 10; i >= 0; i-- {
+			bits = append(bits, byte(index>>uint(i))&1)
+		}
+	}
+
+	checksumLength := len(bits) / 33
+	entropy := make([]byte, (len(bits)-checksumLength)/8)
+	for i := range entropy {
+		for _, bit := range bits[i*8 : i*8+8] {
+			entropy[i] = entropy[i]<<1 | bit
+		}
+	}
+
+	hash := sha256.Sum256(entropy)
+	for i := 0; i < checksumLength; i++ {
+		if bits[len(entropy)*8+i] != (hash[i/8]>>uint(7-i%8))&1 {
+			return ErrInvalidMnemonic
+		}
+	}
+
+	return nil
+}
+
+// MnemonicSeed returns the 64 bytes seed of a BIP-39 mnemonic protected with an optional passphrase.
+// Passphrase is used as given, it's not NFKD normalized so passphrases with non-ASCII characters
+// must be given in normalized form.
+func MnemonicSeed(mnemonic, passphrase string) ([]byte, error) {
+	err := ValidateMnemonic(mnemonic)
+	if err != nil {
+		return nil, err
+	}
+
+	words := strings.Join(strings.Fields(strings.ToLower(mnemonic)), " ")
+	return pbkdf2SHA512([]byte(words), []byte("mnemonic"+passphrase), mnemonicIterations, 64), nil
+}
+
+// MnemonicKeypair derives a keypair for a path (ex. `m/44'/148'/0'`) from a BIP-39 mnemonic as
+// described in SEP-5
+func MnemonicKeypair(mnemonic, passphrase, path string) (*keypair.Full, error) {
+	seed, err := MnemonicSeed(mnemonic, passphrase)
+	if err != nil {
+		return nil, err
+	}
+
+	key, err := derivation.DeriveForPath(path, seed)
+	if err == derivation.ErrInvalidPath {
+		return nil, ErrInvalidDerivationPath
+	} else if err != nil {
+		return nil, err
+	}
+
+	return keypair.FromRawSeed(key.RawSeed())
+}
+
+// pbkdf2SHA512 implements PBKDF2 (RFC 2898) with HMAC-SHA512 as the pseudorandom function
+func pbkdf2SHA512(password, salt []byte, iterations, keyLength int) []byte {
+	prf := hmac.New(sha512.New, password)
+	key := make([]byte, 0, keyLength)
+	u := make([]byte, prf.Size())
+	block := make([]byte, prf.Size())
+	counter := make([]byte, 4)
+
+	for i := uint32(1); len(key) < keyLength; i++ {
+		binary.BigEndian.PutUint32(counter, i)
+		prf.Reset()
+		prf.Write(salt)
+		prf.Write(counter)
+		u = prf.Sum(u[:0])
+		copy(block, u)
+
+		for n := 1; n < iterations; n++ {
+			prf.Reset()
+			prf.Write(u)
+			u = prf.Sum(u[:0])
+			for j := range block {
+				block[j] ^= u[j]
+			}
+		}
+
+		key = append(key, block...)
+	}
+
+	return key[:keyLength]
+}
@@ -0,0 +1,91 @@
+package crypto
+
+import (
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMnemonicKeypair(t *testing.T) {
+	Convey("MnemonicKeypair", t, func() {
+		Convey("derives keypairs of SEP-5 test vectors", func() {
+			tests := []struct {
+				mnemonic   string
+				passphrase string
+				path       string
+				address    string
+				seed       string
+			}{
+				{
+					"illness spike retreat truth genius clock brain pass fit cave bargain toe",
+					"",
+					"m/44'/148'/0'",
+					"GDRXE2BQUC3AZNPVFSCEZ76NJ3WWL25FYFK6RGZGIEKWE4SOOHSUJUJ6",
+					"SBGWSG6BTNCKCOB3DIFBGCVMUPQFYPA2G4O34RMTB343OYPXU5DJDVMN",
+				},
+				{
+					"illness spike retreat truth genius clock brain pass fit cave bargain toe",
+					"",
+					"m/44'/148'/1'",
+					"GBAW5XGWORWVFE2XTJYDTLDHXTY2Q2MO73HYCGB3XMFMQ562Q2W2GJQX",
+					"SCEPFFWGAG5P2VX5DHIYK3XEMZYLTYWIPWYEKXFHSK25RVMIUNJ7CTIS",
+				},
+				{
+					"resource asthma orphan phone ice canvas fire useful arch jewel impose vague theory cushion top",
+					"",
+					"m/44'/148'/0'",
+					"GAVXVW5MCK7Q66RIBWZZKZEDQTRXWCZUP4DIIFXCCENGW2P6W4OA34RH",
+					"SAKS7I2PNDBE5SJSUSU2XLJ7K5XJ3V3K4UDFAHMSBQYPOKE247VHAGDB",
+				},
+				{
+					"cable spray genius state float twenty onion head street palace net private method loan turn phrase state blanket interest dry amazing dress blast tube",
+					"p4ssphr4se",
+					"m/44'/148'/0'",
+					"GDAHPZ2NSYIIHZXM56Y36SBVTV5QKFIZGYMMBHOU53ETUSWTP62B63EQ",
+					"SAFWTGXVS7ELMNCXELFWCFZOPMHUZ5LXNBGUVRCY3FHLFPXK4QPXYP2X",
+				},
+				{
+					"abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon about",
+					"",
+					"m/44'/148'/0'",
+					"GB3JDWCQJCWMJ3IILWIGDTQJJC5567PGVEVXSCVPEQOTDN64VJBDQBYX",
+					"SBUV3MRWKNS6AYKZ6E6MOUVF2OYMON3MIUASWL3JLY5E3ISDJFELYBRZ",
+				},
+			}
+
+			for _, test := range tests {
+				kp, err := MnemonicKeypair(test.mnemonic, test.passphrase, test.path)
+				require.NoError(t, err)
+				assert.Equal(t, test.address, kp.Address())
+				assert.Equal(t, test.seed, kp.Seed())
+			}
+		})
+
+		Convey("ignores case and extra whitespace of mnemonic", func() {
+			kp, err := MnemonicKeypair("  Illness spike retreat truth genius clock\nbrain pass fit cave bargain TOE ", "", "m/44'/148'/0'")
+			require.NoError(t, err)
+			assert.Equal(t, "GDRXE2BQUC3AZNPVFSCEZ76NJ3WWL25FYFK6RGZGIEKWE4SOOHSUJUJ6", kp.Address())
+		})
+
+		Convey("returns error when mnemonic is invalid", func() {
+			for _, mnemonic := range []string{
+				"",
+				"illness spike retreat truth genius clock brain pass fit cave bargain",
+				"illness spike retreat truth genius clock brain pass fit cave bargain foo",
+				"illness spike retreat truth genius clock brain pass fit cave bargain bargain",
+			} {
+				_, err := MnemonicKeypair(mnemonic, "", "m/44'/148'/0'")
+				assert.Equal(t, ErrInvalidMnemonic, err, mnemonic)
+			}
+		})
+
+		Convey("returns error when path is invalid", func() {
+			for _, path := range []string{"m", "m/44/148/0", "m/44'/148'/a'", "44'/148'/0'"} {
+				_, err := MnemonicKeypair("illness spike retreat truth genius clock brain pass fit cave bargain toe", "", path)
+				assert.Equal(t, ErrInvalidDerivationPath, err, path)
+			}
+		})
+	})
+}
@@ -3,6 +3,10 @@
 // migrations_gateway/01_init.sql
 // migrations_gateway/02_payment_id.sql
 // migrations_gateway/03_transaction_id.sql
+// migrations_gateway/04_queued_payment.sql
+// migrations_gateway/05_request_hash.sql
+// migrations_gateway/06_batch_item.sql
+// migrations_gateway/07_batch_item_payment_id.sql
 // migrations_compliance/01_init.sql
 // migrations_compliance/02_auth_data.sql
 // DO NOT EDIT!
@@ -132,6 +136,86 @@ func migrations_gateway03_transaction_idSql() (*asset, error) {
 	return a, nil
 }
 
+var _migrations_gateway04_queued_paymentSql = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x00\xff\x84\x91\x4f\x4f\xc2\x40\x10\xc5\xef\xfb\x29\xe6\xd8\x46\x49\xc0\x04\x63\x42\x38\x14\xba\x6a\x63\x59\xa0\xee\x1e\x38\x75\x37\x30\x6a\x13\xdb\xc2\x76\xd6\x3f\xdf\xde\x74\x11\xa1\x84\xc4\x5b\x3b\xfb\x9b\xf7\x5e\xde\xf4\x7a\x70\x55\x16\xaf\xd6\x10\x82\xda\xb2\x69\xc6\x23\xc9\x41\x46\x93\x94\x83\x5e\x3a\x74\xb8\x59\x98\xef\x12\x2b\xd2\x10\x30\x00\x5d\x6c\x34\x14\x15\x05\x83\x41\x08\x62\x2e\x41\xa8\x34\x85\x48\xc9\x79\x9e\x88\x69\xc6\x67\x5c\xc8\xeb\x96\xdb\xee\xb7\xf2\x96\xff\x30\x76\xfd\x66\x6c\x70\x33\x1c\x86\x10\xf3\xfb\x48\xa5\xfb\x45\x4f\x36\x64\xc8\x35\x47\x6a\xd0\x3f\x2a\xef\x81\xda\xd9\x35\x1e\x81\xe1\xed\x19\x60\x71\xe7\xb0\x21\x0d\x84\x5f\xd4\x7d\x32\x44\x58\x6e\xa9\xb9\x10\xfa\x10\xa4\xef\x35\xde\x4d\x43\x39\x5a\x5b\xdb\xff\xf2\xee\x7c\x2b\xb9\x21\x0d\x1b\x43\x48\x45\x89\x5d\x4f\x2f\xf5\x6b\xdc\xc5\xce\xb5\x16\x59\x32\x8b\xb2\x15\x3c\xf1\x15\x04\x6d\xb5\x61\x3b\x55\x22\x59\x2a\xee\x87\x9d\x1a\x83\xd3\x3f\x4f\x7a\xe4\xd0\x5f\x70\xf8\x0a\x59\x08\x5c\x3c\x24\x82\x8f\x93\xaa\xaa\xe3\xc9\x9f\xef\xf4\x31\xca\x9e\xb9\x1c\x3b\x7a\xb9\x1b\x31\x76\x7a\xfc\xb8\xfe\xac\x58\x9c\xcd\x17\x97\x8f\x3f\x62\x3f\x03\x00\x69\x40\x40\x9c\x2a\x02\x00\x00")
+
+func migrations_gateway04_queued_paymentSqlBytes() ([]byte, error) {
+	return bindataRead(
+		_migrations_gateway04_queued_paymentSql,
+		"migrations_gateway/04_queued_payment.sql",
+	)
+}
+
+func migrations_gateway04_queued_paymentSql() (*asset, error) {
+	bytes, err := migrations_gateway04_queued_paymentSqlBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	info := bindataFileInfo{name: "migrations_gateway/04_queued_payment.sql", size: 554, mode: os.FileMode(420), modTime: time.Unix(1530000000, 0)}
+	a := &asset{bytes: bytes, info: info}
+	return a, nil
+}
+
+var _migrations_gateway05_request_hashSql = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x00\xff\xd3\xd5\x55\xd0\xce\xcd\x4c\x2f\x4a\x2c\x49\x55\x08\x2d\xe0\x72\xf4\x09\x71\x0d\x52\x08\x71\x74\xf2\x71\x55\x48\x08\x4e\xcd\x2b\x09\x29\x4a\xcc\x2b\x4e\x4c\x2e\xc9\xcc\xcf\x4b\x50\x70\x74\x71\x51\x48\x28\x4a\x2d\x2c\x4d\x2d\x2e\x89\xcf\x48\x2c\xce\x48\x50\x08\x73\x0c\x72\xf6\x70\x0c\xd2\x30\x33\xd1\x54\x70\x71\x75\x73\x0c\xf5\x09\x51\xf0\x0b\xf5\xf1\xb1\xe6\xe2\xd2\x45\x32\xda\x25\xbf\x3c\x8f\x80\xe1\x2e\x41\xfe\x01\x68\xa6\x5b\x73\x01\x00\x7b\xb3\xb0\x35\x9f\x00\x00\x00")
+
+func migrations_gateway05_request_hashSqlBytes() ([]byte, error) {
+	return bindataRead(
+		_migrations_gateway05_request_hashSql,
+		"migrations_gateway/05_request_hash.sql",
+	)
+}
+
+func migrations_gateway05_request_hashSql() (*asset, error) {
+	bytes, err := migrations_gateway05_request_hashSqlBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	info := bindataFileInfo{name: "migrations_gateway/05_request_hash.sql", size: 159, mode: os.FileMode(420), modTime: time.Unix(1530000000, 0)}
+	a := &asset{bytes: bytes, info: info}
+	return a, nil
+}
+
+var _migrations_gateway06_batch_itemSql = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x02\xff\x65\x91\x41\x4f\x84\x30\x10\x85\xef\xfd\x15\x73\x84\x28\x89\x18\xd7\x98\x6c\xf6\x50\x96\xaa\x8d\x6c\xd9\x45\x7a\xd8\x13\x6d\xa0\x0a\x31\x94\xb5\x14\x8d\xff\x5e\x20\x51\x60\xf7\x36\x99\x7c\x6f\xde\xe4\x3d\xcf\x83\xab\xba\x7a\x37\xd2\x2a\xe0\x27\xb4\x4d\x08\x4e\x09\xa4\x38\x88\x08\x88\x40\xda\xbc\xa4\x56\xd5\x02\x1c\x04\x20\xaa\x42\x40\xa5\xad\xe3\xfb\x2e\xb0\x38\x05\xc6\xa3\x08\x30\x4f\xe3\x8c\xb2\x5e\xb9\x23\x2c\xbd\x1e\xb9\x5e\x92\x7d\xa8\x1f\x01\x5f\xd2\xe4\xa5\x34\xce\xed\x6a\x35\x49\x46\xa6\xb5\xd2\x76\xed\x44\xf8\x37\x67\x80\x35\x52\xb7\x32\xb7\x55\xa3\xb3\xc1\xf8\x0f\xbc\xbf\x73\x21\x24\x8f\x98\x47\x33\x58\x19\xd3\x98\x33\xbb\x0b\xc8\xa8\xcf\x4e\xb5\x36\x2b\x65\x5b\x2e\xef\x2d\x8c\xbb\x53\xd1\xa7\x51\x64\xd2\x0a\x18\x26\x5b\xd5\x6a\x41\xec\x13\xba\xc3\xc9\x11\x5e\xc8\x11\x9c\x21\x14\x77\xd8\x72\x46\x0f\x9c\x8c\xcb\x59\x00\xce\x34\xbb\xc8\x05\xc2\x9e\x28\x23\x1b\xaa\x75\x13\x06\xff\x1f\x6e\x9f\x71\xf2\x4a\xd2\x4d\x67\xdf\x1e\xd6\x08\x79\xb3\x4e\xc2\xe6\x5b\xa3\x30\x89\xf7\x97\x9d\xac\xd1\x2f\x16\x79\x30\xa6\xbd\x01\x00\x00")
+
+func migrations_gateway06_batch_itemSqlBytes() ([]byte, error) {
+	return bindataRead(
+		_migrations_gateway06_batch_itemSql,
+		"migrations_gateway/06_batch_item.sql",
+	)
+}
+
+func migrations_gateway06_batch_itemSql() (*asset, error) {
+	bytes, err := migrations_gateway06_batch_itemSqlBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	info := bindataFileInfo{name: "migrations_gateway/06_batch_item.sql", size: 445, mode: os.FileMode(420), modTime: time.Unix(1530000000, 0)}
+	a := &asset{bytes: bytes, info: info}
+	return a, nil
+}
+
+var _migrations_gateway07_batch_item_payment_idSql = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x02\xff\xd3\xd5\x55\xd0\xce\xcd\x4c\x2f\x4a\x2c\x49\x55\x08\x2d\xe0\x72\xf4\x09\x71\x0d\x52\x08\x71\x74\xf2\x71\x55\x48\x70\x4a\x2c\x49\xce\xf0\x2c\x49\xcd\x4d\x50\x70\x74\x71\x51\x48\x28\x48\xac\xcc\x4d\xcd\x2b\x89\xcf\x4c\x49\x50\x08\x73\x0c\x72\xf6\x70\x0c\xd2\x30\x32\x35\xd5\x54\x70\x71\x75\x73\x0c\xf5\x09\x51\xf0\x0b\xf5\xf1\xb1\xe6\xe2\xd2\x45\x32\xd3\x25\xbf\x3c\x0f\xa7\xa9\x2e\x41\xfe\x01\x28\xc6\x5a\x73\x01\x00\x65\x83\x13\x83\x90\x00\x00\x00")
+
+func migrations_gateway07_batch_item_payment_idSqlBytes() ([]byte, error) {
+	return bindataRead(
+		_migrations_gateway07_batch_item_payment_idSql,
+		"migrations_gateway/07_batch_item_payment_id.sql",
+	)
+}
+
+func migrations_gateway07_batch_item_payment_idSql() (*asset, error) {
+	bytes, err := migrations_gateway07_batch_item_payment_idSqlBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	info := bindataFileInfo{name: "migrations_gateway/07_batch_item_payment_id.sql", size: 144, mode: os.FileMode(420), modTime: time.Unix(1530000000, 0)}
+	a := &asset{bytes: bytes, info: info}
+	return a, nil
+}
+
 var _migrations_compliance01_initSql = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x00\xff\xac\x94\x4d\x73\xaa\x30\x14\x86\xf7\xfc\x8a\xb3\xc4\xb9\xba\xf0\xce\xd5\xb9\x33\x8e\x0b\x94\xd8\x32\x45\xb4\x34\x2c\x5c\x85\x54\x42\xcd\x54\x12\x27\x86\x6a\xfb\xeb\x3b\xd0\x96\x2f\xbf\xea\xb4\x3b\x38\x3c\x27\xbc\xe7\x49\x26\x9d\x0e\xfc\x49\xf8\x93\xa2\x9a\x41\xb0\x31\xc6\x3e\xb2\x30\x02\x6c\x8d\x5c\x04\xa1\x95\xea\x95\x54\xfc\x8d\x45\x58\x51\xb1\xa5\x4b\xcd\xa5\x08\xc1\x34\x00\x42\x1e\x85\xc0\x85\x36\xbb\xdd\x16\x78\x33\x0c\x5e\xe0\xba\x60\x05\x78\x46\x1c\x6f\xec\xa3\x29\xf2\x70\x3b\xe3\x74\xd9\x49\xb2\x9e\xe5\x8a\x2a\xb3\xff\xaf\x6c\xca\xa9\x84\x25\x32\x84\x17\xaa\x8e\x7f\xae\x2e\xb2\x8f\x54\x08\x9a\xed\x75\x1d\xa1\x45\x56\x42\x75\x08\x11\xd5\x4c\xf3\x84\xd5\xa1\x88\x6a\x7a\xa4\x79\xee\x3b\x53\xcb\x5f\xc0\x1d\x5a\x80\x99\x4d\xd6\x32\x5a\x80\xbc\x1b\xc7\x43\x43\x47\x08\x69\x8f\xc0\x46\x13\x2b\x70\x31\x8c\x6f\x2d\xff\x01\xe1\x61\xaa\xe3\xff\x03\xa3\xe9\x6b\xbd\x96\x3b\x16\x4d\x9c\x2b\x1d\x09\x9a\xb0\x72\xfa\xbf\xbd\x5e\x63\xfc\x48\x26\x94\x8b\x73\xc4\x26\x7d\x5c\xf3\x25\x79\x66\xaf\x9f\x86\x7b\xfd\x06\x41\x3f\xb2\x9d\x96\x73\x28\x21\xab\x06\x9e\x73\x1f\xa0\xbc\x58\xc4\x30\xbf\x9e\x0e\x88\x6a\x0c\xb3\xfa\xf6\x33\xa1\xc1\x96\xa9\x2b\x95\xc6\x9c\x5c\xb2\x1a\x73\x72\x59\x6c\xcc\xc9\x65\xb7\xe9\x96\xa9\xfc\x70\x9f\x5e\xe7\x17\xf4\xd7\xa2\x90\xe2\x9f\x66\x23\x63\xbb\xcc\xf3\x6d\xeb\xd5\x5b\xc0\x96\x3b\x61\xd8\xfe\x6c\x7e\xfe\x16\x18\xd4\x99\xe2\xe4\x1f\xad\xe7\x1b\x38\x30\xde\x03\x00\x00\xff\xff\xb0\xd9\x8a\xda\x6d\x04\x00\x00")
 
 func migrations_compliance01_initSqlBytes() ([]byte, error) {
@@ -227,6 +311,10 @@ var _bindata = map[string]func() (*asset, error){
 	"migrations_gateway/01_init.sql": migrations_gateway01_initSql,
 	"migrations_gateway/02_payment_id.sql": migrations_gateway02_payment_idSql,
 	"migrations_gateway/03_transaction_id.sql": migrations_gateway03_transaction_idSql,
+	"migrations_gateway/04_queued_payment.sql": migrations_gateway04_queued_paymentSql,
+	"migrations_gateway/05_request_hash.sql": migrations_gateway05_request_hashSql,
+	"migrations_gateway/06_batch_item.sql": migrations_gateway06_batch_itemSql,
+	"migrations_gateway/07_batch_item_payment_id.sql": migrations_gateway07_batch_item_payment_idSql,
 	"migrations_compliance/01_init.sql": migrations_compliance01_initSql,
 	"migrations_compliance/02_auth_data.sql": migrations_compliance02_auth_dataSql,
 }
@@ -279,6 +367,10 @@ var _bintree = &bintree{nil, map[string]*bintree{
 		"01_init.sql": &bintree{migrations_gateway01_initSql, map[string]*bintree{}},
 		"02_payment_id.sql": &bintree{migrations_gateway02_payment_idSql, map[string]*bintree{}},
 		"03_transaction_id.sql": &bintree{migrations_gateway03_transaction_idSql, map[string]*bintree{}},
+		"04_queued_payment.sql": &bintree{migrations_gateway04_queued_paymentSql, map[string]*bintree{}},
+		"05_request_hash.sql": &bintree{migrations_gateway05_request_hashSql, map[string]*bintree{}},
+		"06_batch_item.sql": &bintree{migrations_gateway06_batch_itemSql, map[string]*bintree{}},
+		"07_batch_item_payment_id.sql": &bintree{migrations_gateway07_batch_item_payment_idSql, map[string]*bintree{}},
 	}},
 }}
 
@@ -76,6 +76,10 @@ func (d *Driver) Insert(object entities.Entity) (id int64, err error) {
 		result, err = d.database.NamedExec(query, object)
 	case *entities.ReceivedPayment:
 		result, err = d.database.NamedExec(query, object)
+	case *entities.QueuedPayment:
+		result, err = d.database.NamedExec(query, object)
+	case *entities.BatchItem:
+		result, err = d.database.NamedExec(query, object)
 	}
 
 	if err != nil {
@@ -136,6 +140,10 @@ func (d *Driver) Update(object entities.Entity) (err error) {
 		_, err = d.database.NamedExec(query, object)
 	case *entities.ReceivedPayment:
 		_, err = d.database.NamedExec(query, object)
+	case *entities.QueuedPayment:
+		_, err = d.database.NamedExec(query, object)
+	case *entities.BatchItem:
+		_, err = d.database.NamedExec(query, object)
 	}
 
 	return
@@ -215,6 +223,13 @@ func (d *Driver) GetMany(slice interface{}, where, order, offset, limit *string,
 			tmp[i].SetExists()
 		}
 		slice = &tmp
+	case *[]*entities.QueuedPayment:
+		err = d.database.Select(slice, query.String(), params...)
+		tmp := *slice
+		for i := range tmp {
+			tmp[i].SetExists()
+		}
+		slice = &tmp
 	}
 
 	if err != nil && err.Error() == "sql: no rows in result set" {
@@ -244,10 +259,18 @@ func getTypeData(object interface{}) (typeValue reflect.Type, tableName string,
 	case *entities.ReceivedPayment:
 		typeValue = reflect.TypeOf(*object)
 		tableName = "ReceivedPayment"
+	case *entities.QueuedPayment:
+		typeValue = reflect.TypeOf(*object)
+		tableName = "QueuedPayment"
+	case *entities.BatchItem:
+		typeValue = reflect.TypeOf(*object)
+		tableName = "BatchItem"
 	case *[]*entities.SentTransaction:
 		tableName = "SentTransaction"
 	case *[]*entities.ReceivedPayment:
 		tableName = "ReceivedPayment"
+	case *[]*entities.QueuedPayment:
+		tableName = "QueuedPayment"
 	default:
 		return typeValue, tableName, fmt.Errorf("Unknown entity type: %T", object)
 	}
@@ -0,0 +1,18 @@
+-- +migrate Up
+CREATE TABLE `QueuedPayment` (
+  `id` int(11) NOT NULL AUTO_INCREMENT,
+  `payment_id` varchar(255) DEFAULT NULL,
+  `status` varchar(10) NOT NULL,
+  `source` varchar(56) NOT NULL,
+  `request` text NOT NULL,
+  `attempts` int(11) NOT NULL DEFAULT 0,
+  `last_error` varchar(255) DEFAULT NULL,
+  `queued_at` datetime NOT NULL,
+  `last_attempt_at` datetime DEFAULT NULL,
+  PRIMARY KEY (`id`),
+  UNIQUE KEY `payment_id` (`payment_id`),
+  KEY `status` (`status`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8;
+
+-- +migrate Down
+DROP TABLE `QueuedPayment`;
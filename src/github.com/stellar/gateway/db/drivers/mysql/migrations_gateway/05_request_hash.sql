@@ -0,0 +1,5 @@
+-- +migrate Up
+ALTER TABLE `SentTransaction` ADD `request_hash` VARCHAR(64) DEFAULT NULL;
+
+-- +migrate Down
+ALTER TABLE `SentTransaction` DROP `request_hash`;
@@ -0,0 +1,15 @@
+-- +migrate Up
+CREATE TABLE `BatchItem` (
+  `id` int(11) NOT NULL AUTO_INCREMENT,
+  `item_key` varchar(255) NOT NULL,
+  `status` varchar(10) NOT NULL,
+  `transaction_id` varchar(64) DEFAULT NULL,
+  `error` varchar(255) DEFAULT NULL,
+  `request_hash` varchar(64) NOT NULL,
+  `updated_at` datetime NOT NULL,
+  PRIMARY KEY (`id`),
+  UNIQUE KEY `item_key` (`item_key`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8;
+
+-- +migrate Down
+DROP TABLE `BatchItem`;
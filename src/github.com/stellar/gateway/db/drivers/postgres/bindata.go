@@ -3,6 +3,10 @@
 // migrations_gateway/01_init.sql
 // migrations_gateway/02_payment_id.sql
 // migrations_gateway/03_transaction_id.sql
+// migrations_gateway/04_queued_payment.sql
+// migrations_gateway/05_request_hash.sql
+// migrations_gateway/06_batch_item.sql
+// migrations_gateway/07_batch_item_payment_id.sql
 // migrations_compliance/01_init.sql
 // migrations_compliance/02_auth_data.sql
 // DO NOT EDIT!
@@ -132,6 +136,86 @@ func migrations_gateway03_transaction_idSql() (*asset, error) {
 	return a, nil
 }
 
+var _migrations_gateway04_queued_paymentSql = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x00\xff\x6c\x91\x4f\x6b\x83\x40\x10\xc5\xef\xfb\x29\xde\x31\xd2\x06\xd2\x82\xbd\xe4\x64\xeb\x16\xa4\x56\x8d\x28\x34\x27\x59\xe2\x90\x2e\xc4\x3f\xd9\x1d\xfb\xe7\xdb\x17\x4d\x94\x28\x3d\xff\xde\xcc\x7b\xf3\x66\xbd\xc6\x5d\xa5\x8f\x46\x31\x21\x6f\xc5\x4b\x2a\xbd\x4c\x22\xf3\x9e\x43\x89\x5d\x47\x1d\x95\x89\xfa\xad\xa8\x66\xac\x04\xa0\x4b\x58\x32\x5a\x9d\xee\x05\xd0\x5e\x40\xa1\x4b\x7c\x29\x73\xf8\x54\x66\xf5\xe8\xba\x0e\xf2\x28\xd8\xe5\x12\xbe\x7c\xf5\xf2\x30\x43\x94\x87\x61\x2f\xb7\xac\xb8\xb3\x93\xf4\x61\xe3\x20\x8a\x6f\x70\xd3\x99\x03\x4d\xd8\x7d\x9a\x63\x43\xe7\x8e\x2c\x83\xe9\x87\x67\x40\x31\x53\xd5\xb2\x85\xae\x99\x8e\x64\x26\x38\x05\xd8\xf4\xf3\x27\x65\xb9\x20\x63\x1a\x33\x59\x0c\x61\x97\x29\xcf\xc3\xcd\x85\x62\xb0\xae\xc8\xb2\xaa\xda\x99\xdd\xb0\xe7\xea\x39\x57\x2d\x37\x25\x69\xf0\xee\xa5\x7b\xbc\xc9\x3d\x56\xba\x74\x84\xb3\x15\x63\xbf\x41\xe4\xcb\x8f\xd1\x6b\xec\xf1\x5a\x50\x1c\x2d\x8b\xbf\x80\x7e\xfc\xf6\x5b\x7e\xf3\x5d\x0b\x3f\x8d\x93\xff\xbe\xb5\x15\x7f\x03\x00\x94\xcd\x73\x68\xd9\x01\x00\x00")
+
+func migrations_gateway04_queued_paymentSqlBytes() ([]byte, error) {
+	return bindataRead(
+		_migrations_gateway04_queued_paymentSql,
+		"migrations_gateway/04_queued_payment.sql",
+	)
+}
+
+func migrations_gateway04_queued_paymentSql() (*asset, error) {
+	bytes, err := migrations_gateway04_queued_paymentSqlBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	info := bindataFileInfo{name: "migrations_gateway/04_queued_payment.sql", size: 473, mode: os.FileMode(420), modTime: time.Unix(1530000000, 0)}
+	a := &asset{bytes: bytes, info: info}
+	return a, nil
+}
+
+var _migrations_gateway05_request_hashSql = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x00\xff\xd3\xd5\x55\xd0\xce\xcd\x4c\x2f\x4a\x2c\x49\x55\x08\x2d\xe0\x72\xf4\x09\x71\x0d\x52\x08\x71\x74\xf2\x71\x55\x08\x4e\xcd\x2b\x09\x29\x4a\xcc\x2b\x4e\x4c\x2e\xc9\xcc\xcf\x53\x70\x74\x71\x51\x28\x4a\x2d\x2c\x4d\x2d\x2e\x89\xcf\x48\x2c\xce\x50\x08\x73\x0c\x72\xf6\x70\x0c\xd2\x30\x33\xd1\x54\x70\x71\x75\x73\x0c\xf5\x09\x51\xf0\x0b\xf5\xf1\xb1\xe6\xe2\xd2\x45\x32\xd6\x25\xbf\x3c\x0f\xaf\xc1\x2e\x41\xfe\x01\x28\x26\x5b\x73\x01\x00\x85\x48\x16\x85\x97\x00\x00\x00")
+
+func migrations_gateway05_request_hashSqlBytes() ([]byte, error) {
+	return bindataRead(
+		_migrations_gateway05_request_hashSql,
+		"migrations_gateway/05_request_hash.sql",
+	)
+}
+
+func migrations_gateway05_request_hashSql() (*asset, error) {
+	bytes, err := migrations_gateway05_request_hashSqlBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	info := bindataFileInfo{name: "migrations_gateway/05_request_hash.sql", size: 151, mode: os.FileMode(420), modTime: time.Unix(1530000000, 0)}
+	a := &asset{bytes: bytes, info: info}
+	return a, nil
+}
+
+var _migrations_gateway06_batch_itemSql = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x02\xff\x5d\xd0\x4d\x8f\x82\x40\x0c\x06\xe0\xfb\xfc\x8a\x1e\x99\xac\x24\xab\x51\x2f\x9e\x50\x66\x13\x22\x8b\x4a\x98\x83\x27\xd2\xc0\x64\x99\xb8\x7c\xd8\x29\x9a\xfd\xf7\x8b\x07\x3f\xf0\xd8\xf6\x49\xdf\xb4\xbe\x0f\x1f\xb5\xfd\x21\x64\x03\xba\x13\x9b\x54\x05\x99\x82\x2c\x58\xc7\x0a\xd6\xc8\x45\x15\xb1\xa9\xc1\x13\x00\xb6\x04\x67\xc8\xe2\xef\xe4\x56\x0c\xdd\xfc\x64\xfe\xe0\x82\x54\x54\x48\xde\x6c\xb1\x90\xa0\x93\xe8\xa0\x15\x24\xbb\x0c\x12\x1d\xc7\x37\xe8\x18\xb9\x77\x0f\x36\xfd\x94\xa3\x31\x13\x36\x0e\x0b\xb6\x6d\x93\x0f\x01\x77\xb6\x9c\x4b\x08\xd5\x57\xa0\xe3\x27\x35\x44\x2d\x8d\xf3\xde\x09\x99\x73\x6f\x1c\xe7\x15\xba\x6a\xb4\xeb\x35\xb2\xef\xca\xe1\xd8\x32\x47\x06\xb6\xf5\xc0\xb1\xee\x46\x60\x9f\x46\xdf\x41\x7a\x84\xad\x3a\x82\x67\x4b\x29\xe4\x4a\x08\xff\xe5\x4f\x61\x7b\x6d\x44\x98\xee\xf6\xef\x7f\x5a\x89\x7f\x51\x12\x68\xc9\x4f\x01\x00\x00")
+
+func migrations_gateway06_batch_itemSqlBytes() ([]byte, error) {
+	return bindataRead(
+		_migrations_gateway06_batch_itemSql,
+		"migrations_gateway/06_batch_item.sql",
+	)
+}
+
+func migrations_gateway06_batch_itemSql() (*asset, error) {
+	bytes, err := migrations_gateway06_batch_itemSqlBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	info := bindataFileInfo{name: "migrations_gateway/06_batch_item.sql", size: 335, mode: os.FileMode(420), modTime: time.Unix(1530000000, 0)}
+	a := &asset{bytes: bytes, info: info}
+	return a, nil
+}
+
+var _migrations_gateway07_batch_item_payment_idSql = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x02\xff\xd3\xd5\x55\xd0\xce\xcd\x4c\x2f\x4a\x2c\x49\x55\x08\x2d\xe0\x72\xf4\x09\x71\x0d\x52\x08\x71\x74\xf2\x71\x55\x70\x4a\x2c\x49\xce\xf0\x2c\x49\xcd\x55\x70\x74\x71\x51\x28\x48\xac\xcc\x4d\xcd\x2b\x89\xcf\x4c\x51\x08\x73\x0c\x72\xf6\x70\x0c\xd2\x30\x32\x35\xd5\x54\xf0\x0b\xf5\xf1\x51\x70\x71\x75\x73\x0c\xf5\x09\x01\x73\xac\xb9\xb8\x74\x91\x0c\x75\xc9\x2f\xcf\xc3\x61\xac\x4b\x90\x7f\x00\x92\xb9\xd6\x5c\x00\x25\xf7\xb2\x6f\x8d\x00\x00\x00")
+
+func migrations_gateway07_batch_item_payment_idSqlBytes() ([]byte, error) {
+	return bindataRead(
+		_migrations_gateway07_batch_item_payment_idSql,
+		"migrations_gateway/07_batch_item_payment_id.sql",
+	)
+}
+
+func migrations_gateway07_batch_item_payment_idSql() (*asset, error) {
+	bytes, err := migrations_gateway07_batch_item_payment_idSqlBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	info := bindataFileInfo{name: "migrations_gateway/07_batch_item_payment_id.sql", size: 141, mode: os.FileMode(420), modTime: time.Unix(1530000000, 0)}
+	a := &asset{bytes: bytes, info: info}
+	return a, nil
+}
+
 var _migrations_compliance01_initSql = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x00\xff\x94\x93\x41\x6f\x82\x40\x10\x85\xef\xfb\x2b\xe6\x28\xa9\x5e\x9a\xea\x85\x13\xad\x34\x21\xb5\x68\x09\x24\xf5\xb4\x19\xdd\x45\x27\x65\xc1\x2c\x4b\xd5\xfe\xfa\x86\x5a\x85\xad\xa2\xe9\x75\xdf\xdb\x99\xf7\x3e\xd8\xc1\x00\xee\x14\xad\x34\x1a\x09\xc9\x86\x3d\x45\xbe\x17\xfb\x10\x7b\x8f\x13\x1f\xbc\xca\xac\x0b\x4d\x5f\x52\xc4\x1a\xf3\x12\x97\x86\x8a\x1c\x7a\x0c\x80\x04\x2c\x68\x55\x4a\x4d\x98\xf5\x19\x80\x69\x74\x4e\x02\x3e\x51\x2f\xd7\xa8\x7b\xa3\x07\x07\xc2\x69\x0c\x61\x32\x99\xd4\x36\x25\x55\xd1\x29\xb6\x67\xec\x84\x06\x23\x77\xc6\x32\xe0\x29\x0e\x47\x03\x86\x94\x2c\x0d\xaa\x8d\xe5\x11\x68\xf0\xfc\x26\x03\x98\x45\xc1\xab\x17\xcd\xe1\xc5\x9f\x43\x8f\x84\xc3\x1c\x97\xfd\x69\x9b\x65\xc5\x56\x8a\xe7\xe0\x62\xc3\x1c\x95\x3c\x45\xbf\x1f\x0e\xed\xec\xa2\x50\x48\x79\xb7\xbe\xa9\x16\x19\x2d\xf9\x87\xdc\xc3\x8f\x61\x38\xb2\x75\x3c\xec\xee\xee\x75\x16\x9f\x39\xd0\x14\x48\xc2\xe0\x2d\xf1\x21\x08\xc7\xfe\x3b\x60\x4a\x7c\xb1\xe7\xbf\x91\xa6\x61\xbb\xd8\xe1\xd0\x71\xaf\x5d\x6c\x65\xb5\x2f\x37\x42\x17\xbb\xa4\x94\xfa\x22\xbd\x94\xf8\x75\x80\x29\xf1\x5b\x0c\x53\xe2\xb7\x30\x56\xa5\xd4\xed\xff\xef\x6c\xc6\xff\x39\x3b\x5d\x94\xab\x9a\x95\x95\x89\x1f\xd7\x37\xd8\x0e\x40\x2c\x57\xff\x98\xb2\x9e\xcc\xda\xcf\x6f\x5c\x6c\x73\x36\x8e\xa6\xb3\x6b\xcf\xcf\xb5\x1c\xc7\x8f\x73\xe9\xb4\xde\xed\xb2\xef\x00\x00\x00\xff\xff\x02\xc5\x23\x8a\xe0\x03\x00\x00")
 
 func migrations_compliance01_initSqlBytes() ([]byte, error) {
@@ -227,6 +311,10 @@ var _bindata = map[string]func() (*asset, error){
 	"migrations_gateway/01_init.sql": migrations_gateway01_initSql,
 	"migrations_gateway/02_payment_id.sql": migrations_gateway02_payment_idSql,
 	"migrations_gateway/03_transaction_id.sql": migrations_gateway03_transaction_idSql,
+	"migrations_gateway/04_queued_payment.sql": migrations_gateway04_queued_paymentSql,
+	"migrations_gateway/05_request_hash.sql": migrations_gateway05_request_hashSql,
+	"migrations_gateway/06_batch_item.sql": migrations_gateway06_batch_itemSql,
+	"migrations_gateway/07_batch_item_payment_id.sql": migrations_gateway07_batch_item_payment_idSql,
 	"migrations_compliance/01_init.sql": migrations_compliance01_initSql,
 	"migrations_compliance/02_auth_data.sql": migrations_compliance02_auth_dataSql,
 }
@@ -279,6 +367,10 @@ var _bintree = &bintree{nil, map[string]*bintree{
 		"01_init.sql": &bintree{migrations_gateway01_initSql, map[string]*bintree{}},
 		"02_payment_id.sql": &bintree{migrations_gateway02_payment_idSql, map[string]*bintree{}},
 		"03_transaction_id.sql": &bintree{migrations_gateway03_transaction_idSql, map[string]*bintree{}},
+		"04_queued_payment.sql": &bintree{migrations_gateway04_queued_paymentSql, map[string]*bintree{}},
+		"05_request_hash.sql": &bintree{migrations_gateway05_request_hashSql, map[string]*bintree{}},
+		"06_batch_item.sql": &bintree{migrations_gateway06_batch_itemSql, map[string]*bintree{}},
+		"07_batch_item_payment_id.sql": &bintree{migrations_gateway07_batch_item_payment_idSql, map[string]*bintree{}},
 	}},
 }}
 
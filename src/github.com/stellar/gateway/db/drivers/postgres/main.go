@@ -87,6 +87,10 @@ func (d *Driver) Insert(object entities.Entity) (id int64, err error) {
 		err = stmt.Get(&id, object)
 	case *entities.ReceivedPayment:
 		err = stmt.Get(&id, object)
+	case *entities.QueuedPayment:
+		err = stmt.Get(&id, object)
+	case *entities.BatchItem:
+		err = stmt.Get(&id, object)
 	}
 
 	if err != nil {
@@ -145,6 +149,10 @@ func (d *Driver) Update(object entities.Entity) (err error) {
 		_, err = d.database.NamedExec(query, object)
 	case *entities.ReceivedPayment:
 		_, err = d.database.NamedExec(query, object)
+	case *entities.QueuedPayment:
+		_, err = d.database.NamedExec(query, object)
+	case *entities.BatchItem:
+		_, err = d.database.NamedExec(query, object)
 	}
 
 	return
@@ -229,6 +237,13 @@ func (d *Driver) GetMany(slice interface{}, where, order, offset, limit *string,
 			tmp[i].SetExists()
 		}
 		slice = &tmp
+	case *[]*entities.QueuedPayment:
+		err = d.database.Select(slice, query.String(), params...)
+		tmp := *slice
+		for i := range tmp {
+			tmp[i].SetExists()
+		}
+		slice = &tmp
 	}
 
 	if err != nil && err.Error() == "sql: no rows in result set" {
@@ -258,10 +273,18 @@ func getTypeData(object interface{}) (typeValue reflect.Type, tableName string,
 	case *entities.ReceivedPayment:
 		typeValue = reflect.TypeOf(*object)
 		tableName = "ReceivedPayment"
+	case *entities.QueuedPayment:
+		typeValue = reflect.TypeOf(*object)
+		tableName = "QueuedPayment"
+	case *entities.BatchItem:
+		typeValue = reflect.TypeOf(*object)
+		tableName = "BatchItem"
 	case *[]*entities.SentTransaction:
 		tableName = "SentTransaction"
 	case *[]*entities.ReceivedPayment:
 		tableName = "ReceivedPayment"
+	case *[]*entities.QueuedPayment:
+		tableName = "QueuedPayment"
 	default:
 		return typeValue, tableName, fmt.Errorf("Unknown entity type: %T", object)
 	}
@@ -0,0 +1,18 @@
+-- +migrate Up
+CREATE TABLE QueuedPayment (
+  id serial,
+  payment_id varchar(255) UNIQUE DEFAULT NULL,
+  status varchar(10) NOT NULL,
+  source varchar(56) NOT NULL,
+  request text NOT NULL,
+  attempts integer NOT NULL DEFAULT 0,
+  last_error varchar(255) DEFAULT NULL,
+  queued_at timestamp NOT NULL,
+  last_attempt_at timestamp DEFAULT NULL,
+  PRIMARY KEY (id)
+);
+
+CREATE INDEX queued_payment_status ON QueuedPayment (status);
+
+-- +migrate Down
+DROP TABLE QueuedPayment;
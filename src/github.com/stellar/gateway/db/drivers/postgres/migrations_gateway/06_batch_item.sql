@@ -0,0 +1,14 @@
+-- +migrate Up
+CREATE TABLE BatchItem (
+  id serial,
+  item_key varchar(255) UNIQUE NOT NULL,
+  status varchar(10) NOT NULL,
+  transaction_id varchar(64) DEFAULT NULL,
+  error varchar(255) DEFAULT NULL,
+  request_hash varchar(64) NOT NULL,
+  updated_at timestamp NOT NULL,
+  PRIMARY KEY (id)
+);
+
+-- +migrate Down
+DROP TABLE BatchItem;
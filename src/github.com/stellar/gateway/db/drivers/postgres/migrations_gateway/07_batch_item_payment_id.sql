@@ -0,0 +1,5 @@
+-- +migrate Up
+ALTER TABLE BatchItem ADD payment_id VARCHAR(255) NULL DEFAULT NULL;
+
+-- +migrate Down
+ALTER TABLE BatchItem DROP payment_id;
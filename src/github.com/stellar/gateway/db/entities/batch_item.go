@@ -0,0 +1,107 @@
+package entities
+
+import (
+	"database/sql/driver"
+	"errors"
+	"time"
+)
+
+// BatchItemStatus type represents status of a batch payment item
+type BatchItemStatus string
+
+// Scan implements database/sql.Scanner interface
+func (s *BatchItemStatus) Scan(src interface{}) error {
+	value, ok := src.([]byte)
+	if !ok {
+		return errors.New("Cannot convert value to BatchItemStatus")
+	}
+	*s = BatchItemStatus(value)
+	return nil
+}
+
+// Value implements driver.Valuer
+func (status BatchItemStatus) Value() (driver.Value, error) {
+	return driver.Value(string(status)), nil
+}
+
+var _ driver.Valuer = BatchItemStatus("")
+
+const (
+	// BatchItemStatusSuccess is a status indicating that the payment has been sent
+	BatchItemStatusSuccess BatchItemStatus = "success"
+	// BatchItemStatusFailure is a status indicating that the transaction sending the payment failed
+	BatchItemStatusFailure BatchItemStatus = "failure"
+	// BatchItemStatusPending is a status indicating that the transaction sending the payment is being
+	// submitted or its outcome is unknown
+	BatchItemStatusPending BatchItemStatus = "pending"
+)
+
+// BatchItem represents the result of a /batch-payment payment sent with an idempotency key
+type BatchItem struct {
+	exists bool
+	ID     *int64 `db:"id" json:"id"`
+	// Idempotency key of the payment, unique
+	ItemKey string          `db:"item_key" json:"item_key"`
+	Status  BatchItemStatus `db:"status" json:"status"` // success/failure/pending
+	// Hash of the transaction sending the payment, nil when it was not submitted
+	TransactionID *string `db:"transaction_id" json:"transaction_id"`
+	// Payment ID of the transaction sending the payment, set before it's submitted. The transaction
+	// and its hash are stored with it (see SentTransaction) so its outcome can be checked later.
+	PaymentID *string `db:"payment_id" json:"payment_id"`
+	// Error code of the failed payment
+	Error *string `db:"error" json:"error"`
+	// SHA-256 hash of normalized params of the payment, used to detect key reuse
+	RequestHash string    `db:"request_hash" json:"request_hash"`
+	UpdatedAt   time.Time `db:"updated_at" json:"updated_at"`
+}
+
+// GetID returns ID of the entity
+func (e *BatchItem) GetID() *int64 {
+	if e.ID == nil {
+		return nil
+	}
+	newID := *e.ID
+	return &newID
+}
+
+// SetID sets ID of the entity
+func (e *BatchItem) SetID(id int64) {
+	e.ID = &id
+}
+
+// IsNew returns true if the entity has not been persisted yet
+func (e *BatchItem) IsNew() bool {
+	return !e.exists
+}
+
+// SetExists sets entity as persisted
+func (e *BatchItem) SetExists() {
+	e.exists = true
+}
+
+// MarkPending marks the payment as being sent in the transaction sent with paymentID.
+// transactionID is nil when the transaction has not been signed yet.
+func (e *BatchItem) MarkPending(paymentID string, transactionID *string) {
+	e.Status = BatchItemStatusPending
+	e.PaymentID = &paymentID
+	e.TransactionID = transactionID
+	e.Error = nil
+	e.UpdatedAt = time.Now()
+}
+
+// MarkSucceeded marks the payment as sent in the transaction
+func (e *BatchItem) MarkSucceeded(transactionID string) {
+	e.Status = BatchItemStatusSuccess
+	e.TransactionID = &transactionID
+	e.Error = nil
+	e.UpdatedAt = time.Now()
+}
+
+// MarkFailed marks the payment as failed with the error code. transactionID is nil when the
+// transaction was not submitted.
+func (e *BatchItem) MarkFailed(transactionID *string, errorCode string) {
+	e.Status = BatchItemStatusFailure
+	e.TransactionID = transactionID
+	e.Error = &errorCode
+	e.UpdatedAt = time.Now()
+}
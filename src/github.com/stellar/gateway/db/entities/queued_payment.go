@@ -0,0 +1,106 @@
+package entities
+
+import (
+	"database/sql/driver"
+	"errors"
+	"time"
+)
+
+// QueuedPaymentStatus type represents queued payment status
+type QueuedPaymentStatus string
+
+// Scan implements database/sql.Scanner interface
+func (s *QueuedPaymentStatus) Scan(src interface{}) error {
+	value, ok := src.([]byte)
+	if !ok {
+		return errors.New("Cannot convert value to QueuedPaymentStatus")
+	}
+	*s = QueuedPaymentStatus(value)
+	return nil
+}
+
+// Value implements driver.Valuer
+func (status QueuedPaymentStatus) Value() (driver.Value, error) {
+	return driver.Value(string(status)), nil
+}
+
+var _ driver.Valuer = QueuedPaymentStatus("")
+
+const (
+	// QueuedPaymentStatusQueued is a status indicating that payment is waiting to be sent
+	QueuedPaymentStatusQueued QueuedPaymentStatus = "queued"
+	// QueuedPaymentStatusSent is a status indicating that payment has been successfully sent
+	QueuedPaymentStatusSent QueuedPaymentStatus = "sent"
+	// QueuedPaymentStatusFailed is a status indicating that payment has been rejected and will not be retried
+	QueuedPaymentStatusFailed QueuedPaymentStatus = "failed"
+	// QueuedPaymentStatusUnknown is a status indicating that submission of the payment transaction failed
+	// and it may have been sent anyway. It will not be retried.
+	QueuedPaymentStatusUnknown QueuedPaymentStatus = "unknown"
+)
+
+// QueuedPayment represents compliance payment queued while compliance server was unavailable
+type QueuedPayment struct {
+	exists        bool
+	ID            *int64              `db:"id" json:"id"`
+	PaymentID     *string             `db:"payment_id" json:"payment_id"`
+	Status        QueuedPaymentStatus `db:"status" json:"status"` // queued/sent/failed/unknown
+	Source        string              `db:"source" json:"source"`
+	Request       string              `db:"request" json:"request"` // url encoded payment request without `source`
+	Attempts      int                 `db:"attempts" json:"attempts"`
+	LastError     *string             `db:"last_error" json:"last_error"`
+	QueuedAt      time.Time           `db:"queued_at" json:"queued_at"`
+	LastAttemptAt *time.Time          `db:"last_attempt_at" json:"last_attempt_at"`
+}
+
+// GetID returns ID of the entity
+func (e *QueuedPayment) GetID() *int64 {
+	if e.ID == nil {
+		return nil
+	}
+	newID := *e.ID
+	return &newID
+}
+
+// SetID sets ID of the entity
+func (e *QueuedPayment) SetID(id int64) {
+	e.ID = &id
+}
+
+// IsNew returns true if the entity has not been persisted yet
+func (e *QueuedPayment) IsNew() bool {
+	return !e.exists
+}
+
+// SetExists sets entity as persisted
+func (e *QueuedPayment) SetExists() {
+	e.exists = true
+}
+
+// MarkAttempt records a failed attempt to send the payment. Payment stays queued.
+func (e *QueuedPayment) MarkAttempt(lastError string) {
+	now := time.Now()
+	e.Attempts++
+	e.LastAttemptAt = &now
+	e.LastError = &lastError
+}
+
+// MarkSent marks payment as sent
+func (e *QueuedPayment) MarkSent() {
+	now := time.Now()
+	e.Attempts++
+	e.LastAttemptAt = &now
+	e.LastError = nil
+	e.Status = QueuedPaymentStatusSent
+}
+
+// MarkFailed marks payment as failed
+func (e *QueuedPayment) MarkFailed(lastError string) {
+	e.MarkAttempt(lastError)
+	e.Status = QueuedPaymentStatusFailed
+}
+
+// MarkUnknown marks payment as unknown
+func (e *QueuedPayment) MarkUnknown(lastError string) {
+	e.MarkAttempt(lastError)
+	e.Status = QueuedPaymentStatusUnknown
+}
@@ -48,6 +48,8 @@ type SentTransaction struct {
 	Ledger        *uint64               `db:"ledger" json:"ledger"`
 	EnvelopeXdr   string                `db:"envelope_xdr" json:"envelope_xdr"`
 	ResultXdr     *string               `db:"result_xdr" json:"result_xdr"`
+	// SHA-256 hash of normalized params of the payment request, used to detect payment ID reuse
+	RequestHash *string `db:"request_hash" json:"request_hash"`
 }
 
 // GetID returns ID of the entity
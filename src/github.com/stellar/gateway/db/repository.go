@@ -13,12 +13,15 @@ type RepositoryInterface interface {
 	GetLastCursorValue() (cursor *string, err error)
 	GetAuthorizedTransactionByMemo(memo string) (*entities.AuthorizedTransaction, error)
 	GetSentTransactionByPaymentID(paymentID string) (*entities.SentTransaction, error)
+	GetBatchItemByKey(key string) (*entities.BatchItem, error)
 	GetAllowedFiByDomain(domain string) (*entities.AllowedFi, error)
 	GetAllowedUserByDomainAndUserID(domain, userID string) (*entities.AllowedUser, error)
 	GetAuthData(requestID string) (*entities.AuthData, error)
 	GetReceivedPaymentByOperationID(operationID int64) (*entities.ReceivedPayment, error)
 	GetReceivedPayments(page, limit int) ([]*entities.ReceivedPayment, error)
 	GetSentTransactions(page, limit int) ([]*entities.SentTransaction, error)
+	GetQueuedPayments(page, limit int) ([]*entities.QueuedPayment, error)
+	GetPendingQueuedPayments(limit int) ([]*entities.QueuedPayment, error)
 }
 
 // Repository helps getting data from DB
@@ -96,6 +99,29 @@ func (r Repository) GetSentTransactionByPaymentID(paymentID string) (*entities.S
 	return &found, nil
 }
 
+// GetBatchItemByKey returns result of a batch payment searching by its idempotency key
+func (r Repository) GetBatchItemByKey(key string) (*entities.BatchItem, error) {
+
+	var found entities.BatchItem
+
+	err := r.repo.GetRaw(
+		&found,
+		"SELECT * FROM BatchItem WHERE item_key = ?",
+		key,
+	)
+
+	if r.repo.NoRows(err) {
+		return nil, nil
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	found.SetExists()
+	return &found, nil
+}
+
 // GetAllowedFiByDomain returns allowed FI by a domain
 func (r Repository) GetAllowedFiByDomain(domain string) (*entities.AllowedFi, error) {
 
@@ -224,6 +250,36 @@ func (r Repository) GetSentTransactions(page, limit int) ([]*entities.SentTransa
 	return transactions, err
 }
 
+// GetQueuedPayments returns queued compliance payments
+func (r Repository) GetQueuedPayments(page, limit int) ([]*entities.QueuedPayment, error) {
+	payments := []*entities.QueuedPayment{}
+
+	if page == 0 {
+		page = 1
+	}
+
+	offset := (page - 1) * limit
+
+	limitQuery := fmt.Sprintf("%d", limit)
+	offsetQuery := fmt.Sprintf("%d", offset)
+	orderQuery := "id desc"
+
+	err := r.driver.GetMany(&payments, nil, &orderQuery, &offsetQuery, &limitQuery)
+	return payments, err
+}
+
+// GetPendingQueuedPayments returns compliance payments waiting to be sent, oldest first
+func (r Repository) GetPendingQueuedPayments(limit int) ([]*entities.QueuedPayment, error) {
+	payments := []*entities.QueuedPayment{}
+
+	whereQuery := "status = '" + string(entities.QueuedPaymentStatusQueued) + "'"
+	limitQuery := fmt.Sprintf("%d", limit)
+	orderQuery := "id asc"
+
+	err := r.driver.GetMany(&payments, &whereQuery, &orderQuery, nil, &limitQuery)
+	return payments, err
+}
+
 // getLastReceivedPayment returns the last received payment
 func (r Repository) getLastReceivedPayment() (*entities.ReceivedPayment, error) {
 	var receivedPayment entities.ReceivedPayment
@@ -0,0 +1,122 @@
+package events
+
+import (
+	"encoding/json"
+	"sync/atomic"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// Types of payment events
+const (
+	// PaymentSubmitted is published when the transaction has been submitted but it's not in a
+	// ledger yet (async submission or confirmation timeout)
+	PaymentSubmitted = "submitted"
+	// PaymentConfirmed is published when the transaction is in a ledger
+	PaymentConfirmed = "confirmed"
+	// PaymentFailed is published when the payment has been rejected or its transaction failed
+	PaymentFailed = "failed"
+)
+
+// PaymentEvent is published for every /payment request and every payment of transactions submitted
+// by /batch-payment. Params of the request are included as sent, the source is never included as it
+// can be a secret seed.
+type PaymentEvent struct {
+	// PaymentSubmitted, PaymentConfirmed or PaymentFailed
+	Type string `json:"type"`
+	// ID of the HTTP request, see server.RequestID
+	RequestID string `json:"request_id,omitempty"`
+	// `id` param of the payment (or the batch)
+	PaymentID string `json:"payment_id,omitempty"`
+	// `key` of a keyed batch payment
+	Key         string `json:"key,omitempty"`
+	Destination string `json:"destination,omitempty"`
+	Amount      string `json:"amount,omitempty"`
+	AssetCode   string `json:"asset_code,omitempty"`
+	AssetIssuer string `json:"asset_issuer,omitempty"`
+	// Hash of the transaction, unknown for payments rejected before submission
+	Hash   string  `json:"hash,omitempty"`
+	Ledger *uint64 `json:"ledger,omitempty"`
+	// Error code of failed payments
+	Error string    `json:"error,omitempty"`
+	Time  time.Time `json:"time"`
+}
+
+// Publisher is implemented by event publishers
+type Publisher interface {
+	// PublishPayment publishes a payment event
+	PublishPayment(event PaymentEvent)
+}
+
+// Noop discards all events. It's used when no broker is configured.
+type Noop struct{}
+
+// PublishPayment implements Publisher
+func (Noop) PublishPayment(event PaymentEvent) {}
+
+// Broker sends messages to a message broker
+type Broker interface {
+	Publish(subject string, data []byte) error
+}
+
+// AsyncPublisher publishes events encoded as JSON to a broker in a background goroutine (see Run), so
+// requests never wait for the broker. Events are published to `<subject>.<type>`, ex. `bridge.payments.confirmed`.
+// Up to bufferSize events wait to be published, when the buffer is full new events are dropped or,
+// when block is true, PublishPayment waits for room in the buffer. Events failed to be published are
+// logged and dropped.
+type AsyncPublisher struct {
+	broker  Broker
+	subject string
+	events  chan PaymentEvent
+	block   bool
+	dropped uint64
+}
+
+// NewAsyncPublisher creates a new AsyncPublisher
+func NewAsyncPublisher(broker Broker, subject string, bufferSize int, block bool) *AsyncPublisher {
+	return &AsyncPublisher{
+		broker:  broker,
+		subject: subject,
+		events:  make(chan PaymentEvent, bufferSize),
+		block:   block,
+	}
+}
+
+// PublishPayment implements Publisher
+func (p *AsyncPublisher) PublishPayment(event PaymentEvent) {
+	if p.block {
+		p.events <- event
+		return
+	}
+
+	select {
+	case p.events <- event:
+	default:
+		dropped := atomic.AddUint64(&p.dropped, 1)
+		log.WithFields(log.Fields{"type": event.Type, "request_id": event.RequestID, "dropped": dropped}).Warn("Event buffer is full, payment event dropped")
+	}
+}
+
+// Dropped returns the number of events dropped because the buffer was full
+func (p *AsyncPublisher) Dropped() uint64 {
+	return atomic.LoadUint64(&p.dropped)
+}
+
+// Run publishes buffered events until Close is called
+func (p *AsyncPublisher) Run() {
+	for event := range p.events {
+		data, err := json.Marshal(event)
+		if err == nil {
+			err = p.broker.Publish(p.subject+"."+event.Type, data)
+		}
+		if err != nil {
+			log.WithFields(log.Fields{"type": event.Type, "request_id": event.RequestID, "hash": event.Hash, "err": err}).Error("Error publishing payment event")
+		}
+	}
+}
+
+// Close stops Run once all buffered events are published. Events must not be published after Close.
+func (p *AsyncPublisher) Close() {
+	close(p.events)
+}
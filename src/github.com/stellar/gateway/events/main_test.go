@@ -0,0 +1,106 @@
+package events
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	. "github.com/smartystreets/goconvey/convey"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type testMessage struct {
+	subject string
+	data    []byte
+}
+
+type testBroker struct {
+	messages chan testMessage
+	err      error
+}
+
+func (b *testBroker) Publish(subject string, data []byte) error {
+	b.messages <- testMessage{subject, data}
+	return b.err
+}
+
+func TestAsyncPublisher(t *testing.T) {
+	Convey("AsyncPublisher", t, func() {
+		broker := &testBroker{messages: make(chan testMessage)}
+		ledger := uint64(123)
+		event := PaymentEvent{
+			Type:      PaymentConfirmed,
+			RequestID: "req-1",
+			Hash:      "abc",
+			Ledger:    &ledger,
+			Time:      time.Unix(1500000000, 0).UTC(),
+		}
+
+		Convey("publishes JSON encoded events to subject of their type", func() {
+			publisher := NewAsyncPublisher(broker, "bridge.payments", 1, false)
+			go publisher.Run()
+			defer publisher.Close()
+
+			publisher.PublishPayment(event)
+
+			message := <-broker.messages
+			assert.Equal(t, "bridge.payments.confirmed", message.subject)
+			assert.JSONEq(t, `{"type": "confirmed", "request_id": "req-1", "hash": "abc", "ledger": 123, "time": "2017-07-14T02:40:00Z"}`, string(message.data))
+		})
+
+		Convey("continues publishing after broker error", func() {
+			broker.err = errors.New("connection refused")
+			publisher := NewAsyncPublisher(broker, "bridge.payments", 1, false)
+			go publisher.Run()
+			defer publisher.Close()
+
+			publisher.PublishPayment(event)
+			<-broker.messages
+			publisher.PublishPayment(event)
+			<-broker.messages
+		})
+
+		Convey("drops events when buffer is full", func() {
+			publisher := NewAsyncPublisher(broker, "bridge.payments", 2, false)
+
+			publisher.PublishPayment(event)
+			publisher.PublishPayment(event)
+			publisher.PublishPayment(event)
+			assert.Equal(t, uint64(1), publisher.Dropped())
+
+			go publisher.Run()
+			defer publisher.Close()
+			for i := 0; i < 2; i++ {
+				var published PaymentEvent
+				require.NoError(t, json.Unmarshal((<-broker.messages).data, &published))
+				assert.Equal(t, "req-1", published.RequestID)
+			}
+		})
+
+		Convey("waits for room in buffer when blocking", func() {
+			publisher := NewAsyncPublisher(broker, "bridge.payments", 1, true)
+			publisher.PublishPayment(event)
+
+			published := make(chan struct{})
+			go func() {
+				publisher.PublishPayment(event)
+				close(published)
+			}()
+
+			select {
+			case <-published:
+				t.Fatal("event published to full buffer")
+			case <-time.After(50 * time.Millisecond):
+			}
+
+			go publisher.Run()
+			defer publisher.Close()
+			<-broker.messages
+			<-published
+			<-broker.messages
+			assert.Equal(t, uint64(0), publisher.Dropped())
+		})
+	})
+}
@@ -0,0 +1,146 @@
+package events
+
+import (
+	"bufio"
+	"crypto/tls"
+	"crypto/x509"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNATS(t *testing.T) {
+	Convey("NewNATS", t, func() {
+		Convey("uses default port", func() {
+			broker, err := NewNATS("nats://localhost", nil)
+			require.NoError(t, err)
+			assert.Equal(t, "localhost:4222", broker.address)
+			assert.Nil(t, broker.tls)
+		})
+
+		Convey("enables TLS for tls:// URLs", func() {
+			broker, err := NewNATS("tls://nats.example.com:4443", nil)
+			require.NoError(t, err)
+			assert.Equal(t, "nats.example.com:4443", broker.address)
+			require.NotNil(t, broker.tls)
+			assert.Equal(t, "nats.example.com", broker.tls.ServerName)
+		})
+
+		Convey("returns error when URL is invalid", func() {
+			for _, value := range []string{"localhost:4222", "http://localhost:4222", "nats://"} {
+				_, err := NewNATS(value, nil)
+				assert.Error(t, err, value)
+			}
+		})
+	})
+
+	Convey("NATS", t, func() {
+		listener, err := net.Listen("tcp", "127.0.0.1:0")
+		require.NoError(t, err)
+		defer listener.Close()
+
+		broker, err := NewNATS("nats://bridge:secret@"+listener.Addr().String(), nil)
+		require.NoError(t, err)
+
+		published := make(chan error)
+		go func() {
+			published <- broker.Publish("bridge.payments.confirmed", []byte(`{"hash":"abc"}`))
+		}()
+
+		conn, err := listener.Accept()
+		require.NoError(t, err)
+		defer conn.Close()
+		reader := bufio.NewReader(conn)
+
+		Convey("sends CONNECT with credentials and publishes messages", func() {
+			_, err = conn.Write([]byte("INFO {\"server_id\":\"test\"}\r\n"))
+			require.NoError(t, err)
+
+			line, err := reader.ReadString('\n')
+			require.NoError(t, err)
+			assert.True(t, strings.HasPrefix(line, "CONNECT {"))
+			assert.Contains(t, line, `"user":"bridge"`)
+			assert.Contains(t, line, `"pass":"secret"`)
+			assert.Contains(t, line, `"verbose":false`)
+
+			line, err = reader.ReadString('\n')
+			require.NoError(t, err)
+			assert.Equal(t, "PUB bridge.payments.confirmed 14\r\n", line)
+			line, err = reader.ReadString('\n')
+			require.NoError(t, err)
+			assert.Equal(t, "{\"hash\":\"abc\"}\r\n", line)
+			assert.NoError(t, <-published)
+
+			Convey("answers PING", func() {
+				_, err := conn.Write([]byte("PING\r\n"))
+				require.NoError(t, err)
+				line, err := reader.ReadString('\n')
+				require.NoError(t, err)
+				assert.Equal(t, "PONG\r\n", line)
+			})
+		})
+
+		Convey("fails when server requires TLS", func() {
+			_, err := conn.Write([]byte("INFO {\"server_id\":\"test\",\"tls_required\":true}\r\n"))
+			require.NoError(t, err)
+			assert.EqualError(t, <-published, "NATS server requires TLS, use tls:// URL")
+		})
+	})
+
+	Convey("NATS with TLS", t, func() {
+		// Certificate of httptest servers is valid for 127.0.0.1
+		httpsServer := httptest.NewTLSServer(http.NotFoundHandler())
+		certificates := httpsServer.TLS.Certificates
+		roots := x509.NewCertPool()
+		roots.AddCert(httpsServer.Certificate())
+		httpsServer.Close()
+
+		listener, err := net.Listen("tcp", "127.0.0.1:0")
+		require.NoError(t, err)
+		defer listener.Close()
+
+		broker, err := NewNATS("tls://"+listener.Addr().String(), &tls.Config{RootCAs: roots})
+		require.NoError(t, err)
+
+		published := make(chan error)
+		go func() {
+			published <- broker.Publish("bridge.payments.confirmed", []byte(`{"hash":"abc"}`))
+		}()
+
+		conn, err := listener.Accept()
+		require.NoError(t, err)
+		defer conn.Close()
+
+		Convey("upgrades connection to TLS after INFO", func() {
+			_, err = conn.Write([]byte("INFO {\"server_id\":\"test\",\"tls_required\":true}\r\n"))
+			require.NoError(t, err)
+
+			tlsConn := tls.Server(conn, &tls.Config{Certificates: certificates})
+			defer tlsConn.Close()
+			reader := bufio.NewReader(tlsConn)
+
+			line, err := reader.ReadString('\n')
+			require.NoError(t, err)
+			assert.True(t, strings.HasPrefix(line, "CONNECT {"))
+
+			line, err = reader.ReadString('\n')
+			require.NoError(t, err)
+			assert.Equal(t, "PUB bridge.payments.confirmed 14\r\n", line)
+			_, err = reader.ReadString('\n')
+			require.NoError(t, err)
+			assert.NoError(t, <-published)
+		})
+
+		Convey("fails when server does not support TLS", func() {
+			_, err = conn.Write([]byte("INFO {\"server_id\":\"test\"}\r\n"))
+			require.NoError(t, err)
+			assert.EqualError(t, <-published, "NATS server does not support TLS")
+		})
+	})
+}
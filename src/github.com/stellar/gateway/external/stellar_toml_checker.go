@@ -0,0 +1,164 @@
+package external
+
+import (
+	"fmt"
+	"io"
+	"net/url"
+	"sync"
+	"time"
+
+	"github.com/BurntSushi/toml"
+	"github.com/stellar/gateway/protocols"
+	"github.com/stellar/go/clients/stellartoml"
+)
+
+// StellarTomlCheckerMaxSize is the maximum size of stellar.toml file checked by StellarTomlChecker
+const StellarTomlCheckerMaxSize = 100 * 1024
+
+// StellarToml contains fields of stellar.toml file checked by StellarTomlChecker
+type StellarToml struct {
+	FederationServer string                `toml:"FEDERATION_SERVER"`
+	AuthServer       string                `toml:"AUTH_SERVER"`
+	SigningKey       string                `toml:"SIGNING_KEY"`
+	Currencies       []StellarTomlCurrency `toml:"CURRENCIES"`
+}
+
+// StellarTomlCurrency is a currency declared in `[[CURRENCIES]]` table of stellar.toml file
+type StellarTomlCurrency struct {
+	Code   string `toml:"code"`
+	Issuer string `toml:"issuer"`
+}
+
+// StellarTomlCheck is the result of checking stellar.toml file of a domain
+type StellarTomlCheck struct {
+	// Nil when the file cannot be fetched or parsed
+	Toml *StellarToml
+	// Problems found in the file, empty when it's valid
+	Errors []string
+}
+
+type stellarTomlEntry struct {
+	check     *StellarTomlCheck
+	expiresAt time.Time
+}
+
+// StellarTomlChecker fetches and validates stellar.toml files of domains (ex. of asset issuers
+// being onboarded). Results, including failures, are cached so a domain is not fetched on every
+// request.
+type StellarTomlChecker struct {
+	http    stellartoml.HTTP
+	ttl     time.Duration
+	now     func() time.Time
+	entries map[string]stellarTomlEntry
+	mutex   sync.Mutex
+}
+
+// NewStellarTomlChecker creates a new StellarTomlChecker fetching files using http and caching
+// results for ttl
+func NewStellarTomlChecker(http stellartoml.HTTP, ttl time.Duration, now func() time.Time) *StellarTomlChecker {
+	return &StellarTomlChecker{
+		http:    http,
+		ttl:     ttl,
+		now:     now,
+		entries: make(map[string]stellarTomlEntry),
+	}
+}
+
+// Check returns the result of checking stellar.toml file of the domain. The file is fetched from
+// `https://{domain}/.well-known/stellar.toml` when it's not cached or its entry expired.
+func (c *StellarTomlChecker) Check(domain string) *StellarTomlCheck {
+	c.mutex.Lock()
+	entry, ok := c.entries[domain]
+	c.mutex.Unlock()
+
+	now := c.now()
+	if ok && now.Before(entry.expiresAt) {
+		return entry.check
+	}
+
+	// The file is fetched without holding the lock, concurrent misses fetch it twice
+	check := c.fetch(domain)
+
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	for cached, entry := range c.entries {
+		if !now.Before(entry.expiresAt) {
+			delete(c.entries, cached)
+		}
+	}
+
+	c.entries[domain] = stellarTomlEntry{
+		check:     check,
+		expiresAt: now.Add(c.ttl),
+	}
+
+	return check
+}
+
+// fetch fetches, parses and validates stellar.toml file of the domain
+func (c *StellarTomlChecker) fetch(domain string) *StellarTomlCheck {
+	resp, err := c.http.Get("https://" + domain + stellartoml.WellKnownPath)
+	if err != nil {
+		return &StellarTomlCheck{Errors: []string{"stellar.toml is unreachable: " + err.Error()}}
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return &StellarTomlCheck{Errors: []string{fmt.Sprintf("stellar.toml is unreachable: HTTP status %d", resp.StatusCode)}}
+	}
+
+	// One byte more than the limit is read to find out if the file is too large
+	limitReader := &io.LimitedReader{R: resp.Body, N: StellarTomlCheckerMaxSize + 1}
+	var file StellarToml
+	_, err = toml.DecodeReader(limitReader, &file)
+	if limitReader.N == 0 {
+		return &StellarTomlCheck{Errors: []string{fmt.Sprintf("stellar.toml exceeds %d bytes limit", StellarTomlCheckerMaxSize)}}
+	}
+	if err != nil {
+		return &StellarTomlCheck{Errors: []string{"stellar.toml is malformed: " + err.Error()}}
+	}
+
+	return &StellarTomlCheck{Toml: &file, Errors: file.validate()}
+}
+
+// validate returns problems found in the file
+func (file *StellarToml) validate() []string {
+	var errors []string
+
+	if file.FederationServer == "" {
+		errors = append(errors, "FEDERATION_SERVER is missing")
+	} else if !isHTTPSURL(file.FederationServer) {
+		errors = append(errors, "FEDERATION_SERVER is not a valid https URL")
+	}
+
+	if file.AuthServer != "" && !isHTTPSURL(file.AuthServer) {
+		errors = append(errors, "AUTH_SERVER is not a valid https URL")
+	}
+
+	if file.SigningKey == "" {
+		errors = append(errors, "SIGNING_KEY is missing")
+	} else if !protocols.IsValidAccountID(file.SigningKey) {
+		errors = append(errors, "SIGNING_KEY is not a valid account ID")
+	}
+
+	if len(file.Currencies) == 0 {
+		errors = append(errors, "CURRENCIES are missing")
+	}
+
+	for i, currency := range file.Currencies {
+		if !protocols.IsValidAssetCode(currency.Code) {
+			errors = append(errors, fmt.Sprintf("CURRENCIES[%d] code is missing or invalid", i))
+		}
+		if !protocols.IsValidAccountID(currency.Issuer) {
+			errors = append(errors, fmt.Sprintf("CURRENCIES[%d] issuer is missing or invalid", i))
+		}
+	}
+
+	return errors
+}
+
+func isHTTPSURL(value string) bool {
+	u, err := url.Parse(value)
+	return err == nil && u.Scheme == "https" && u.Host != ""
+}
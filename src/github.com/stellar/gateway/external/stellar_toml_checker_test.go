@@ -0,0 +1,109 @@
+package external
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	. "github.com/smartystreets/goconvey/convey"
+	"github.com/stellar/gateway/mocks"
+	"github.com/stellar/gateway/net"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const validStellarToml = `
+FEDERATION_SERVER = "https://api.stellar.org/federation"
+AUTH_SERVER = "https://api.stellar.org/auth"
+SIGNING_KEY = "GBDOSO3K4JTGSWJSIHXAOFIBMAABVM3YK3FI6VJPKIHHM56XAFIUCGD6"
+
+[[CURRENCIES]]
+code = "USD"
+issuer = "GBDOSO3K4JTGSWJSIHXAOFIBMAABVM3YK3FI6VJPKIHHM56XAFIUCGD6"
+`
+
+func TestStellarTomlChecker(t *testing.T) {
+	Convey("StellarTomlChecker", t, func() {
+		mockHTTPClient := new(mocks.MockHTTPClient)
+		now := time.Unix(1500000000, 0)
+		checker := NewStellarTomlChecker(mockHTTPClient, time.Minute, func() time.Time { return now })
+		url := "https://stellar.org/.well-known/stellar.toml"
+
+		Convey("returns fields of a valid file", func() {
+			mockHTTPClient.On("Get", url).Return(net.BuildHTTPResponse(200, validStellarToml), nil).Once()
+
+			check := checker.Check("stellar.org")
+			assert.Empty(t, check.Errors)
+			require.NotNil(t, check.Toml)
+			assert.Equal(t, "https://api.stellar.org/federation", check.Toml.FederationServer)
+			assert.Equal(t, "https://api.stellar.org/auth", check.Toml.AuthServer)
+			assert.Equal(t, "GBDOSO3K4JTGSWJSIHXAOFIBMAABVM3YK3FI6VJPKIHHM56XAFIUCGD6", check.Toml.SigningKey)
+			assert.Equal(t, []StellarTomlCurrency{{Code: "USD", Issuer: "GBDOSO3K4JTGSWJSIHXAOFIBMAABVM3YK3FI6VJPKIHHM56XAFIUCGD6"}}, check.Toml.Currencies)
+		})
+
+		Convey("returns missing and invalid fields", func() {
+			mockHTTPClient.On("Get", url).Return(net.BuildHTTPResponse(200, `
+FEDERATION_SERVER = "http://api.stellar.org/federation"
+
+[[CURRENCIES]]
+code = "USD"
+issuer = "GBDOSO3K4JTGSWJSIHXAOFIBMAABVM3YK3FI6VJPKIHHM56XAFIUCGD7"
+`), nil).Once()
+
+			check := checker.Check("stellar.org")
+			require.NotNil(t, check.Toml)
+			assert.Equal(t, []string{
+				"FEDERATION_SERVER is not a valid https URL",
+				"SIGNING_KEY is missing",
+				"CURRENCIES[0] issuer is missing or invalid",
+			}, check.Errors)
+		})
+
+		Convey("returns error when file is unreachable", func() {
+			mockHTTPClient.On("Get", url).Return(net.BuildHTTPResponse(404, "Not Found"), nil).Once()
+
+			check := checker.Check("stellar.org")
+			assert.Nil(t, check.Toml)
+			assert.Equal(t, []string{"stellar.toml is unreachable: HTTP status 404"}, check.Errors)
+		})
+
+		Convey("returns error when request fails", func() {
+			mockHTTPClient.On("Get", url).Return(net.BuildHTTPResponse(0, ""), errors.New("connection refused")).Once()
+
+			check := checker.Check("stellar.org")
+			assert.Nil(t, check.Toml)
+			assert.Equal(t, []string{"stellar.toml is unreachable: connection refused"}, check.Errors)
+		})
+
+		Convey("returns error when file is malformed", func() {
+			mockHTTPClient.On("Get", url).Return(net.BuildHTTPResponse(200, `FEDERATION_SERVER = `), nil).Once()
+
+			check := checker.Check("stellar.org")
+			assert.Nil(t, check.Toml)
+			require.Len(t, check.Errors, 1)
+			assert.True(t, strings.HasPrefix(check.Errors[0], "stellar.toml is malformed: "))
+		})
+
+		Convey("returns error when file is too large", func() {
+			body := validStellarToml + "# " + strings.Repeat("a", StellarTomlCheckerMaxSize) + "\n"
+			mockHTTPClient.On("Get", url).Return(net.BuildHTTPResponse(200, body), nil).Once()
+
+			check := checker.Check("stellar.org")
+			assert.Nil(t, check.Toml)
+			assert.Equal(t, []string{"stellar.toml exceeds 102400 bytes limit"}, check.Errors)
+		})
+
+		Convey("fetches the file again after ttl", func() {
+			mockHTTPClient.On("Get", url).Return(net.BuildHTTPResponse(200, validStellarToml), nil).Twice()
+
+			checker.Check("stellar.org")
+			checker.Check("stellar.org")
+			mockHTTPClient.AssertNumberOfCalls(t, "Get", 1)
+
+			now = now.Add(time.Minute)
+			checker.Check("stellar.org")
+			mockHTTPClient.AssertNumberOfCalls(t, "Get", 2)
+		})
+	})
+}
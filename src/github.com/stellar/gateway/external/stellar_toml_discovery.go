@@ -0,0 +1,130 @@
+package external
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/stellar/gateway/net"
+	"github.com/stellar/go/clients/stellartoml"
+)
+
+// StellarTomlGetter fetches stellar.toml file of a domain
+type StellarTomlGetter interface {
+	GetStellarToml(domain string) (*stellartoml.Response, error)
+}
+
+// StellarTomlDiscoveryError is returned by StellarTomlDiscovery when stellar.toml file of the domain
+// cannot be fetched after all retries
+type StellarTomlDiscoveryError struct {
+	Domain string
+	Err    error
+}
+
+func (e *StellarTomlDiscoveryError) Error() string {
+	return "stellar.toml discovery of " + e.Domain + " failed: " + e.Err.Error()
+}
+
+type stellarTomlDiscoveryEntry struct {
+	response  *stellartoml.Response
+	expiresAt time.Time
+}
+
+// StellarTomlDiscovery fetches stellar.toml files of federation domains, the first step of resolving
+// a payment address, separately from the federation record lookup. Failed fetches are retried
+// `retries` times, waiting retryWait before the first retry and twice as long before every next one.
+// Fetched files are cached for ttl, failures are not cached.
+type StellarTomlDiscovery struct {
+	client    StellarTomlGetter
+	retries   int
+	retryWait time.Duration
+	ttl       time.Duration
+	now       func() time.Time
+	sleep     func(time.Duration)
+	// Failed fetches are not retried once ctx is done or its retry budget is used up (see WithContext)
+	ctx     context.Context
+	entries map[string]stellarTomlDiscoveryEntry
+	mutex   *sync.Mutex
+}
+
+// NewStellarTomlDiscovery creates a new StellarTomlDiscovery fetching files using client
+func NewStellarTomlDiscovery(client StellarTomlGetter, retries int, retryWait, ttl time.Duration, now func() time.Time) *StellarTomlDiscovery {
+	return &StellarTomlDiscovery{
+		client:    client,
+		retries:   retries,
+		retryWait: retryWait,
+		ttl:       ttl,
+		now:       now,
+		sleep:     time.Sleep,
+		entries:   make(map[string]stellarTomlDiscoveryEntry),
+		mutex:     &sync.Mutex{},
+	}
+}
+
+// WithContext returns a copy of d sharing its cache that cancels fetches and does not retry failed
+// ones once ctx is done, ex. when the client request resolving an address times out, or beyond the
+// retry budget of ctx (see net.TakeRetry)
+func (d *StellarTomlDiscovery) WithContext(ctx context.Context) *StellarTomlDiscovery {
+	discovery := *d
+	discovery.ctx = ctx
+	if client, ok := d.client.(*stellartoml.Client); ok {
+		if httpClient, ok := client.HTTP.(*http.Client); ok {
+			contextClient := *client
+			contextClient.HTTP = net.ContextClient(httpClient, ctx)
+			discovery.client = &contextClient
+		}
+	}
+	return &discovery
+}
+
+// GetStellarToml returns stellar.toml file of the domain. It implements federation.StellarTOML so it
+// can be used by the federation client. StellarTomlDiscoveryError is returned when the file cannot
+// be fetched.
+func (d *StellarTomlDiscovery) GetStellarToml(domain string) (*stellartoml.Response, error) {
+	d.mutex.Lock()
+	entry, ok := d.entries[domain]
+	d.mutex.Unlock()
+
+	if ok && d.now().Before(entry.expiresAt) {
+		return entry.response, nil
+	}
+
+	// The file is fetched without holding the lock, concurrent misses fetch it twice
+	var response *stellartoml.Response
+	var err error
+	wait := d.retryWait
+	for attempt := 0; ; attempt++ {
+		response, err = d.client.GetStellarToml(domain)
+		if err == nil || attempt == d.retries || !net.TakeRetry(d.ctx, wait) {
+			break
+		}
+
+		log.WithFields(log.Fields{"domain": domain, "attempt": attempt + 1, "err": err}).Warn("Cannot fetch stellar.toml, retrying")
+		d.sleep(wait)
+		wait *= 2
+	}
+
+	if err != nil {
+		return nil, &StellarTomlDiscoveryError{Domain: domain, Err: err}
+	}
+
+	now := d.now()
+
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+
+	for cached, entry := range d.entries {
+		if !now.Before(entry.expiresAt) {
+			delete(d.entries, cached)
+		}
+	}
+
+	d.entries[domain] = stellarTomlDiscoveryEntry{
+		response:  response,
+		expiresAt: now.Add(d.ttl),
+	}
+
+	return response, nil
+}
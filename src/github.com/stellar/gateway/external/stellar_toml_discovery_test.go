@@ -0,0 +1,79 @@
+package external
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	. "github.com/smartystreets/goconvey/convey"
+	"github.com/stellar/gateway/mocks"
+	"github.com/stellar/gateway/net"
+	"github.com/stellar/go/clients/stellartoml"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStellarTomlDiscovery(t *testing.T) {
+	Convey("StellarTomlDiscovery", t, func() {
+		mockStellarTomlResolver := new(mocks.MockStellartomlResolver)
+		now := time.Unix(1500000000, 0)
+		discovery := NewStellarTomlDiscovery(mockStellarTomlResolver, 2, time.Second, time.Hour, func() time.Time { return now })
+		var waits []time.Duration
+		discovery.sleep = func(wait time.Duration) { waits = append(waits, wait) }
+
+		file := &stellartoml.Response{FederationServer: "https://api.stellar.org/federation"}
+		fetchErr := errors.New("http request errored: connection refused")
+
+		Convey("caches fetched file for ttl", func() {
+			mockStellarTomlResolver.On("GetStellarToml", "stellar.org").Return(file, nil).Twice()
+
+			response, err := discovery.GetStellarToml("stellar.org")
+			require.NoError(t, err)
+			assert.Equal(t, file, response)
+			discovery.GetStellarToml("stellar.org")
+			mockStellarTomlResolver.AssertNumberOfCalls(t, "GetStellarToml", 1)
+
+			now = now.Add(time.Hour)
+			discovery.GetStellarToml("stellar.org")
+			mockStellarTomlResolver.AssertNumberOfCalls(t, "GetStellarToml", 2)
+		})
+
+		Convey("retries failed fetches with backoff", func() {
+			mockStellarTomlResolver.On("GetStellarToml", "stellar.org").Return((*stellartoml.Response)(nil), fetchErr).Twice()
+			mockStellarTomlResolver.On("GetStellarToml", "stellar.org").Return(file, nil).Once()
+
+			response, err := discovery.GetStellarToml("stellar.org")
+			require.NoError(t, err)
+			assert.Equal(t, file, response)
+			assert.Equal(t, []time.Duration{time.Second, 2 * time.Second}, waits)
+		})
+
+		Convey("returns discovery error after all retries", func() {
+			mockStellarTomlResolver.On("GetStellarToml", "stellar.org").Return((*stellartoml.Response)(nil), fetchErr).Times(3)
+
+			_, err := discovery.GetStellarToml("stellar.org")
+			require.IsType(t, &StellarTomlDiscoveryError{}, err)
+			assert.Equal(t, "stellar.org", err.(*StellarTomlDiscoveryError).Domain)
+			assert.EqualError(t, err, "stellar.toml discovery of stellar.org failed: http request errored: connection refused")
+			mockStellarTomlResolver.AssertNumberOfCalls(t, "GetStellarToml", 3)
+
+			Convey("and does not cache the failure", func() {
+				mockStellarTomlResolver.On("GetStellarToml", "stellar.org").Return(file, nil).Once()
+
+				response, err := discovery.GetStellarToml("stellar.org")
+				require.NoError(t, err)
+				assert.Equal(t, file, response)
+			})
+		})
+
+		Convey("stops retrying when retry budget is used up", func() {
+			mockStellarTomlResolver.On("GetStellarToml", "stellar.org").Return((*stellartoml.Response)(nil), fetchErr).Twice()
+
+			_, err := discovery.WithContext(net.WithRetryBudget(context.Background(), 1)).GetStellarToml("stellar.org")
+			require.IsType(t, &StellarTomlDiscoveryError{}, err)
+			mockStellarTomlResolver.AssertNumberOfCalls(t, "GetStellarToml", 2)
+			assert.Equal(t, []time.Duration{time.Second}, waits)
+		})
+	})
+}
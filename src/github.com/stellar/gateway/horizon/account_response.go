@@ -1,7 +1,123 @@
 package horizon
 
+import (
+	"fmt"
+
+	"github.com/stellar/go/amount"
+	"github.com/stellar/go/xdr"
+)
+
 // AccountResponse contains account data returned by Horizon
 type AccountResponse struct {
-	AccountID      string `json:"id"`
-	SequenceNumber string `json:"sequence"`
+	AccountID      string       `json:"id"`
+	SequenceNumber string       `json:"sequence"`
+	SubentryCount  int32        `json:"subentry_count"`
+	Balances       []Balance    `json:"balances"`
+	Flags          AccountFlags `json:"flags"`
+	Thresholds     Thresholds   `json:"thresholds"`
+	Signers        []Signer     `json:"signers"`
+	HomeDomain     string       `json:"home_domain"`
+	// Base64 encoded values of data entries
+	Data map[string]string `json:"data"`
+}
+
+// memoRequiredDataKey is a data entry set to "1" by accounts requiring incoming payments to have a memo
+const memoRequiredDataKey = "config.memo_required"
+
+// MemoRequired returns true when the account requires incoming payments to have a memo
+func (a AccountResponse) MemoRequired() bool {
+	// "MQ==" is base64 encoded "1"
+	return a.Data[memoRequiredDataKey] == "MQ=="
+}
+
+// MinimumBalance returns the minimum native balance (in stroops) the account must keep: two base
+// reserves plus one for every subentry (trustline, offer, signer and data entry)
+func (a AccountResponse) MinimumBalance(baseReserve xdr.Int64) xdr.Int64 {
+	return (2 + xdr.Int64(a.SubentryCount)) * baseReserve
+}
+
+// Thresholds contains weights of signatures required by operations of the account
+type Thresholds struct {
+	Low    int32 `json:"low_threshold"`
+	Medium int32 `json:"med_threshold"`
+	High   int32 `json:"high_threshold"`
+}
+
+// Signer contains public key and weight of an account signer. The master key is one of the signers.
+type Signer struct {
+	PublicKey string `json:"public_key"`
+	Weight    int32  `json:"weight"`
+}
+
+// SignerWeight returns weight of the given signer or 0 if it's not a signer of the account
+func (a AccountResponse) SignerWeight(publicKey string) int32 {
+	for _, signer := range a.Signers {
+		if signer.PublicKey == publicKey {
+			return signer.Weight
+		}
+	}
+	return 0
+}
+
+// Balance contains a single balance (trustline) of an account. AssetCode and AssetIssuer are empty
+// for native balance.
+type Balance struct {
+	Balance     string `json:"balance"`
+	Limit       string `json:"limit"`
+	AssetType   string `json:"asset_type"`
+	AssetCode   string `json:"asset_code"`
+	AssetIssuer string `json:"asset_issuer"`
+	// Amount reserved by offers selling this asset, empty when not returned by Horizon
+	SellingLiabilities string `json:"selling_liabilities"`
+	// Amount reserved by offers buying this asset, empty when not returned by Horizon
+	BuyingLiabilities string `json:"buying_liabilities"`
+	// nil when not returned by Horizon
+	IsAuthorized *bool `json:"is_authorized"`
+}
+
+// Capacity returns the amount (in stroops) the trustline can still receive: its limit minus the
+// balance and buying liabilities, never negative. Amounts are subtracted only when smaller than
+// the remaining capacity so the result cannot overflow.
+func (b Balance) Capacity() (xdr.Int64, error) {
+	limit, err := amount.Parse(b.Limit)
+	if err != nil {
+		return 0, err
+	}
+
+	capacity := limit
+	for _, value := range []string{b.Balance, b.BuyingLiabilities} {
+		if value == "" {
+			continue
+		}
+
+		parsed, err := amount.Parse(value)
+		if err != nil {
+			return 0, err
+		}
+		if parsed < 0 {
+			return 0, fmt.Errorf("negative amount: %s", value)
+		}
+
+		if parsed >= capacity {
+			return 0, nil
+		}
+		capacity -= parsed
+	}
+	return capacity, nil
+}
+
+// AccountFlags contains flags of an account
+type AccountFlags struct {
+	AuthRequired  bool `json:"auth_required"`
+	AuthRevocable bool `json:"auth_revocable"`
+}
+
+// Balance returns a balance of the given credit asset or nil when the account does not trust it
+func (a AccountResponse) Balance(code, issuer string) *Balance {
+	for i := range a.Balances {
+		if a.Balances[i].AssetCode == code && a.Balances[i].AssetIssuer == issuer {
+			return &a.Balances[i]
+		}
+	}
+	return nil
 }
@@ -2,6 +2,11 @@ package horizon
 
 // EffectsPageResponse contains page of effects returned by Horizon
 type EffectsPageResponse struct {
+	Links struct {
+		Next struct {
+			Href string `json:"href"`
+		} `json:"next"`
+	} `json:"_links"`
 	Embedded struct {
 		Records []EffectResponse
 	} `json:"_embedded"`
@@ -9,6 +14,13 @@ type EffectsPageResponse struct {
 
 // EffectResponse contains effect data returned by Horizon
 type EffectResponse struct {
-	Type   string `json:"type"`
-	Amount string `json:"amount"`
+	ID          string `json:"id"`
+	Type        string `json:"type"`
+	Account     string `json:"account"`
+	Amount      string `json:"amount"`
+	AssetType   string `json:"asset_type"`
+	AssetCode   string `json:"asset_code"`
+	AssetIssuer string `json:"asset_issuer"`
+	// account_created only
+	StartingBalance string `json:"starting_balance"`
 }
@@ -0,0 +1,28 @@
+package horizon
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// Error is returned when Horizon responds with an error status code. Problem is the raw
+// response body, a problem+json document (type, title, status, detail, extras) or, when the
+// body is not JSON, a JSON string containing it.
+type Error struct {
+	Status  int
+	Problem json.RawMessage
+	body    []byte
+}
+
+// newError creates an Error from the status code and body of a Horizon response
+func newError(status int, body []byte) *Error {
+	problem := json.RawMessage(body)
+	if !json.Valid(body) {
+		problem, _ = json.Marshal(string(body))
+	}
+	return &Error{Status: status, Problem: problem, body: body}
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("StatusCode indicates error: %s", e.body)
+}
@@ -0,0 +1,18 @@
+package horizon
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewError(t *testing.T) {
+	err := newError(500, []byte(`{"type": "server_error", "status": 500}`))
+	assert.Equal(t, 500, err.Status)
+	assert.JSONEq(t, `{"type": "server_error", "status": 500}`, string(err.Problem))
+	assert.Equal(t, `StatusCode indicates error: {"type": "server_error", "status": 500}`, err.Error())
+
+	err = newError(502, []byte("Bad Gateway"))
+	assert.Equal(t, `"Bad Gateway"`, string(err.Problem))
+	assert.Equal(t, "StatusCode indicates error: Bad Gateway", err.Error())
+}
@@ -0,0 +1,53 @@
+package horizon
+
+import (
+	"sync"
+	"time"
+)
+
+// FeeStatsCache caches fee stats loaded from Horizon so clients polling for a fee during congestion
+// don't send a request to Horizon each time. Errors are not cached.
+type FeeStatsCache struct {
+	horizon   HorizonInterface
+	ttl       time.Duration
+	now       func() time.Time
+	stats     FeeStatsResponse
+	expiresAt time.Time
+	mutex     sync.Mutex
+}
+
+// NewFeeStatsCache creates a new FeeStatsCache keeping fee stats for ttl
+func NewFeeStatsCache(horizon HorizonInterface, ttl time.Duration, now func() time.Time) *FeeStatsCache {
+	return &FeeStatsCache{
+		horizon: horizon,
+		ttl:     ttl,
+		now:     now,
+	}
+}
+
+// FeeStats returns fee stats of the last ledgers. They are loaded from Horizon when not cached
+// or expired.
+func (c *FeeStatsCache) FeeStats() (FeeStatsResponse, error) {
+	c.mutex.Lock()
+	stats, expiresAt := c.stats, c.expiresAt
+	c.mutex.Unlock()
+
+	now := c.now()
+	if now.Before(expiresAt) {
+		return stats, nil
+	}
+
+	// Horizon is called without holding the lock, concurrent misses load fee stats twice
+	stats, err := c.horizon.LoadFeeStats()
+	if err != nil {
+		return FeeStatsResponse{}, err
+	}
+
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	c.stats = stats
+	c.expiresAt = now.Add(c.ttl)
+
+	return stats, nil
+}
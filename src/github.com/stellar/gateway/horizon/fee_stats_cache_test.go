@@ -0,0 +1,77 @@
+package horizon
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	. "github.com/smartystreets/goconvey/convey"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFeeStatsCache(t *testing.T) {
+	var requests int
+	var failing bool
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		if r.URL.Path != "/fee_stats" || failing {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			w.Write([]byte(`{"status": 503}`))
+			return
+		}
+		w.Write([]byte(`{
+  "last_ledger": "22606298",
+  "last_ledger_base_fee": "100",
+  "ledger_capacity_usage": "0.97",
+  "fee_charged": {"max": "3000", "min": "100", "mode": "100", "p10": "100", "p50": "200", "p90": "1000", "p99": "3000"},
+  "max_fee": {"max": "10000", "min": "100", "mode": "100", "p10": "100", "p50": "500", "p90": "2000", "p99": "10000"}
+}`))
+	}))
+	defer server.Close()
+
+	h := New(server.URL)
+
+	Convey("FeeStatsCache", t, func() {
+		requests = 0
+		failing = false
+		now := time.Unix(1500000000, 0)
+		cache := NewFeeStatsCache(&h, 5*time.Second, func() time.Time { return now })
+
+		Convey("returns fee stats", func() {
+			stats, err := cache.FeeStats()
+			require.NoError(t, err)
+			assert.Equal(t, "22606298", stats.LastLedger)
+			assert.Equal(t, "100", stats.LastLedgerBaseFee)
+			assert.Equal(t, "0.97", stats.LedgerCapacityUsage)
+			assert.Equal(t, "1000", stats.FeeCharged.P90)
+			assert.Equal(t, "10000", stats.MaxFee.P99)
+		})
+
+		Convey("loads fee stats again after ttl", func() {
+			_, err := cache.FeeStats()
+			require.NoError(t, err)
+			_, err = cache.FeeStats()
+			require.NoError(t, err)
+			assert.Equal(t, 1, requests)
+
+			now = now.Add(5 * time.Second)
+			_, err = cache.FeeStats()
+			require.NoError(t, err)
+			assert.Equal(t, 2, requests)
+		})
+
+		Convey("does not cache errors", func() {
+			failing = true
+			_, err := cache.FeeStats()
+			assert.Error(t, err)
+
+			failing = false
+			stats, err := cache.FeeStats()
+			require.NoError(t, err)
+			assert.Equal(t, "22606298", stats.LastLedger)
+			assert.Equal(t, 2, requests)
+		})
+	})
+}
@@ -0,0 +1,31 @@
+package horizon
+
+// FeeStatsResponse contains fee stats of the last ledgers returned by Horizon /fee_stats endpoint.
+// All values are strings, fees are in stroops.
+type FeeStatsResponse struct {
+	LastLedger          string `json:"last_ledger"`
+	LastLedgerBaseFee   string `json:"last_ledger_base_fee"`
+	LedgerCapacityUsage string `json:"ledger_capacity_usage"`
+	// Fees per operation charged to transactions included in the last ledgers
+	FeeCharged FeeDistribution `json:"fee_charged"`
+	// Maximum fees per operation transactions included in the last ledgers were willing to pay
+	MaxFee FeeDistribution `json:"max_fee"`
+}
+
+// FeeDistribution contains percentiles of fees per operation
+type FeeDistribution struct {
+	Max  string `json:"max"`
+	Min  string `json:"min"`
+	Mode string `json:"mode"`
+	P10  string `json:"p10"`
+	P20  string `json:"p20"`
+	P30  string `json:"p30"`
+	P40  string `json:"p40"`
+	P50  string `json:"p50"`
+	P60  string `json:"p60"`
+	P70  string `json:"p70"`
+	P80  string `json:"p80"`
+	P90  string `json:"p90"`
+	P95  string `json:"p95"`
+	P99  string `json:"p99"`
+}
@@ -0,0 +1,11 @@
+package horizon
+
+import "github.com/stellar/go/xdr"
+
+// LedgerResponse contains ledger data returned by Horizon
+type LedgerResponse struct {
+	Sequence int32 `json:"sequence"`
+	// Minimum fee per operation
+	BaseFeeInStroops     xdr.Int64 `json:"base_fee_in_stroops"`
+	BaseReserveInStroops xdr.Int64 `json:"base_reserve_in_stroops"`
+}
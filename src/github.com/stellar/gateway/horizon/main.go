@@ -2,30 +2,39 @@ package horizon
 
 import (
 	"bufio"
+	"context"
 	"encoding/base64"
 	"encoding/json"
-	"fmt"
 	"github.com/sirupsen/logrus"
 	"io"
 	"io/ioutil"
 	"net/http"
 	"net/url"
+	"strconv"
 	"strings"
 	"time"
 
+	"github.com/stellar/gateway/net"
 	"github.com/stellar/go/support/errors"
 	"github.com/stellar/go/xdr"
 )
 
+// ErrAccountNotFound is returned by LoadAccount when the account does not exist
+var ErrAccountNotFound = errors.New("Account not found")
+
 // PaymentHandler is a function that is called when a new payment is received
 type PaymentHandler func(PaymentResponse) error
 
 // HorizonInterface allows mocking Horizon struct object
 type HorizonInterface interface {
 	LoadAccount(accountID string) (response AccountResponse, err error)
+	LoadFeeStats() (response FeeStatsResponse, err error)
+	LoadLatestLedger() (response LedgerResponse, err error)
 	LoadMemo(p *PaymentResponse) (err error)
 	LoadAccountMergeAmount(p *PaymentResponse) error
 	LoadOperation(operationID string) (response PaymentResponse, err error)
+	LoadTransaction(hash string) (response *TransactionResponse, err error)
+	LoadTransactionEffects(hash string) (effects []EffectResponse, err error)
 	StreamPayments(accountID string, cursor *string, onPaymentHandler PaymentHandler) (err error)
 	SubmitTransaction(txeBase64 string) (response SubmitTransactionResponse, err error)
 }
@@ -33,11 +42,22 @@ type HorizonInterface interface {
 // Horizon implements methods to get (or submit) data from Horizon server
 type Horizon struct {
 	ServerURL string
-	log       *logrus.Entry
+	// Transport used by all requests to Horizon server. http.DefaultTransport is used when nil.
+	Transport http.RoundTripper
+	// Maximum time requests rate limited by Horizon (429) are retried for. RateLimitedError is
+	// returned when the time in `Retry-After` header would exceed it. Zero means no retries.
+	MaxRetryWait time.Duration
+	// Requests to Horizon server, including waits before retries, are cancelled when Context is done
+	// (see WithContext). Nil means they are never cancelled.
+	Context context.Context
+	log     *logrus.Entry
 }
 
 const submitTimeout = 60 * time.Second
 
+// effectsPageLimit is a number of effects loaded in a single request, maximum allowed by Horizon
+const effectsPageLimit = 200
+
 // New creates a new Horizon instance
 func New(serverURL string) (horizon Horizon) {
 	horizon.ServerURL = serverURL
@@ -47,12 +67,39 @@ func New(serverURL string) (horizon Horizon) {
 	return
 }
 
+// client returns http.Client using Horizon transport. Zero timeout means no timeout.
+// Requests rate limited by Horizon are retried (see MaxRetryWait) within the retry budget of Context.
+func (h *Horizon) client(timeout time.Duration) *http.Client {
+	transport := h.Transport
+	if transport == nil {
+		transport = http.DefaultTransport
+	}
+
+	var roundTripper http.RoundTripper = rateLimitTransport{transport: transport, maxWait: h.MaxRetryWait, ctx: h.Context}
+	if h.Context != nil {
+		roundTripper = net.NewContextTransport(roundTripper, h.Context)
+	}
+
+	return &http.Client{
+		Transport: roundTripper,
+		Timeout:   timeout,
+	}
+}
+
+// WithContext returns a copy of h sending requests cancelled when ctx is done, ex. requests made
+// on behalf of a client request
+func (h *Horizon) WithContext(ctx context.Context) *Horizon {
+	contextHorizon := *h
+	contextHorizon.Context = ctx
+	return &contextHorizon
+}
+
 // LoadAccount loads a single account from Horizon server
 func (h *Horizon) LoadAccount(accountID string) (response AccountResponse, err error) {
 	h.log.WithFields(logrus.Fields{
 		"accountID": accountID,
 	}).Info("Loading account")
-	resp, err := http.Get(h.ServerURL + "/accounts/" + accountID)
+	resp, err := h.client(0).Get(h.ServerURL + "/accounts/" + accountID)
 	if err != nil {
 		return
 	}
@@ -63,11 +110,16 @@ func (h *Horizon) LoadAccount(accountID string) (response AccountResponse, err e
 		return
 	}
 
-	if resp.StatusCode != 200 {
+	if resp.StatusCode == http.StatusNotFound {
 		h.log.WithFields(logrus.Fields{
 			"accountID": accountID,
 		}).Info("Account does not exist")
-		err = fmt.Errorf("StatusCode indicates error: %s", body)
+		err = ErrAccountNotFound
+		return
+	}
+
+	if resp.StatusCode != 200 {
+		err = newError(resp.StatusCode, body)
 		return
 	}
 
@@ -82,12 +134,92 @@ func (h *Horizon) LoadAccount(accountID string) (response AccountResponse, err e
 	return
 }
 
+// LoadRoot loads Horizon root endpoint containing the network passphrase of the Horizon server
+func (h *Horizon) LoadRoot() (response RootResponse, err error) {
+	resp, err := h.client(0).Get(h.ServerURL + "/")
+	if err != nil {
+		return
+	}
+
+	defer resp.Body.Close()
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return
+	}
+
+	if resp.StatusCode != 200 {
+		err = newError(resp.StatusCode, body)
+		return
+	}
+
+	err = json.Unmarshal(body, &response)
+	return
+}
+
+// LoadLatestLedger loads the latest ledger from Horizon server
+func (h *Horizon) LoadLatestLedger() (response LedgerResponse, err error) {
+	resp, err := h.client(0).Get(h.ServerURL + "/ledgers?order=desc&limit=1")
+	if err != nil {
+		return
+	}
+
+	defer resp.Body.Close()
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return
+	}
+
+	if resp.StatusCode != 200 {
+		err = newError(resp.StatusCode, body)
+		return
+	}
+
+	var page struct {
+		Embedded struct {
+			Records []LedgerResponse `json:"records"`
+		} `json:"_embedded"`
+	}
+	err = json.Unmarshal(body, &page)
+	if err != nil {
+		return
+	}
+
+	if len(page.Embedded.Records) == 0 {
+		err = errors.New("No ledgers found")
+		return
+	}
+
+	return page.Embedded.Records[0], nil
+}
+
+// LoadFeeStats loads fee stats of the last ledgers from Horizon server
+func (h *Horizon) LoadFeeStats() (response FeeStatsResponse, err error) {
+	resp, err := h.client(0).Get(h.ServerURL + "/fee_stats")
+	if err != nil {
+		return
+	}
+
+	defer resp.Body.Close()
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return
+	}
+
+	if resp.StatusCode != 200 {
+		err = newError(resp.StatusCode, body)
+		return
+	}
+
+	err = json.Unmarshal(body, &response)
+	return
+}
+
 // LoadOperation loads a single operation from Horizon server
 func (h *Horizon) LoadOperation(operationID string) (response PaymentResponse, err error) {
 	h.log.WithFields(logrus.Fields{
 		"operationID": operationID,
 	}).Info("Loading operation")
-	resp, err := http.Get(h.ServerURL + "/operations/" + operationID)
+	resp, err := h.client(0).Get(h.ServerURL + "/operations/" + operationID)
 	if err != nil {
 		return
 	}
@@ -102,7 +234,7 @@ func (h *Horizon) LoadOperation(operationID string) (response PaymentResponse, e
 		h.log.WithFields(logrus.Fields{
 			"operationID": operationID,
 		}).Error("Operation does not exist")
-		err = fmt.Errorf("StatusCode indicates error: %s", body)
+		err = newError(resp.StatusCode, body)
 		return
 	}
 
@@ -117,9 +249,113 @@ func (h *Horizon) LoadOperation(operationID string) (response PaymentResponse, e
 	return
 }
 
+// LoadTransaction loads a single transaction from Horizon server. Returns nil response when
+// transaction with a given hash has not been included in the ledger.
+func (h *Horizon) LoadTransaction(hash string) (response *TransactionResponse, err error) {
+	h.log.WithFields(logrus.Fields{
+		"hash": hash,
+	}).Info("Loading transaction")
+	resp, err := h.client(0).Get(h.ServerURL + "/transactions/" + hash)
+	if err != nil {
+		return
+	}
+
+	defer resp.Body.Close()
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return
+	}
+
+	if resp.StatusCode == http.StatusNotFound {
+		h.log.WithFields(logrus.Fields{
+			"hash": hash,
+		}).Info("Transaction does not exist")
+		return
+	}
+
+	if resp.StatusCode != 200 {
+		err = newError(resp.StatusCode, body)
+		return
+	}
+
+	response = &TransactionResponse{}
+	err = json.Unmarshal(body, response)
+	if err != nil {
+		response = nil
+		return
+	}
+
+	h.log.WithFields(logrus.Fields{
+		"hash": hash,
+	}).Info("Transaction loaded")
+	return
+}
+
+// LoadTransactionEffects loads all effects of a transaction from Horizon server following next pages.
+// Returns nil effects when transaction with a given hash has not been included in the ledger.
+func (h *Horizon) LoadTransactionEffects(hash string) (effects []EffectResponse, err error) {
+	h.log.WithFields(logrus.Fields{
+		"hash": hash,
+	}).Info("Loading transaction effects")
+
+	effects = []EffectResponse{}
+	next := h.ServerURL + "/transactions/" + hash + "/effects?order=asc&limit=" + strconv.Itoa(effectsPageLimit)
+	for next != "" {
+		var page EffectsPageResponse
+		found, err := h.loadEffectsPage(next, &page)
+		if err != nil {
+			return nil, err
+		}
+
+		if !found {
+			h.log.WithFields(logrus.Fields{
+				"hash": hash,
+			}).Info("Transaction does not exist")
+			return nil, nil
+		}
+
+		effects = append(effects, page.Embedded.Records...)
+
+		// Last page is not full, no need to load an empty one
+		next = ""
+		if len(page.Embedded.Records) == effectsPageLimit {
+			next = page.Links.Next.Href
+		}
+	}
+
+	h.log.WithFields(logrus.Fields{
+		"hash":    hash,
+		"effects": len(effects),
+	}).Info("Transaction effects loaded")
+	return effects, nil
+}
+
+func (h *Horizon) loadEffectsPage(url string, page *EffectsPageResponse) (found bool, err error) {
+	resp, err := h.client(0).Get(url)
+	if err != nil {
+		return false, err
+	}
+
+	defer resp.Body.Close()
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return false, err
+	}
+
+	if resp.StatusCode == http.StatusNotFound {
+		return false, nil
+	}
+
+	if resp.StatusCode != 200 {
+		return false, newError(resp.StatusCode, body)
+	}
+
+	return true, json.Unmarshal(body, page)
+}
+
 // LoadMemo loads memo for a transaction in PaymentResponse
 func (h *Horizon) LoadMemo(p *PaymentResponse) (err error) {
-	res, err := http.Get(p.Links.Transaction.Href)
+	res, err := h.client(0).Get(p.Links.Transaction.Href)
 	if err != nil {
 		return err
 	}
@@ -127,13 +363,23 @@ func (h *Horizon) LoadMemo(p *PaymentResponse) (err error) {
 	return json.NewDecoder(res.Body).Decode(&p.Memo)
 }
 
+// HomeDomainForAccount returns home domain of the account. It implements federation.Horizon
+// used for reverse federation lookups.
+func (h *Horizon) HomeDomainForAccount(accountID string) (string, error) {
+	account, err := h.LoadAccount(accountID)
+	if err != nil {
+		return "", err
+	}
+	return account.HomeDomain, nil
+}
+
 // LoadAccountMergeAmount loads `account_merge` operation amount from it's effects
 func (h *Horizon) LoadAccountMergeAmount(p *PaymentResponse) error {
 	if p.Type != "account_merge" {
 		return errors.New("Not `account_merge` operation")
 	}
 
-	res, err := http.Get(p.Links.Effects.Href)
+	res, err := h.client(0).Get(p.Links.Effects.Href)
 	if err != nil {
 		return errors.Wrap(err, "Error getting effects for operation")
 	}
@@ -167,8 +413,7 @@ func (h *Horizon) StreamPayments(accountID string, cursor *string, onPaymentHand
 	}
 	req.Header.Set("Accept", "text/event-stream")
 
-	client := &http.Client{}
-	resp, err := client.Do(req)
+	resp, err := h.client(0).Do(req)
 	if err != nil {
 		return err
 	}
@@ -226,10 +471,7 @@ func (h *Horizon) SubmitTransaction(txeBase64 string) (response SubmitTransactio
 	v := url.Values{}
 	v.Set("tx", txeBase64)
 
-	client := http.Client{
-		Timeout: submitTimeout,
-	}
-	resp, err := client.PostForm(h.ServerURL+"/transactions", v)
+	resp, err := h.client(submitTimeout).PostForm(h.ServerURL+"/transactions", v)
 	if err != nil {
 		return
 	}
@@ -248,15 +490,24 @@ func (h *Horizon) SubmitTransaction(txeBase64 string) (response SubmitTransactio
 		return
 	}
 
+	if resp.StatusCode >= 400 {
+		response.RawError = body
+	}
+
 	if response.Ledger != nil {
 		h.log.WithFields(logrus.Fields{
 			"ledger": *response.Ledger,
 		}).Info("Success response from horizon")
-	} else {
+	} else if response.Extras != nil {
 		h.log.WithFields(logrus.Fields{
 			"envelope": response.Extras.EnvelopeXdr,
 			"result":   response.Extras.ResultXdr,
 		}).Info("Error response from horizon")
+	} else {
+		// ex. timeout, the transaction can still be included in a ledger
+		h.log.WithFields(logrus.Fields{
+			"status": resp.StatusCode,
+		}).Info("Transaction result unknown")
 	}
 
 	return
@@ -0,0 +1,67 @@
+package horizon
+
+import (
+	"sync"
+	"time"
+)
+
+type memoRequiredEntry struct {
+	required  bool
+	expiresAt time.Time
+}
+
+// MemoRequiredCache caches whether accounts require incoming payments to have a memo so the
+// destination account is not loaded before every payment. Accounts that cannot be loaded are
+// not cached.
+type MemoRequiredCache struct {
+	horizon HorizonInterface
+	ttl     time.Duration
+	now     func() time.Time
+	entries map[string]memoRequiredEntry
+	mutex   sync.Mutex
+}
+
+// NewMemoRequiredCache creates a new MemoRequiredCache keeping entries for ttl
+func NewMemoRequiredCache(horizon HorizonInterface, ttl time.Duration, now func() time.Time) *MemoRequiredCache {
+	return &MemoRequiredCache{
+		horizon: horizon,
+		ttl:     ttl,
+		now:     now,
+		entries: make(map[string]memoRequiredEntry),
+	}
+}
+
+// MemoRequired returns true when the account requires incoming payments to have a memo.
+// The account is loaded from Horizon when it's not cached or its entry expired.
+func (c *MemoRequiredCache) MemoRequired(accountID string) (bool, error) {
+	c.mutex.Lock()
+	entry, ok := c.entries[accountID]
+	c.mutex.Unlock()
+
+	now := c.now()
+	if ok && now.Before(entry.expiresAt) {
+		return entry.required, nil
+	}
+
+	// Horizon is called without holding the lock, concurrent misses load the account twice
+	account, err := c.horizon.LoadAccount(accountID)
+	if err != nil {
+		return false, err
+	}
+
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	for id, entry := range c.entries {
+		if !now.Before(entry.expiresAt) {
+			delete(c.entries, id)
+		}
+	}
+
+	c.entries[accountID] = memoRequiredEntry{
+		required:  account.MemoRequired(),
+		expiresAt: now.Add(c.ttl),
+	}
+
+	return account.MemoRequired(), nil
+}
@@ -0,0 +1,68 @@
+package horizon
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	. "github.com/smartystreets/goconvey/convey"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMemoRequiredCache(t *testing.T) {
+	var requests map[string]int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests[r.URL.Path]++
+		switch r.URL.Path {
+		case "/accounts/GREQUIRED":
+			w.Write([]byte(`{"id": "GREQUIRED", "data": {"config.memo_required": "MQ=="}}`))
+		case "/accounts/GNOTREQUIRED":
+			w.Write([]byte(`{"id": "GNOTREQUIRED", "data": {"config.memo_required": "MA=="}}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(`{"status": 404}`))
+		}
+	}))
+	defer server.Close()
+
+	h := New(server.URL)
+
+	Convey("MemoRequiredCache", t, func() {
+		requests = map[string]int{}
+		now := time.Unix(1500000000, 0)
+		cache := NewMemoRequiredCache(&h, time.Minute, func() time.Time { return now })
+
+		Convey("returns requirement of the account", func() {
+			required, err := cache.MemoRequired("GREQUIRED")
+			require.NoError(t, err)
+			assert.True(t, required)
+
+			required, err = cache.MemoRequired("GNOTREQUIRED")
+			require.NoError(t, err)
+			assert.False(t, required)
+		})
+
+		Convey("loads the account again after ttl", func() {
+			_, err := cache.MemoRequired("GREQUIRED")
+			require.NoError(t, err)
+			_, err = cache.MemoRequired("GREQUIRED")
+			require.NoError(t, err)
+			assert.Equal(t, 1, requests["/accounts/GREQUIRED"])
+
+			now = now.Add(time.Minute)
+			_, err = cache.MemoRequired("GREQUIRED")
+			require.NoError(t, err)
+			assert.Equal(t, 2, requests["/accounts/GREQUIRED"])
+		})
+
+		Convey("does not cache errors", func() {
+			_, err := cache.MemoRequired("GMISSING")
+			assert.Error(t, err)
+			_, err = cache.MemoRequired("GMISSING")
+			assert.Error(t, err)
+			assert.Equal(t, 2, requests["/accounts/GMISSING"])
+		})
+	})
+}
@@ -0,0 +1,81 @@
+package horizon
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/stellar/gateway/net"
+)
+
+// defaultRetryAfter is used when 429 response has no valid `Retry-After` header
+const defaultRetryAfter = time.Second
+
+// RateLimitedError is returned when Horizon responds with 429 status and waiting for the time
+// in its `Retry-After` header would exceed MaxRetryWait
+type RateLimitedError struct {
+	RetryAfter time.Duration
+}
+
+func (e *RateLimitedError) Error() string {
+	return "Horizon rate limit exceeded, retry after " + e.RetryAfter.String()
+}
+
+// rateLimitTransport retries requests rate limited by Horizon after the time in `Retry-After` header
+// as long as the total wait does not exceed maxWait and the retry budget of ctx allows it (see
+// net.TakeRetry)
+type rateLimitTransport struct {
+	transport http.RoundTripper
+	maxWait   time.Duration
+	ctx       context.Context
+}
+
+func (t rateLimitTransport) RoundTrip(r *http.Request) (*http.Response, error) {
+	var waited time.Duration
+	for {
+		resp, err := t.transport.RoundTrip(r)
+		if err != nil || resp.StatusCode != http.StatusTooManyRequests {
+			return resp, err
+		}
+		resp.Body.Close()
+
+		retryAfter := parseRetryAfter(resp.Header.Get("Retry-After"), time.Now())
+		// Request body cannot be sent again without GetBody
+		if waited+retryAfter > t.maxWait || (r.Body != nil && r.GetBody == nil) || !net.TakeRetry(t.ctx, retryAfter) {
+			return nil, &RateLimitedError{RetryAfter: retryAfter}
+		}
+
+		select {
+		case <-time.After(retryAfter):
+		case <-r.Context().Done():
+			return nil, r.Context().Err()
+		}
+		waited += retryAfter
+
+		if r.GetBody != nil {
+			body, err := r.GetBody()
+			if err != nil {
+				return nil, err
+			}
+			r = r.Clone(r.Context())
+			r.Body = body
+		}
+	}
+}
+
+// parseRetryAfter parses `Retry-After` header value which is a number of seconds or an HTTP date
+func parseRetryAfter(value string, now time.Time) time.Duration {
+	if seconds, err := strconv.Atoi(value); err == nil && seconds >= 0 {
+		return time.Duration(seconds) * time.Second
+	}
+
+	if date, err := http.ParseTime(value); err == nil {
+		if date.Before(now) {
+			return 0
+		}
+		return date.Sub(now)
+	}
+
+	return defaultRetryAfter
+}
@@ -0,0 +1,89 @@
+package horizon
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	. "github.com/smartystreets/goconvey/convey"
+	"github.com/stellar/gateway/net"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRateLimitTransport(t *testing.T) {
+	requests := 0
+	retryAfter := "0"
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		if requests == 1 {
+			w.Header().Set("Retry-After", retryAfter)
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+		r.ParseForm()
+		w.Write([]byte(r.PostForm.Get("tx")))
+	}))
+	defer server.Close()
+
+	h := New(server.URL)
+	h.MaxRetryWait = time.Second
+
+	Convey("rateLimitTransport", t, func() {
+		requests = 0
+
+		Convey("retries when Retry-After is within max wait", func() {
+			retryAfter = "0"
+
+			resp, err := h.client(0).PostForm(server.URL, url.Values{"tx": {"envelope"}})
+			require.NoError(t, err)
+			defer resp.Body.Close()
+			assert.Equal(t, http.StatusOK, resp.StatusCode)
+			assert.Equal(t, 2, requests)
+
+			body, err := ioutil.ReadAll(resp.Body)
+			require.NoError(t, err)
+			assert.Equal(t, "envelope", string(body), "body must be sent again")
+		})
+
+		Convey("returns error when Retry-After exceeds max wait", func() {
+			retryAfter = "30"
+
+			_, err := h.client(0).Get(server.URL)
+			require.Error(t, err)
+			assert.Equal(t, 1, requests)
+
+			rateLimited, ok := err.(*url.Error).Err.(*RateLimitedError)
+			require.True(t, ok)
+			assert.Equal(t, 30*time.Second, rateLimited.RetryAfter)
+		})
+
+		Convey("returns error when retry budget is used up", func() {
+			retryAfter = "0"
+			ctx := net.WithRetryBudget(context.Background(), 0)
+
+			_, err := h.WithContext(ctx).client(0).Get(server.URL)
+			require.Error(t, err)
+			assert.Equal(t, 1, requests)
+
+			_, ok := err.(*url.Error).Err.(*RateLimitedError)
+			assert.True(t, ok)
+		})
+	})
+}
+
+func TestParseRetryAfter(t *testing.T) {
+	now := time.Date(2018, 3, 1, 12, 0, 0, 0, time.UTC)
+
+	Convey("parseRetryAfter", t, func() {
+		assert.Equal(t, 5*time.Second, parseRetryAfter("5", now))
+		assert.Equal(t, 10*time.Second, parseRetryAfter("Thu, 01 Mar 2018 12:00:10 GMT", now))
+		assert.Equal(t, time.Duration(0), parseRetryAfter("Thu, 01 Mar 2018 11:00:00 GMT", now))
+		assert.Equal(t, defaultRetryAfter, parseRetryAfter("", now))
+		assert.Equal(t, defaultRetryAfter, parseRetryAfter("soon", now))
+	})
+}
@@ -0,0 +1,8 @@
+package horizon
+
+// RootResponse contains data returned by Horizon root endpoint
+type RootResponse struct {
+	HorizonVersion    string `json:"horizon_version"`
+	CoreVersion       string `json:"core_version"`
+	NetworkPassphrase string `json:"network_passphrase"`
+}
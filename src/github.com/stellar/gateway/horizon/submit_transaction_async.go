@@ -0,0 +1,85 @@
+package horizon
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/url"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// Statuses of transactions submitted to Horizon async submission endpoint
+const (
+	AsyncStatusPending       = "PENDING"
+	AsyncStatusDuplicate     = "DUPLICATE"
+	AsyncStatusTryAgainLater = "TRY_AGAIN_LATER"
+	AsyncStatusError         = "ERROR"
+)
+
+// asyncTryAgainLaterWait is the time clients are asked to wait when Stellar Core is not accepting
+// transactions (TRY_AGAIN_LATER status)
+const asyncTryAgainLaterWait = time.Second
+
+// asyncSubmitTransactionResponse is a body of Horizon `POST /transactions_async` response
+type asyncSubmitTransactionResponse struct {
+	Status         string `json:"tx_status"`
+	Hash           string `json:"hash"`
+	ErrorResultXdr string `json:"errorResultXdr"`
+}
+
+// SubmitTransactionAsync submits a transaction to Horizon async submission endpoint which responds
+// as soon as Stellar Core accepts the transaction, before it's included in a ledger. Accepted
+// transactions are returned with Status set and without Ledger, rejected ones with Extras like
+// SubmitTransaction responses. RateLimitedError is returned when Stellar Core asks to try again later.
+func (h *Horizon) SubmitTransactionAsync(txeBase64 string) (response SubmitTransactionResponse, err error) {
+	resp, err := h.client(submitTimeout).PostForm(h.ServerURL+"/transactions_async", url.Values{"tx": {txeBase64}})
+	if err != nil {
+		return
+	}
+
+	defer resp.Body.Close()
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return
+	}
+
+	var asyncResponse asyncSubmitTransactionResponse
+	err = json.Unmarshal(body, &asyncResponse)
+	if err != nil {
+		h.log.WithFields(logrus.Fields{
+			"status": resp.StatusCode,
+			"body":   string(body),
+		}).Error("Cannot unmarshal horizon async submission response")
+		err = fmt.Errorf("Invalid horizon async submission response (status %d)", resp.StatusCode)
+		return
+	}
+
+	response.Hash = asyncResponse.Hash
+	if resp.StatusCode >= 400 {
+		response.RawError = body
+	}
+
+	switch asyncResponse.Status {
+	case AsyncStatusPending, AsyncStatusDuplicate:
+		response.Status = asyncResponse.Status
+	case AsyncStatusError:
+		response.Extras = &SubmitTransactionResponseExtras{
+			EnvelopeXdr: txeBase64,
+			ResultXdr:   asyncResponse.ErrorResultXdr,
+		}
+	case AsyncStatusTryAgainLater:
+		err = &RateLimitedError{RetryAfter: asyncTryAgainLaterWait}
+		return
+	default:
+		err = fmt.Errorf("Unknown horizon async submission status %q (status %d)", asyncResponse.Status, resp.StatusCode)
+		return
+	}
+
+	h.log.WithFields(logrus.Fields{
+		"hash":      asyncResponse.Hash,
+		"tx_status": asyncResponse.Status,
+	}).Info("Transaction submitted to horizon async endpoint")
+	return
+}
@@ -0,0 +1,78 @@
+package horizon
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSubmitTransactionAsync(t *testing.T) {
+	var status int
+	var body string
+	var path, tx string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		r.ParseForm()
+		path = r.URL.Path
+		tx = r.PostForm.Get("tx")
+		w.WriteHeader(status)
+		w.Write([]byte(body))
+	}))
+	defer server.Close()
+
+	h := New(server.URL)
+
+	Convey("SubmitTransactionAsync", t, func() {
+		Convey("returns pending transaction", func() {
+			status, body = 201, `{"tx_status": "PENDING", "hash": "abc"}`
+
+			response, err := h.SubmitTransactionAsync("envelope")
+			require.NoError(t, err)
+			assert.Equal(t, "/transactions_async", path)
+			assert.Equal(t, "envelope", tx)
+			assert.Equal(t, "abc", response.Hash)
+			assert.Equal(t, AsyncStatusPending, response.Status)
+			assert.Nil(t, response.Ledger)
+			assert.Nil(t, response.Extras)
+			assert.Equal(t, 202, response.HTTPStatus())
+		})
+
+		Convey("returns duplicate transaction", func() {
+			status, body = 409, `{"tx_status": "DUPLICATE", "hash": "abc"}`
+
+			response, err := h.SubmitTransactionAsync("envelope")
+			require.NoError(t, err)
+			assert.Equal(t, AsyncStatusDuplicate, response.Status)
+		})
+
+		Convey("returns error result in extras", func() {
+			status, body = 400, `{"tx_status": "ERROR", "hash": "abc", "errorResultXdr": "AAAAAAAAAGT////7AAAAAA=="}`
+
+			response, err := h.SubmitTransactionAsync("envelope")
+			require.NoError(t, err)
+			assert.Equal(t, "", response.Status)
+			require.NotNil(t, response.Extras)
+			assert.Equal(t, "envelope", response.Extras.EnvelopeXdr)
+			assert.Equal(t, "AAAAAAAAAGT////7AAAAAA==", response.Extras.ResultXdr)
+			assert.Equal(t, 200, response.HTTPStatus())
+		})
+
+		Convey("returns RateLimitedError when Stellar Core asks to try again later", func() {
+			status, body = 503, `{"tx_status": "TRY_AGAIN_LATER", "hash": "abc"}`
+
+			_, err := h.SubmitTransactionAsync("envelope")
+			require.Error(t, err)
+			assert.IsType(t, &RateLimitedError{}, err)
+		})
+
+		Convey("returns error on unexpected response", func() {
+			status, body = 502, "Bad Gateway"
+
+			_, err := h.SubmitTransactionAsync("envelope")
+			assert.EqualError(t, err, "Invalid horizon async submission response (status 502)")
+		})
+	})
+}
@@ -6,15 +6,40 @@ import (
 
 // SubmitTransactionResponse contains result of submitting transaction to Stellar network
 type SubmitTransactionResponse struct {
-	Hash       string                           `json:"hash,omitempty"`
-	SendAmount string                           `json:"send_amount,omitempty"` // Path payment only.
-	ResultXdr  *string                          `json:"result_xdr,omitempty"`  // Only success response.
-	Ledger     *uint64                          `json:"ledger"`
-	Extras     *SubmitTransactionResponseExtras `json:"extras,omitempty"`
+	Hash          string                           `json:"hash,omitempty"`
+	SendAmount    string                           `json:"send_amount,omitempty"`     // Path payment only.
+	ResultXdr     *string                          `json:"result_xdr,omitempty"`      // Only success response.
+	ResultMetaXdr *string                          `json:"result_meta_xdr,omitempty"` // Only success response.
+	Ledger        *uint64                          `json:"ledger"`
+	Extras        *SubmitTransactionResponseExtras `json:"extras,omitempty"`
+	// Only async submission: status of the transaction accepted by Stellar Core (PENDING or DUPLICATE)
+	Status string `json:"tx_status,omitempty"`
+	// Sequence number consumed by the transaction, the current sequence number of the source account
+	// once the transaction is applied. Only responses of transactions accepted by the network.
+	SourceSequence string `json:"source_sequence,omitempty"`
+	// Amount of the payment sent by /payment formatted using `display_decimals` of the asset
+	Amount string `json:"amount,omitempty"`
+	// Amount of the payment sent by /payment in stroops
+	AmountStroops string `json:"amount_stroops,omitempty"`
+	// Type of the memo attached to the payment sent by /payment (`id`, `text`, `hash` or `return`)
+	MemoType string `json:"memo_type,omitempty"`
+	// Value of the memo attached to the payment sent by /payment, `hash` and `return` memos are hex encoded
+	Memo string `json:"memo,omitempty"`
+	// Fee charged for the transaction formatted using `display_decimals` of XLM. Only responses
+	// containing result_xdr.
+	Fee string `json:"fee,omitempty"`
+	// Fee charged for the transaction in stroops
+	FeeStroops string `json:"fee_stroops,omitempty"`
+	// Raw body of Horizon error response (problem+json document), not returned to clients
+	RawError json.RawMessage `json:"-"`
 }
 
-// HTTPStatus implements protocols.SuccessResponse interface
+// HTTPStatus implements protocols.SuccessResponse interface. Transactions accepted by async
+// submission but not yet included in a ledger are returned with 202 status.
 func (response *SubmitTransactionResponse) HTTPStatus() int {
+	if response.Ledger == nil && response.Status != "" {
+		return 202
+	}
 	return 200
 }
 
@@ -0,0 +1,12 @@
+package horizon
+
+// TransactionResponse contains a single transaction returned by Horizon
+type TransactionResponse struct {
+	Hash          string `json:"hash"`
+	Ledger        uint64 `json:"ledger"`
+	EnvelopeXdr   string `json:"envelope_xdr"`
+	ResultXdr     string `json:"result_xdr"`
+	ResultMetaXdr string `json:"result_meta_xdr"`
+	// False for transactions failed in a ledger, nil when Horizon returns successful transactions only
+	Successful *bool `json:"successful"`
+}
@@ -0,0 +1,81 @@
+package listener
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"strconv"
+	"time"
+
+	"github.com/stellar/go/strkey"
+	"github.com/stellar/go/support/errors"
+)
+
+var (
+	// ErrInvalidMAC is returned by VerifyMAC when the MAC does not match the payload
+	ErrInvalidMAC = errors.New("invalid MAC")
+	// ErrMACTimestampOutsideWindow is returned by VerifyMAC when the payload timestamp
+	// is too far from the current time, ex. when a captured notification is replayed
+	ErrMACTimestampOutsideWindow = errors.New("MAC timestamp outside of window")
+)
+
+// VerifyMAC verifies `X_PAYLOAD_MAC` (encodedMAC) and `X_PAYLOAD_TIMESTAMP` (timestamp) headers
+// of a notification sent by the bridge server with the given `mac_key`. The bridge server only signs
+// notifications, receivers enforce the window: when it's not zero (notifications are sent with
+// `sign_timestamps` enabled) the timestamp is part of the signed payload and it must be within
+// window from now.
+func VerifyMAC(key string, body []byte, encodedMAC, timestamp string, window time.Duration, now time.Time) error {
+	rawMAC, err := base64.StdEncoding.DecodeString(encodedMAC)
+	if err != nil {
+		return ErrInvalidMAC
+	}
+
+	payload := body
+	if window > 0 {
+		payload = timestampedPayload(timestamp, body)
+	}
+
+	expected, err := payloadMAC(key, payload)
+	if err != nil {
+		return err
+	}
+
+	if !hmac.Equal(rawMAC, expected) {
+		return ErrInvalidMAC
+	}
+
+	if window > 0 {
+		unix, err := strconv.ParseInt(timestamp, 10, 64)
+		if err != nil {
+			return ErrInvalidMAC
+		}
+
+		diff := now.Sub(time.Unix(unix, 0))
+		if diff > window || diff < -window {
+			return ErrMACTimestampOutsideWindow
+		}
+	}
+
+	return nil
+}
+
+// timestampedPayload returns payload signed when `sign_timestamps` is set: unix timestamp and body
+// separated with a dot
+func timestampedPayload(timestamp string, body []byte) []byte {
+	payload := make([]byte, 0, len(timestamp)+1+len(body))
+	payload = append(payload, timestamp...)
+	payload = append(payload, '.')
+	return append(payload, body...)
+}
+
+// payloadMAC returns HMAC-SHA256 of raw using decoded key (secret seed)
+func payloadMAC(key string, raw []byte) ([]byte, error) {
+	rawkey, err := strkey.Decode(strkey.VersionByteSeed, key)
+	if err != nil {
+		return nil, errors.Wrap(err, "invalid MAC key")
+	}
+
+	macer := hmac.New(sha256.New, rawkey)
+	macer.Write(raw)
+	return macer.Sum(nil), nil
+}
@@ -0,0 +1,47 @@
+package listener
+
+import (
+	"encoding/base64"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestVerifyMAC(t *testing.T) {
+	key := "SABLR5HOI2IUOYB27TR4TO7HWDJIGSRJTT4UUTXXZOFVVPGQKJ5ME43J"
+	body := []byte("foo=base")
+	now := time.Unix(1500000000, 0)
+	window := 5 * time.Minute
+
+	rawMAC, err := payloadMAC(key, body)
+	require.NoError(t, err)
+	mac := base64.StdEncoding.EncodeToString(rawMAC)
+
+	rawMAC, err = payloadMAC(key, timestampedPayload("1500000000", body))
+	require.NoError(t, err)
+	timestampedMAC := base64.StdEncoding.EncodeToString(rawMAC)
+
+	// body only MAC when window is not set
+	assert.NoError(t, VerifyMAC(key, body, mac, "", 0, now))
+	assert.Equal(t, ErrInvalidMAC, VerifyMAC(key, []byte("foo=bar"), mac, "", 0, now))
+	assert.Equal(t, ErrInvalidMAC, VerifyMAC(key, body, "not base64", "", 0, now))
+
+	// timestamp is signed when window is set
+	assert.NoError(t, VerifyMAC(key, body, timestampedMAC, "1500000000", window, now))
+	assert.NoError(t, VerifyMAC(key, body, timestampedMAC, "1500000000", window, now.Add(window)))
+	assert.NoError(t, VerifyMAC(key, body, timestampedMAC, "1500000000", window, now.Add(-window)))
+	assert.Equal(t, ErrInvalidMAC, VerifyMAC(key, body, mac, "1500000000", window, now))
+	assert.Equal(t, ErrInvalidMAC, VerifyMAC(key, body, timestampedMAC, "1500000001", window, now))
+
+	// replayed notifications are rejected
+	assert.Equal(t, ErrMACTimestampOutsideWindow, VerifyMAC(key, body, timestampedMAC, "1500000000", window, now.Add(window+time.Second)))
+	assert.Equal(t, ErrMACTimestampOutsideWindow, VerifyMAC(key, body, timestampedMAC, "1500000000", window, now.Add(-window-time.Second)))
+
+	// invalid key
+	err = VerifyMAC("broken", body, mac, "", 0, now)
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), "invalid MAC key")
+	}
+}
@@ -1,8 +1,6 @@
 package listener
 
 import (
-	"crypto/hmac"
-	"crypto/sha256"
 	"encoding/json"
 	"io/ioutil"
 	"net/http"
@@ -20,7 +18,6 @@ import (
 	"github.com/stellar/gateway/horizon"
 	callback "github.com/stellar/gateway/protocols/compliance"
 	"github.com/stellar/go/protocols/compliance"
-	"github.com/stellar/go/strkey"
 	"github.com/stellar/go/support/errors"
 )
 
@@ -365,7 +362,14 @@ func (pl *PaymentListener) postForm(
 	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
 
 	if pl.config.MACKey != "" {
-		rawMAC, err := pl.getMAC(pl.config.MACKey, []byte(strbody))
+		payload := []byte(strbody)
+		if pl.config.SignTimestamps {
+			timestamp := strconv.FormatInt(pl.now().Unix(), 10)
+			req.Header.Set("X_PAYLOAD_TIMESTAMP", timestamp)
+			payload = timestampedPayload(timestamp, payload)
+		}
+
+		rawMAC, err := pl.getMAC(pl.config.MACKey, payload)
 		if err != nil {
 			return nil, errors.Wrap(err, "getMAC failed")
 		}
@@ -383,13 +387,5 @@ func (pl *PaymentListener) postForm(
 }
 
 func (pl *PaymentListener) getMAC(key string, raw []byte) ([]byte, error) {
-
-	rawkey, err := strkey.Decode(strkey.VersionByteSeed, pl.config.MACKey)
-	if err != nil {
-		return nil, errors.Wrap(err, "invalid MAC key")
-	}
-
-	macer := hmac.New(sha256.New, rawkey)
-	macer.Write(raw)
-	return macer.Sum(nil), nil
+	return payloadMAC(key, raw)
 }
@@ -675,7 +675,7 @@ func TestPostForm_MACKey(t *testing.T) {
 	defer srv.Close()
 
 	cfg := &config.Config{}
-	pl, err := NewPaymentListener(cfg, nil, nil, nil, nil)
+	pl, err := NewPaymentListener(cfg, nil, nil, nil, func() time.Time { return time.Unix(1500000000, 0) })
 	require.NoError(t, err)
 
 	// no mac if the key is not set
@@ -687,6 +687,25 @@ func TestPostForm_MACKey(t *testing.T) {
 	_, err = pl.postForm(srv.URL+"/mac", url.Values{"foo": []string{"base"}})
 	require.NoError(t, err)
 
+	// signs timestamp together with the body when window is set
+	handler.HandleFunc("/mac_timestamp", func(w http.ResponseWriter, req *http.Request) {
+		body, err := ioutil.ReadAll(req.Body)
+		require.NoError(t, err)
+
+		assert.Equal(t, "1500000000", req.Header.Get("X_PAYLOAD_TIMESTAMP"))
+
+		macer := hmac.New(sha256.New, rawkey)
+		macer.Write([]byte("1500000000."))
+		macer.Write(body)
+		encExpected := base64.StdEncoding.EncodeToString(macer.Sum(nil))
+
+		assert.Equal(t, encExpected, req.Header.Get("X_PAYLOAD_MAC"), "MAC is wrong")
+	})
+	cfg.SignTimestamps = true
+	_, err = pl.postForm(srv.URL+"/mac_timestamp", url.Values{"foo": []string{"base"}})
+	require.NoError(t, err)
+	cfg.SignTimestamps = false
+
 	// errors is the key is invalid
 	cfg.MACKey = "broken"
 	_, err = pl.postForm(srv.URL+"/mac", url.Values{"foo": []string{"base"}})
@@ -0,0 +1,37 @@
+package metrics
+
+import (
+	"sort"
+	"time"
+)
+
+// Metrics is implemented by metrics backends. Names are snake_case without units or backend specific
+// suffixes, backends add them (ex. Prometheus exports `payments` counter as `bridge_payments_total`).
+type Metrics interface {
+	// IncCounter increments counter `name`
+	IncCounter(name string, tags Tags)
+	// ObserveDuration records duration of a single event
+	ObserveDuration(name string, duration time.Duration, tags Tags)
+}
+
+// Tags are labels of a single metric value
+type Tags map[string]string
+
+// Noop discards all metrics. It's used when no metrics backend is configured.
+type Noop struct{}
+
+// IncCounter implements Metrics
+func (Noop) IncCounter(name string, tags Tags) {}
+
+// ObserveDuration implements Metrics
+func (Noop) ObserveDuration(name string, duration time.Duration, tags Tags) {}
+
+// sortedKeys returns tag names in order so the same tags always produce the same series
+func (t Tags) sortedKeys() []string {
+	keys := make([]string, 0, len(t))
+	for key := range t {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
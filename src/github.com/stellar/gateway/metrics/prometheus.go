@@ -0,0 +1,127 @@
+package metrics
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Prometheus keeps metrics in memory and exposes them in Prometheus text format when used as
+// http.Handler. Counters are exported as `<prefix>_<name>_total`, durations as
+// `<prefix>_<name>_seconds` summaries (sum and count only).
+type Prometheus struct {
+	prefix   string
+	families map[string]*family
+	mutex    sync.Mutex
+}
+
+// family contains all series of a single metric, by labels
+type family struct {
+	metricType string
+	series     map[string]*series
+}
+
+type series struct {
+	sum   float64
+	count uint64
+}
+
+// NewPrometheus creates a new Prometheus backend with metric names prefixed with prefix
+func NewPrometheus(prefix string) *Prometheus {
+	return &Prometheus{
+		prefix:   prefix,
+		families: map[string]*family{},
+	}
+}
+
+// IncCounter implements Metrics
+func (p *Prometheus) IncCounter(name string, tags Tags) {
+	p.observe(p.name(name)+"_total", "counter", 1, tags)
+}
+
+// ObserveDuration implements Metrics
+func (p *Prometheus) ObserveDuration(name string, duration time.Duration, tags Tags) {
+	p.observe(p.name(name)+"_seconds", "summary", duration.Seconds(), tags)
+}
+
+func (p *Prometheus) observe(name, metricType string, value float64, tags Tags) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	f, ok := p.families[name]
+	if !ok {
+		f = &family{metricType: metricType, series: map[string]*series{}}
+		p.families[name] = f
+	}
+
+	key := labels(tags)
+	s, ok := f.series[key]
+	if !ok {
+		s = &series{}
+		f.series[key] = s
+	}
+	s.sum += value
+	s.count++
+}
+
+// ServeHTTP writes all metrics in Prometheus text format
+func (p *Prometheus) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	names := make([]string, 0, len(p.families))
+	for name := range p.families {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var out bytes.Buffer
+	for _, name := range names {
+		f := p.families[name]
+		fmt.Fprintf(&out, "# TYPE %s %s\n", name, f.metricType)
+
+		keys := make([]string, 0, len(f.series))
+		for key := range f.series {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+
+		for _, key := range keys {
+			s := f.series[key]
+			if f.metricType == "counter" {
+				fmt.Fprintf(&out, "%s%s %d\n", name, key, s.count)
+			} else {
+				fmt.Fprintf(&out, "%s_sum%s %s\n", name, key, strconv.FormatFloat(s.sum, 'g', -1, 64))
+				fmt.Fprintf(&out, "%s_count%s %d\n", name, key, s.count)
+			}
+		}
+	}
+
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4")
+	w.Write(out.Bytes())
+}
+
+func (p *Prometheus) name(name string) string {
+	if p.prefix == "" {
+		return name
+	}
+	return p.prefix + "_" + name
+}
+
+// labels formats tags as Prometheus labels (`{name="value",...}`)
+func labels(tags Tags) string {
+	if len(tags) == 0 {
+		return ""
+	}
+
+	pairs := make([]string, 0, len(tags))
+	for _, key := range tags.sortedKeys() {
+		pairs = append(pairs, key+"="+strconv.Quote(tags[key]))
+	}
+	return "{" + strings.Join(pairs, ",") + "}"
+}
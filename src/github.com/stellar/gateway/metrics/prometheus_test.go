@@ -0,0 +1,33 @@
+package metrics
+
+import (
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	. "github.com/smartystreets/goconvey/convey"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPrometheus(t *testing.T) {
+	Convey("Prometheus", t, func() {
+		p := NewPrometheus("bridge")
+
+		p.IncCounter("payments", Tags{"status": "200", "result": "success"})
+		p.IncCounter("payments", Tags{"result": "success", "status": "200"})
+		p.IncCounter("payments", Tags{"status": "400", "result": "error"})
+		p.ObserveDuration("payment_duration", 1500*time.Millisecond, Tags{"result": "success"})
+		p.ObserveDuration("payment_duration", 500*time.Millisecond, Tags{"result": "success"})
+
+		recorder := httptest.NewRecorder()
+		p.ServeHTTP(recorder, httptest.NewRequest("GET", "/metrics", nil))
+
+		assert.Equal(t, `# TYPE bridge_payment_duration_seconds summary
+bridge_payment_duration_seconds_sum{result="success"} 2
+bridge_payment_duration_seconds_count{result="success"} 2
+# TYPE bridge_payments_total counter
+bridge_payments_total{result="error",status="400"} 1
+bridge_payments_total{result="success",status="200"} 2
+`, recorder.Body.String())
+	})
+}
@@ -0,0 +1,58 @@
+package metrics
+
+import (
+	"bytes"
+	"net"
+	"strconv"
+	"time"
+)
+
+// StatsD sends metrics to a StatsD agent over UDP. Counters are sent as `c`, durations as `ms` metrics.
+// Tags are sent in DogStatsD format (`|#name:value`) when dogStatsD is true and dropped otherwise.
+type StatsD struct {
+	conn      net.Conn
+	prefix    string
+	dogStatsD bool
+}
+
+// NewStatsD creates a new StatsD backend sending metrics to address (host:port) with names prefixed with prefix
+func NewStatsD(address, prefix string, dogStatsD bool) (*StatsD, error) {
+	conn, err := net.Dial("udp", address)
+	if err != nil {
+		return nil, err
+	}
+
+	return &StatsD{conn: conn, prefix: prefix, dogStatsD: dogStatsD}, nil
+}
+
+// IncCounter implements Metrics
+func (s *StatsD) IncCounter(name string, tags Tags) {
+	s.send(name, "1", "c", tags)
+}
+
+// ObserveDuration implements Metrics
+func (s *StatsD) ObserveDuration(name string, duration time.Duration, tags Tags) {
+	ms := strconv.FormatFloat(duration.Seconds()*1000, 'f', -1, 64)
+	s.send(name, ms, "ms", tags)
+}
+
+func (s *StatsD) send(name, value, metricType string, tags Tags) {
+	var line bytes.Buffer
+	if s.prefix != "" {
+		line.WriteString(s.prefix + ".")
+	}
+	line.WriteString(name + ":" + value + "|" + metricType)
+
+	if s.dogStatsD && len(tags) > 0 {
+		line.WriteString("|#")
+		for i, key := range tags.sortedKeys() {
+			if i > 0 {
+				line.WriteString(",")
+			}
+			line.WriteString(key + ":" + tags[key])
+		}
+	}
+
+	// UDP, metrics are lost when the agent is down
+	s.conn.Write(line.Bytes())
+}
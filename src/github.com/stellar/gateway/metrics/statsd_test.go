@@ -0,0 +1,46 @@
+package metrics
+
+import (
+	"net"
+	"testing"
+	"time"
+
+	. "github.com/smartystreets/goconvey/convey"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStatsD(t *testing.T) {
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer conn.Close()
+
+	read := func() string {
+		buffer := make([]byte, 1024)
+		conn.SetReadDeadline(time.Now().Add(time.Second))
+		n, _, err := conn.ReadFrom(buffer)
+		require.NoError(t, err)
+		return string(buffer[:n])
+	}
+
+	Convey("StatsD", t, func() {
+		Convey("tags are dropped", func() {
+			s, err := NewStatsD(conn.LocalAddr().String(), "bridge", false)
+			require.NoError(t, err)
+
+			s.IncCounter("payments", Tags{"result": "success"})
+			assert.Equal(t, "bridge.payments:1|c", read())
+
+			s.ObserveDuration("payment_duration", 1500*time.Millisecond, nil)
+			assert.Equal(t, "bridge.payment_duration:1500|ms", read())
+		})
+
+		Convey("DogStatsD tags are sent", func() {
+			s, err := NewStatsD(conn.LocalAddr().String(), "bridge", true)
+			require.NoError(t, err)
+
+			s.IncCounter("payments", Tags{"status": "200", "result": "success"})
+			assert.Equal(t, "bridge.payments:1|c|#result:success,status:200", read())
+		})
+	})
+}
@@ -7,6 +7,7 @@ import (
 
 	"github.com/stellar/gateway/db"
 	"github.com/stellar/gateway/db/entities"
+	"github.com/stellar/gateway/events"
 	"github.com/stellar/gateway/horizon"
 	"github.com/stellar/go/clients/stellartoml"
 	fproto "github.com/stellar/go/protocols/federation"
@@ -79,6 +80,16 @@ func (m *MockHTTPClient) Do(req *http.Request) (resp *http.Response, err error)
 	return a.Get(0).(*http.Response), a.Error(1)
 }
 
+// MockEventsPublisher ...
+type MockEventsPublisher struct {
+	mock.Mock
+}
+
+// PublishPayment is a mocking a method
+func (m *MockEventsPublisher) PublishPayment(event events.PaymentEvent) {
+	m.Called(event)
+}
+
 // MockHorizon ...
 type MockHorizon struct {
 	mock.Mock
@@ -90,12 +101,36 @@ func (m *MockHorizon) LoadAccount(accountID string) (response horizon.AccountRes
 	return a.Get(0).(horizon.AccountResponse), a.Error(1)
 }
 
+// LoadFeeStats is a mocking a method
+func (m *MockHorizon) LoadFeeStats() (response horizon.FeeStatsResponse, err error) {
+	a := m.Called()
+	return a.Get(0).(horizon.FeeStatsResponse), a.Error(1)
+}
+
+// LoadLatestLedger is a mocking a method
+func (m *MockHorizon) LoadLatestLedger() (response horizon.LedgerResponse, err error) {
+	a := m.Called()
+	return a.Get(0).(horizon.LedgerResponse), a.Error(1)
+}
+
 // LoadOperation is a mocking a method
 func (m *MockHorizon) LoadOperation(operationID string) (response horizon.PaymentResponse, err error) {
 	a := m.Called(operationID)
 	return a.Get(0).(horizon.PaymentResponse), a.Error(1)
 }
 
+// LoadTransaction is a mocking a method
+func (m *MockHorizon) LoadTransaction(hash string) (response *horizon.TransactionResponse, err error) {
+	a := m.Called(hash)
+	return a.Get(0).(*horizon.TransactionResponse), a.Error(1)
+}
+
+// LoadTransactionEffects is a mocking a method
+func (m *MockHorizon) LoadTransactionEffects(hash string) (effects []horizon.EffectResponse, err error) {
+	a := m.Called(hash)
+	return a.Get(0).([]horizon.EffectResponse), a.Error(1)
+}
+
 // LoadMemo is a mocking a method
 func (m *MockHorizon) LoadMemo(p *horizon.PaymentResponse) (err error) {
 	a := m.Called(p)
@@ -200,6 +235,30 @@ func (m *MockRepository) GetSentTransactionByPaymentID(paymentID string) (*entit
 	return a.Get(0).(*entities.SentTransaction), a.Error(1)
 }
 
+func (m *MockRepository) GetBatchItemByKey(key string) (*entities.BatchItem, error) {
+	a := m.Called(key)
+	if a.Get(0) == nil {
+		return nil, a.Error(1)
+	}
+	return a.Get(0).(*entities.BatchItem), a.Error(1)
+}
+
+func (m *MockRepository) GetQueuedPayments(page, limit int) ([]*entities.QueuedPayment, error) {
+	a := m.Called(page, limit)
+	if a.Get(0) == nil {
+		return nil, a.Error(1)
+	}
+	return a.Get(0).([]*entities.QueuedPayment), a.Error(1)
+}
+
+func (m *MockRepository) GetPendingQueuedPayments(limit int) ([]*entities.QueuedPayment, error) {
+	a := m.Called(limit)
+	if a.Get(0) == nil {
+		return nil, a.Error(1)
+	}
+	return a.Get(0).([]*entities.QueuedPayment), a.Error(1)
+}
+
 var _ db.RepositoryInterface = &MockRepository{}
 
 // MockSignerVerifier ...
@@ -243,11 +302,45 @@ type MockTransactionSubmitter struct {
 }
 
 // SubmitTransaction is a mocking a method
-func (ts *MockTransactionSubmitter) SubmitTransaction(paymentID *string, seed string, operation, memo interface{}) (response horizon.SubmitTransactionResponse, err error) {
-	a := ts.Called(paymentID, seed, operation, memo)
+func (ts *MockTransactionSubmitter) SubmitTransaction(paymentID *string, seed string, operation, memo interface{}, signers ...string) (response horizon.SubmitTransactionResponse, err error) {
+	var a mock.Arguments
+	if len(signers) > 0 {
+		a = ts.Called(paymentID, seed, operation, memo, signers)
+	} else {
+		a = ts.Called(paymentID, seed, operation, memo)
+	}
+	return a.Get(0).(horizon.SubmitTransactionResponse), a.Error(1)
+}
+
+// ResubmitTransaction is a mocking a method
+func (ts *MockTransactionSubmitter) ResubmitTransaction(envelopeXdr string) (response horizon.SubmitTransactionResponse, err error) {
+	a := ts.Called(envelopeXdr)
+	return a.Get(0).(horizon.SubmitTransactionResponse), a.Error(1)
+}
+
+// SubmitTransactionAsync is a mocking a method
+func (ts *MockTransactionSubmitter) SubmitTransactionAsync(paymentID *string, seed string, operation, memo interface{}, signers ...string) (response horizon.SubmitTransactionResponse, err error) {
+	var a mock.Arguments
+	if len(signers) > 0 {
+		a = ts.Called(paymentID, seed, operation, memo, signers)
+	} else {
+		a = ts.Called(paymentID, seed, operation, memo)
+	}
 	return a.Get(0).(horizon.SubmitTransactionResponse), a.Error(1)
 }
 
+// ResubmitTransactionAsync is a mocking a method
+func (ts *MockTransactionSubmitter) ResubmitTransactionAsync(envelopeXdr string) (response horizon.SubmitTransactionResponse, err error) {
+	a := ts.Called(envelopeXdr)
+	return a.Get(0).(horizon.SubmitTransactionResponse), a.Error(1)
+}
+
+// ReserveSequence is a mocking a method
+func (ts *MockTransactionSubmitter) ReserveSequence(source string, ttl time.Duration) (sequence uint64, expiresAt time.Time, err error) {
+	a := ts.Called(source, ttl)
+	return a.Get(0).(uint64), a.Get(1).(time.Time), a.Error(2)
+}
+
 // SignAndSubmitRawTransaction is a mocking a method
 func (ts *MockTransactionSubmitter) SignAndSubmitRawTransaction(paymentID *string, seed string, tx *xdr.Transaction) (response horizon.SubmitTransactionResponse, err error) {
 	a := ts.Called(paymentID, seed, tx)
@@ -0,0 +1,64 @@
+package net
+
+import (
+	"context"
+	"io"
+	"net/http"
+)
+
+// ContextTransport is a http.RoundTripper cancelling requests when a context is done. It's used for
+// requests made on behalf of a client request so they are cancelled when the client request times
+// out (see `request_timeout` config param) or the client disconnects. The context of the request
+// itself (ex. http.Client timeout) is still respected.
+type ContextTransport struct {
+	transport http.RoundTripper
+	ctx       context.Context
+}
+
+// NewContextTransport creates a new ContextTransport sending requests using transport
+// (http.DefaultTransport when nil) and cancelling them when ctx is done
+func NewContextTransport(transport http.RoundTripper, ctx context.Context) *ContextTransport {
+	if transport == nil {
+		transport = http.DefaultTransport
+	}
+	return &ContextTransport{transport: transport, ctx: ctx}
+}
+
+// RoundTrip implements http.RoundTripper
+func (t *ContextTransport) RoundTrip(r *http.Request) (*http.Response, error) {
+	ctx, cancel := context.WithCancel(r.Context())
+	stop := context.AfterFunc(t.ctx, cancel)
+	release := func() {
+		stop()
+		cancel()
+	}
+
+	resp, err := t.transport.RoundTrip(r.WithContext(ctx))
+	if err != nil {
+		release()
+		return nil, err
+	}
+
+	// The body is read after RoundTrip returns, the request context is released when it's closed
+	resp.Body = &contextBody{ReadCloser: resp.Body, release: release}
+	return resp, nil
+}
+
+// contextBody releases the request context when the response body is closed
+type contextBody struct {
+	io.ReadCloser
+	release func()
+}
+
+func (b *contextBody) Close() error {
+	err := b.ReadCloser.Close()
+	b.release()
+	return err
+}
+
+// ContextClient returns a copy of client cancelling requests when ctx is done (see ContextTransport)
+func ContextClient(client *http.Client, ctx context.Context) *http.Client {
+	contextClient := *client
+	contextClient.Transport = NewContextTransport(client.Transport, ctx)
+	return &contextClient
+}
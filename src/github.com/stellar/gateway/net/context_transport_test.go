@@ -0,0 +1,73 @@
+package net
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	. "github.com/smartystreets/goconvey/convey"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestContextTransport(t *testing.T) {
+	Convey("ContextTransport", t, func() {
+		done := make(chan struct{})
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.URL.Path == "/slow" {
+				select {
+				case <-done:
+				case <-r.Context().Done():
+				}
+				return
+			}
+			w.Write([]byte("ok"))
+		}))
+		defer server.Close()
+		defer close(done)
+
+		Convey("sends requests while the context is not done", func() {
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+			client := http.Client{Transport: NewContextTransport(nil, ctx)}
+
+			resp, err := client.Get(server.URL)
+			require.NoError(t, err)
+			body, err := ioutil.ReadAll(resp.Body)
+			require.NoError(t, err)
+			require.NoError(t, resp.Body.Close())
+			assert.Equal(t, "ok", string(body))
+		})
+
+		Convey("does not send requests when the context is done", func() {
+			ctx, cancel := context.WithCancel(context.Background())
+			cancel()
+			client := http.Client{Transport: NewContextTransport(nil, ctx)}
+
+			_, err := client.Get(server.URL)
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), context.Canceled.Error())
+		})
+
+		Convey("cancels requests in progress when the context times out", func() {
+			ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+			defer cancel()
+			client := http.Client{Transport: NewContextTransport(nil, ctx)}
+
+			start := time.Now()
+			_, err := client.Get(server.URL + "/slow")
+			require.Error(t, err)
+			assert.True(t, time.Since(start) < 5*time.Second)
+		})
+
+		Convey("respects client timeout", func() {
+			client := http.Client{Transport: NewContextTransport(nil, context.Background()), Timeout: 50 * time.Millisecond}
+
+			_, err := client.Get(server.URL + "/slow")
+			require.Error(t, err)
+		})
+	})
+}
@@ -0,0 +1,94 @@
+package net
+
+import (
+	"bytes"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"regexp"
+
+	"github.com/sirupsen/logrus"
+)
+
+// RedactedValue replaces secrets in logged bodies
+const RedactedValue = "REDACTED"
+
+var (
+	// Stellar secret seeds
+	seedPattern = regexp.MustCompile(`\bS[A-Z2-7]{55}\b`)
+	// Values of form params and JSON fields named like secrets
+	secretFormPattern = regexp.MustCompile(`(?i)\b((?:api_?key|password|secret|token)=)[^&\s]*`)
+	secretJSONPattern = regexp.MustCompile(`(?i)("(?:api_?key|password|secret|token)"\s*:\s*)"[^"]*"`)
+)
+
+// RedactSecrets replaces secret seeds and values of params named like secrets (api key, password,
+// secret, token) in a form encoded or JSON body with RedactedValue
+func RedactSecrets(body string) string {
+	body = seedPattern.ReplaceAllString(body, RedactedValue)
+	body = secretFormPattern.ReplaceAllString(body, "${1}"+RedactedValue)
+	return secretJSONPattern.ReplaceAllString(body, `${1}"`+RedactedValue+`"`)
+}
+
+// DebugTransport is a http.RoundTripper logging full requests and responses, with secrets redacted,
+// at debug level. It's used for requests made on behalf of a client request with debug logging enabled.
+type DebugTransport struct {
+	transport http.RoundTripper
+	log       logrus.FieldLogger
+}
+
+// NewDebugTransport creates a new DebugTransport sending requests using transport
+// (http.DefaultTransport when nil) and logging them to log
+func NewDebugTransport(transport http.RoundTripper, log logrus.FieldLogger) *DebugTransport {
+	if transport == nil {
+		transport = http.DefaultTransport
+	}
+	return &DebugTransport{transport: transport, log: log}
+}
+
+// RoundTrip implements http.RoundTripper
+func (t *DebugTransport) RoundTrip(r *http.Request) (*http.Response, error) {
+	requestBody, err := readBody(&r.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	log := t.log.WithFields(logrus.Fields{
+		"method": r.Method,
+		"url":    RedactSecrets(r.URL.String()),
+	})
+	log.WithField("body", RedactSecrets(requestBody)).Debug("Sending request")
+
+	resp, err := t.transport.RoundTrip(r)
+	if err != nil {
+		log.WithField("err", err).Debug("Request failed")
+		return nil, err
+	}
+
+	responseBody, err := readBody(&resp.Body)
+	if err != nil {
+		resp.Body.Close()
+		return nil, err
+	}
+
+	log.WithFields(logrus.Fields{
+		"status": resp.StatusCode,
+		"body":   RedactSecrets(responseBody),
+	}).Debug("Received response")
+	return resp, nil
+}
+
+// readBody reads body and replaces it with a reader of the same content
+func readBody(body *io.ReadCloser) (string, error) {
+	if *body == nil || *body == http.NoBody {
+		return "", nil
+	}
+
+	data, err := ioutil.ReadAll(*body)
+	(*body).Close()
+	if err != nil {
+		return "", err
+	}
+
+	*body = ioutil.NopCloser(bytes.NewReader(data))
+	return string(data), nil
+}
@@ -0,0 +1,75 @@
+package net
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+	"github.com/sirupsen/logrus/hooks/test"
+	. "github.com/smartystreets/goconvey/convey"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRedactSecrets(t *testing.T) {
+	Convey("RedactSecrets", t, func() {
+		Convey("redacts secret seeds", func() {
+			assert.Equal(
+				t,
+				"source=REDACTED&destination=GD4I7AFSLZGTDL34TQLWJOM2NHLIIOEKD5RHHZUW54HERBLSIRKUOXRR",
+				RedactSecrets("source=SDWLS4G3XCNIYPKXJWWGGJT6UDY63WV6PEFTWP7JZMQB4RE7EUJQN5XM&destination=GD4I7AFSLZGTDL34TQLWJOM2NHLIIOEKD5RHHZUW54HERBLSIRKUOXRR"),
+			)
+		})
+
+		Convey("redacts form params named like secrets", func() {
+			assert.Equal(t, "apiKey=REDACTED&amount=10&password=REDACTED", RedactSecrets("apiKey=abc&amount=10&password=xyz"))
+		})
+
+		Convey("redacts JSON fields named like secrets", func() {
+			assert.Equal(t, `{"token": "REDACTED", "amount": "10"}`, RedactSecrets(`{"token": "abc", "amount": "10"}`))
+		})
+	})
+}
+
+func TestDebugTransport(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		r.ParseForm()
+		w.WriteHeader(http.StatusCreated)
+		w.Write([]byte(`{"tx": "` + r.PostForm.Get("tx") + `"}`))
+	}))
+	defer server.Close()
+
+	logger, hook := test.NewNullLogger()
+	logger.Level = logrus.DebugLevel
+	client := http.Client{Transport: NewDebugTransport(nil, logger)}
+
+	Convey("DebugTransport", t, func() {
+		hook.Reset()
+
+		Convey("logs request and response bodies", func() {
+			resp, err := client.PostForm(server.URL+"/transactions", url.Values{"tx": {"envelope"}, "apiKey": {"secret"}})
+			require.NoError(t, err)
+			body, err := ioutil.ReadAll(resp.Body)
+			resp.Body.Close()
+			require.NoError(t, err)
+			assert.Equal(t, `{"tx": "envelope"}`, string(body))
+
+			require.Len(t, hook.Entries, 2)
+			assert.Equal(t, "POST", hook.Entries[0].Data["method"])
+			assert.Equal(t, server.URL+"/transactions", hook.Entries[0].Data["url"])
+			assert.Equal(t, "apiKey=REDACTED&tx=envelope", hook.Entries[0].Data["body"])
+			assert.Equal(t, http.StatusCreated, hook.Entries[1].Data["status"])
+			assert.Equal(t, `{"tx": "envelope"}`, hook.Entries[1].Data["body"])
+		})
+
+		Convey("logs failed requests", func() {
+			_, err := client.Get("http://127.0.0.1:0/")
+			require.Error(t, err)
+			require.Len(t, hook.Entries, 2)
+			assert.Contains(t, hook.LastEntry().Message, "Request failed")
+		})
+	})
+}
@@ -0,0 +1,74 @@
+package net
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+)
+
+// ResponseTooLargeError is returned when reading a response body exceeding the size limit
+type ResponseTooLargeError struct {
+	MaxSize int64
+}
+
+func (e *ResponseTooLargeError) Error() string {
+	return fmt.Sprintf("response body exceeds %d bytes limit", e.MaxSize)
+}
+
+// LimitReadCloser returns a ReadCloser reading from body that returns ResponseTooLargeError
+// after maxSize bytes are read and more remain. Non-positive maxSize means no limit.
+func LimitReadCloser(body io.ReadCloser, maxSize int64) io.ReadCloser {
+	if maxSize <= 0 {
+		return body
+	}
+	return &limitedReadCloser{
+		ReadCloser: body,
+		reader:     io.LimitReader(body, maxSize+1),
+		maxSize:    maxSize,
+	}
+}
+
+type limitedReadCloser struct {
+	io.ReadCloser
+	reader  io.Reader
+	maxSize int64
+	read    int64
+}
+
+func (l *limitedReadCloser) Read(p []byte) (int, error) {
+	n, err := l.reader.Read(p)
+	l.read += int64(n)
+	if l.read > l.maxSize {
+		return n - int(l.read-l.maxSize), &ResponseTooLargeError{l.maxSize}
+	}
+	return n, err
+}
+
+// LimitTransport is a http.RoundTripper limiting size of response bodies so a malicious
+// or broken server cannot exhaust memory of the client.
+type LimitTransport struct {
+	transport http.RoundTripper
+	maxSize   int64
+}
+
+// NewLimitTransport creates a new LimitTransport sending requests using transport.
+// Non-positive maxSize means no limit.
+func NewLimitTransport(transport http.RoundTripper, maxSize int64) *LimitTransport {
+	return &LimitTransport{transport: transport, maxSize: maxSize}
+}
+
+// RoundTrip implements http.RoundTripper
+func (t *LimitTransport) RoundTrip(r *http.Request) (*http.Response, error) {
+	resp, err := t.transport.RoundTrip(r)
+	if err != nil {
+		return resp, err
+	}
+
+	if resp.ContentLength > t.maxSize && t.maxSize > 0 {
+		resp.Body.Close()
+		return nil, &ResponseTooLargeError{t.maxSize}
+	}
+
+	resp.Body = LimitReadCloser(resp.Body, t.maxSize)
+	return resp, nil
+}
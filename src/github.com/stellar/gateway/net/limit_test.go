@@ -0,0 +1,80 @@
+package net
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLimitReadCloser(t *testing.T) {
+	Convey("LimitReadCloser", t, func() {
+		Convey("body within limit", func() {
+			body, err := ioutil.ReadAll(LimitReadCloser(ioutil.NopCloser(strings.NewReader("12345")), 5))
+			require.NoError(t, err)
+			assert.Equal(t, "12345", string(body))
+		})
+
+		Convey("body exceeding limit", func() {
+			body, err := ioutil.ReadAll(LimitReadCloser(ioutil.NopCloser(strings.NewReader("123456")), 5))
+			assert.EqualError(t, err, "response body exceeds 5 bytes limit")
+			assert.Equal(t, "12345", string(body))
+		})
+
+		Convey("no limit", func() {
+			body, err := ioutil.ReadAll(LimitReadCloser(ioutil.NopCloser(strings.NewReader("123456")), 0))
+			require.NoError(t, err)
+			assert.Equal(t, "123456", string(body))
+		})
+	})
+}
+
+func TestLimitTransport(t *testing.T) {
+	client := http.Client{Transport: NewLimitTransport(http.DefaultTransport, 10)}
+
+	Convey("LimitTransport", t, func() {
+		Convey("streamed response exceeding limit", func() {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.Write([]byte("12345"))
+				w.(http.Flusher).Flush()
+				w.Write([]byte("678901"))
+			}))
+			defer server.Close()
+
+			resp, err := client.Get(server.URL)
+			require.NoError(t, err)
+			defer resp.Body.Close()
+			_, err = ioutil.ReadAll(resp.Body)
+			assert.IsType(t, &ResponseTooLargeError{}, err)
+		})
+
+		Convey("Content-Length exceeding limit", func() {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.Write([]byte("12345678901"))
+			}))
+			defer server.Close()
+
+			_, err := client.Get(server.URL)
+			assert.Contains(t, err.Error(), "response body exceeds 10 bytes limit")
+		})
+
+		Convey("response within limit", func() {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.Write([]byte("1234567890"))
+			}))
+			defer server.Close()
+
+			resp, err := client.Get(server.URL)
+			require.NoError(t, err)
+			defer resp.Body.Close()
+			body, err := ioutil.ReadAll(resp.Body)
+			require.NoError(t, err)
+			assert.Equal(t, "1234567890", string(body))
+		})
+	})
+}
@@ -7,6 +7,7 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"net/url"
+	"strings"
 )
 
 // HTTPClientInterface helps mocking http.Client in tests
@@ -37,6 +38,27 @@ func GetResponse(testServer *httptest.Server, values url.Values) (int, []byte) {
 	return res.StatusCode, response
 }
 
+// GetResponseWithHeaders is used in tests
+func GetResponseWithHeaders(testServer *httptest.Server, values url.Values, headers http.Header) (int, []byte) {
+	req, err := http.NewRequest("POST", testServer.URL, strings.NewReader(values.Encode()))
+	if err != nil {
+		panic(err)
+	}
+	req.Header = headers
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	res, err := http.DefaultClient.Do(req)
+	if err != nil {
+		panic(err)
+	}
+	response, err := ioutil.ReadAll(res.Body)
+	res.Body.Close()
+	if err != nil {
+		panic(err)
+	}
+	return res.StatusCode, response
+}
+
 // JSONGetResponse is used in tests
 func JSONGetResponse(testServer *httptest.Server, data map[string]interface{}) (int, []byte) {
 	j, err := json.Marshal(data)
@@ -0,0 +1,54 @@
+package net
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+type retryBudgetKey struct{}
+
+// retryBudget is a number of retries left to calls made on behalf of a single client request
+type retryBudget struct {
+	mutex     sync.Mutex
+	remaining int
+}
+
+// WithRetryBudget returns a copy of ctx allowing at most retries retries of all downstream calls
+// (Horizon requests, stellar.toml fetches, transactions) made with it together, so retries of every
+// hop cannot add up to an unbounded amount of time spent on a single client request (see
+// `retry_budget` config param).
+func WithRetryBudget(ctx context.Context, retries int) context.Context {
+	return context.WithValue(ctx, retryBudgetKey{}, &retryBudget{remaining: retries})
+}
+
+// TakeRetry reports whether a call made with ctx can be retried after waiting wait and, if so, uses
+// one retry of the budget of ctx (see WithRetryBudget). Calls are not retried once ctx is done or when
+// the retry would start after its deadline. Calls made with nil ctx or ctx without a budget are
+// limited by the deadline only.
+func TakeRetry(ctx context.Context, wait time.Duration) bool {
+	if ctx == nil {
+		return true
+	}
+
+	if ctx.Err() != nil {
+		return false
+	}
+
+	if deadline, ok := ctx.Deadline(); ok && !time.Now().Add(wait).Before(deadline) {
+		return false
+	}
+
+	budget, ok := ctx.Value(retryBudgetKey{}).(*retryBudget)
+	if !ok {
+		return true
+	}
+
+	budget.mutex.Lock()
+	defer budget.mutex.Unlock()
+	if budget.remaining <= 0 {
+		return false
+	}
+	budget.remaining--
+	return true
+}
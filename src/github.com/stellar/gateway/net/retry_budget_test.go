@@ -0,0 +1,51 @@
+package net
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	. "github.com/smartystreets/goconvey/convey"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTakeRetry(t *testing.T) {
+	Convey("TakeRetry", t, func() {
+		Convey("without context", func() {
+			assert.True(t, TakeRetry(nil, time.Hour))
+		})
+
+		Convey("without budget", func() {
+			ctx := context.Background()
+			for i := 0; i < 10; i++ {
+				assert.True(t, TakeRetry(ctx, 0))
+			}
+		})
+
+		Convey("with budget", func() {
+			ctx := WithRetryBudget(context.Background(), 2)
+			assert.True(t, TakeRetry(ctx, 0))
+			assert.True(t, TakeRetry(ctx, 0))
+			assert.False(t, TakeRetry(ctx, 0))
+		})
+
+		Convey("with zero budget", func() {
+			ctx := WithRetryBudget(context.Background(), 0)
+			assert.False(t, TakeRetry(ctx, 0))
+		})
+
+		Convey("when retry would start after deadline", func() {
+			ctx, cancel := context.WithTimeout(WithRetryBudget(context.Background(), 1), time.Minute)
+			defer cancel()
+			assert.False(t, TakeRetry(ctx, time.Hour))
+			// Retry not started is not counted
+			assert.True(t, TakeRetry(ctx, time.Second))
+		})
+
+		Convey("when context is done", func() {
+			ctx, cancel := context.WithCancel(context.Background())
+			cancel()
+			assert.False(t, TakeRetry(ctx, 0))
+		})
+	})
+}
@@ -0,0 +1,93 @@
+package net
+
+import (
+	"crypto/tls"
+	"net"
+	"net/http"
+	"net/http/httptrace"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// Phases of a request logged by TimeoutTransport when the request fails
+const (
+	PhaseDNS             = "dns"
+	PhaseDial            = "dial"
+	PhaseTLSHandshake    = "tls_handshake"
+	PhaseWriteRequest    = "write_request"
+	PhaseResponseHeaders = "response_headers"
+)
+
+// TimeoutTransport is a http.RoundTripper with connection-level timeouts. Zero timeout means no timeout.
+// Failed requests are logged with a phase in which they failed so slow DNS, servers not accepting
+// connections and servers not responding can be told apart.
+type TimeoutTransport struct {
+	transport *http.Transport
+	log       logrus.FieldLogger
+}
+
+// NewTimeoutTransport creates a new TimeoutTransport logging failed requests to log
+func NewTimeoutTransport(dialTimeout, tlsHandshakeTimeout, responseHeaderTimeout time.Duration, log logrus.FieldLogger) *TimeoutTransport {
+	dialer := &net.Dialer{
+		Timeout:   dialTimeout,
+		KeepAlive: 30 * time.Second,
+	}
+
+	return &TimeoutTransport{
+		transport: &http.Transport{
+			Proxy:                 http.ProxyFromEnvironment,
+			DialContext:           dialer.DialContext,
+			TLSHandshakeTimeout:   tlsHandshakeTimeout,
+			ResponseHeaderTimeout: responseHeaderTimeout,
+			IdleConnTimeout:       90 * time.Second,
+		},
+		log: log,
+	}
+}
+
+// RoundTrip implements http.RoundTripper
+func (t *TimeoutTransport) RoundTrip(r *http.Request) (*http.Response, error) {
+	var mutex sync.Mutex
+	phase := PhaseDial
+	setPhase := func(p string) {
+		mutex.Lock()
+		phase = p
+		mutex.Unlock()
+	}
+
+	trace := &httptrace.ClientTrace{
+		DNSStart:          func(httptrace.DNSStartInfo) { setPhase(PhaseDNS) },
+		ConnectStart:      func(string, string) { setPhase(PhaseDial) },
+		TLSHandshakeStart: func() { setPhase(PhaseTLSHandshake) },
+		TLSHandshakeDone: func(_ tls.ConnectionState, err error) {
+			if err == nil {
+				setPhase(PhaseWriteRequest)
+			}
+		},
+		GotConn:      func(httptrace.GotConnInfo) { setPhase(PhaseWriteRequest) },
+		WroteRequest: func(httptrace.WroteRequestInfo) { setPhase(PhaseResponseHeaders) },
+	}
+
+	resp, err := t.transport.RoundTrip(r.WithContext(httptrace.WithClientTrace(r.Context(), trace)))
+	if err != nil {
+		mutex.Lock()
+		failedPhase := phase
+		mutex.Unlock()
+
+		timeout := false
+		if netErr, ok := err.(net.Error); ok {
+			timeout = netErr.Timeout()
+		}
+
+		t.log.WithFields(logrus.Fields{
+			"host":    r.URL.Host,
+			"phase":   failedPhase,
+			"timeout": timeout,
+			"err":     err,
+		}).Warn("HTTP request failed")
+	}
+
+	return resp, err
+}
@@ -0,0 +1,73 @@
+package net
+
+import (
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus/hooks/test"
+	. "github.com/smartystreets/goconvey/convey"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTimeoutTransport(t *testing.T) {
+	logger, hook := test.NewNullLogger()
+	transport := NewTimeoutTransport(time.Second, 100*time.Millisecond, 100*time.Millisecond, logger)
+	client := http.Client{Transport: transport}
+
+	Convey("TimeoutTransport", t, func() {
+		hook.Reset()
+
+		Convey("response received in time", func() {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+			defer server.Close()
+
+			resp, err := client.Get(server.URL)
+			require.NoError(t, err)
+			resp.Body.Close()
+			assert.Equal(t, 200, resp.StatusCode)
+			assert.Nil(t, hook.LastEntry())
+		})
+
+		Convey("response headers timeout", func() {
+			done := make(chan struct{})
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				<-done
+			}))
+			defer server.Close()
+			defer close(done)
+
+			_, err := client.Get(server.URL)
+			require.Error(t, err)
+			require.NotNil(t, hook.LastEntry())
+			assert.Equal(t, PhaseResponseHeaders, hook.LastEntry().Data["phase"])
+			assert.Equal(t, true, hook.LastEntry().Data["timeout"])
+		})
+
+		Convey("TLS handshake timeout", func() {
+			// Accepts connections but never starts a handshake
+			listener, err := net.Listen("tcp", "127.0.0.1:0")
+			require.NoError(t, err)
+			defer listener.Close()
+
+			go func() {
+				for {
+					conn, err := listener.Accept()
+					if err != nil {
+						return
+					}
+					defer conn.Close()
+				}
+			}()
+
+			_, err = client.Get("https://" + listener.Addr().String())
+			require.Error(t, err)
+			require.NotNil(t, hook.LastEntry())
+			assert.Equal(t, PhaseTLSHandshake, hook.LastEntry().Data["phase"])
+			assert.Equal(t, true, hook.LastEntry().Data["timeout"])
+		})
+	})
+}
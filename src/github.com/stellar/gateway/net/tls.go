@@ -0,0 +1,70 @@
+package net
+
+import (
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/hex"
+	"errors"
+	"io/ioutil"
+	"strings"
+)
+
+// NewTLSConfig creates TLS config for connecting to servers with self-signed or private certificates.
+// caBundle is a path to PEM file with CA certificates trusted instead of system roots, fingerprint is
+// a hex encoded SHA-256 fingerprint of the only certificate accepted (colons allowed). insecureSkipVerify
+// disables verification completely and must never be used in production.
+// Returns nil config when all params are empty.
+func NewTLSConfig(caBundle, fingerprint string, insecureSkipVerify bool) (*tls.Config, error) {
+	if caBundle == "" && fingerprint == "" && !insecureSkipVerify {
+		return nil, nil
+	}
+
+	config := &tls.Config{InsecureSkipVerify: insecureSkipVerify}
+
+	if caBundle != "" {
+		pem, err := ioutil.ReadFile(caBundle)
+		if err != nil {
+			return nil, err
+		}
+
+		config.RootCAs = x509.NewCertPool()
+		if !config.RootCAs.AppendCertsFromPEM(pem) {
+			return nil, errors.New("No certificates found in " + caBundle)
+		}
+	}
+
+	if fingerprint != "" {
+		pinned, err := ParseFingerprint(fingerprint)
+		if err != nil {
+			return nil, err
+		}
+
+		// Pinned certificate is trusted regardless of its chain (ex. self-signed) unless CA bundle is given too
+		if caBundle == "" {
+			config.InsecureSkipVerify = true
+		}
+		config.VerifyPeerCertificate = func(rawCerts [][]byte, _ [][]*x509.Certificate) error {
+			if len(rawCerts) == 0 {
+				return errors.New("No certificate presented")
+			}
+			sum := sha256.Sum256(rawCerts[0])
+			if hex.EncodeToString(sum[:]) != pinned {
+				return errors.New("Certificate fingerprint does not match pinned fingerprint")
+			}
+			return nil
+		}
+	}
+
+	return config, nil
+}
+
+// ParseFingerprint normalizes hex encoded SHA-256 certificate fingerprint (ex. `AB:CD:...`)
+func ParseFingerprint(fingerprint string) (string, error) {
+	normalized := strings.ToLower(strings.Replace(fingerprint, ":", "", -1))
+	decoded, err := hex.DecodeString(normalized)
+	if err != nil || len(decoded) != sha256.Size {
+		return "", errors.New("Fingerprint must be hex encoded SHA-256 hash")
+	}
+	return normalized, nil
+}
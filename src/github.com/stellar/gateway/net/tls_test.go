@@ -0,0 +1,64 @@
+package net
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewTLSConfig(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer server.Close()
+
+	sum := sha256.Sum256(server.Certificate().Raw)
+	fingerprint := hex.EncodeToString(sum[:])
+
+	get := func(fingerprint string, insecureSkipVerify bool) error {
+		config, err := NewTLSConfig("", fingerprint, insecureSkipVerify)
+		require.NoError(t, err)
+		client := http.Client{Transport: &http.Transport{TLSClientConfig: config}}
+		resp, err := client.Get(server.URL)
+		if err == nil {
+			resp.Body.Close()
+		}
+		return err
+	}
+
+	Convey("NewTLSConfig", t, func() {
+		Convey("returns nil config when nothing is set", func() {
+			config, err := NewTLSConfig("", "", false)
+			assert.NoError(t, err)
+			assert.Nil(t, config)
+		})
+
+		Convey("accepts self-signed certificate matching pinned fingerprint", func() {
+			assert.NoError(t, get(fingerprint, false))
+		})
+
+		Convey("rejects certificate not matching pinned fingerprint", func() {
+			assert.Error(t, get(strings.Repeat("0", 64), false))
+		})
+
+		Convey("accepts any certificate when verification is disabled", func() {
+			assert.NoError(t, get("", true))
+		})
+
+		Convey("returns error for invalid fingerprint", func() {
+			_, err := NewTLSConfig("", "abc", false)
+			assert.Error(t, err)
+		})
+	})
+}
+
+func TestParseFingerprint(t *testing.T) {
+	fingerprint, err := ParseFingerprint("AB:CD:EF:01:23:45:67:89:AB:CD:EF:01:23:45:67:89:AB:CD:EF:01:23:45:67:89:AB:CD:EF:01:23:45:67:89")
+	assert.NoError(t, err)
+	assert.Equal(t, "abcdef0123456789abcdef0123456789abcdef0123456789abcdef0123456789", fingerprint)
+}
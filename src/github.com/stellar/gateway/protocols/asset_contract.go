@@ -0,0 +1,48 @@
+package protocols
+
+import (
+	"bytes"
+	"encoding/base32"
+	"encoding/binary"
+
+	"github.com/stellar/go/crc16"
+	"github.com/stellar/go/hash"
+	"github.com/stellar/go/xdr"
+)
+
+const (
+	// envelopeTypeContractID is ENVELOPE_TYPE_CONTRACT_ID of HashIDPreimage XDR union
+	envelopeTypeContractID = 8
+	// contractIDPreimageFromAsset is CONTRACT_ID_PREIMAGE_FROM_ASSET of ContractIDPreimage XDR union
+	contractIDPreimageFromAsset = 1
+	// versionByteContract is the strkey version byte of contract IDs (base32-encodes to 'C...')
+	versionByteContract = 2 << 3
+)
+
+// ContractID returns the ID of the Stellar Asset Contract of this asset on the network with the given
+// passphrase, encoded as a strkey (`C...`). The ID is derived deterministically the same way Soroban
+// does it, so it's returned even when the contract has not been deployed yet.
+func (a Asset) ContractID(networkPassphrase string) (string, error) {
+	asset, err := a.ToBaseAsset().ToXDR()
+	if err != nil {
+		return "", err
+	}
+
+	networkID := hash.Hash([]byte(networkPassphrase))
+
+	var preimage bytes.Buffer
+	binary.Write(&preimage, binary.BigEndian, int32(envelopeTypeContractID))
+	preimage.Write(networkID[:])
+	binary.Write(&preimage, binary.BigEndian, int32(contractIDPreimageFromAsset))
+	_, err = xdr.Marshal(&preimage, asset)
+	if err != nil {
+		return "", err
+	}
+
+	contractID := hash.Hash(preimage.Bytes())
+
+	// Vendored strkey package does not support contract version byte
+	raw := append([]byte{versionByteContract}, contractID[:]...)
+	raw = append(raw, crc16.Checksum(raw)...)
+	return base32.StdEncoding.EncodeToString(raw), nil
+}
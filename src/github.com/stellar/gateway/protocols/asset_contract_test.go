@@ -0,0 +1,22 @@
+package protocols
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAssetContractID(t *testing.T) {
+	contractID, err := Asset{}.ContractID("Test SDF Network ; September 2015")
+	require.NoError(t, err)
+	assert.Equal(t, "CDLZFC3SYJYDZT7K67VZ75HPJVIEUVNIXF47ZG2FB2RMQQVU2HHGCYSC", contractID)
+
+	contractID, err = Asset{}.ContractID("Public Global Stellar Network ; September 2015")
+	require.NoError(t, err)
+	assert.Equal(t, "CAS3J7GYLGXMF6TDJBBYYSE3HQ6BBSMLNUQ34T6TZMYMW2EVH34XOWMA", contractID)
+
+	contractID, err = Asset{Code: "USDC", Issuer: "GA5ZSEJYB37JRC5AVCIA5MOP4RHTM335X2KGX3IHOJAPP5RE34K4KZVN"}.ContractID("Public Global Stellar Network ; September 2015")
+	require.NoError(t, err)
+	assert.Equal(t, "CCW67TSZV3SSS2HXMBQ5JFGCKJNXKZM7UQUWUZPUTHXSTZLEO7SJMI75", contractID)
+}
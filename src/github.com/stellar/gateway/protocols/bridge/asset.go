@@ -0,0 +1,57 @@
+package bridge
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/url"
+
+	"github.com/stellar/gateway/protocols"
+)
+
+// AssetRequest represents request made to /asset endpoint of bridge server
+type AssetRequest struct {
+	// Code of the asset, empty for XLM
+	AssetCode string `name:"asset_code"`
+	// Issuer of the asset, empty for XLM
+	AssetIssuer string `name:"asset_issuer"`
+
+	protocols.FormRequest
+}
+
+// FromRequest will populate request fields using http.Request.
+func (request *AssetRequest) FromRequest(r *http.Request) error {
+	return request.FormRequest.FromRequest(r, request)
+}
+
+// ToValues will create url.Values from request.
+func (request *AssetRequest) ToValues() url.Values {
+	return request.FormRequest.ToValues(request)
+}
+
+// Validate validates if request fields are valid. Useful when checking if a request is correct.
+func (request *AssetRequest) Validate() error {
+	asset := protocols.Asset{Code: request.AssetCode, Issuer: request.AssetIssuer}
+	if !asset.Validate() {
+		return protocols.NewInvalidParameterError("asset", asset.String(), "Invalid asset.")
+	}
+	return nil
+}
+
+// AssetResponse represents a response returned by /asset endpoint
+type AssetResponse struct {
+	AssetCode   string `json:"asset_code,omitempty"`
+	AssetIssuer string `json:"asset_issuer,omitempty"`
+	// ID of the Stellar Asset Contract of the asset on the configured network
+	ContractID string `json:"contract_id"`
+}
+
+// HTTPStatus returns http status of the response
+func (response AssetResponse) HTTPStatus() int {
+	return http.StatusOK
+}
+
+// Marshal marshals the response
+func (response AssetResponse) Marshal() []byte {
+	json, _ := json.MarshalIndent(response, "", "  ")
+	return json
+}
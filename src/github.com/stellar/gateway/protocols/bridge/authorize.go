@@ -6,6 +6,7 @@ import (
 
 	"github.com/stellar/gateway/bridge/config"
 	"github.com/stellar/gateway/protocols"
+	"github.com/stellar/go/strkey"
 )
 
 var (
@@ -44,8 +45,9 @@ func (request *AuthorizeRequest) Validate(allowedAssets []config.Asset, issuingA
 		return err
 	}
 
-	if !protocols.IsValidAccountID(request.AccountID) {
-		return protocols.NewInvalidParameterError("account_id", request.AccountID, "Account ID must start with `G`.")
+	err = protocols.CheckKey(request.AccountID, strkey.VersionByteAccountID)
+	if err != nil {
+		return protocols.NewInvalidParameterError("account_id", request.AccountID, err.Error())
 	}
 
 	// Is asset allowed?
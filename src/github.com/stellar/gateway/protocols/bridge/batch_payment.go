@@ -0,0 +1,383 @@
+package bridge
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"github.com/stellar/gateway/horizon"
+	"github.com/stellar/gateway/protocols"
+	b "github.com/stellar/go/build"
+	"github.com/stellar/go/strkey"
+)
+
+// MaxOperationsPerTransaction is the maximum number of operations in a transaction allowed by the protocol
+const MaxOperationsPerTransaction = 100
+
+var (
+	// BatchPaymentEmpty is an error response
+	BatchPaymentEmpty = &protocols.ErrorResponse{Code: "batch_empty", Message: "Batch must contain at least one payment.", Status: http.StatusBadRequest}
+	// BatchPaymentCannotResolveDestinations is an error response
+	BatchPaymentCannotResolveDestinations = &protocols.ErrorResponse{Code: "cannot_resolve_destinations", Message: "Cannot resolve one or more destinations.", Status: http.StatusBadRequest}
+	// BatchPaymentTooManyOperations is an error response
+	BatchPaymentTooManyOperations = &protocols.ErrorResponse{Code: "batch_too_many_operations", Message: "Batch exceeds maximum number of operations in a transaction.", Status: http.StatusBadRequest}
+	// BatchPaymentDuplicate is an error response
+	BatchPaymentDuplicate = &protocols.ErrorResponse{Code: "batch_duplicate_payment", Message: "Batch contains identical payments.", Status: http.StatusBadRequest}
+	// BatchPaymentFeeTooHigh is an error response
+	BatchPaymentFeeTooHigh = &protocols.ErrorResponse{Code: "batch_fee_too_high", Message: "Transaction fee of the batch exceeds the maximum fee allowed by this server.", Status: http.StatusBadRequest}
+	// BatchPaymentSourceFailed is an error response
+	BatchPaymentSourceFailed = &protocols.ErrorResponse{Code: "batch_source_failed", Message: "Transactions of one or more source accounts of the batch failed.", Status: http.StatusBadRequest}
+	// BatchPaymentTooManyPayments is an error response
+	BatchPaymentTooManyPayments = &protocols.ErrorResponse{Code: "batch_too_many_payments", Message: "Batch exceeds maximum number of payments allowed by this server.", Status: http.StatusBadRequest}
+	// BatchPaymentAmountTooHigh is an error response
+	BatchPaymentAmountTooHigh = &protocols.ErrorResponse{Code: "batch_amount_too_high", Message: "Total amount of an asset sent by the batch exceeds the maximum allowed by this server.", Status: http.StatusBadRequest}
+	// BatchPaymentOrdering is an error response
+	BatchPaymentOrdering = &protocols.ErrorResponse{Code: "batch_ordering", Message: "Payment depends on an operation applied after it.", Status: http.StatusBadRequest}
+	// BatchPaymentInProgress is an error response
+	BatchPaymentInProgress = &protocols.ErrorResponse{Code: "batch_payment_in_progress", Message: "Payment with given key is being sent by another request. Repeat the request later.", Status: http.StatusConflict}
+)
+
+// BatchPaymentRequest represents request made to /batch-payment endpoint of the bridge server.
+// Payments are sent in a single transaction unless the batch is split (see `batch.split_transactions` config param)
+// or payments are sent from multiple source accounts, in which case every source account sends its own transaction.
+type BatchPaymentRequest struct {
+	// Payment ID
+	ID string `json:"id"`
+	// Source account secret
+	Source string `json:"source"`
+	// Memo type
+	MemoType string `json:"memo_type"`
+	// Memo value
+	Memo string `json:"memo"`
+	// Payments to send
+	Payments []BatchPaymentItem `json:"payments"`
+	// When true result_meta_xdr of submitted transactions is returned
+	IncludeMeta bool `json:"include_meta"`
+	// Fee per operation (in stroops) paid by the transaction. Default base fee is used when zero.
+	PerOpFee uint64 `json:"per_op_fee"`
+}
+
+// BatchPaymentItem represents a single payment in BatchPaymentRequest
+type BatchPaymentItem struct {
+	// Destination address (like bob*stellar.org) or account ID
+	Destination string `json:"destination"`
+	// Amount destination should receive
+	Amount string `json:"amount"`
+	// Code of the asset destination should receive
+	AssetCode string `json:"asset_code"`
+	// Issuer of the asset destination should receive
+	AssetIssuer string `json:"asset_issuer"`
+	// Account ID of the account sending this payment. Transaction source is used when empty.
+	OperationSource string `json:"operation_source"`
+	// Secret seed of the source of the transaction sending this payment. Request source is used when empty.
+	Source string `json:"source"`
+	// Forces operation type (`payment` or `create_account`) of XLM payments skipping destination account existence check
+	Operation string `json:"operation"`
+	// Idempotency key of the payment, unique within the request. Payments sent successfully are
+	// not sent again when a batch with the same key is retried.
+	Key string `json:"key"`
+}
+
+// Validate validates if request fields are valid. Useful when checking if a request is correct.
+func (request *BatchPaymentRequest) Validate() error {
+	if request.Source != "" {
+		err := protocols.CheckKey(request.Source, strkey.VersionByteSeed)
+		if err != nil {
+			return protocols.NewInvalidParameterError("source", "", err.Error())
+		}
+	}
+
+	if request.MemoType == "" && request.Memo != "" {
+		return protocols.NewMissingParameter("memo_type")
+	}
+
+	if request.MemoType != "" && request.Memo == "" {
+		return protocols.NewMissingParameter("memo")
+	}
+
+	if len(request.Payments) == 0 {
+		return BatchPaymentEmpty
+	}
+
+	err := request.validateKeys()
+	if err != nil {
+		return err
+	}
+
+	if request.PerOpFee != 0 && request.PerOpFee < b.DefaultBaseFee {
+		return protocols.NewInvalidParameterError("per_op_fee", strconv.FormatUint(request.PerOpFee, 10), "Fee per operation cannot be lower than "+strconv.FormatUint(b.DefaultBaseFee, 10)+" stroops.")
+	}
+
+	for i, payment := range request.Payments {
+		field := "payments[" + strconv.Itoa(i) + "]"
+
+		if payment.Destination == "" {
+			return protocols.NewMissingParameter(field + "[destination]")
+		}
+
+		if !protocols.IsValidAmount(payment.Amount) {
+			return protocols.NewInvalidParameterError(field+"[amount]", payment.Amount, "Invalid amount.")
+		}
+
+		asset := protocols.Asset{
+			Code:   payment.AssetCode,
+			Issuer: payment.AssetIssuer,
+		}
+
+		if !asset.Validate() {
+			return protocols.NewInvalidParameterError(field+"[asset]", asset.String(), "Invalid asset.")
+		}
+
+		switch payment.Operation {
+		case "", "payment":
+		case "create_account":
+			if payment.AssetCode != "" {
+				return protocols.NewInvalidParameterError(field+"[operation]", payment.Operation, "create_account operation can only send XLM.")
+			}
+		default:
+			return protocols.NewInvalidParameterError(field+"[operation]", payment.Operation, "Operation must be `payment` or `create_account`.")
+		}
+
+		if payment.OperationSource != "" {
+			err := protocols.CheckKey(payment.OperationSource, strkey.VersionByteAccountID)
+			if err != nil {
+				return protocols.NewInvalidParameterError(field+"[operation_source]", payment.OperationSource, err.Error())
+			}
+		}
+
+		if payment.Source != "" {
+			err := protocols.CheckKey(payment.Source, strkey.VersionByteSeed)
+			if err != nil {
+				return protocols.NewInvalidParameterError(field+"[source]", "", err.Error())
+			}
+		}
+	}
+
+	return nil
+}
+
+// validateKeys checks that either all payments or none of them have keys, keys are unique and
+// are not sent together with `id`, as every transaction of a retried batch would reuse its ID
+func (request *BatchPaymentRequest) validateKeys() error {
+	if request.Payments[0].Key == "" {
+		for _, payment := range request.Payments {
+			if payment.Key != "" {
+				return protocols.NewMissingParameter("payments[0][key]")
+			}
+		}
+		return nil
+	}
+
+	if request.ID != "" {
+		return protocols.NewInvalidParameterError("id", request.ID, "Batch id cannot be used together with payment keys.")
+	}
+
+	seen := make(map[string]bool)
+	for i, payment := range request.Payments {
+		field := "payments[" + strconv.Itoa(i) + "][key]"
+		if payment.Key == "" {
+			return protocols.NewMissingParameter(field)
+		}
+		if seen[payment.Key] {
+			return protocols.NewInvalidParameterError(field, payment.Key, "Key must be unique within the batch.")
+		}
+		seen[payment.Key] = true
+	}
+
+	return nil
+}
+
+// HasKeys returns true when payments of the batch have idempotency keys (all of them or none,
+// see Validate)
+func (request *BatchPaymentRequest) HasKeys() bool {
+	return len(request.Payments) > 0 && request.Payments[0].Key != ""
+}
+
+// NewBatchPaymentCannotResolveDestinationsError creates a new BatchPaymentCannotResolveDestinations error.
+// `destinations` maps each failed destination to the reason of the failure.
+func NewBatchPaymentCannotResolveDestinationsError(destinations map[string]string) *protocols.ErrorResponse {
+	return &protocols.ErrorResponse{
+		Status:  BatchPaymentCannotResolveDestinations.Status,
+		Code:    BatchPaymentCannotResolveDestinations.Code,
+		Message: BatchPaymentCannotResolveDestinations.Message,
+		Data:    map[string]interface{}{"destinations": destinations},
+		LogData: map[string]interface{}{"destinations": destinations},
+	}
+}
+
+// NewBatchPaymentTooManyOperationsError creates a new BatchPaymentTooManyOperations error
+func NewBatchPaymentTooManyOperationsError(maxOperations int) *protocols.ErrorResponse {
+	return &protocols.ErrorResponse{
+		Status:  BatchPaymentTooManyOperations.Status,
+		Code:    BatchPaymentTooManyOperations.Code,
+		Message: BatchPaymentTooManyOperations.Message,
+		Data:    map[string]interface{}{"max_operations": maxOperations},
+	}
+}
+
+// NewBatchPaymentDuplicateError creates a new BatchPaymentDuplicate error. `duplicate` is the index
+// of the first payment identical to a preceding payment `original`.
+func NewBatchPaymentDuplicateError(duplicate, original int) *protocols.ErrorResponse {
+	return &protocols.ErrorResponse{
+		Status:  BatchPaymentDuplicate.Status,
+		Code:    BatchPaymentDuplicate.Code,
+		Message: BatchPaymentDuplicate.Message,
+		Data: map[string]interface{}{
+			"name":         "payments[" + strconv.Itoa(duplicate) + "]",
+			"duplicate_of": "payments[" + strconv.Itoa(original) + "]",
+		},
+	}
+}
+
+// NewBatchPaymentInProgressError creates a new BatchPaymentInProgress error of the payment with `key`
+func NewBatchPaymentInProgressError(key string) *protocols.ErrorResponse {
+	return &protocols.ErrorResponse{
+		Status:  BatchPaymentInProgress.Status,
+		Code:    BatchPaymentInProgress.Code,
+		Message: BatchPaymentInProgress.Message,
+		Data:    map[string]interface{}{"key": key},
+	}
+}
+
+// NewBatchPaymentFeeTooHighError creates a new BatchPaymentFeeTooHigh error. `fee` is the fee of the
+// largest transaction of the batch and `maxFee` the maximum fee of a transaction (both in stroops).
+func NewBatchPaymentFeeTooHighError(fee, maxFee uint64) *protocols.ErrorResponse {
+	return &protocols.ErrorResponse{
+		Status:  BatchPaymentFeeTooHigh.Status,
+		Code:    BatchPaymentFeeTooHigh.Code,
+		Message: BatchPaymentFeeTooHigh.Message,
+		Data: map[string]interface{}{
+			"fee":     fee,
+			"max_fee": maxFee,
+		},
+	}
+}
+
+// NewBatchPaymentTooManyPaymentsError creates a new BatchPaymentTooManyPayments error. `payments`
+// is the number of payments in the batch.
+func NewBatchPaymentTooManyPaymentsError(payments, maxPayments int) *protocols.ErrorResponse {
+	return &protocols.ErrorResponse{
+		Status:  BatchPaymentTooManyPayments.Status,
+		Code:    BatchPaymentTooManyPayments.Code,
+		Message: BatchPaymentTooManyPayments.Message,
+		Data: map[string]interface{}{
+			"payments":     payments,
+			"max_payments": maxPayments,
+		},
+	}
+}
+
+// NewBatchPaymentAmountTooHighError creates a new BatchPaymentAmountTooHigh error. `total` is the
+// total amount of the asset sent by all payments of the batch.
+func NewBatchPaymentAmountTooHighError(asset protocols.Asset, total, maxAmount string) *protocols.ErrorResponse {
+	return &protocols.ErrorResponse{
+		Status:  BatchPaymentAmountTooHigh.Status,
+		Code:    BatchPaymentAmountTooHigh.Code,
+		Message: BatchPaymentAmountTooHigh.Message,
+		Data: map[string]interface{}{
+			"asset_code":   asset.Code,
+			"asset_issuer": asset.Issuer,
+			"amount":       total,
+			"max_amount":   maxAmount,
+		},
+	}
+}
+
+// NewBatchPaymentOrderingError creates a new BatchPaymentOrdering error. `payment` is the index of
+// the payment depending on the payment `dependency` creating its account.
+func NewBatchPaymentOrderingError(payment, dependency int) *protocols.ErrorResponse {
+	return &protocols.ErrorResponse{
+		Status:  BatchPaymentOrdering.Status,
+		Code:    BatchPaymentOrdering.Code,
+		Message: BatchPaymentOrdering.Message,
+		Data: map[string]interface{}{
+			"name":       "payments[" + strconv.Itoa(payment) + "]",
+			"depends_on": "payments[" + strconv.Itoa(dependency) + "]",
+		},
+	}
+}
+
+// NewBatchPaymentSourceFailedError creates a new BatchPaymentSourceFailed error containing results
+// of all source accounts of the batch. HTTP status of the error is the status of the first failure.
+func NewBatchPaymentSourceFailedError(results []BatchPaymentSourceResult) *protocols.ErrorResponse {
+	status := BatchPaymentSourceFailed.Status
+	var failed []string
+	for _, result := range results {
+		if result.Error == nil {
+			continue
+		}
+		if len(failed) == 0 {
+			status = result.Error.Status
+		}
+		failed = append(failed, result.Source)
+	}
+
+	return &protocols.ErrorResponse{
+		Status:  status,
+		Code:    BatchPaymentSourceFailed.Code,
+		Message: BatchPaymentSourceFailed.Message,
+		Data:    map[string]interface{}{"sources": results},
+		LogData: map[string]interface{}{"failed_sources": failed},
+	}
+}
+
+// BatchPaymentResponse represents response returned by /batch-payment endpoint when
+// the batch has been split into multiple transactions or sent from multiple source accounts
+type BatchPaymentResponse struct {
+	protocols.SuccessResponse
+	Transactions []horizon.SubmitTransactionResponse `json:"transactions,omitempty"`
+	Sources      []BatchPaymentSourceResult          `json:"sources,omitempty"`
+	// Results of payments sent with keys
+	Items []BatchPaymentItemResult `json:"items,omitempty"`
+}
+
+// BatchPaymentItemResult is the result of a payment of a batch sent with a key
+type BatchPaymentItemResult struct {
+	Key string `json:"key"`
+	// `success`, `failure` or `pending` (outcome of the transaction is unknown)
+	Status string `json:"status"`
+	// Hash of the transaction sending the payment, empty when it was not submitted
+	Hash string `json:"hash,omitempty"`
+	// Error code of the failed payment
+	Error string `json:"error,omitempty"`
+	// True when the payment was sent by a previous request with the same key and was not sent again
+	Skipped bool `json:"skipped,omitempty"`
+}
+
+// BatchPaymentSourceResult is the result of the transaction sent by a single source account of a batch
+type BatchPaymentSourceResult struct {
+	// Account ID of the transaction source
+	Source string `json:"source"`
+	// Submitted transaction, nil when the transaction failed
+	Transaction *horizon.SubmitTransactionResponse `json:"transaction,omitempty"`
+	// Error of the failed transaction
+	Error *protocols.ErrorResponse `json:"error,omitempty"`
+}
+
+// SetError sets the error of the result. Error responses are often shared package variables so
+// a copy with numeric error code set is stored.
+func (result *BatchPaymentSourceResult) SetError(errorResponse *protocols.ErrorResponse) {
+	response := *errorResponse
+	response.ErrorCode = protocols.ErrorCode(errorResponse.Code)
+	result.Error = &response
+}
+
+// Marshal marshals BatchPaymentResponse
+func (response *BatchPaymentResponse) Marshal() []byte {
+	json, _ := json.MarshalIndent(response, "", "  ")
+	return json
+}
+
+// Operations is a transaction mutator adding all of its operations to a transaction
+type Operations []b.TransactionMutator
+
+// MutateTransaction for Operations adds each operation to the transaction, in order
+func (ops Operations) MutateTransaction(t *b.TransactionBuilder) error {
+	for _, op := range ops {
+		err := op.MutateTransaction(t)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
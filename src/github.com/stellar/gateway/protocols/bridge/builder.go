@@ -149,6 +149,8 @@ type OperationBody interface {
 type BuilderResponse struct {
 	protocols.SuccessResponse
 	TransactionEnvelope string `json:"transaction_envelope"`
+	// Hex encoded transaction hash
+	Hash string `json:"hash"`
 }
 
 // Marshal marshals BuilderResponse
@@ -1,6 +1,7 @@
 package bridge
 
 import (
+	"fmt"
 	"strconv"
 
 	"github.com/stellar/gateway/protocols"
@@ -22,12 +23,15 @@ type ManageOfferOperationBody struct {
 
 // ToTransactionMutator returns go-stellar-base TransactionMutator
 func (op ManageOfferOperationBody) ToTransactionMutator() b.TransactionMutator {
+	// Validated in Validate(). Passed as a fraction so the builder does not approximate it.
+	n, d, _ := protocols.ParsePrice(op.Price)
+
 	mutators := []interface{}{
 		b.Amount(op.Amount),
 		b.Rate{
 			Selling: op.Selling.ToBaseAsset(),
 			Buying:  op.Buying.ToBaseAsset(),
-			Price:   b.Price(op.Price),
+			Price:   b.Price(fmt.Sprintf("%d/%d", n, d)),
 		},
 	}
 
@@ -53,6 +57,11 @@ func (op ManageOfferOperationBody) Validate() error {
 		}
 	}
 
+	_, _, err := protocols.ParsePrice(op.Price)
+	if err != nil {
+		return protocols.NewInvalidParameterError("price", op.Price, err.Error())
+	}
+
 	if op.Source != nil && !protocols.IsValidAccountID(*op.Source) {
 		return protocols.NewInvalidParameterError("source", *op.Source, "Source must be a public key (starting with `G`).")
 	}
@@ -0,0 +1,88 @@
+package bridge
+
+import (
+	"net/http"
+	"net/url"
+
+	"github.com/stellar/gateway/protocols"
+	"github.com/stellar/go/amount"
+	"github.com/stellar/go/strkey"
+)
+
+var (
+	// ChangeTrustMalformed is an error response
+	ChangeTrustMalformed = &protocols.ErrorResponse{Code: "change_trust_malformed", Message: "Asset or limit is malformed.", Status: http.StatusBadRequest}
+	// ChangeTrustNoIssuer is an error response
+	ChangeTrustNoIssuer = &protocols.ErrorResponse{Code: "change_trust_no_issuer", Message: "Asset issuer does not exist.", Status: http.StatusBadRequest}
+	// ChangeTrustInvalidLimit is an error response
+	ChangeTrustInvalidLimit = &protocols.ErrorResponse{Code: "change_trust_invalid_limit", Message: "Limit cannot be lower than the balance. Trustline can be removed only when the balance is zero.", Status: http.StatusBadRequest}
+	// ChangeTrustLowReserve is an error response
+	ChangeTrustLowReserve = &protocols.ErrorResponse{Code: "change_trust_low_reserve", Message: "Not enough funds to create a new trustline.", Status: http.StatusBadRequest}
+	// ChangeTrustSelfNotAllowed is an error response
+	ChangeTrustSelfNotAllowed = &protocols.ErrorResponse{Code: "change_trust_self_not_allowed", Message: "Issuer cannot trust its own asset.", Status: http.StatusBadRequest}
+)
+
+// ChangeTrustRequest represents request made to /change-trust endpoint of bridge server
+type ChangeTrustRequest struct {
+	// Source account secret
+	Source      string `name:"source"`
+	AssetCode   string `name:"asset_code" required:""`
+	AssetIssuer string `name:"asset_issuer" required:""`
+	// Trustline limit, max limit when empty. Zero removes the trustline.
+	Limit string `name:"limit"`
+
+	protocols.FormRequest
+}
+
+// FromRequest will populate request fields using http.Request.
+func (request *ChangeTrustRequest) FromRequest(r *http.Request) error {
+	return request.FormRequest.FromRequest(r, request)
+}
+
+// ToValues will create url.Values from request.
+func (request *ChangeTrustRequest) ToValues() url.Values {
+	return request.FormRequest.ToValues(request)
+}
+
+// Validate validates if request fields are valid. Useful when checking if a request is correct.
+func (request *ChangeTrustRequest) Validate() error {
+	err := request.FormRequest.CheckRequired(request)
+	if err != nil {
+		return err
+	}
+
+	if request.Source != "" {
+		err = protocols.CheckKey(request.Source, strkey.VersionByteSeed)
+		if err != nil {
+			return protocols.NewInvalidParameterError("source", "", err.Error())
+		}
+	}
+
+	if !protocols.IsValidAssetCode(request.AssetCode) {
+		return protocols.NewInvalidParameterError("asset_code", request.AssetCode, "Asset code is invalid.")
+	}
+
+	err = protocols.CheckKey(request.AssetIssuer, strkey.VersionByteAccountID)
+	if err != nil {
+		return protocols.NewInvalidParameterError("asset_issuer", request.AssetIssuer, err.Error())
+	}
+
+	if request.Limit != "" {
+		limit, err := amount.Parse(request.Limit)
+		if err != nil || limit < 0 {
+			return protocols.NewInvalidParameterError("limit", request.Limit, "Limit is not a valid amount.")
+		}
+	}
+
+	return nil
+}
+
+// RemovesTrust returns true when the request removes the trustline (limit is zero)
+func (request *ChangeTrustRequest) RemovesTrust() bool {
+	if request.Limit == "" {
+		return false
+	}
+
+	limit, err := amount.Parse(request.Limit)
+	return err == nil && limit == 0
+}
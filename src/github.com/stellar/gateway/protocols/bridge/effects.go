@@ -0,0 +1,98 @@
+package bridge
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"net/url"
+
+	"github.com/stellar/gateway/horizon"
+	"github.com/stellar/gateway/protocols"
+)
+
+var (
+	// EffectsTransactionNotFound is an error response
+	EffectsTransactionNotFound = &protocols.ErrorResponse{Code: "transaction_not_found", Message: "Transaction not found.", Status: http.StatusNotFound}
+)
+
+// EffectsRequest represents request made to /effects endpoint of bridge server
+type EffectsRequest struct {
+	// Hash of the transaction
+	Hash string `name:"hash" required:""`
+
+	protocols.FormRequest
+}
+
+// FromRequest will populate request fields using http.Request.
+func (request *EffectsRequest) FromRequest(r *http.Request) error {
+	return request.FormRequest.FromRequest(r, request)
+}
+
+// ToValues will create url.Values from request.
+func (request *EffectsRequest) ToValues() url.Values {
+	return request.FormRequest.ToValues(request)
+}
+
+// Validate validates if request fields are valid. Useful when checking if a request is correct.
+func (request *EffectsRequest) Validate() error {
+	err := request.FormRequest.CheckRequired(request)
+	if err != nil {
+		return err
+	}
+
+	hash, err := hex.DecodeString(request.Hash)
+	if err != nil || len(hash) != 32 {
+		return protocols.NewInvalidParameterError("hash", request.Hash, "Hash must be 64 hex characters.")
+	}
+
+	return nil
+}
+
+// Effect is a single effect of a transaction. Amount of `account_created` effect is the starting
+// balance, asset of native amounts is `native` with empty code and issuer.
+type Effect struct {
+	ID          string `json:"id"`
+	Type        string `json:"type"`
+	Account     string `json:"account"`
+	Amount      string `json:"amount,omitempty"`
+	AssetType   string `json:"asset_type,omitempty"`
+	AssetCode   string `json:"asset_code,omitempty"`
+	AssetIssuer string `json:"asset_issuer,omitempty"`
+}
+
+// NewEffect creates Effect from effect returned by Horizon
+func NewEffect(effect horizon.EffectResponse) Effect {
+	result := Effect{
+		ID:          effect.ID,
+		Type:        effect.Type,
+		Account:     effect.Account,
+		Amount:      effect.Amount,
+		AssetType:   effect.AssetType,
+		AssetCode:   effect.AssetCode,
+		AssetIssuer: effect.AssetIssuer,
+	}
+
+	if effect.Type == "account_created" {
+		result.Amount = effect.StartingBalance
+		result.AssetType = "native"
+	}
+
+	return result
+}
+
+// EffectsResponse represents a response returned by /effects endpoint
+type EffectsResponse struct {
+	Hash    string   `json:"hash"`
+	Effects []Effect `json:"effects"`
+}
+
+// HTTPStatus returns http status of the response
+func (response EffectsResponse) HTTPStatus() int {
+	return http.StatusOK
+}
+
+// Marshal marshals the response
+func (response EffectsResponse) Marshal() []byte {
+	json, _ := json.MarshalIndent(response, "", "  ")
+	return json
+}
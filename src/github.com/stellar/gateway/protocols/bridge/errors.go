@@ -23,8 +23,31 @@ var (
 	TransactionInsufficientFee = &protocols.ErrorResponse{Code: "transaction_insufficient_fee", Message: "Transaction fee is too small.", Status: http.StatusBadRequest}
 	// TransactionBadAuthExtra is an error response
 	TransactionBadAuthExtra = &protocols.ErrorResponse{Code: "transaction_bad_auth_extra", Message: "Unused signatures attached to transaction.", Status: http.StatusBadRequest}
+	// TransactionNotConfirmed is an error response
+	TransactionNotConfirmed = &protocols.ErrorResponse{Code: "transaction_not_confirmed", Message: "Transaction has been submitted but is not in a ledger yet. Check its status later.", Status: http.StatusAccepted}
 )
 
+// NewTransactionNotConfirmedError creates a new TransactionNotConfirmed error with the hash of the transaction
+func NewTransactionNotConfirmedError(hash string) *protocols.ErrorResponse {
+	return &protocols.ErrorResponse{
+		Status:  TransactionNotConfirmed.Status,
+		Code:    TransactionNotConfirmed.Code,
+		Message: TransactionNotConfirmed.Message,
+		Data:    map[string]interface{}{"hash": hash},
+	}
+}
+
+// NewTransactionInsufficientFeeError creates a new TransactionInsufficientFee error with the current
+// minimum fee per operation (in stroops)
+func NewTransactionInsufficientFeeError(baseFee int64) *protocols.ErrorResponse {
+	return &protocols.ErrorResponse{
+		Status:  TransactionInsufficientFee.Status,
+		Code:    TransactionInsufficientFee.Code,
+		Message: TransactionInsufficientFee.Message,
+		Data:    map[string]interface{}{"base_fee": baseFee},
+	}
+}
+
 // ErrorFromHorizonResponse checks if horizon.SubmitTransactionResponse is an error response and creates ErrorResponse for it
 func ErrorFromHorizonResponse(response horizon.SubmitTransactionResponse) *protocols.ErrorResponse {
 	if response.Ledger == nil && response.Extras != nil {
@@ -79,6 +102,45 @@ func ErrorFromHorizonResponse(response horizon.SubmitTransactionResponse) *proto
 				default:
 					return protocols.InternalServerError
 				}
+			} else if operationsResult.Tr.ChangeTrustResult != nil {
+				switch operationsResult.Tr.ChangeTrustResult.Code {
+				case xdr.ChangeTrustResultCodeChangeTrustMalformed:
+					return ChangeTrustMalformed
+				case xdr.ChangeTrustResultCodeChangeTrustNoIssuer:
+					return ChangeTrustNoIssuer
+				case xdr.ChangeTrustResultCodeChangeTrustInvalidLimit:
+					return ChangeTrustInvalidLimit
+				case xdr.ChangeTrustResultCodeChangeTrustLowReserve:
+					return ChangeTrustLowReserve
+				case xdr.ChangeTrustResultCodeChangeTrustSelfNotAllowed:
+					return ChangeTrustSelfNotAllowed
+				default:
+					return protocols.InternalServerError
+				}
+			} else if operationsResult.Tr.SetOptionsResult != nil {
+				switch operationsResult.Tr.SetOptionsResult.Code {
+				case xdr.SetOptionsResultCodeSetOptionsLowReserve:
+					return SetOptionsLowReserve
+				case xdr.SetOptionsResultCodeSetOptionsTooManySigners:
+					return SetOptionsTooManySigners
+				case xdr.SetOptionsResultCodeSetOptionsBadSigner:
+					return SetOptionsBadSigner
+				default:
+					return protocols.InternalServerError
+				}
+			} else if operationsResult.Tr.CreateAccountResult != nil {
+				switch operationsResult.Tr.CreateAccountResult.Code {
+				case xdr.CreateAccountResultCodeCreateAccountMalformed:
+					return PaymentMalformed
+				case xdr.CreateAccountResultCodeCreateAccountUnderfunded:
+					return PaymentUnderfunded
+				case xdr.CreateAccountResultCodeCreateAccountAlreadyExist:
+					return PaymentAccountAlreadyExists
+				case xdr.CreateAccountResultCodeCreateAccountLowReserve:
+					return PaymentLowReserve
+				default:
+					return protocols.InternalServerError
+				}
 			} else if operationsResult.Tr.PaymentResult != nil {
 				switch operationsResult.Tr.PaymentResult.Code {
 				case xdr.PaymentResultCodePaymentMalformed:
@@ -140,6 +202,27 @@ func ErrorFromHorizonResponse(response horizon.SubmitTransactionResponse) *proto
 	return nil
 }
 
+// CreateAccountAlreadyExists checks if a transaction failed because destination of its
+// create_account operation already exists
+func CreateAccountAlreadyExists(response horizon.SubmitTransactionResponse) bool {
+	if response.Ledger != nil || response.Extras == nil {
+		return false
+	}
+
+	txResult, err := unmarshalTransactionResult(response.Extras.ResultXdr)
+	if err != nil || txResult.Result.Results == nil {
+		return false
+	}
+
+	for _, result := range *txResult.Result.Results {
+		if result.Tr != nil && result.Tr.CreateAccountResult != nil &&
+			result.Tr.CreateAccountResult.Code == xdr.CreateAccountResultCodeCreateAccountAlreadyExist {
+			return true
+		}
+	}
+	return false
+}
+
 func unmarshalTransactionResult(transactionResult string) (txResult xdr.TransactionResult, err error) {
 	reader := strings.NewReader(transactionResult)
 	b64r := base64.NewDecoder(base64.StdEncoding, reader)
@@ -0,0 +1,62 @@
+package bridge
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/url"
+
+	"github.com/stellar/gateway/protocols"
+	"github.com/stellar/go/address"
+)
+
+// FederationRequest represents request made to /federation endpoint of bridge server
+type FederationRequest struct {
+	// Stellar address (like bob*stellar.org)
+	Address string `name:"address" required:""`
+
+	protocols.FormRequest
+}
+
+// FromRequest will populate request fields using http.Request.
+func (request *FederationRequest) FromRequest(r *http.Request) error {
+	return request.FormRequest.FromRequest(r, request)
+}
+
+// ToValues will create url.Values from request.
+func (request *FederationRequest) ToValues() url.Values {
+	return request.FormRequest.ToValues(request)
+}
+
+// Validate validates if request fields are valid. Useful when checking if a request is correct.
+func (request *FederationRequest) Validate() error {
+	err := request.FormRequest.CheckRequired(request)
+	if err != nil {
+		return err
+	}
+
+	_, _, err = address.Split(request.Address)
+	if err != nil {
+		return protocols.NewInvalidParameterError("address", request.Address, "Address must be a Stellar address (like bob*stellar.org).")
+	}
+
+	return nil
+}
+
+// FederationResponse represents a response returned by /federation endpoint
+type FederationResponse struct {
+	Address   string `json:"address"`
+	AccountID string `json:"account_id"`
+	MemoType  string `json:"memo_type,omitempty"`
+	Memo      string `json:"memo,omitempty"`
+}
+
+// HTTPStatus returns http status of the response
+func (response FederationResponse) HTTPStatus() int {
+	return http.StatusOK
+}
+
+// Marshal marshals the response
+func (response FederationResponse) Marshal() []byte {
+	json, _ := json.MarshalIndent(response, "", "  ")
+	return json
+}
@@ -0,0 +1,99 @@
+package bridge
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/url"
+
+	"github.com/stellar/gateway/protocols"
+)
+
+// FeeStatsConfidences are percentiles of fees returned by /fee-stats endpoint and accepted as
+// its `confidence` param
+var FeeStatsConfidences = []string{"p10", "p50", "p90", "p99"}
+
+// IsFeeStatsConfidence returns true when confidence is one of FeeStatsConfidences
+func IsFeeStatsConfidence(confidence string) bool {
+	for _, c := range FeeStatsConfidences {
+		if c == confidence {
+			return true
+		}
+	}
+	return false
+}
+
+// FeeStatsRequest represents request made to /fee-stats endpoint of bridge server
+type FeeStatsRequest struct {
+	// Percentile the recommended fee is based on, `fee_stats.confidence` config param is used when empty
+	Confidence string `name:"confidence"`
+
+	protocols.FormRequest
+}
+
+// FromRequest will populate request fields using http.Request.
+func (request *FeeStatsRequest) FromRequest(r *http.Request) error {
+	return request.FormRequest.FromRequest(r, request)
+}
+
+// ToValues will create url.Values from request.
+func (request *FeeStatsRequest) ToValues() url.Values {
+	return request.FormRequest.ToValues(request)
+}
+
+// Validate validates if request fields are valid. Useful when checking if a request is correct.
+func (request *FeeStatsRequest) Validate() error {
+	if request.Confidence != "" && !IsFeeStatsConfidence(request.Confidence) {
+		return protocols.NewInvalidParameterError("confidence", request.Confidence, "Confidence must be one of: p10, p50, p90, p99.")
+	}
+	return nil
+}
+
+// FeePercentiles contains percentiles of fees per operation in stroops
+type FeePercentiles struct {
+	P10 int64 `json:"p10"`
+	P50 int64 `json:"p50"`
+	P90 int64 `json:"p90"`
+	P99 int64 `json:"p99"`
+}
+
+// Get returns the percentile named confidence (ex. `p90`), 0 when the name is unknown
+func (p FeePercentiles) Get(confidence string) int64 {
+	switch confidence {
+	case "p10":
+		return p.P10
+	case "p50":
+		return p.P50
+	case "p90":
+		return p.P90
+	case "p99":
+		return p.P99
+	default:
+		return 0
+	}
+}
+
+// FeeStatsResponse represents a response returned by /fee-stats endpoint
+type FeeStatsResponse struct {
+	LastLedger int64 `json:"last_ledger"`
+	// Base fee per operation of the last ledger in stroops
+	BaseFee             int64  `json:"base_fee"`
+	LedgerCapacityUsage string `json:"ledger_capacity_usage"`
+	// Fees per operation charged to transactions included in the last ledgers
+	FeeCharged FeePercentiles `json:"fee_charged"`
+	// Maximum fees per operation transactions included in the last ledgers were willing to pay
+	MaxFee     FeePercentiles `json:"max_fee"`
+	Confidence string         `json:"confidence"`
+	// Fee per operation in stroops: `max_fee` percentile of Confidence, at least BaseFee
+	RecommendedFee int64 `json:"recommended_fee"`
+}
+
+// HTTPStatus returns http status of the response
+func (response FeeStatsResponse) HTTPStatus() int {
+	return http.StatusOK
+}
+
+// Marshal marshals the response
+func (response FeeStatsResponse) Marshal() []byte {
+	json, _ := json.MarshalIndent(response, "", "  ")
+	return json
+}
@@ -0,0 +1,40 @@
+package bridge
+
+import (
+	"encoding/json"
+	"net/http"
+)
+
+// HealthResponse represents a response returned by /health endpoint
+type HealthResponse struct {
+	// `ok` or `paused` when the server is paused for maintenance
+	Status string `json:"status"`
+	Paused bool   `json:"paused"`
+}
+
+// HTTPStatus returns http status of the response
+func (response HealthResponse) HTTPStatus() int {
+	return http.StatusOK
+}
+
+// Marshal marshals the response
+func (response HealthResponse) Marshal() []byte {
+	json, _ := json.MarshalIndent(response, "", "  ")
+	return json
+}
+
+// MaintenanceResponse represents a response returned by /admin/maintenance endpoint
+type MaintenanceResponse struct {
+	Paused bool `json:"paused"`
+}
+
+// HTTPStatus returns http status of the response
+func (response MaintenanceResponse) HTTPStatus() int {
+	return http.StatusOK
+}
+
+// Marshal marshals the response
+func (response MaintenanceResponse) Marshal() []byte {
+	json, _ := json.MarshalIndent(response, "", "  ")
+	return json
+}
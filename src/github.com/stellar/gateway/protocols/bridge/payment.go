@@ -1,13 +1,17 @@
 package bridge
 
 import (
+	"math"
 	"net/http"
 	"net/url"
+	"strconv"
 	"strings"
 
 	"github.com/stellar/gateway/protocols"
 	callback "github.com/stellar/gateway/protocols/compliance"
+	"github.com/stellar/go/address"
 	"github.com/stellar/go/keypair"
+	"github.com/stellar/go/strkey"
 )
 
 var (
@@ -15,12 +19,42 @@ var (
 
 	// PaymentCannotResolveDestination is an error response
 	PaymentCannotResolveDestination = &protocols.ErrorResponse{Code: "cannot_resolve_destination", Message: "Cannot resolve federated Stellar address.", Status: http.StatusBadRequest}
+	// PaymentCannotResolveSender is an error response
+	PaymentCannotResolveSender = &protocols.ErrorResponse{Code: "cannot_resolve_sender", Message: "Sender param is missing and it cannot be resolved by federation server of the source account home domain.", Status: http.StatusBadRequest}
 	// PaymentCannotUseMemo is an error response
 	PaymentCannotUseMemo = &protocols.ErrorResponse{Code: "cannot_use_memo", Message: "Memo given in request but federation returned memo fields.", Status: http.StatusBadRequest}
 	// PaymentSourceNotExist is an error response
 	PaymentSourceNotExist = &protocols.ErrorResponse{Code: "source_not_exist", Message: "Source account does not exist.", Status: http.StatusBadRequest}
 	// PaymentAssetCodeNotAllowed is an error response
 	PaymentAssetCodeNotAllowed = &protocols.ErrorResponse{Code: "asset_code_not_allowed", Message: "Given asset_code not allowed.", Status: http.StatusBadRequest}
+	// PaymentMemoNotAllowed is an error response
+	PaymentMemoNotAllowed = &protocols.ErrorResponse{Code: "memo_not_allowed", Message: "Memo is not allowed by this server.", Status: http.StatusBadRequest}
+	// PaymentMemoRequired is an error response
+	PaymentMemoRequired = &protocols.ErrorResponse{Code: "memo_required", Message: "Destination requires a memo.", Status: http.StatusBadRequest}
+	// PaymentMemoTypeNotAllowed is an error response
+	PaymentMemoTypeNotAllowed = &protocols.ErrorResponse{Code: "memo_type_not_allowed", Message: "Memo type is not accepted by destination.", Status: http.StatusBadRequest}
+	// PaymentMemoTypeForbidden is an error response
+	PaymentMemoTypeForbidden = &protocols.ErrorResponse{Code: "memo_type_forbidden", Message: "Memo type is not allowed by this server.", Status: http.StatusBadRequest}
+	// PaymentComplianceRequired is an error response
+	PaymentComplianceRequired = &protocols.ErrorResponse{Code: "compliance_required", Message: "Payment must be sent using compliance protocol.", Status: http.StatusBadRequest}
+	// PaymentComplianceNotConfigured is an error response
+	PaymentComplianceNotConfigured = &protocols.ErrorResponse{Code: "compliance_not_configured", Message: "extra_memo can be sent using compliance protocol only but compliance server is not configured.", Status: http.StatusBadRequest}
+	// PaymentIdempotencyKeyConflict is an error response
+	PaymentIdempotencyKeyConflict = &protocols.ErrorResponse{Code: "idempotency_key_conflict", Message: "Payment with given id has already been sent with different params.", Status: http.StatusConflict}
+	// PaymentRateLimited is an error response
+	PaymentRateLimited = &protocols.ErrorResponse{Code: "rate_limited", Message: "Rate limit of the asset exceeded. Repeat your request later.", Status: http.StatusTooManyRequests}
+	// PaymentAssetNotAllowed is an error response
+	PaymentAssetNotAllowed = &protocols.ErrorResponse{Code: "asset_not_allowed", Message: "Asset is not allowed by this server.", Status: http.StatusBadRequest}
+	// PaymentSourceUnderfunded is an error response
+	PaymentSourceUnderfunded = &protocols.ErrorResponse{Code: "source_underfunded", Message: "Source account balance does not cover the starting balance of the new account, transaction fee and the minimum balance of the source.", Status: http.StatusBadRequest}
+	// PaymentWouldBreachFloor is an error response
+	PaymentWouldBreachFloor = &protocols.ErrorResponse{Code: "would_breach_floor", Message: "Payment would bring the balance of the source account below its configured minimum balance.", Status: http.StatusBadRequest}
+	// PaymentFederationDiscoveryFailed is an error response
+	PaymentFederationDiscoveryFailed = &protocols.ErrorResponse{Code: "federation_discovery_failed", Message: "Cannot fetch stellar.toml file of the federation domain. Repeat your request later.", Status: http.StatusBadGateway}
+	// PaymentNotIssuance is an error response
+	PaymentNotIssuance = &protocols.ErrorResponse{Code: "not_issuance", Message: "This server only issues assets. Asset must be issued by the source account of the payment.", Status: http.StatusBadRequest}
+	// PaymentDestinationNotAuthorized is an error response
+	PaymentDestinationNotAuthorized = &protocols.ErrorResponse{Code: "destination_not_authorized", Message: "Destination trustline is not authorized by the asset issuer. It needs to be allowed first by using /authorize endpoint.", Status: http.StatusBadRequest}
 
 	// compliance
 
@@ -28,6 +62,14 @@ var (
 	PaymentPending = &protocols.ErrorResponse{Code: "pending", Message: "Transaction pending. Repeat your request after given time.", Status: http.StatusAccepted}
 	// PaymentDenied is an error response
 	PaymentDenied = &protocols.ErrorResponse{Code: "denied", Message: "Transaction denied by destination.", Status: http.StatusForbidden}
+	// PaymentQueued is an error response
+	PaymentQueued = &protocols.ErrorResponse{Code: "queued", Message: "Compliance server is unavailable. Payment has been queued and will be sent when it is back.", Status: http.StatusAccepted}
+	// PaymentPendingQueued is an error response
+	PaymentPendingQueued = &protocols.ErrorResponse{Code: "pending_queued", Message: "Transaction pending. Payment has been queued and will be sent when compliance server approves it.", Status: http.StatusAccepted}
+	// PaymentInvalidAmount is an error response
+	PaymentInvalidAmount = &protocols.ErrorResponse{Code: "payment_invalid_amount", Message: "Amount must be greater than zero. Zero amount payments are not allowed and accounts cannot be created with zero starting balance.", Status: http.StatusBadRequest}
+	// PaymentComplianceResponseMismatch is an error response
+	PaymentComplianceResponseMismatch = &protocols.ErrorResponse{Code: "compliance_response_mismatch", Message: "Transaction built by compliance server does not match the payment request. It has not been sent.", Status: http.StatusBadGateway}
 
 	// payment op errors
 
@@ -49,6 +91,10 @@ var (
 	PaymentLineFull = &protocols.ErrorResponse{Code: "payment_line_full", Message: "Sending this payment would make a destination go above their limit.", Status: http.StatusBadRequest}
 	// PaymentNoIssuer is an error response
 	PaymentNoIssuer = &protocols.ErrorResponse{Code: "payment_no_issuer", Message: "Missing issuer on asset.", Status: http.StatusBadRequest}
+	// PaymentAccountAlreadyExists is an error response
+	PaymentAccountAlreadyExists = &protocols.ErrorResponse{Code: "payment_account_already_exists", Message: "Destination account already exists. Send the payment using payment operation.", Status: http.StatusBadRequest}
+	// PaymentLowReserve is an error response
+	PaymentLowReserve = &protocols.ErrorResponse{Code: "payment_low_reserve", Message: "Amount is too low to create the destination account.", Status: http.StatusBadRequest}
 	// PaymentTooFewOffers is an error response
 	PaymentTooFewOffers = &protocols.ErrorResponse{Code: "payment_too_few_offers", Message: "Not enough offers to satisfy path.", Status: http.StatusBadRequest}
 	// PaymentOfferCrossSelf is an error response
@@ -61,8 +107,10 @@ var (
 type PaymentRequest struct {
 	// Payment ID
 	ID string `name:"id"`
-	// Source account secret
+	// Source account secret or, when Signer is set, source account ID
 	Source string `name:"source"`
+	// Secret of a signer of the source account. Used when Source is an account ID.
+	Signer string `name:"signer"`
 	// Sender address (like alice*stellar.org)
 	Sender string `name:"sender"`
 	// Destination address (like bob*stellar.org)
@@ -91,6 +139,29 @@ type PaymentRequest struct {
 	UseCompliance bool `name:"use_compliance"`
 	// Extra memo. If set, UseCompliance value will be ignored and it will use compliance.
 	ExtraMemo string `name:"extra_memo"`
+	// Forces operation type (`payment` or `create_account`) of XLM payments skipping destination account existence check
+	Operation string `name:"operation"`
+	// When true result_meta_xdr of the submitted transaction is returned
+	IncludeMeta bool `name:"include_meta"`
+	// When true the response is sent after the transaction is found in a ledger
+	WaitForConfirmation bool `name:"wait_for_confirmation"`
+	// `sync` or `async`, `submission.mode` config param is used when empty. Compliance payments are always submitted synchronously.
+	SubmissionMode string `name:"submission_mode"`
+	// Name of a data entry set on the source account by manage_data operation sent in the same transaction
+	DataName string `name:"data_name"`
+	// Value of the data entry
+	DataValue string `name:"data_value"`
+	// When true and destination does not trust the asset, change_trust operation is sent before the payment
+	AutoTrust bool `name:"auto_trust"`
+	// Destination account secret. Signs change_trust operation when AutoTrust is set.
+	DestinationSeed string `name:"destination_seed"`
+	// SEP-7 payment URI (web+stellar:pay?...). Destination, amount, asset and memo are read from it.
+	URI string `name:"uri"`
+	// Sequence number of the transaction. Current sequence number of the source account is used when empty.
+	SequenceNumber string `name:"sequence_number"`
+
+	// Account ID of the destination once it's resolved (ex. by federation or compliance server). Not a param.
+	DestinationAccountID string
 
 	protocols.FormRequest
 }
@@ -134,9 +205,33 @@ func (request *PaymentRequest) Validate() error {
 	}
 
 	if request.Source != "" {
-		_, err = keypair.Parse(request.Source)
+		err = protocols.CheckKey(request.Source, strkey.VersionByteSeed)
+		// Account ID is also accepted, a separate signer is checked below
+		if err == protocols.ErrKeyNotSecret && protocols.IsValidAccountID(request.Source) {
+			err = nil
+		}
+		if err != nil {
+			return protocols.NewInvalidParameterError("source", "", err.Error())
+		}
+	}
+
+	// Source account ID must be signed by a separate signer
+	if protocols.IsValidAccountID(request.Source) && request.Signer == "" {
+		return protocols.NewMissingParameter("signer")
+	}
+
+	if request.Signer != "" {
+		if request.Source == "" {
+			return protocols.NewMissingParameter("source")
+		}
+
+		if !protocols.IsValidAccountID(request.Source) {
+			return protocols.NewInvalidParameterError("source", "", "Source must be an account ID (starting with `G`) when signer is sent.")
+		}
+
+		err = protocols.CheckKey(request.Signer, strkey.VersionByteSeed)
 		if err != nil {
-			return protocols.NewInvalidParameterError("source", request.Source, "Source must be a public key (starting with `G`).")
+			return protocols.NewInvalidParameterError("signer", "", err.Error())
 		}
 	}
 
@@ -144,6 +239,13 @@ func (request *PaymentRequest) Validate() error {
 		return protocols.NewMissingParameter("destination")
 	}
 
+	if request.Sender != "" {
+		_, _, err = address.Split(request.Sender)
+		if err != nil {
+			return protocols.NewInvalidParameterError("sender", request.Sender, "Sender must be a Stellar address.")
+		}
+	}
+
 	if !protocols.IsValidAmount(request.Amount) {
 		return protocols.NewInvalidParameterError("amount", request.Amount, "Invalid amount.")
 	}
@@ -182,8 +284,70 @@ func (request *PaymentRequest) Validate() error {
 	}
 
 	if request.AssetIssuer != "" {
-		if !protocols.IsValidAccountID(request.AssetIssuer) {
-			return protocols.NewInvalidParameterError("asset_issuer", request.AssetIssuer, "Asset issuer must be a public key (starting with `G`).")
+		err = protocols.CheckKey(request.AssetIssuer, strkey.VersionByteAccountID)
+		if err != nil {
+			return protocols.NewInvalidParameterError("asset_issuer", request.AssetIssuer, err.Error())
+		}
+	}
+
+	switch request.Operation {
+	case "", "payment":
+	case "create_account":
+		if request.AssetCode != "" || request.SendMax != "" {
+			return protocols.NewInvalidParameterError("operation", request.Operation, "create_account operation can only send XLM without path.")
+		}
+	default:
+		return protocols.NewInvalidParameterError("operation", request.Operation, "Operation must be `payment` or `create_account`.")
+	}
+
+	err = checkSubmissionMode(request.SubmissionMode)
+	if err != nil {
+		return err
+	}
+
+	// Data entry
+	if request.SequenceNumber != "" {
+		sequenceNumber, err := strconv.ParseUint(request.SequenceNumber, 10, 64)
+		if err != nil || sequenceNumber == 0 || sequenceNumber > math.MaxInt64 {
+			return protocols.NewInvalidParameterError("sequence_number", request.SequenceNumber, "Sequence number must be a positive integer.")
+		}
+	}
+
+	if request.DataName == "" && request.DataValue != "" {
+		return protocols.NewMissingParameter("data_name")
+	}
+
+	if request.DataName != "" && request.DataValue == "" {
+		return protocols.NewMissingParameter("data_value")
+	}
+
+	if len(request.DataName) > 64 {
+		return protocols.NewInvalidParameterError("data_name", request.DataName, "Data name must be at most 64 bytes long.")
+	}
+
+	if len(request.DataValue) > 64 {
+		return protocols.NewInvalidParameterError("data_value", request.DataValue, "Data value must be at most 64 bytes long.")
+	}
+
+	// Auto trust
+	if request.AutoTrust {
+		if request.AssetCode == "" {
+			return protocols.NewInvalidParameterError("auto_trust", "true", "Trustline can be added for credit assets only.")
+		}
+
+		if request.DestinationSeed == "" {
+			return protocols.NewMissingParameter("destination_seed")
+		}
+	}
+
+	if request.DestinationSeed != "" {
+		if !request.AutoTrust {
+			return protocols.NewInvalidParameterError("destination_seed", "", "Destination seed can be sent with auto_trust only.")
+		}
+
+		err = protocols.CheckKey(request.DestinationSeed, strkey.VersionByteSeed)
+		if err != nil {
+			return protocols.NewInvalidParameterError("destination_seed", "", err.Error())
 		}
 	}
 
@@ -206,8 +370,9 @@ func (request *PaymentRequest) Validate() error {
 	}
 
 	if request.SendAssetIssuer != "" {
-		if !protocols.IsValidAccountID(request.SendAssetIssuer) {
-			return protocols.NewInvalidParameterError("send_asset_issuer", request.SendAssetIssuer, "Send asset issuer must be a public key (starting with `G`).")
+		err = protocols.CheckKey(request.SendAssetIssuer, strkey.VersionByteAccountID)
+		if err != nil {
+			return protocols.NewInvalidParameterError("send_asset_issuer", request.SendAssetIssuer, err.Error())
 		}
 	}
 
@@ -228,3 +393,120 @@ func NewPaymentPendingError(seconds int) *protocols.ErrorResponse {
 		Data:    map[string]interface{}{"pending": seconds},
 	}
 }
+
+// NewPaymentComplianceResponseMismatchError creates a new PaymentComplianceResponseMismatch error.
+// `name` is the request param (or transaction field) the transaction does not match.
+func NewPaymentComplianceResponseMismatchError(name string) *protocols.ErrorResponse {
+	return &protocols.ErrorResponse{
+		Status:  PaymentComplianceResponseMismatch.Status,
+		Code:    PaymentComplianceResponseMismatch.Code,
+		Message: PaymentComplianceResponseMismatch.Message,
+		Data:    map[string]interface{}{"name": name},
+	}
+}
+
+// NewPaymentMemoRequiredError creates a new PaymentMemoRequired error
+func NewPaymentMemoRequiredError(destination string, memoTypes []string) *protocols.ErrorResponse {
+	return &protocols.ErrorResponse{
+		Status:  PaymentMemoRequired.Status,
+		Code:    PaymentMemoRequired.Code,
+		Message: PaymentMemoRequired.Message,
+		Data:    map[string]interface{}{"destination": destination, "memo_types": memoTypes},
+	}
+}
+
+// NewPaymentMemoTypeNotAllowedError creates a new PaymentMemoTypeNotAllowed error
+func NewPaymentMemoTypeNotAllowedError(destination string, memoTypes []string) *protocols.ErrorResponse {
+	return &protocols.ErrorResponse{
+		Status:  PaymentMemoTypeNotAllowed.Status,
+		Code:    PaymentMemoTypeNotAllowed.Code,
+		Message: PaymentMemoTypeNotAllowed.Message,
+		Data:    map[string]interface{}{"destination": destination, "memo_types": memoTypes},
+	}
+}
+
+// NewPaymentMemoTypeForbiddenError creates a new PaymentMemoTypeForbidden error listing memo types allowed by the server
+func NewPaymentMemoTypeForbiddenError(memoTypes []string) *protocols.ErrorResponse {
+	return &protocols.ErrorResponse{
+		Status:  PaymentMemoTypeForbidden.Status,
+		Code:    PaymentMemoTypeForbidden.Code,
+		Message: PaymentMemoTypeForbidden.Message,
+		Data:    map[string]interface{}{"memo_types": memoTypes},
+	}
+}
+
+// NewPaymentAssetNotAllowedError creates a new PaymentAssetNotAllowed error
+func NewPaymentAssetNotAllowedError(assetCode, assetIssuer string) *protocols.ErrorResponse {
+	return &protocols.ErrorResponse{
+		Status:  PaymentAssetNotAllowed.Status,
+		Code:    PaymentAssetNotAllowed.Code,
+		Message: PaymentAssetNotAllowed.Message,
+		Data:    map[string]interface{}{"asset_code": assetCode, "asset_issuer": assetIssuer},
+	}
+}
+
+// NewPaymentSourceUnderfundedError creates a new PaymentSourceUnderfunded error. `available` is the XLM
+// balance of the source, `required` is the balance needed to create the account.
+func NewPaymentSourceUnderfundedError(available, required string) *protocols.ErrorResponse {
+	return &protocols.ErrorResponse{
+		Status:  PaymentSourceUnderfunded.Status,
+		Code:    PaymentSourceUnderfunded.Code,
+		Message: PaymentSourceUnderfunded.Message,
+		Data:    map[string]interface{}{"available": available, "required": required},
+	}
+}
+
+// NewPaymentWouldBreachFloorError creates a new PaymentWouldBreachFloor error. `balance` is the
+// current balance of the asset and `balanceAfter` the balance after the payment and fees are paid.
+func NewPaymentWouldBreachFloorError(accountID, assetCode, assetIssuer, balance, balanceAfter, minBalance string) *protocols.ErrorResponse {
+	return &protocols.ErrorResponse{
+		Status:  PaymentWouldBreachFloor.Status,
+		Code:    PaymentWouldBreachFloor.Code,
+		Message: PaymentWouldBreachFloor.Message,
+		Data: map[string]interface{}{
+			"account_id":    accountID,
+			"asset_code":    assetCode,
+			"asset_issuer":  assetIssuer,
+			"balance":       balance,
+			"balance_after": balanceAfter,
+			"min_balance":   minBalance,
+		},
+	}
+}
+
+// NewPaymentFederationDiscoveryFailedError creates a new PaymentFederationDiscoveryFailed error with
+// the domain stellar.toml file of which cannot be fetched
+func NewPaymentFederationDiscoveryFailedError(domain string) *protocols.ErrorResponse {
+	return &protocols.ErrorResponse{
+		Status:  PaymentFederationDiscoveryFailed.Status,
+		Code:    PaymentFederationDiscoveryFailed.Code,
+		Message: PaymentFederationDiscoveryFailed.Message,
+		Data:    map[string]interface{}{"domain": domain},
+	}
+}
+
+// NewPaymentNotIssuanceError creates a new PaymentNotIssuance error. `name` is the param of the
+// asset issuer, `source` is the account sending the asset.
+func NewPaymentNotIssuanceError(name, assetCode, assetIssuer, source string) *protocols.ErrorResponse {
+	return &protocols.ErrorResponse{
+		Status:  PaymentNotIssuance.Status,
+		Code:    PaymentNotIssuance.Code,
+		Message: PaymentNotIssuance.Message,
+		Data: map[string]interface{}{
+			"name":         name,
+			"asset_code":   assetCode,
+			"asset_issuer": assetIssuer,
+			"source":       source,
+		},
+	}
+}
+
+// NewPaymentRateLimitedError creates a new PaymentRateLimited error
+func NewPaymentRateLimitedError(assetCode, assetIssuer string) *protocols.ErrorResponse {
+	return &protocols.ErrorResponse{
+		Status:  PaymentRateLimited.Status,
+		Code:    PaymentRateLimited.Code,
+		Message: PaymentRateLimited.Message,
+		Data:    map[string]interface{}{"asset_code": assetCode, "asset_issuer": assetIssuer},
+	}
+}
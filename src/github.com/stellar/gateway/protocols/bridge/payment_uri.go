@@ -0,0 +1,123 @@
+package bridge
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/hex"
+	"net/url"
+	"strings"
+
+	"github.com/stellar/gateway/protocols"
+	"github.com/stellar/go/keypair"
+)
+
+const (
+	// PaymentURIScheme is a scheme of SEP-7 URIs
+	PaymentURIScheme = "web+stellar:"
+	// paymentURISignaturePrefix is prepended to the signed part of SEP-7 URI before verifying the signature
+	paymentURISignaturePrefix = "stellar.sep.7 - URI Scheme"
+)
+
+// PaymentURI represents SEP-7 `pay` operation URI (web+stellar:pay?destination=...).
+// Memo type and value are converted to the format used by /payment endpoint.
+type PaymentURI struct {
+	Destination       string
+	Amount            string
+	AssetCode         string
+	AssetIssuer       string
+	MemoType          string
+	Memo              string
+	NetworkPassphrase string
+	OriginDomain      string
+	Signature         []byte
+	// part of the URI covered by the signature
+	signed string
+}
+
+// ParsePaymentURI parses SEP-7 `pay` operation URI. Other operations are not supported.
+func ParsePaymentURI(uri string) (*PaymentURI, error) {
+	if !strings.HasPrefix(uri, PaymentURIScheme) {
+		return nil, protocols.NewInvalidParameterError("uri", uri, "URI must start with `web+stellar:`.")
+	}
+
+	operation := strings.TrimPrefix(uri, PaymentURIScheme)
+	query := ""
+	if i := strings.Index(operation, "?"); i >= 0 {
+		operation, query = operation[:i], operation[i+1:]
+	}
+
+	if operation != "pay" {
+		return nil, protocols.NewInvalidParameterError("uri", uri, "Only `pay` operation is supported.")
+	}
+
+	values, err := url.ParseQuery(query)
+	if err != nil {
+		return nil, protocols.NewInvalidParameterError("uri", uri, "URI query is invalid.")
+	}
+
+	paymentURI := &PaymentURI{
+		Destination:       values.Get("destination"),
+		Amount:            values.Get("amount"),
+		AssetCode:         values.Get("asset_code"),
+		AssetIssuer:       values.Get("asset_issuer"),
+		Memo:              values.Get("memo"),
+		NetworkPassphrase: values.Get("network_passphrase"),
+		OriginDomain:      values.Get("origin_domain"),
+	}
+
+	if paymentURI.Destination == "" {
+		return nil, protocols.NewInvalidParameterError("uri", uri, "URI destination is missing.")
+	}
+
+	if paymentURI.Memo != "" {
+		switch values.Get("memo_type") {
+		case "", "MEMO_TEXT":
+			paymentURI.MemoType = "text"
+		case "MEMO_ID":
+			paymentURI.MemoType = "id"
+		case "MEMO_HASH":
+			// SEP-7 hash memos are base64 encoded, /payment expects hex
+			memo, err := base64.StdEncoding.DecodeString(paymentURI.Memo)
+			if err != nil {
+				return nil, protocols.NewInvalidParameterError("uri", uri, "URI hash memo must be base64 encoded.")
+			}
+			paymentURI.MemoType = "hash"
+			paymentURI.Memo = hex.EncodeToString(memo)
+		default:
+			return nil, protocols.NewInvalidParameterError("uri", uri, "URI memo type not supported.")
+		}
+	}
+
+	// Signature must be the last param, everything before it is signed
+	if i := strings.LastIndex(uri, "&signature="); i >= 0 {
+		paymentURI.signed = uri[:i]
+		paymentURI.Signature, err = base64.StdEncoding.DecodeString(values.Get("signature"))
+		if err != nil || len(paymentURI.Signature) == 0 {
+			return nil, protocols.NewInvalidParameterError("uri", uri, "URI signature must be base64 encoded.")
+		}
+	} else if values.Get("signature") != "" {
+		return nil, protocols.NewInvalidParameterError("uri", uri, "URI signature must be the last param.")
+	}
+
+	if paymentURI.OriginDomain != "" && paymentURI.Signature == nil {
+		return nil, protocols.NewInvalidParameterError("uri", uri, "URI with origin_domain must be signed.")
+	}
+
+	return paymentURI, nil
+}
+
+// Verify checks the signature of the URI using `URI_REQUEST_SIGNING_KEY` of the origin domain
+func (uri *PaymentURI) Verify(signingKey string) error {
+	kp, err := keypair.Parse(signingKey)
+	if err != nil {
+		return err
+	}
+
+	var payload bytes.Buffer
+	payload.Write(make([]byte, 35))
+	payload.WriteByte(4)
+	payload.WriteString(paymentURISignaturePrefix)
+	payload.WriteString(uri.signed)
+
+	return kp.Verify(payload.Bytes(), uri.Signature)
+}
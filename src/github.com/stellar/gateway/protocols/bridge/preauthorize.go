@@ -0,0 +1,132 @@
+package bridge
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"strconv"
+
+	"github.com/stellar/gateway/horizon"
+	"github.com/stellar/gateway/protocols"
+	"github.com/stellar/go/keypair"
+	"github.com/stellar/go/strkey"
+	"github.com/stellar/go/xdr"
+)
+
+var (
+	// SetOptionsLowReserve is an error response
+	SetOptionsLowReserve = &protocols.ErrorResponse{Code: "set_options_low_reserve", Message: "Not enough funds to add a new signer.", Status: http.StatusBadRequest}
+	// SetOptionsTooManySigners is an error response
+	SetOptionsTooManySigners = &protocols.ErrorResponse{Code: "set_options_too_many_signers", Message: "Account already has the maximum number of signers.", Status: http.StatusBadRequest}
+	// SetOptionsBadSigner is an error response
+	SetOptionsBadSigner = &protocols.ErrorResponse{Code: "set_options_bad_signer", Message: "Signer is invalid.", Status: http.StatusBadRequest}
+)
+
+// PreauthorizeRequest represents request made to /preauthorize endpoint of bridge server
+type PreauthorizeRequest struct {
+	// Source account secret
+	Source string `name:"source"`
+	// Base64 encoded envelope of the transaction to pre-authorize. Signatures are ignored.
+	TransactionEnvelope string `name:"tx" required:""`
+	// Weight of the signer, 1 when empty. Zero removes the signer.
+	Weight string `name:"weight"`
+
+	protocols.FormRequest
+}
+
+// FromRequest will populate request fields using http.Request.
+func (request *PreauthorizeRequest) FromRequest(r *http.Request) error {
+	return request.FormRequest.FromRequest(r, request)
+}
+
+// ToValues will create url.Values from request.
+func (request *PreauthorizeRequest) ToValues() url.Values {
+	return request.FormRequest.ToValues(request)
+}
+
+// Validate validates if request fields are valid. Useful when checking if a request is correct.
+func (request *PreauthorizeRequest) Validate() error {
+	err := request.FormRequest.CheckRequired(request)
+	if err != nil {
+		return err
+	}
+
+	if request.Source != "" {
+		err = protocols.CheckKey(request.Source, strkey.VersionByteSeed)
+		if err != nil {
+			return protocols.NewInvalidParameterError("source", "", err.Error())
+		}
+	}
+
+	var envelope xdr.TransactionEnvelope
+	err = xdr.SafeUnmarshalBase64(request.TransactionEnvelope, &envelope)
+	if err != nil {
+		return protocols.NewInvalidParameterError("tx", "", "Transaction envelope must be a base64 encoded XDR.")
+	}
+
+	if request.Source != "" {
+		err = request.CheckSourceAccount(request.Source)
+		if err != nil {
+			return err
+		}
+	}
+
+	if request.Weight != "" {
+		_, err = strconv.ParseUint(request.Weight, 10, 8)
+		if err != nil {
+			return protocols.NewInvalidParameterError("weight", request.Weight, "Weight must be an integer between 0 and 255.")
+		}
+	}
+
+	return nil
+}
+
+// CheckSourceAccount checks if the transaction to pre-authorize is sent from the account of the
+// given seed. The signer is added to this account so a transaction of another account could never
+// use it. The request must be validated first.
+func (request *PreauthorizeRequest) CheckSourceAccount(seed string) error {
+	kp, err := keypair.Parse(seed)
+	if err != nil {
+		return protocols.NewInvalidParameterError("source", "", err.Error())
+	}
+
+	tx := request.Transaction()
+	if tx.SourceAccount.Address() != kp.Address() {
+		return protocols.NewInvalidParameterError("tx", tx.SourceAccount.Address(), "Transaction source account must be the account the signer is added to.")
+	}
+
+	return nil
+}
+
+// Transaction returns the transaction to pre-authorize. The request must be validated first.
+func (request *PreauthorizeRequest) Transaction() xdr.Transaction {
+	var envelope xdr.TransactionEnvelope
+	xdr.SafeUnmarshalBase64(request.TransactionEnvelope, &envelope)
+	return envelope.Tx
+}
+
+// SignerWeight returns weight of the pre-authorized transaction signer
+func (request *PreauthorizeRequest) SignerWeight() uint32 {
+	if request.Weight == "" {
+		return 1
+	}
+	weight, _ := strconv.ParseUint(request.Weight, 10, 8)
+	return uint32(weight)
+}
+
+// PreauthorizeResponse represents response returned by /preauthorize endpoint
+type PreauthorizeResponse struct {
+	protocols.SuccessResponse
+	// Hex encoded hash of the pre-authorized transaction
+	Hash string `json:"hash"`
+	// Pre-authorized transaction signer key (`T...`) added to the source account
+	Signer string `json:"signer"`
+	// Result of the transaction adding the signer
+	Transaction horizon.SubmitTransactionResponse `json:"transaction"`
+}
+
+// Marshal marshals PreauthorizeResponse
+func (response *PreauthorizeResponse) Marshal() []byte {
+	json, _ := json.MarshalIndent(response, "", "  ")
+	return json
+}
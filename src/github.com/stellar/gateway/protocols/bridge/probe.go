@@ -0,0 +1,28 @@
+package bridge
+
+import (
+	"encoding/json"
+	"net/http"
+)
+
+// ProbeResponse represents a response returned by /admin/probe endpoint
+type ProbeResponse struct {
+	AccountID string  `json:"account_id"`
+	Hash      string  `json:"hash,omitempty"`
+	Ledger    *uint64 `json:"ledger,omitempty"`
+	// `success` or `code` of the error returned for the transaction
+	Result string `json:"result"`
+	// Time it took to submit the transaction in milliseconds
+	DurationMs int64 `json:"duration_ms"`
+}
+
+// HTTPStatus returns http status of the response
+func (response ProbeResponse) HTTPStatus() int {
+	return http.StatusOK
+}
+
+// Marshal marshals the response
+func (response ProbeResponse) Marshal() []byte {
+	json, _ := json.MarshalIndent(response, "", "  ")
+	return json
+}
@@ -0,0 +1,59 @@
+package bridge
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/stellar/gateway/protocols"
+	"github.com/stellar/go/strkey"
+)
+
+// ReserveSequenceRequest represents request made to /reserve-sequence endpoint of bridge server
+type ReserveSequenceRequest struct {
+	// Source account secret or ID
+	Source string `name:"source"`
+
+	protocols.FormRequest
+}
+
+// FromRequest will populate request fields using http.Request.
+func (request *ReserveSequenceRequest) FromRequest(r *http.Request) error {
+	return request.FormRequest.FromRequest(r, request)
+}
+
+// ToValues will create url.Values from request.
+func (request *ReserveSequenceRequest) ToValues() url.Values {
+	return request.FormRequest.ToValues(request)
+}
+
+// Validate validates if request fields are valid. Useful when checking if a request is correct.
+func (request *ReserveSequenceRequest) Validate() error {
+	if request.Source == "" || protocols.IsValidAccountID(request.Source) {
+		return nil
+	}
+
+	err := protocols.CheckKey(request.Source, strkey.VersionByteSeed)
+	if err != nil {
+		return protocols.NewInvalidParameterError("source", "", err.Error())
+	}
+	return nil
+}
+
+// ReserveSequenceResponse represents response returned by /reserve-sequence endpoint
+type ReserveSequenceResponse struct {
+	protocols.SuccessResponse
+	// Account ID of the source account
+	AccountID string `json:"account_id"`
+	// Reserved sequence number
+	SequenceNumber string `json:"sequence_number"`
+	// Time after which the sequence number can be reclaimed if it's not used
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+// Marshal marshals ReserveSequenceResponse
+func (response *ReserveSequenceResponse) Marshal() []byte {
+	json, _ := json.MarshalIndent(response, "", "  ")
+	return json
+}
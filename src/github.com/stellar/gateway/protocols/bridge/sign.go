@@ -0,0 +1,52 @@
+package bridge
+
+import (
+	"strconv"
+
+	"github.com/stellar/gateway/protocols"
+	"github.com/stellar/go/strkey"
+	"github.com/stellar/go/xdr"
+)
+
+// MaxSignaturesPerTransaction is the maximum number of signatures of a transaction allowed by the protocol
+const MaxSignaturesPerTransaction = 20
+
+// SignRequest represents request made to /sign endpoint of bridge server
+type SignRequest struct {
+	// Base64 encoded transaction envelope, can already contain signatures of other parties
+	TransactionEnvelope string `json:"tx"`
+	// Secret seeds adding their signatures to the envelope
+	Signers []string `json:"signers"`
+	// When true the transaction is submitted after signing
+	Submit bool `json:"submit"`
+	// When true result_meta_xdr of the submitted transaction is returned
+	IncludeMeta bool `json:"include_meta"`
+	// When true the response is sent after the submitted transaction is found in a ledger
+	WaitForConfirmation bool `json:"wait_for_confirmation"`
+	// `sync` or `async`, `submission.mode` config param is used when empty
+	SubmissionMode string `json:"submission_mode"`
+}
+
+// Validate validates if request fields are valid. Useful when checking if a request is correct.
+// Signers are not required as they can be taken from the bearer token.
+func (request *SignRequest) Validate() error {
+	if request.TransactionEnvelope == "" {
+		return protocols.NewMissingParameter("tx")
+	}
+
+	var envelope xdr.TransactionEnvelope
+	err := xdr.SafeUnmarshalBase64(request.TransactionEnvelope, &envelope)
+	if err != nil {
+		return protocols.NewInvalidParameterError("tx", "", "Transaction envelope must be a base64 encoded XDR.")
+	}
+
+	for i, signer := range request.Signers {
+		err := protocols.CheckKey(signer, strkey.VersionByteSeed)
+		if err != nil {
+			// Never return the secret
+			return protocols.NewInvalidParameterError("signers["+strconv.Itoa(i)+"]", "", err.Error())
+		}
+	}
+
+	return checkSubmissionMode(request.SubmissionMode)
+}
@@ -0,0 +1,56 @@
+package bridge
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/stellar/gateway/protocols"
+)
+
+var (
+	// AccountNotFound is an error response
+	AccountNotFound = &protocols.ErrorResponse{Code: "account_not_found", Message: "Account not found.", Status: http.StatusNotFound}
+)
+
+// SpendableResponse represents a response returned by /account/{address}/spendable endpoint.
+// All amounts except amounts of trustlines are in XLM.
+type SpendableResponse struct {
+	AccountID string `json:"account_id"`
+	// Native balance of the account
+	Balance       string `json:"balance"`
+	BaseReserve   string `json:"base_reserve"`
+	SubentryCount int32  `json:"subentry_count"`
+	// Balance the account must keep: (2 + subentry_count) * base_reserve
+	MinimumBalance string `json:"minimum_balance"`
+	// XLM reserved by the account's offers selling XLM
+	SellingLiabilities string `json:"selling_liabilities"`
+	FeeBuffer          string `json:"fee_buffer"`
+	// Maximum amount of XLM that can be sent from the account, never negative
+	Spendable string `json:"spendable"`
+	// Credit asset balances of the account
+	Trustlines []SpendableTrustline `json:"trustlines"`
+}
+
+// SpendableTrustline represents a single trustline returned in SpendableResponse
+type SpendableTrustline struct {
+	AssetType   string `json:"asset_type"`
+	AssetCode   string `json:"asset_code"`
+	AssetIssuer string `json:"asset_issuer"`
+	Balance     string `json:"balance"`
+	Limit       string `json:"limit"`
+	// Amount reserved by the account's offers buying the asset
+	BuyingLiabilities string `json:"buying_liabilities"`
+	// Maximum amount the account can receive: limit minus balance and buying liabilities, never negative
+	Capacity string `json:"capacity"`
+}
+
+// HTTPStatus returns http status of the response
+func (response SpendableResponse) HTTPStatus() int {
+	return http.StatusOK
+}
+
+// Marshal marshals the response
+func (response SpendableResponse) Marshal() []byte {
+	json, _ := json.MarshalIndent(response, "", "  ")
+	return json
+}
@@ -0,0 +1,73 @@
+package bridge
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"regexp"
+
+	"github.com/stellar/gateway/protocols"
+)
+
+var domainPattern = regexp.MustCompile(`(?i)^[a-z0-9]([a-z0-9-]*[a-z0-9])?(\.[a-z0-9]([a-z0-9-]*[a-z0-9])?)+$`)
+
+// StellarTomlRequest represents request made to /stellar-toml endpoint of bridge server
+type StellarTomlRequest struct {
+	// Domain whose stellar.toml file is checked
+	Domain string `name:"domain" required:""`
+
+	protocols.FormRequest
+}
+
+// FromRequest will populate request fields using http.Request.
+func (request *StellarTomlRequest) FromRequest(r *http.Request) error {
+	return request.FormRequest.FromRequest(r, request)
+}
+
+// ToValues will create url.Values from request.
+func (request *StellarTomlRequest) ToValues() url.Values {
+	return request.FormRequest.ToValues(request)
+}
+
+// Validate validates if request fields are valid. Useful when checking if a request is correct.
+func (request *StellarTomlRequest) Validate() error {
+	err := request.FormRequest.CheckRequired(request)
+	if err != nil {
+		return err
+	}
+
+	if !domainPattern.MatchString(request.Domain) {
+		return protocols.NewInvalidParameterError("domain", request.Domain, "Invalid domain.")
+	}
+	return nil
+}
+
+// StellarTomlCurrency represents a currency declared in stellar.toml file
+type StellarTomlCurrency struct {
+	Code   string `json:"code"`
+	Issuer string `json:"issuer"`
+}
+
+// StellarTomlResponse represents a response returned by /stellar-toml endpoint
+type StellarTomlResponse struct {
+	Domain string `json:"domain"`
+	// True when the file has been fetched and no problems were found
+	Valid            bool                  `json:"valid"`
+	FederationServer string                `json:"federation_server,omitempty"`
+	AuthServer       string                `json:"auth_server,omitempty"`
+	SigningKey       string                `json:"signing_key,omitempty"`
+	Currencies       []StellarTomlCurrency `json:"currencies,omitempty"`
+	// Problems found in the file (ex. unreachable file, malformed TOML, missing fields)
+	Errors []string `json:"errors,omitempty"`
+}
+
+// HTTPStatus returns http status of the response
+func (response StellarTomlResponse) HTTPStatus() int {
+	return http.StatusOK
+}
+
+// Marshal marshals the response
+func (response StellarTomlResponse) Marshal() []byte {
+	json, _ := json.MarshalIndent(response, "", "  ")
+	return json
+}
@@ -0,0 +1,102 @@
+package bridge
+
+import (
+	"encoding/base64"
+	"net/http"
+	"net/url"
+
+	"github.com/stellar/gateway/protocols"
+	"github.com/stellar/go/xdr"
+)
+
+// Values of `submission_mode` param
+const (
+	// SubmissionModeSync submits transactions to Horizon `POST /transactions` waiting for the ledger
+	SubmissionModeSync = "sync"
+	// SubmissionModeAsync submits transactions to Horizon `POST /transactions_async` which responds
+	// as soon as the transaction is accepted by Stellar Core
+	SubmissionModeAsync = "async"
+)
+
+// Values of `data.reason` of SubmitInvalidEnvelope error
+const (
+	// SubmitInvalidEnvelopeBase64 means the envelope is not a valid base64 string
+	SubmitInvalidEnvelopeBase64 = "base64"
+	// SubmitInvalidEnvelopeXDR means the envelope is valid base64 but not a TransactionEnvelope XDR
+	SubmitInvalidEnvelopeXDR = "xdr"
+)
+
+var (
+	// SubmitInvalidEnvelope is an error response
+	SubmitInvalidEnvelope = &protocols.ErrorResponse{Code: "submit_invalid_envelope", Message: "Transaction envelope is malformed.", Status: http.StatusBadRequest}
+)
+
+// NewSubmitInvalidEnvelopeError creates a new SubmitInvalidEnvelope error with the reason
+// (SubmitInvalidEnvelopeBase64 or SubmitInvalidEnvelopeXDR) and the parse error
+func NewSubmitInvalidEnvelopeError(reason string, err error) *protocols.ErrorResponse {
+	return &protocols.ErrorResponse{
+		Status:  SubmitInvalidEnvelope.Status,
+		Code:    SubmitInvalidEnvelope.Code,
+		Message: SubmitInvalidEnvelope.Message,
+		Data:    map[string]interface{}{"name": "tx", "reason": reason, "error": err.Error()},
+	}
+}
+
+// SubmitRequest represents request made to /submit endpoint of bridge server
+type SubmitRequest struct {
+	// Base64 encoded signed transaction envelope (ex. returned by /builder)
+	TransactionEnvelope string `name:"tx" required:""`
+	// When true result_meta_xdr is included in the response
+	IncludeMeta bool `name:"include_meta"`
+	// When true the response is sent after the transaction is found in a ledger
+	WaitForConfirmation bool `name:"wait_for_confirmation"`
+	// `sync` or `async`, `submission.mode` config param is used when empty
+	SubmissionMode string `name:"submission_mode"`
+
+	protocols.FormRequest
+}
+
+// FromRequest will populate request fields using http.Request.
+func (request *SubmitRequest) FromRequest(r *http.Request) error {
+	return request.FormRequest.FromRequest(r, request)
+}
+
+// ToValues will create url.Values from request.
+func (request *SubmitRequest) ToValues() url.Values {
+	return request.FormRequest.ToValues(request)
+}
+
+// Validate validates if request fields are valid. Useful when checking if a request is correct.
+func (request *SubmitRequest) Validate() error {
+	err := request.FormRequest.CheckRequired(request)
+	if err != nil {
+		return err
+	}
+
+	var envelope xdr.TransactionEnvelope
+	err = xdr.SafeUnmarshalBase64(request.TransactionEnvelope, &envelope)
+	if err != nil {
+		// Base64 is decoded while XDR is read so decode it again to tell which one is malformed
+		_, decodeErr := base64.StdEncoding.DecodeString(request.TransactionEnvelope)
+		if decodeErr != nil {
+			return NewSubmitInvalidEnvelopeError(SubmitInvalidEnvelopeBase64, decodeErr)
+		}
+		return NewSubmitInvalidEnvelopeError(SubmitInvalidEnvelopeXDR, err)
+	}
+
+	if len(envelope.Signatures) == 0 {
+		return protocols.NewInvalidParameterError("tx", "", "Transaction envelope is not signed.")
+	}
+
+	return checkSubmissionMode(request.SubmissionMode)
+}
+
+// checkSubmissionMode checks if `submission_mode` param is valid
+func checkSubmissionMode(mode string) error {
+	switch mode {
+	case "", SubmissionModeSync, SubmissionModeAsync:
+		return nil
+	default:
+		return protocols.NewInvalidParameterError("submission_mode", mode, "Submission mode must be `sync` or `async`.")
+	}
+}
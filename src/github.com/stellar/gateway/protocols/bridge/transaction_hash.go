@@ -0,0 +1,64 @@
+package bridge
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/url"
+
+	"github.com/stellar/gateway/protocols"
+	"github.com/stellar/go/xdr"
+)
+
+// TransactionHashRequest represents request made to /transaction-hash endpoint of bridge server
+type TransactionHashRequest struct {
+	// Base64 encoded transaction envelope, signatures are ignored
+	TransactionEnvelope string `name:"tx" required:""`
+	// Passphrase of the network the transaction is hashed for, `network_passphrase` config param is used when empty
+	NetworkPassphrase string `name:"network_passphrase"`
+
+	protocols.FormRequest
+}
+
+// FromRequest will populate request fields using http.Request.
+func (request *TransactionHashRequest) FromRequest(r *http.Request) error {
+	return request.FormRequest.FromRequest(r, request)
+}
+
+// ToValues will create url.Values from request.
+func (request *TransactionHashRequest) ToValues() url.Values {
+	return request.FormRequest.ToValues(request)
+}
+
+// Validate validates if request fields are valid. Useful when checking if a request is correct.
+func (request *TransactionHashRequest) Validate() error {
+	err := request.FormRequest.CheckRequired(request)
+	if err != nil {
+		return err
+	}
+
+	var envelope xdr.TransactionEnvelope
+	err = xdr.SafeUnmarshalBase64(request.TransactionEnvelope, &envelope)
+	if err != nil {
+		return protocols.NewInvalidParameterError("tx", "", "Transaction envelope must be a base64 encoded XDR.")
+	}
+
+	return nil
+}
+
+// TransactionHashResponse represents a response returned by /transaction-hash endpoint
+type TransactionHashResponse struct {
+	// Hex encoded hash of the transaction signed by its signers
+	Hash              string `json:"hash"`
+	NetworkPassphrase string `json:"network_passphrase"`
+}
+
+// HTTPStatus returns http status of the response
+func (response TransactionHashResponse) HTTPStatus() int {
+	return http.StatusOK
+}
+
+// Marshal marshals the response
+func (response TransactionHashResponse) Marshal() []byte {
+	json, _ := json.MarshalIndent(response, "", "  ")
+	return json
+}
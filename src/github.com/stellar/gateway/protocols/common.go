@@ -1,12 +1,15 @@
 package protocols
 
 import (
+	"encoding/json"
 	"fmt"
 	"net/http"
 	"net/url"
 	"reflect"
 	"regexp"
+	"sort"
 	"strconv"
+	"strings"
 
 	"github.com/facebookgo/structtag"
 	"github.com/stellar/go/build"
@@ -14,6 +17,7 @@ import (
 )
 
 var federationDestinationFieldName = regexp.MustCompile("forward_destination\\[fields\\]\\[([a-z_-]+)\\]")
+var pathFieldName = regexp.MustCompile(`^path\[[0-4]\]\[asset_(code|issuer)\]$`)
 
 // Asset represents native or credit asset
 type Asset struct {
@@ -171,6 +175,95 @@ func (request *FormRequest) CheckRequired(destination interface{}) error {
 	return nil
 }
 
+// UnknownParams returns sorted names of form params of the request that are not fields of
+// destination (ex. misspelled `destnation`). known are names of params read outside of destination
+// (ex. by middlewares). FromRequest must be called before.
+func (request *FormRequest) UnknownParams(destination interface{}, known ...string) []string {
+	names := make(map[string]bool)
+	for _, name := range known {
+		names[name] = true
+	}
+
+	typ := reflect.ValueOf(destination).Elem().Type()
+	for i := 0; i < typ.NumField(); i++ {
+		names[typ.Field(i).Tag.Get("name")] = true
+	}
+
+	var unknown []string
+	for key := range request.HTTPRequest.PostForm {
+		switch {
+		case names[key]:
+		case names["forward_destination"] && (key == "forward_destination[domain]" || federationDestinationFieldName.MatchString(key)):
+		case names["path"] && pathFieldName.MatchString(key):
+		default:
+			unknown = append(unknown, key)
+		}
+	}
+
+	sort.Strings(unknown)
+	return unknown
+}
+
+// UnknownJSONFields returns sorted names of fields of JSON object data that are not fields of
+// destination (a pointer to struct with `json` tags). Fields of nested objects are named like form
+// params, ex. `payments[1][destnation]`.
+func UnknownJSONFields(data []byte, destination interface{}) ([]string, error) {
+	var object interface{}
+	err := json.Unmarshal(data, &object)
+	if err != nil {
+		return nil, err
+	}
+
+	unknown := unknownJSONFields("", object, reflect.TypeOf(destination).Elem())
+	sort.Strings(unknown)
+	return unknown, nil
+}
+
+func unknownJSONFields(prefix string, value interface{}, typ reflect.Type) []string {
+	for typ.Kind() == reflect.Ptr {
+		typ = typ.Elem()
+	}
+
+	var unknown []string
+	switch value := value.(type) {
+	case map[string]interface{}:
+		if typ.Kind() != reflect.Struct {
+			return nil
+		}
+
+		fields := make(map[string]reflect.Type)
+		for i := 0; i < typ.NumField(); i++ {
+			name := strings.Split(typ.Field(i).Tag.Get("json"), ",")[0]
+			if name != "" && name != "-" {
+				fields[name] = typ.Field(i).Type
+			}
+		}
+
+		for key, fieldValue := range value {
+			name := key
+			if prefix != "" {
+				name = prefix + "[" + key + "]"
+			}
+
+			fieldType, ok := fields[key]
+			if !ok {
+				unknown = append(unknown, name)
+				continue
+			}
+			unknown = append(unknown, unknownJSONFields(name, fieldValue, fieldType)...)
+		}
+	case []interface{}:
+		if typ.Kind() != reflect.Slice {
+			return nil
+		}
+
+		for i, item := range value {
+			unknown = append(unknown, unknownJSONFields(prefix+"["+strconv.Itoa(i)+"]", item, typ.Elem())...)
+		}
+	}
+	return unknown
+}
+
 // ToValues transforms request object to url.Values
 func (request *FormRequest) ToValues(object interface{}) (values url.Values) {
 	values = make(map[string][]string)
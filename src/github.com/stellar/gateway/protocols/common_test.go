@@ -2,6 +2,7 @@ package protocols_test
 
 import (
 	"net/http"
+	"net/url"
 	"reflect"
 	"testing"
 
@@ -43,5 +44,39 @@ func TestProtocols(t *testing.T) {
 			require.NoError(t, err)
 			assert.True(t, reflect.DeepEqual(request, request2))
 		})
+
+		Convey(".UnknownParams", func() {
+			httpRequest := &http.Request{PostForm: url.Values{
+				"destnation":                      {"bob*stellar.org"},
+				"amount":                          {"10"},
+				"path[0][asset_code]":             {"USD"},
+				"path[0][asset_issuer]":           {"GBDOSO3K4JTGSWJSIHXAOFIBMAABVM3YK3FI6VJPKIHHM56XAFIUCGD6"},
+				"forward_destination[domain]":     {"stellar.org"},
+				"forward_destination[fields][id]": {"1"},
+				"apiKey":                          {"key"},
+				"memo_typ":                        {"id"},
+			}}
+
+			request := &callback.PaymentRequest{}
+			err := request.FromRequest(httpRequest)
+			require.NoError(t, err)
+			assert.Equal(t, []string{"destnation", "memo_typ"}, request.UnknownParams(request, "apiKey"))
+		})
+	})
+
+	Convey("UnknownJSONFields", t, func() {
+		unknown, err := protocols.UnknownJSONFields([]byte(`{
+  "source": "S",
+  "memo_tpe": "id",
+  "payments": [
+    {"destination": "bob*stellar.org", "amount": "1"},
+    {"destnation": "bob*stellar.org", "amount": "1"}
+  ]
+}`), &callback.BatchPaymentRequest{})
+		require.NoError(t, err)
+		assert.Equal(t, []string{"memo_tpe", "payments[1][destnation]"}, unknown)
+
+		_, err = protocols.UnknownJSONFields([]byte(`{`), &callback.BatchPaymentRequest{})
+		assert.Error(t, err)
 	})
 }
@@ -1,7 +1,10 @@
 package compliance
 
 import (
+	"encoding/base64"
+	"encoding/hex"
 	"encoding/json"
+	"errors"
 	"net/http"
 	"net/url"
 	"strings"
@@ -95,6 +98,30 @@ type SendResponse struct {
 	proto.AuthResponse `json:"auth_response"`
 	// xdr.Transaction base64-encoded. Sequence number of this transaction will be equal 0.
 	TransactionXdr string `json:"transaction_xdr,omitempty"`
+	// Hash of the attachment used as transaction memo, hex or base64 encoded. Some compliance
+	// servers return it separately instead of (or in addition to) setting it in the transaction.
+	Memo string `json:"memo,omitempty"`
+}
+
+// MemoHash decodes Memo field. Both hex and base64 (standard and URL) encodings are accepted.
+func (response *SendResponse) MemoHash() (hash [32]byte, err error) {
+	var memo []byte
+	if len(response.Memo) == hex.EncodedLen(len(hash)) {
+		memo, err = hex.DecodeString(response.Memo)
+	} else {
+		memo, err = base64.StdEncoding.DecodeString(response.Memo)
+		if err != nil {
+			memo, err = base64.URLEncoding.DecodeString(response.Memo)
+		}
+	}
+
+	if err != nil || len(memo) != len(hash) {
+		err = errors.New("memo must be 32 bytes, hex or base64 encoded")
+		return
+	}
+
+	copy(hash[:], memo)
+	return
 }
 
 // Marshal marshals SendResponse
@@ -0,0 +1,112 @@
+package protocols
+
+// errorCodes maps `code` of every error response to its numeric `error_code`. Both are part of the API:
+// they MUST NOT change once released, new errors get new numbers and numbers of removed errors are
+// never reused. Messages are not part of the API and can change at any time.
+var errorCodes = map[string]int{
+	// Common errors
+	"internal_server_error":   100,
+	"invalid_parameter":       101,
+	"missing_parameter":       102,
+	"unauthorized":            103,
+	"request_timeout":         104,
+	"horizon_rate_limited":    105,
+	"unsupported_api_version": 106,
+	"service_maintenance":     107,
+	"client_rate_limited":     108,
+
+	// Transaction errors
+	"transaction_bad_seq":              200,
+	"transaction_bad_auth":             201,
+	"transaction_insufficient_balance": 202,
+	"transaction_no_account":           203,
+	"transaction_insufficient_fee":     204,
+	"transaction_bad_auth_extra":       205,
+	"transaction_not_confirmed":        206,
+
+	// Payment errors
+	"cannot_resolve_destination":     300,
+	"cannot_use_memo":                301,
+	"source_not_exist":               302,
+	"asset_code_not_allowed":         303,
+	"memo_not_allowed":               304,
+	"compliance_required":            305,
+	"memo_required":                  306,
+	"memo_type_not_allowed":          307,
+	"rate_limited":                   308,
+	"destination_not_authorized":     309,
+	"memo_type_forbidden":            310,
+	"compliance_not_configured":      311,
+	"idempotency_key_conflict":       312,
+	"cannot_resolve_sender":          313,
+	"asset_not_allowed":              314,
+	"source_underfunded":             315,
+	"would_breach_floor":             316,
+	"federation_discovery_failed":    317,
+	"not_issuance":                   318,
+	"pending":                        320,
+	"denied":                         321,
+	"queued":                         322,
+	"compliance_response_mismatch":   323,
+	"pending_queued":                 324,
+	"payment_malformed":              340,
+	"payment_underfunded":            341,
+	"payment_src_no_trust":           342,
+	"payment_src_not_authorized":     343,
+	"payment_no_destination":         344,
+	"payment_no_trust":               345,
+	"payment_not_authorized":         346,
+	"payment_line_full":              347,
+	"payment_no_issuer":              348,
+	"payment_too_few_offers":         349,
+	"payment_offer_cross_self":       350,
+	"payment_over_sendmax":           351,
+	"payment_account_already_exists": 352,
+	"payment_low_reserve":            353,
+	"payment_invalid_amount":         354,
+
+	// Batch payment errors
+	"batch_empty":                 400,
+	"cannot_resolve_destinations": 401,
+	"batch_too_many_operations":   402,
+	"batch_duplicate_payment":     403,
+	"batch_fee_too_high":          404,
+	"batch_source_failed":         405,
+	"batch_too_many_payments":     406,
+	"batch_amount_too_high":       407,
+	"batch_ordering":              408,
+	"batch_payment_in_progress":   409,
+
+	// Allow trust errors
+	"allow_trust_malformed":          500,
+	"allow_trust_no_trustline":       501,
+	"allow_trust_trust_not_required": 502,
+	"allow_trust_cant_revoke":        503,
+
+	// Compliance server errors
+	"transaction_not_found":   600,
+	"auth_server_not_defined": 601,
+
+	// Change trust errors
+	"change_trust_malformed":        700,
+	"change_trust_no_issuer":        701,
+	"change_trust_invalid_limit":    702,
+	"change_trust_low_reserve":      703,
+	"change_trust_self_not_allowed": 704,
+
+	// Account errors
+	"account_not_found": 800,
+
+	// Set options errors
+	"set_options_low_reserve":      900,
+	"set_options_too_many_signers": 901,
+	"set_options_bad_signer":       902,
+
+	// Submit errors
+	"submit_invalid_envelope": 1000,
+}
+
+// ErrorCode returns numeric error code of the given error `code` or 0 if it is unknown
+func ErrorCode(code string) int {
+	return errorCodes[code]
+}
@@ -0,0 +1,37 @@
+package protocols
+
+import (
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestErrorCodes(t *testing.T) {
+	Convey("errorCodes", t, func() {
+		Convey("numeric codes are unique", func() {
+			seen := map[int]string{}
+			for code, number := range errorCodes {
+				other, exists := seen[number]
+				assert.False(t, exists, "%s and %s share error_code %d", code, other, number)
+				seen[number] = code
+			}
+		})
+	})
+
+	Convey("ErrorResponse.Marshal", t, func() {
+		Convey("it adds error_code and does not modify the error", func() {
+			assert.JSONEq(t, `{
+  "code": "missing_parameter",
+  "error_code": 102,
+  "message": "Required parameter is missing."
+}`, string(MissingParameterError.Marshal()))
+			assert.Equal(t, 0, MissingParameterError.ErrorCode)
+		})
+
+		Convey("it omits error_code of unknown errors", func() {
+			response := &ErrorResponse{Code: "unknown", Message: "Unknown."}
+			assert.JSONEq(t, `{"code": "unknown", "message": "Unknown."}`, string(response.Marshal()))
+		})
+	})
+}
@@ -12,6 +12,18 @@ var (
 	InvalidParameterError = &ErrorResponse{Code: "invalid_parameter", Message: "Invalid parameter.", Status: http.StatusBadRequest}
 	// MissingParameterError is an error response
 	MissingParameterError = &ErrorResponse{Code: "missing_parameter", Message: "Required parameter is missing.", Status: http.StatusBadRequest}
+	// UnauthorizedError is an error response
+	UnauthorizedError = &ErrorResponse{Code: "unauthorized", Message: "Missing or invalid bearer token.", Status: http.StatusUnauthorized}
+	// RequestTimeoutError is an error response
+	RequestTimeoutError = &ErrorResponse{Code: "request_timeout", Message: "Request has not been processed in time. Repeat it with the same `id` to check its status.", Status: http.StatusGatewayTimeout}
+	// HorizonRateLimitedError is an error response
+	HorizonRateLimitedError = &ErrorResponse{Code: "horizon_rate_limited", Message: "Horizon server is rate limiting requests, please try again later.", Status: http.StatusServiceUnavailable}
+	// UnsupportedAPIVersionError is an error response
+	UnsupportedAPIVersionError = &ErrorResponse{Code: "unsupported_api_version", Message: "Requested API version is not supported.", Status: http.StatusBadRequest}
+	// ServiceMaintenanceError is an error response
+	ServiceMaintenanceError = &ErrorResponse{Code: "service_maintenance", Message: "Server is paused for maintenance, please try again later.", Status: http.StatusServiceUnavailable}
+	// ClientRateLimitedError is an error response
+	ClientRateLimitedError = &ErrorResponse{Code: "client_rate_limited", Message: "Too many requests from your IP address, please try again later.", Status: http.StatusTooManyRequests}
 )
 
 // NewInternalServerError creates and returns a new InternalServerError
@@ -25,6 +37,38 @@ func NewInternalServerError(logMessage string, logData map[string]interface{}) *
 	}
 }
 
+// NewHorizonRateLimitedError creates and returns a new HorizonRateLimitedError with the number
+// of seconds to wait before retrying the request
+func NewHorizonRateLimitedError(retryAfter int) *ErrorResponse {
+	return &ErrorResponse{
+		Status:  HorizonRateLimitedError.Status,
+		Code:    HorizonRateLimitedError.Code,
+		Message: HorizonRateLimitedError.Message,
+		Data:    map[string]interface{}{"retry_after": retryAfter},
+	}
+}
+
+// NewClientRateLimitedError creates and returns a new ClientRateLimitedError with the number of
+// seconds to wait before retrying the request
+func NewClientRateLimitedError(retryAfter int) *ErrorResponse {
+	return &ErrorResponse{
+		Status:  ClientRateLimitedError.Status,
+		Code:    ClientRateLimitedError.Code,
+		Message: ClientRateLimitedError.Message,
+		Data:    map[string]interface{}{"retry_after": retryAfter},
+	}
+}
+
+// NewUnsupportedAPIVersionError creates and returns a new UnsupportedAPIVersionError listing supported versions
+func NewUnsupportedAPIVersionError(supportedVersions []string) *ErrorResponse {
+	return &ErrorResponse{
+		Status:  UnsupportedAPIVersionError.Status,
+		Code:    UnsupportedAPIVersionError.Code,
+		Message: UnsupportedAPIVersionError.Message,
+		Data:    map[string]interface{}{"supported_versions": supportedVersions},
+	}
+}
+
 // NewInvalidParameterError creates and returns a new InvalidParameterError
 func NewInvalidParameterError(name, value, moreInfo string, additionalLogData ...map[string]interface{}) *ErrorResponse {
 	logData := map[string]interface{}{"name": name, "value": value}
@@ -67,12 +111,16 @@ type ErrorResponse struct {
 	Status int `json:"-"`
 	// Error status code
 	Code string `json:"code"`
+	// Numeric error code, set from Code when marshalled (see errorCodes)
+	ErrorCode int `json:"error_code,omitempty"`
 	// Error message that will be returned to API consumer
 	Message string `json:"message"`
 	// Additional information returned to API consumer
 	MoreInfo string `json:"more_info,omitempty"`
 	// Error data that will be returned to API consumer
 	Data map[string]interface{} `json:"data,omitempty"`
+	// Raw Horizon error document, returned only when requested (see WithHorizonError)
+	HorizonError json.RawMessage `json:"horizon_error,omitempty"`
 	// Error message that will be logged.
 	LogMessage string `json:"-"`
 	// Error data that will be logged.
@@ -87,6 +135,17 @@ func (error *ErrorResponse) Error() string {
 	return error.Message
 }
 
+// WithHorizonError returns a copy of the error response containing problem, a raw error document
+// returned by Horizon. The error response is returned unchanged when problem is empty.
+func (error *ErrorResponse) WithHorizonError(problem json.RawMessage) *ErrorResponse {
+	if len(problem) == 0 {
+		return error
+	}
+	response := *error
+	response.HorizonError = problem
+	return &response
+}
+
 // HTTPStatus returns ErrorResponse.Status
 func (error *ErrorResponse) HTTPStatus() int {
 	return error.Status
@@ -94,6 +153,9 @@ func (error *ErrorResponse) HTTPStatus() int {
 
 // Marshal marshals ErrorResponse
 func (error *ErrorResponse) Marshal() []byte {
-	json, _ := json.MarshalIndent(error, "", "  ")
+	// Error responses are often shared package variables so modify a copy
+	response := *error
+	response.ErrorCode = ErrorCode(error.Code)
+	json, _ := json.MarshalIndent(&response, "", "  ")
 	return json
 }
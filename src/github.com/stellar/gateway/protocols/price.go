@@ -0,0 +1,51 @@
+package protocols
+
+import (
+	"errors"
+	"math"
+	"math/big"
+	"regexp"
+)
+
+var (
+	// ErrPriceInvalid is returned by ParsePrice when price format is invalid
+	ErrPriceInvalid = errors.New("Price must be a decimal (like `1.25`) or a fraction (like `5/4`).")
+	// ErrPriceNotPositive is returned by ParsePrice when price is zero
+	ErrPriceNotPositive = errors.New("Price must be positive.")
+	// ErrPriceNotRepresentable is returned by ParsePrice when price cannot be represented exactly
+	ErrPriceNotRepresentable = errors.New("Price cannot be represented exactly as a fraction of 32-bit integers. Use fewer decimal places.")
+)
+
+// Signs, exponents and partial decimals (like `.5`) are not accepted
+var priceRegexp = regexp.MustCompile(`^([0-9]+(\.[0-9]+)?|[0-9]+/[0-9]+)$`)
+
+// ParsePrice parses an offer price given as a decimal (like `1.25`) or a fraction (like `5/4`) and
+// returns its reduced numerator and denominator. Prices that cannot be represented exactly by a
+// fraction of int32 values are rejected instead of being approximated.
+func ParsePrice(price string) (n, d int32, err error) {
+	if !priceRegexp.MatchString(price) {
+		err = ErrPriceInvalid
+		return
+	}
+
+	rat, ok := new(big.Rat).SetString(price)
+	if !ok {
+		// Zero denominator
+		err = ErrPriceInvalid
+		return
+	}
+
+	if rat.Sign() <= 0 {
+		err = ErrPriceNotPositive
+		return
+	}
+
+	// big.Rat is always reduced
+	maxInt32 := big.NewInt(math.MaxInt32)
+	if rat.Num().Cmp(maxInt32) > 0 || rat.Denom().Cmp(maxInt32) > 0 {
+		err = ErrPriceNotRepresentable
+		return
+	}
+
+	return int32(rat.Num().Int64()), int32(rat.Denom().Int64()), nil
+}
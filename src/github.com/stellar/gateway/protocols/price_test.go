@@ -0,0 +1,57 @@
+package protocols_test
+
+import (
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+	"github.com/stellar/gateway/protocols"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParsePrice(t *testing.T) {
+	Convey("ParsePrice", t, func() {
+		Convey("parses decimals and fractions", func() {
+			tests := []struct {
+				price string
+				n, d  int32
+			}{
+				{"1", 1, 1},
+				{"1.25", 5, 4},
+				{"5/4", 5, 4},
+				{"10/8", 5, 4},
+				{"0.0000001", 1, 10000000},
+				{"2.93850088", 36731261, 12500000},
+				{"2147483647", 2147483647, 1},
+				{"1/2147483647", 1, 2147483647},
+			}
+
+			for _, test := range tests {
+				n, d, err := protocols.ParsePrice(test.price)
+				assert.NoError(t, err, test.price)
+				assert.Equal(t, test.n, n, test.price)
+				assert.Equal(t, test.d, d, test.price)
+			}
+		})
+
+		Convey("rejects invalid format", func() {
+			for _, price := range []string{"", "abc", "-1", "+1", ".5", "1.", "1e3", "1/2/3", "1.5/2", " 1", "1/0", "0x10"} {
+				_, _, err := protocols.ParsePrice(price)
+				assert.Equal(t, protocols.ErrPriceInvalid, err, price)
+			}
+		})
+
+		Convey("rejects zero", func() {
+			for _, price := range []string{"0", "0.00", "0/5"} {
+				_, _, err := protocols.ParsePrice(price)
+				assert.Equal(t, protocols.ErrPriceNotPositive, err, price)
+			}
+		})
+
+		Convey("rejects prices that cannot be represented exactly", func() {
+			for _, price := range []string{"2147483648", "1/2147483648", "0.00000000001", "3.14159265358979"} {
+				_, _, err := protocols.ParsePrice(price)
+				assert.Equal(t, protocols.ErrPriceNotRepresentable, err, price)
+			}
+		})
+	})
+}
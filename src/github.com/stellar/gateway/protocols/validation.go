@@ -1,36 +1,83 @@
 package protocols
 
 import (
+	"encoding/base32"
+	"errors"
+
 	"github.com/stellar/go/amount"
-	"github.com/stellar/go/keypair"
+	"github.com/stellar/go/crc16"
+	"github.com/stellar/go/strkey"
 )
 
-// IsValidAccountID returns true if account ID is valid
-func IsValidAccountID(accountID string) bool {
-	_, err := keypair.Parse(accountID)
-	if err != nil {
-		return false
-	}
+const (
+	// rawKeyLength is a length of decoded account ID or secret seed: 1 version byte,
+	// 32 bytes of ed25519 key and 2 bytes of checksum.
+	rawKeyLength = 35
+	// encodedKeyLength is a length of base32 encoded rawKeyLength bytes
+	encodedKeyLength = 56
+)
 
-	if accountID[0] != 'G' {
-		return false
+var (
+	// ErrKeyEmpty is returned by CheckKey when key is empty
+	ErrKeyEmpty = errors.New("Key is empty.")
+	// ErrKeyInvalidLength is returned by CheckKey when key has invalid length
+	ErrKeyInvalidLength = errors.New("Key must be 56 characters long.")
+	// ErrKeyInvalidEncoding is returned by CheckKey when key is not valid base32 string
+	ErrKeyInvalidEncoding = errors.New("Key must contain upper case letters and digits 2-7 only.")
+	// ErrKeyInvalidChecksum is returned by CheckKey when key checksum does not match
+	ErrKeyInvalidChecksum = errors.New("Key checksum is invalid, check the key for typos.")
+	// ErrKeyNotAccountID is returned by CheckKey when account ID was expected but other key type was sent
+	ErrKeyNotAccountID = errors.New("Key must be an account ID (starting with `G`).")
+	// ErrKeyNotSecret is returned by CheckKey when secret seed was expected but other key type was sent
+	ErrKeyNotSecret = errors.New("Key must be a secret seed (starting with `S`).")
+)
+
+// CheckKey checks if key is a valid strkey of a given version (strkey.VersionByteAccountID or
+// strkey.VersionByteSeed) and returns one of ErrKey* errors describing the problem if it's not.
+// Contrary to keypair.Parse it also checks the key length so returned keys can be safely used
+// to sign transactions.
+func CheckKey(key string, version strkey.VersionByte) error {
+	if key == "" {
+		return ErrKeyEmpty
 	}
 
-	return true
-}
+	if len(key) != encodedKeyLength {
+		return ErrKeyInvalidLength
+	}
 
-// IsValidSecret returns true if secret is valid
-func IsValidSecret(secret string) bool {
-	_, err := keypair.Parse(secret)
+	raw, err := base32.StdEncoding.DecodeString(key)
 	if err != nil {
-		return false
+		return ErrKeyInvalidEncoding
 	}
 
-	if secret[0] != 'S' {
-		return false
+	// Padded shorter payloads have a valid encoded length
+	if len(raw) != rawKeyLength {
+		return ErrKeyInvalidLength
 	}
 
-	return true
+	// Checksum is checked first: a typo in the first character changes the version byte too
+	if crc16.Validate(raw[:len(raw)-2], raw[len(raw)-2:]) != nil {
+		return ErrKeyInvalidChecksum
+	}
+
+	if strkey.VersionByte(raw[0]) != version {
+		if version == strkey.VersionByteSeed {
+			return ErrKeyNotSecret
+		}
+		return ErrKeyNotAccountID
+	}
+
+	return nil
+}
+
+// IsValidAccountID returns true if account ID is valid
+func IsValidAccountID(accountID string) bool {
+	return CheckKey(accountID, strkey.VersionByteAccountID) == nil
+}
+
+// IsValidSecret returns true if secret is valid
+func IsValidSecret(secret string) bool {
+	return CheckKey(secret, strkey.VersionByteSeed) == nil
 }
 
 // IsValidAssetCode returns true if asset code is valid
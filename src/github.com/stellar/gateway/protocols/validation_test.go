@@ -0,0 +1,57 @@
+package protocols_test
+
+import (
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+	"github.com/stellar/gateway/protocols"
+	"github.com/stellar/go/strkey"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCheckKey(t *testing.T) {
+	accountID := "GCF3WVYTHF75PEG6622G5G6KU26GOSDQPDHSCJ3DQD7VONH4EYVDOGKJ"
+	seed := "SDWLS4G3XCNIYPKXJWWGGJT6UDY63WV6PEFTWP7JZMQB4RE7EUJQN5XM"
+
+	Convey("CheckKey", t, func() {
+		Convey("valid keys", func() {
+			assert.Nil(t, protocols.CheckKey(accountID, strkey.VersionByteAccountID))
+			assert.Nil(t, protocols.CheckKey(seed, strkey.VersionByteSeed))
+			assert.True(t, protocols.IsValidAccountID(accountID))
+			assert.True(t, protocols.IsValidSecret(seed))
+		})
+
+		Convey("empty key", func() {
+			assert.Equal(t, protocols.ErrKeyEmpty, protocols.CheckKey("", strkey.VersionByteAccountID))
+		})
+
+		Convey("wrong length", func() {
+			assert.Equal(t, protocols.ErrKeyInvalidLength, protocols.CheckKey(accountID[:55], strkey.VersionByteAccountID))
+			assert.Equal(t, protocols.ErrKeyInvalidLength, protocols.CheckKey(accountID+"A", strkey.VersionByteAccountID))
+		})
+
+		Convey("short payload with a valid checksum", func() {
+			// keypair.Parse accepts such keys but signing with them panics
+			key, err := strkey.Encode(strkey.VersionByteSeed, make([]byte, 31))
+			require.NoError(t, err)
+			assert.Equal(t, protocols.ErrKeyInvalidLength, protocols.CheckKey(key, strkey.VersionByteSeed))
+			assert.False(t, protocols.IsValidSecret(key))
+		})
+
+		Convey("invalid characters", func() {
+			assert.Equal(t, protocols.ErrKeyInvalidEncoding, protocols.CheckKey("gcf3wvythf75peg6622g5g6ku26gosdqpdhscj3dqd7vonh4eyvdogkj", strkey.VersionByteAccountID))
+			assert.Equal(t, protocols.ErrKeyInvalidEncoding, protocols.CheckKey(accountID[:55]+"1", strkey.VersionByteAccountID))
+		})
+
+		Convey("invalid checksum", func() {
+			assert.Equal(t, protocols.ErrKeyInvalidChecksum, protocols.CheckKey(accountID[:55]+"A", strkey.VersionByteAccountID))
+			assert.Equal(t, protocols.ErrKeyInvalidChecksum, protocols.CheckKey("GDF3"+accountID[4:], strkey.VersionByteAccountID))
+		})
+
+		Convey("wrong key type", func() {
+			assert.Equal(t, protocols.ErrKeyNotAccountID, protocols.CheckKey(seed, strkey.VersionByteAccountID))
+			assert.Equal(t, protocols.ErrKeyNotSecret, protocols.CheckKey(accountID, strkey.VersionByteSeed))
+		})
+	})
+}
@@ -0,0 +1,136 @@
+package ratelimit
+
+import (
+	"sort"
+	"sync"
+	"time"
+)
+
+// Asset identifies a rate limited asset. Empty Code and Issuer represent native asset.
+type Asset struct {
+	Code   string `json:"asset_code"`
+	Issuer string `json:"asset_issuer"`
+}
+
+// Limit configures a token bucket: Rate tokens are added every second up to Burst tokens.
+// Every payment takes a single token.
+type Limit struct {
+	Rate  float64 `json:"rate"`
+	Burst int     `json:"burst"`
+}
+
+// Stats contains state and counters of an asset bucket
+type Stats struct {
+	Asset
+	Limit
+	Tokens    float64 `json:"tokens"`
+	Allowed   uint64  `json:"allowed"`
+	Throttled uint64  `json:"throttled"`
+}
+
+type bucket struct {
+	limit     Limit
+	tokens    float64
+	updatedAt time.Time
+	allowed   uint64
+	throttled uint64
+}
+
+func (b *bucket) refill(now time.Time) {
+	b.tokens += now.Sub(b.updatedAt).Seconds() * b.limit.Rate
+	if b.tokens > float64(b.limit.Burst) {
+		b.tokens = float64(b.limit.Burst)
+	}
+	b.updatedAt = now
+}
+
+// AssetRateLimiter limits number of payments of each asset using token buckets.
+// Payments of assets without a limit are never throttled.
+type AssetRateLimiter struct {
+	buckets map[Asset]*bucket
+	mutex   sync.Mutex
+	now     func() time.Time
+}
+
+// NewAssetRateLimiter creates a new AssetRateLimiter
+func NewAssetRateLimiter(now func() time.Time) *AssetRateLimiter {
+	return &AssetRateLimiter{
+		buckets: make(map[Asset]*bucket),
+		now:     now,
+	}
+}
+
+// SetLimit sets limit of the asset. Bucket starts full.
+func (l *AssetRateLimiter) SetLimit(asset Asset, limit Limit) {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	l.buckets[asset] = &bucket{
+		limit:     limit,
+		tokens:    float64(limit.Burst),
+		updatedAt: l.now(),
+	}
+}
+
+// Allow takes tokens for the given number of payments of each asset. Tokens are taken only when
+// all assets have enough of them, otherwise the first asset lacking tokens is returned.
+func (l *AssetRateLimiter) Allow(payments map[Asset]int) *Asset {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	now := l.now()
+
+	for asset, count := range payments {
+		b, ok := l.buckets[asset]
+		if !ok {
+			continue
+		}
+
+		b.refill(now)
+		if b.tokens < float64(count) {
+			b.throttled += uint64(count)
+			return &asset
+		}
+	}
+
+	for asset, count := range payments {
+		b, ok := l.buckets[asset]
+		if !ok {
+			continue
+		}
+
+		b.tokens -= float64(count)
+		b.allowed += uint64(count)
+	}
+
+	return nil
+}
+
+// Stats returns state of all buckets sorted by asset code and issuer
+func (l *AssetRateLimiter) Stats() []Stats {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	now := l.now()
+	stats := make([]Stats, 0, len(l.buckets))
+
+	for asset, b := range l.buckets {
+		b.refill(now)
+		stats = append(stats, Stats{
+			Asset:     asset,
+			Limit:     b.limit,
+			Tokens:    b.tokens,
+			Allowed:   b.allowed,
+			Throttled: b.throttled,
+		})
+	}
+
+	sort.Slice(stats, func(i, j int) bool {
+		if stats[i].Code != stats[j].Code {
+			return stats[i].Code < stats[j].Code
+		}
+		return stats[i].Issuer < stats[j].Issuer
+	})
+
+	return stats
+}
@@ -0,0 +1,52 @@
+package ratelimit
+
+import (
+	"testing"
+	"time"
+
+	. "github.com/smartystreets/goconvey/convey"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAssetRateLimiter(t *testing.T) {
+	usd := Asset{Code: "USD", Issuer: "GDSIKW43UA6JTOA47WVEBCZ4MYC74M3GNKNXTVDXFHXYYTNO5GGVN632"}
+	native := Asset{}
+	eur := Asset{Code: "EUR", Issuer: "GDSIKW43UA6JTOA47WVEBCZ4MYC74M3GNKNXTVDXFHXYYTNO5GGVN632"}
+
+	Convey("AssetRateLimiter", t, func() {
+		now := time.Unix(1500000000, 0)
+		limiter := NewAssetRateLimiter(func() time.Time { return now })
+		limiter.SetLimit(usd, Limit{Rate: 0.5, Burst: 2})
+		limiter.SetLimit(native, Limit{Rate: 10, Burst: 10})
+
+		Convey("allows payments up to burst and throttles the rest", func() {
+			assert.Nil(t, limiter.Allow(map[Asset]int{usd: 1}))
+			assert.Nil(t, limiter.Allow(map[Asset]int{usd: 1}))
+			assert.Equal(t, &usd, limiter.Allow(map[Asset]int{usd: 1}))
+
+			Convey("refills tokens over time", func() {
+				now = now.Add(2 * time.Second)
+				assert.Nil(t, limiter.Allow(map[Asset]int{usd: 1}))
+				assert.Equal(t, &usd, limiter.Allow(map[Asset]int{usd: 1}))
+			})
+		})
+
+		Convey("takes tokens only when all assets are within limits", func() {
+			assert.Equal(t, &usd, limiter.Allow(map[Asset]int{native: 5, usd: 3}))
+			assert.Nil(t, limiter.Allow(map[Asset]int{native: 10}))
+			assert.Equal(t, &native, limiter.Allow(map[Asset]int{native: 1}))
+		})
+
+		Convey("never throttles assets without limit", func() {
+			assert.Nil(t, limiter.Allow(map[Asset]int{eur: 1000}))
+		})
+
+		Convey("returns stats sorted by asset", func() {
+			stats := limiter.Stats()
+			assert.Len(t, stats, 2)
+			assert.Equal(t, native, stats[0].Asset)
+			assert.Equal(t, usd, stats[1].Asset)
+			assert.Equal(t, 2.0, stats[1].Tokens)
+		})
+	})
+}
@@ -0,0 +1,75 @@
+package ratelimit
+
+import (
+	"sync"
+	"time"
+)
+
+// clientBucketsCleanupInterval is how often buckets of clients that haven't sent requests for a while are removed
+const clientBucketsCleanupInterval = time.Minute
+
+// ClientRateLimiter limits number of requests of every client (ex. IP address) using a token bucket
+// per client. Every request takes a single token.
+type ClientRateLimiter struct {
+	limit     Limit
+	buckets   map[string]*bucket
+	mutex     sync.Mutex
+	now       func() time.Time
+	cleanedAt time.Time
+}
+
+// NewClientRateLimiter creates a new ClientRateLimiter with the same limit for every client
+func NewClientRateLimiter(limit Limit, now func() time.Time) *ClientRateLimiter {
+	return &ClientRateLimiter{
+		limit:     limit,
+		buckets:   make(map[string]*bucket),
+		now:       now,
+		cleanedAt: now(),
+	}
+}
+
+// Allow takes a token of the client. When there is none it returns false and the time after which
+// the next token is available.
+func (l *ClientRateLimiter) Allow(client string) (bool, time.Duration) {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	now := l.now()
+	l.cleanup(now)
+
+	b, ok := l.buckets[client]
+	if !ok {
+		b = &bucket{
+			limit:     l.limit,
+			tokens:    float64(l.limit.Burst),
+			updatedAt: now,
+		}
+		l.buckets[client] = b
+	}
+
+	b.refill(now)
+	if b.tokens < 1 {
+		b.throttled++
+		return false, time.Duration((1 - b.tokens) / l.limit.Rate * float64(time.Second))
+	}
+
+	b.tokens--
+	b.allowed++
+	return true, 0
+}
+
+// cleanup removes buckets refilled to full, their clients are not distinguishable from new ones.
+// It keeps memory bounded when requests come from many different clients.
+func (l *ClientRateLimiter) cleanup(now time.Time) {
+	if now.Sub(l.cleanedAt) < clientBucketsCleanupInterval {
+		return
+	}
+
+	for client, b := range l.buckets {
+		b.refill(now)
+		if b.tokens >= float64(b.limit.Burst) {
+			delete(l.buckets, client)
+		}
+	}
+	l.cleanedAt = now
+}
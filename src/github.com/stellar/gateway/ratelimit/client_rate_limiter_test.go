@@ -0,0 +1,54 @@
+package ratelimit
+
+import (
+	"testing"
+	"time"
+
+	. "github.com/smartystreets/goconvey/convey"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestClientRateLimiter(t *testing.T) {
+	Convey("ClientRateLimiter", t, func() {
+		now := time.Unix(1500000000, 0)
+		limiter := NewClientRateLimiter(Limit{Rate: 0.5, Burst: 2}, func() time.Time { return now })
+
+		Convey("allows requests up to burst and throttles the rest", func() {
+			allowed, _ := limiter.Allow("10.0.0.1")
+			assert.True(t, allowed)
+			allowed, _ = limiter.Allow("10.0.0.1")
+			assert.True(t, allowed)
+
+			allowed, retryAfter := limiter.Allow("10.0.0.1")
+			assert.False(t, allowed)
+			assert.Equal(t, 2*time.Second, retryAfter)
+
+			Convey("limits every client separately", func() {
+				allowed, _ := limiter.Allow("10.0.0.2")
+				assert.True(t, allowed)
+			})
+
+			Convey("refills tokens over time", func() {
+				now = now.Add(time.Second)
+				allowed, retryAfter := limiter.Allow("10.0.0.1")
+				assert.False(t, allowed)
+				assert.Equal(t, time.Second, retryAfter)
+
+				now = now.Add(time.Second)
+				allowed, _ = limiter.Allow("10.0.0.1")
+				assert.True(t, allowed)
+			})
+		})
+
+		Convey("removes buckets of idle clients", func() {
+			limiter.Allow("10.0.0.1")
+			now = now.Add(10 * time.Second)
+			limiter.Allow("10.0.0.2")
+			assert.Len(t, limiter.buckets, 2)
+
+			now = now.Add(clientBucketsCleanupInterval)
+			limiter.Allow("10.0.0.2")
+			assert.Len(t, limiter.buckets, 1)
+		})
+	})
+}
@@ -0,0 +1,106 @@
+package server
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"sort"
+	"strings"
+)
+
+// APIVersionHeader is a request header selecting the response format version. The version used is
+// sent back in the response header of the same name.
+const APIVersionHeader = "Api-Version"
+
+// apiVersions maps supported response format versions to functions converting response bodies
+// from the current format. Version 1 is the format used before versioning was introduced.
+var apiVersions = map[string]func(body map[string]json.RawMessage){
+	"1": func(body map[string]json.RawMessage) {},
+}
+
+// SupportedAPIVersions returns sorted list of supported response format versions
+func SupportedAPIVersions() []string {
+	versions := make([]string, 0, len(apiVersions))
+	for version := range apiVersions {
+		versions = append(versions, version)
+	}
+	sort.Strings(versions)
+	return versions
+}
+
+// IsSupportedAPIVersion returns true if version is a supported response format version
+func IsSupportedAPIVersion(version string) bool {
+	_, ok := apiVersions[version]
+	return ok
+}
+
+// APIVersionMiddleware converts JSON object response bodies to the format version requested in
+// Api-Version header (defaultVersion when not sent) and adds `api_version` field to them.
+// Requests for unsupported versions are answered with unsupportedResponse.
+// Event streams (requested with `Accept: text/event-stream`) are never buffered nor converted.
+func APIVersionMiddleware(defaultVersion string, unsupportedResponse Response) func(next http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		fn := func(w http.ResponseWriter, r *http.Request) {
+			version := r.Header.Get(APIVersionHeader)
+			if version == "" {
+				version = defaultVersion
+			}
+
+			convert, ok := apiVersions[version]
+			if !ok {
+				Write(w, unsupportedResponse)
+				return
+			}
+
+			w.Header().Set(APIVersionHeader, version)
+
+			if strings.Contains(r.Header.Get("Accept"), "text/event-stream") {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			bw := &bufferedResponseWriter{ResponseWriter: w, status: http.StatusOK}
+			next.ServeHTTP(bw, r)
+
+			body := bw.body.Bytes()
+			if strings.HasPrefix(w.Header().Get("Content-Type"), "application/json") {
+				converted, err := versionedJSON(body, version, convert)
+				// Not a JSON object (ex. array), send it unchanged
+				if err == nil {
+					body = converted
+					w.Header().Del("Content-Length")
+				}
+			}
+
+			w.WriteHeader(bw.status)
+			w.Write(body)
+		}
+		return http.HandlerFunc(fn)
+	}
+}
+
+// versionedJSON converts the JSON object and adds `api_version` field to it.
+// Output is indented the same way as responses marshalled by Response.Marshal.
+func versionedJSON(body []byte, version string, convert func(map[string]json.RawMessage)) ([]byte, error) {
+	var object map[string]json.RawMessage
+	decoder := json.NewDecoder(bytes.NewReader(body))
+	err := decoder.Decode(&object)
+	if err != nil {
+		return nil, err
+	}
+
+	// `null` body
+	if object == nil {
+		return nil, errors.New("Not a JSON object")
+	}
+
+	convert(object)
+
+	object["api_version"], err = json.Marshal(version)
+	if err != nil {
+		return nil, err
+	}
+
+	return json.MarshalIndent(object, "", "  ")
+}
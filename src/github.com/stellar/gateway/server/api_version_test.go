@@ -0,0 +1,69 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+	"github.com/stretchr/testify/assert"
+)
+
+type testResponse struct {
+	status int
+	body   string
+}
+
+func (r testResponse) HTTPStatus() int { return r.status }
+func (r testResponse) Marshal() []byte { return []byte(r.body) }
+
+func TestAPIVersionMiddleware(t *testing.T) {
+	var body string
+	handler := APIVersionMiddleware("1", testResponse{http.StatusBadRequest, `{"code": "unsupported_api_version"}`})(
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "application/json")
+			w.Write([]byte(body))
+		}),
+	)
+
+	request := func(version string) *httptest.ResponseRecorder {
+		r := httptest.NewRequest("GET", "/", nil)
+		if version != "" {
+			r.Header.Set(APIVersionHeader, version)
+		}
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, r)
+		return w
+	}
+
+	Convey("APIVersionMiddleware", t, func() {
+		body = `{"hash": "abc", "ledger": 123}`
+
+		Convey("adds default version when header is not sent", func() {
+			w := request("")
+			assert.Equal(t, http.StatusOK, w.Code)
+			assert.Equal(t, "1", w.Header().Get(APIVersionHeader))
+
+			var response map[string]interface{}
+			assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+			assert.Equal(t, map[string]interface{}{"hash": "abc", "ledger": float64(123), "api_version": "1"}, response)
+		})
+
+		Convey("returns error for unsupported version", func() {
+			w := request("2")
+			assert.Equal(t, http.StatusBadRequest, w.Code)
+			assert.Equal(t, `{"code": "unsupported_api_version"}`, w.Body.String())
+		})
+
+		Convey("leaves bodies that are not JSON objects unchanged", func() {
+			body = `["a", "b"]`
+			w := request("1")
+			assert.Equal(t, `["a", "b"]`, w.Body.String())
+
+			body = `null`
+			w = request("1")
+			assert.Equal(t, `null`, w.Body.String())
+		})
+	})
+}
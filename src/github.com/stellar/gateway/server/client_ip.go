@@ -0,0 +1,109 @@
+package server
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// TrustedProxies are networks of reverse proxies trusted to set the header with the client IP
+type TrustedProxies []*net.IPNet
+
+// ParseTrustedProxies parses IPs and CIDR ranges (ex. `10.0.0.1`, `10.0.0.0/8`) of trusted proxies
+func ParseTrustedProxies(values []string) (TrustedProxies, error) {
+	proxies := make(TrustedProxies, 0, len(values))
+	for _, value := range values {
+		if !strings.Contains(value, "/") {
+			ip := net.ParseIP(value)
+			if ip == nil {
+				return nil, fmt.Errorf("invalid IP: %s", value)
+			}
+			if ip.To4() != nil {
+				value += "/32"
+			} else {
+				value += "/128"
+			}
+		}
+
+		_, network, err := net.ParseCIDR(value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid CIDR range: %s", value)
+		}
+		proxies = append(proxies, network)
+	}
+	return proxies, nil
+}
+
+// Contains returns true when ip belongs to one of trusted proxies
+func (proxies TrustedProxies) Contains(ip net.IP) bool {
+	for _, network := range proxies {
+		if network.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+// ClientIP returns the IP of the client sending r. The header (ex. `X-Forwarded-For`) is used only
+// when it's not empty and the request comes from a trusted proxy. It's read from right to left
+// skipping trusted proxies, so IPs added by the client itself cannot spoof its IP.
+func ClientIP(r *http.Request, header string, proxies TrustedProxies) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		host = r.RemoteAddr
+	}
+
+	ip := net.ParseIP(host)
+	if ip == nil {
+		return host
+	}
+
+	if header == "" || !proxies.Contains(ip) {
+		return ip.String()
+	}
+
+	// Proxies append to the header or send it multiple times
+	addresses := strings.Split(strings.Join(r.Header[http.CanonicalHeaderKey(header)], ","), ",")
+	for i := len(addresses) - 1; i >= 0; i-- {
+		forwarded := net.ParseIP(strings.TrimSpace(addresses[i]))
+		if forwarded == nil {
+			break
+		}
+
+		ip = forwarded
+		if !proxies.Contains(ip) {
+			break
+		}
+	}
+
+	return ip.String()
+}
+
+// RateLimiter limits requests of every client
+type RateLimiter interface {
+	// Allow returns false and the time after which the request can be repeated when the client
+	// exceeded its limit
+	Allow(client string) (bool, time.Duration)
+}
+
+// ClientRateLimitMiddleware throttles requests of every client IP (see ClientIP). Throttled
+// requests get the response returned by errorResponse with the number of seconds to wait before
+// retrying, which is also sent in `Retry-After` header.
+func ClientRateLimitMiddleware(limiter RateLimiter, header string, proxies TrustedProxies, errorResponse func(retryAfter int) Response) func(next http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		fn := func(w http.ResponseWriter, r *http.Request) {
+			allowed, wait := limiter.Allow(ClientIP(r, header, proxies))
+			if !allowed {
+				retryAfter := int((wait + time.Second - 1) / time.Second)
+				w.Header().Set("Retry-After", strconv.Itoa(retryAfter))
+				Write(w, errorResponse(retryAfter))
+				return
+			}
+			next.ServeHTTP(w, r)
+		}
+		return http.HandlerFunc(fn)
+	}
+}
@@ -0,0 +1,112 @@
+package server
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	. "github.com/smartystreets/goconvey/convey"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type testRateLimiter struct {
+	clients []string
+	wait    time.Duration
+}
+
+func (l *testRateLimiter) Allow(client string) (bool, time.Duration) {
+	l.clients = append(l.clients, client)
+	return l.wait == 0, l.wait
+}
+
+func TestClientIP(t *testing.T) {
+	proxies, err := ParseTrustedProxies([]string{"10.0.0.0/8", "192.168.1.1", "fd00::1"})
+	require.NoError(t, err)
+
+	request := func(remoteAddr string, forwardedFor ...string) *http.Request {
+		r := httptest.NewRequest("POST", "/payment", nil)
+		r.RemoteAddr = remoteAddr
+		for _, value := range forwardedFor {
+			r.Header.Add("X-Forwarded-For", value)
+		}
+		return r
+	}
+
+	Convey("ClientIP", t, func() {
+		Convey("returns remote address when header is not configured", func() {
+			assert.Equal(t, "10.0.0.1", ClientIP(request("10.0.0.1:4321", "1.2.3.4"), "", proxies))
+		})
+
+		Convey("ignores header sent by untrusted client", func() {
+			assert.Equal(t, "5.6.7.8", ClientIP(request("5.6.7.8:4321", "1.2.3.4"), "X-Forwarded-For", proxies))
+		})
+
+		Convey("returns the last IP not belonging to a trusted proxy", func() {
+			r := request("10.0.0.1:4321", "9.9.9.9, 1.2.3.4, 192.168.1.1")
+			assert.Equal(t, "1.2.3.4", ClientIP(r, "X-Forwarded-For", proxies))
+		})
+
+		Convey("reads header sent multiple times", func() {
+			r := request("[fd00::1]:4321", "9.9.9.9", "1.2.3.4")
+			assert.Equal(t, "1.2.3.4", ClientIP(r, "X-Forwarded-For", proxies))
+		})
+
+		Convey("stops at invalid IP", func() {
+			r := request("10.0.0.1:4321", "1.2.3.4, unknown, 10.0.0.2")
+			assert.Equal(t, "10.0.0.2", ClientIP(r, "X-Forwarded-For", proxies))
+		})
+
+		Convey("returns proxy IP when header is missing", func() {
+			assert.Equal(t, "10.0.0.1", ClientIP(request("10.0.0.1:4321"), "X-Forwarded-For", proxies))
+		})
+	})
+
+	Convey("ParseTrustedProxies", t, func() {
+		Convey("returns error when value is invalid", func() {
+			for _, value := range []string{"10.0.0", "10.0.0.0/33", "proxy.example.com"} {
+				_, err := ParseTrustedProxies([]string{value})
+				assert.Error(t, err, value)
+			}
+		})
+	})
+}
+
+func TestClientRateLimitMiddleware(t *testing.T) {
+	limiter := &testRateLimiter{}
+	errorResponse := func(retryAfter int) Response {
+		return testResponse{http.StatusTooManyRequests, `{"retry_after": ` + strconv.Itoa(retryAfter) + `}`}
+	}
+	handler := ClientRateLimitMiddleware(limiter, "", nil, errorResponse)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"hash": "abc"}`))
+	}))
+
+	Convey("ClientRateLimitMiddleware", t, func() {
+		Reset(func() {
+			limiter.clients = nil
+			limiter.wait = 0
+		})
+
+		Convey("calls the handler when client is within its limit", func() {
+			w := httptest.NewRecorder()
+			handler.ServeHTTP(w, httptest.NewRequest("POST", "/payment", nil))
+
+			assert.Equal(t, []string{"192.0.2.1"}, limiter.clients)
+			assert.Equal(t, http.StatusOK, w.Code)
+			assert.Equal(t, `{"hash": "abc"}`, w.Body.String())
+		})
+
+		Convey("writes error response with Retry-After header when client is throttled", func() {
+			limiter.wait = 1500 * time.Millisecond
+
+			w := httptest.NewRecorder()
+			handler.ServeHTTP(w, httptest.NewRequest("POST", "/payment", nil))
+
+			assert.Equal(t, http.StatusTooManyRequests, w.Code)
+			assert.Equal(t, "2", w.Header().Get("Retry-After"))
+			assert.Equal(t, `{"retry_after": 2}`, w.Body.String())
+		})
+	})
+}
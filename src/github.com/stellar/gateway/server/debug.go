@@ -0,0 +1,112 @@
+package server
+
+import (
+	"bytes"
+	"context"
+	"crypto/subtle"
+	"io/ioutil"
+	"net/http"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stellar/gateway/net"
+)
+
+// Headers enabling debug logging of a single request
+const (
+	// DebugHeader must be `true` to enable debug logging of the request
+	DebugHeader = "X-Debug"
+	// DebugSecretHeader must contain the secret passed to DebugMiddleware
+	DebugSecretHeader = "X-Debug-Secret"
+)
+
+type debugLogKey struct{}
+
+// DebugMiddleware enables debug logging of requests sent with `X-Debug: true` header and a valid
+// `X-Debug-Secret` header. The request and the response are logged with secrets redacted, at debug
+// level regardless of the level of logger, and handlers can log more using DebugLog. Responses of
+// event streams (requested with `Accept: text/event-stream`) are not logged. Requests with an invalid
+// secret are handled as usual.
+func DebugMiddleware(secret string, logger *logrus.Logger) func(next http.Handler) http.Handler {
+	debugLogger := &logrus.Logger{
+		Out:       logger.Out,
+		Formatter: logger.Formatter,
+		Hooks:     logger.Hooks,
+		Level:     logrus.DebugLevel,
+	}
+
+	return func(next http.Handler) http.Handler {
+		fn := func(w http.ResponseWriter, r *http.Request) {
+			if r.Header.Get(DebugHeader) != "true" {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			if subtle.ConstantTimeCompare([]byte(r.Header.Get(DebugSecretHeader)), []byte(secret)) != 1 {
+				logger.WithField("request_id", RequestID(r)).Warn("Invalid debug secret, debug logging not enabled")
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			log := debugLogger.WithFields(logrus.Fields{
+				"request_id": RequestID(r),
+				"method":     r.Method,
+				"path":       r.URL.Path,
+			})
+
+			var body string
+			if r.PostForm != nil {
+				// Body has already been read (ex. by APIKeyMiddleware)
+				body = r.PostForm.Encode()
+			} else if r.Body != nil {
+				data, _ := ioutil.ReadAll(r.Body)
+				r.Body.Close()
+				r.Body = ioutil.NopCloser(bytes.NewReader(data))
+				body = string(data)
+			}
+
+			log.WithFields(logrus.Fields{
+				"query": net.RedactSecrets(r.URL.RawQuery),
+				"body":  net.RedactSecrets(body),
+			}).Debug("Debug request received")
+
+			r = r.WithContext(context.WithValue(r.Context(), debugLogKey{}, log))
+			if strings.Contains(r.Header.Get("Accept"), "text/event-stream") {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			dw := &debugResponseWriter{ResponseWriter: w, status: http.StatusOK}
+			next.ServeHTTP(dw, r)
+
+			log.WithFields(logrus.Fields{
+				"status": dw.status,
+				"body":   net.RedactSecrets(dw.body.String()),
+			}).Debug("Debug request response")
+		}
+		return http.HandlerFunc(fn)
+	}
+}
+
+// DebugLog returns a logger of the request with debug logging enabled by DebugMiddleware or nil
+func DebugLog(r *http.Request) *logrus.Entry {
+	log, _ := r.Context().Value(debugLogKey{}).(*logrus.Entry)
+	return log
+}
+
+// debugResponseWriter keeps a copy of the response written to the client
+type debugResponseWriter struct {
+	http.ResponseWriter
+	status int
+	body   bytes.Buffer
+}
+
+func (w *debugResponseWriter) WriteHeader(status int) {
+	w.status = status
+	w.ResponseWriter.WriteHeader(status)
+}
+
+func (w *debugResponseWriter) Write(data []byte) (int, error) {
+	w.body.Write(data)
+	return w.ResponseWriter.Write(data)
+}
@@ -0,0 +1,87 @@
+package server
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+	"github.com/sirupsen/logrus/hooks/test"
+	. "github.com/smartystreets/goconvey/convey"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDebugMiddleware(t *testing.T) {
+	logger, hook := test.NewNullLogger()
+	secret := "debug-secret-123456"
+
+	var debugLog *logrus.Entry
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		debugLog = DebugLog(r)
+		r.ParseForm()
+		w.Write([]byte(`{"hash": "abc", "seed": "` + r.PostForm.Get("source") + `"}`))
+	}
+
+	serve := func(r *http.Request) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		RequestIDMiddleware()(DebugMiddleware(secret, logger)(http.HandlerFunc(handler))).ServeHTTP(w, r)
+		return w
+	}
+
+	newRequest := func() *http.Request {
+		r := httptest.NewRequest("POST", "/payment", strings.NewReader("source=SDWLS4G3XCNIYPKXJWWGGJT6UDY63WV6PEFTWP7JZMQB4RE7EUJQN5XM&amount=10"))
+		r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		r.Header.Set(RequestIDHeader, "req-1")
+		return r
+	}
+
+	Convey("DebugMiddleware", t, func() {
+		hook.Reset()
+		debugLog = nil
+
+		Convey("does not log requests without debug header", func() {
+			w := serve(newRequest())
+			assert.Equal(t, http.StatusOK, w.Code)
+			assert.Nil(t, debugLog)
+			assert.Nil(t, hook.LastEntry())
+		})
+
+		Convey("does not enable debug logging with invalid secret", func() {
+			r := newRequest()
+			r.Header.Set(DebugHeader, "true")
+			r.Header.Set(DebugSecretHeader, "invalid")
+
+			w := serve(r)
+			assert.Equal(t, http.StatusOK, w.Code)
+			assert.Nil(t, debugLog)
+			require.Len(t, hook.Entries, 1)
+			assert.Equal(t, logrus.WarnLevel, hook.LastEntry().Level)
+		})
+
+		Convey("logs request and response with secrets redacted", func() {
+			r := newRequest()
+			r.Header.Set(DebugHeader, "true")
+			r.Header.Set(DebugSecretHeader, secret)
+
+			w := serve(r)
+			assert.Equal(t, http.StatusOK, w.Code)
+			// Handler gets the whole body
+			assert.Contains(t, w.Body.String(), "SDWLS4G3XCNIYPKXJWWGGJT6UDY63WV6PEFTWP7JZMQB4RE7EUJQN5XM")
+			require.NotNil(t, debugLog)
+			assert.Equal(t, "req-1", debugLog.Data["request_id"])
+
+			require.Len(t, hook.Entries, 2)
+			request := hook.Entries[0]
+			assert.Equal(t, logrus.DebugLevel, request.Level)
+			assert.Equal(t, "req-1", request.Data["request_id"])
+			assert.Equal(t, "source=REDACTED&amount=10", request.Data["body"])
+
+			response := hook.Entries[1]
+			assert.Equal(t, logrus.DebugLevel, response.Level)
+			assert.Equal(t, http.StatusOK, response.Data["status"])
+			assert.Equal(t, `{"hash": "abc", "seed": "REDACTED"}`, response.Data["body"])
+		})
+	})
+}
@@ -0,0 +1,108 @@
+package server
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"regexp"
+	"strings"
+)
+
+// snakeCaseKey matches keys produced by snake_case struct tags. Other keys (ex. Stellar addresses
+// used as map keys in response data) are left untouched.
+var snakeCaseKey = regexp.MustCompile("^[a-z][a-z0-9]*(_[a-z0-9]+)+$")
+
+// jsonContainer is an object or array being copied by camelCaseJSON
+type jsonContainer struct {
+	object bool
+	// Number of keys (objects) or elements (arrays) copied so far
+	count int
+}
+
+// camelCaseJSON converts keys of all objects in the JSON document to camelCase. The document is
+// copied token by token so keys keep their order and numbers (ex. amounts, ledger sequences) are
+// not changed. Output is indented the same way as responses marshalled by Response.Marshal.
+func camelCaseJSON(body []byte) ([]byte, error) {
+	decoder := json.NewDecoder(bytes.NewReader(body))
+	decoder.UseNumber()
+
+	var compact bytes.Buffer
+	var stack []*jsonContainer
+	// True when the next token is a key of the innermost object
+	expectKey := false
+
+	for {
+		token, err := decoder.Token()
+		if err == io.EOF && len(stack) > 0 {
+			return nil, io.ErrUnexpectedEOF
+		} else if err != nil {
+			return nil, err
+		}
+
+		if delim, ok := token.(json.Delim); ok && (delim == '}' || delim == ']') {
+			stack = stack[:len(stack)-1]
+			compact.WriteRune(rune(delim))
+		} else {
+			var parent *jsonContainer
+			if len(stack) > 0 {
+				parent = stack[len(stack)-1]
+			}
+
+			if parent != nil && parent.object && expectKey {
+				if parent.count > 0 {
+					compact.WriteByte(',')
+				}
+				parent.count++
+				key, _ := json.Marshal(camelCase(token.(string)))
+				compact.Write(key)
+				compact.WriteByte(':')
+				expectKey = false
+				continue
+			}
+
+			if parent != nil && !parent.object {
+				if parent.count > 0 {
+					compact.WriteByte(',')
+				}
+				parent.count++
+			}
+
+			if delim, ok := token.(json.Delim); ok {
+				stack = append(stack, &jsonContainer{object: delim == '{'})
+				compact.WriteRune(rune(delim))
+				expectKey = delim == '{'
+				continue
+			}
+
+			value, err := json.Marshal(token)
+			if err != nil {
+				return nil, err
+			}
+			compact.Write(value)
+		}
+
+		if len(stack) == 0 {
+			break
+		}
+		expectKey = stack[len(stack)-1].object
+	}
+
+	var indented bytes.Buffer
+	err := json.Indent(&indented, compact.Bytes(), "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return indented.Bytes(), nil
+}
+
+func camelCase(key string) string {
+	if !snakeCaseKey.MatchString(key) {
+		return key
+	}
+
+	words := strings.Split(key, "_")
+	for i := 1; i < len(words); i++ {
+		words[i] = strings.ToUpper(words[i][:1]) + words[i][1:]
+	}
+	return strings.Join(words, "")
+}
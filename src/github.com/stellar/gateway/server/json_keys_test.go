@@ -0,0 +1,114 @@
+package server
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCamelCaseJSON(t *testing.T) {
+	Convey("camelCaseJSON", t, func() {
+		Convey("converts keys of nested objects and arrays keeping their order", func() {
+			converted, err := camelCaseJSON([]byte(`{"tx_status":"PENDING","source_account":{"sequence_number":"1","home_domain":null},"operations":[{"asset_code":"USD","amount":"1.0"},[{"op_type":1}]],"error_code":101}`))
+			require.NoError(t, err)
+			assert.Equal(t, `{
+  "txStatus": "PENDING",
+  "sourceAccount": {
+    "sequenceNumber": "1",
+    "homeDomain": null
+  },
+  "operations": [
+    {
+      "assetCode": "USD",
+      "amount": "1.0"
+    },
+    [
+      {
+        "opType": 1
+      }
+    ]
+  ],
+  "errorCode": 101
+}`, string(converted))
+		})
+
+		Convey("keeps numbers and values unchanged", func() {
+			converted, err := camelCaseJSON([]byte(`{"fee_charged":9223372036854775807,"rate":0.10,"paid":true,"memo_text":"not_a_key","empty_list":[],"empty_object":{}}`))
+			require.NoError(t, err)
+			assert.Equal(t, `{
+  "feeCharged": 9223372036854775807,
+  "rate": 0.10,
+  "paid": true,
+  "memoText": "not_a_key",
+  "emptyList": [],
+  "emptyObject": {}
+}`, string(converted))
+		})
+
+		Convey("leaves keys not in snake_case untouched", func() {
+			converted, err := camelCaseJSON([]byte(`{"GAPCT362RATBUJ37RN2MOKQIZLHSJMO33MMCSRUXTTHIGVDYWOFG5HDS":{"hash":"a"},"X_Y":1}`))
+			require.NoError(t, err)
+			assert.Equal(t, `{
+  "GAPCT362RATBUJ37RN2MOKQIZLHSJMO33MMCSRUXTTHIGVDYWOFG5HDS": {
+    "hash": "a"
+  },
+  "X_Y": 1
+}`, string(converted))
+		})
+
+		Convey("returns error for invalid documents", func() {
+			for _, body := range []string{``, `{"hash":`, `{"hash":"a"`, `not json`} {
+				_, err := camelCaseJSON([]byte(body))
+				assert.Error(t, err, body)
+			}
+		})
+	})
+}
+
+func TestCamelCaseMiddleware(t *testing.T) {
+	handler := func(contentType, body string) http.Handler {
+		return CamelCaseMiddleware()(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", contentType)
+			w.WriteHeader(http.StatusBadRequest)
+			w.Write([]byte(body))
+		}))
+	}
+
+	Convey("CamelCaseMiddleware", t, func() {
+		Convey("converts keys of JSON responses", func() {
+			w := httptest.NewRecorder()
+			handler("application/json", `{"error_code":101}`).ServeHTTP(w, httptest.NewRequest("GET", "/payment", nil))
+
+			assert.Equal(t, http.StatusBadRequest, w.Code)
+			assert.Equal(t, "{\n  \"errorCode\": 101\n}", w.Body.String())
+		})
+
+		Convey("does not convert responses other than JSON", func() {
+			w := httptest.NewRecorder()
+			handler("text/plain", `{"error_code":101}`).ServeHTTP(w, httptest.NewRequest("GET", "/payment", nil))
+
+			assert.Equal(t, http.StatusBadRequest, w.Code)
+			assert.Equal(t, `{"error_code":101}`, w.Body.String())
+		})
+
+		Convey("sends invalid JSON unchanged", func() {
+			w := httptest.NewRecorder()
+			handler("application/json", `Forbidden`).ServeHTTP(w, httptest.NewRequest("GET", "/payment", nil))
+
+			assert.Equal(t, `Forbidden`, w.Body.String())
+		})
+
+		Convey("does not convert event streams", func() {
+			r := httptest.NewRequest("GET", "/effects", nil)
+			r.Header.Set("Accept", "text/event-stream")
+			w := httptest.NewRecorder()
+			handler("application/json", `{"error_code":101}`).ServeHTTP(w, r)
+
+			assert.Equal(t, `{"error_code":101}`, w.Body.String())
+		})
+	})
+}
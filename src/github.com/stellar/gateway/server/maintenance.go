@@ -0,0 +1,41 @@
+package server
+
+import (
+	"net/http"
+	"sync/atomic"
+)
+
+// Maintenance holds the paused state of a server, it's safe for concurrent use. While the server
+// is paused handlers wrapped with Middleware are not called, other handlers keep working.
+type Maintenance struct {
+	paused int32
+}
+
+// SetPaused pauses or resumes the server
+func (m *Maintenance) SetPaused(paused bool) {
+	var value int32
+	if paused {
+		value = 1
+	}
+	atomic.StoreInt32(&m.paused, value)
+}
+
+// Paused returns true when the server is paused
+func (m *Maintenance) Paused() bool {
+	return atomic.LoadInt32(&m.paused) == 1
+}
+
+// Middleware writes errorResponse instead of calling the next handler while the server is paused.
+// It's used for endpoints changing state (ex. sending payments) only.
+func (m *Maintenance) Middleware(errorResponse Response) func(next http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		fn := func(w http.ResponseWriter, r *http.Request) {
+			if m.Paused() {
+				Write(w, errorResponse)
+				return
+			}
+			next.ServeHTTP(w, r)
+		}
+		return http.HandlerFunc(fn)
+	}
+}
@@ -0,0 +1,54 @@
+package server
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMaintenanceMiddleware(t *testing.T) {
+	maintenance := &Maintenance{}
+	errorResponse := testResponse{http.StatusServiceUnavailable, `{"code": "service_maintenance"}`}
+	handler := maintenance.Middleware(errorResponse)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"hash": "abc"}`))
+	}))
+
+	Convey("Maintenance.Middleware", t, func() {
+		Reset(func() {
+			maintenance.SetPaused(false)
+		})
+
+		Convey("calls the handler when server is not paused", func() {
+			w := httptest.NewRecorder()
+			handler.ServeHTTP(w, httptest.NewRequest("POST", "/payment", nil))
+
+			assert.False(t, maintenance.Paused())
+			assert.Equal(t, http.StatusOK, w.Code)
+			assert.Equal(t, `{"hash": "abc"}`, w.Body.String())
+		})
+
+		Convey("writes error response when server is paused", func() {
+			maintenance.SetPaused(true)
+
+			w := httptest.NewRecorder()
+			handler.ServeHTTP(w, httptest.NewRequest("POST", "/payment", nil))
+
+			assert.True(t, maintenance.Paused())
+			assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+			assert.Equal(t, `{"code": "service_maintenance"}`, w.Body.String())
+		})
+
+		Convey("calls the handler again when server is resumed", func() {
+			maintenance.SetPaused(true)
+			maintenance.SetPaused(false)
+
+			w := httptest.NewRecorder()
+			handler.ServeHTTP(w, httptest.NewRequest("POST", "/payment", nil))
+
+			assert.Equal(t, http.StatusOK, w.Code)
+		})
+	})
+}
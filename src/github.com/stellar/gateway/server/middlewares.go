@@ -1,8 +1,15 @@
 package server
 
 import (
+	"bytes"
+	"compress/gzip"
+	"context"
 	"net/http"
 	"strings"
+	"sync"
+	"time"
+
+	"github.com/stellar/gateway/net"
 )
 
 // StripTrailingSlashMiddleware strips trailing slash.
@@ -63,3 +70,196 @@ func APIKeyMiddleware(apiKey string) func(next http.Handler) http.Handler {
 		return http.HandlerFunc(fn)
 	}
 }
+
+// CompressionMiddleware gzips JSON responses of at least minSize bytes when client accepts gzip encoding.
+// Event streams (requested with `Accept: text/event-stream`) are never buffered nor compressed.
+func CompressionMiddleware(minSize int) func(next http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		fn := func(w http.ResponseWriter, r *http.Request) {
+			if !acceptsGzip(r) || strings.Contains(r.Header.Get("Accept"), "text/event-stream") {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			bw := &bufferedResponseWriter{ResponseWriter: w, status: http.StatusOK}
+			next.ServeHTTP(bw, r)
+
+			header := w.Header()
+			header.Add("Vary", "Accept-Encoding")
+
+			if bw.body.Len() < minSize ||
+				header.Get("Content-Encoding") != "" ||
+				!strings.HasPrefix(header.Get("Content-Type"), "application/json") {
+				w.WriteHeader(bw.status)
+				w.Write(bw.body.Bytes())
+				return
+			}
+
+			header.Set("Content-Encoding", "gzip")
+			header.Del("Content-Length")
+			w.WriteHeader(bw.status)
+
+			gz := gzip.NewWriter(w)
+			gz.Write(bw.body.Bytes())
+			gz.Close()
+		}
+		return http.HandlerFunc(fn)
+	}
+}
+
+// CamelCaseMiddleware converts keys of JSON response bodies from snake_case to camelCase.
+// Event streams (requested with `Accept: text/event-stream`) are never buffered nor converted.
+func CamelCaseMiddleware() func(next http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		fn := func(w http.ResponseWriter, r *http.Request) {
+			if strings.Contains(r.Header.Get("Accept"), "text/event-stream") {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			bw := &bufferedResponseWriter{ResponseWriter: w, status: http.StatusOK}
+			next.ServeHTTP(bw, r)
+
+			body := bw.body.Bytes()
+			if bw.body.Len() > 0 && strings.HasPrefix(w.Header().Get("Content-Type"), "application/json") {
+				converted, err := camelCaseJSON(body)
+				// Not a JSON document, send it unchanged
+				if err == nil {
+					body = converted
+					w.Header().Del("Content-Length")
+				}
+			}
+
+			w.WriteHeader(bw.status)
+			w.Write(body)
+		}
+		return http.HandlerFunc(fn)
+	}
+}
+
+// TimeoutMiddleware writes timeoutResponse when handling a request takes longer than timeout. The request
+// context is cancelled then and anything written by the handler afterwards is discarded.
+// Event streams (requested with `Accept: text/event-stream`) have no deadline.
+func TimeoutMiddleware(timeout time.Duration, timeoutResponse Response) func(next http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		fn := func(w http.ResponseWriter, r *http.Request) {
+			if strings.Contains(r.Header.Get("Accept"), "text/event-stream") {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			ctx, cancel := context.WithTimeout(r.Context(), timeout)
+			defer cancel()
+
+			tw := &timeoutResponseWriter{header: make(http.Header), status: http.StatusOK}
+			done := make(chan struct{})
+			go func() {
+				next.ServeHTTP(tw, r.WithContext(ctx))
+				close(done)
+			}()
+
+			select {
+			case <-done:
+				tw.mutex.Lock()
+				defer tw.mutex.Unlock()
+				for key, values := range tw.header {
+					w.Header()[key] = values
+				}
+				w.WriteHeader(tw.status)
+				w.Write(tw.body.Bytes())
+			case <-ctx.Done():
+				tw.mutex.Lock()
+				defer tw.mutex.Unlock()
+				tw.timedOut = true
+				Write(w, timeoutResponse)
+			}
+		}
+		return http.HandlerFunc(fn)
+	}
+}
+
+// RetryBudgetMiddleware allows at most retries retries of all downstream calls made while handling
+// a request together (see net.WithRetryBudget)
+func RetryBudgetMiddleware(retries int) func(next http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		fn := func(w http.ResponseWriter, r *http.Request) {
+			next.ServeHTTP(w, r.WithContext(net.WithRetryBudget(r.Context(), retries)))
+		}
+		return http.HandlerFunc(fn)
+	}
+}
+
+// acceptsGzip checks if `Accept-Encoding` header of the request allows gzip encoding
+func acceptsGzip(r *http.Request) bool {
+	for _, encoding := range strings.Split(r.Header.Get("Accept-Encoding"), ",") {
+		params := strings.Split(encoding, ";")
+		if strings.TrimSpace(params[0]) != "gzip" {
+			continue
+		}
+
+		for _, param := range params[1:] {
+			// `gzip;q=0` means gzip is not acceptable
+			if strings.Replace(param, " ", "", -1) == "q=0" {
+				return false
+			}
+		}
+		return true
+	}
+	return false
+}
+
+// bufferedResponseWriter holds response status and body until the handler finishes
+type bufferedResponseWriter struct {
+	http.ResponseWriter
+	status      int
+	wroteHeader bool
+	body        bytes.Buffer
+}
+
+func (w *bufferedResponseWriter) WriteHeader(status int) {
+	if w.wroteHeader {
+		return
+	}
+	w.status = status
+	w.wroteHeader = true
+}
+
+func (w *bufferedResponseWriter) Write(b []byte) (int, error) {
+	w.wroteHeader = true
+	return w.body.Write(b)
+}
+
+// timeoutResponseWriter holds response of a handler running in a separate goroutine. It has its own
+// headers so the handler never touches the real response after the deadline.
+type timeoutResponseWriter struct {
+	header      http.Header
+	status      int
+	wroteHeader bool
+	timedOut    bool
+	body        bytes.Buffer
+	mutex       sync.Mutex
+}
+
+func (w *timeoutResponseWriter) Header() http.Header {
+	return w.header
+}
+
+func (w *timeoutResponseWriter) WriteHeader(status int) {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+	if w.wroteHeader || w.timedOut {
+		return
+	}
+	w.status = status
+	w.wroteHeader = true
+}
+
+func (w *timeoutResponseWriter) Write(b []byte) (int, error) {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+	if w.timedOut {
+		return 0, http.ErrHandlerTimeout
+	}
+	w.wroteHeader = true
+	return w.body.Write(b)
+}
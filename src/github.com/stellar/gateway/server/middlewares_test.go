@@ -0,0 +1,196 @@
+package server
+
+import (
+	"compress/gzip"
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	. "github.com/smartystreets/goconvey/convey"
+	"github.com/stellar/gateway/net"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCompressionMiddleware(t *testing.T) {
+	largeBody := `{"hash": "` + strings.Repeat("a", 100) + `"}`
+	smallBody := `{"hash": "abc"}`
+
+	handler := func(contentType, body string) http.Handler {
+		return CompressionMiddleware(64)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", contentType)
+			w.Header().Set("Content-Length", "1")
+			w.WriteHeader(http.StatusCreated)
+			w.Write([]byte(body))
+		}))
+	}
+
+	serve := func(h http.Handler, acceptEncoding string) *httptest.ResponseRecorder {
+		r := httptest.NewRequest("GET", "/payment", nil)
+		if acceptEncoding != "" {
+			r.Header.Set("Accept-Encoding", acceptEncoding)
+		}
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, r)
+		return w
+	}
+
+	Convey("CompressionMiddleware", t, func() {
+		Convey("gzips large JSON responses when client accepts gzip", func() {
+			w := serve(handler("application/json", largeBody), "deflate, gzip;q=0.8")
+
+			assert.Equal(t, http.StatusCreated, w.Code)
+			assert.Equal(t, "gzip", w.Header().Get("Content-Encoding"))
+			assert.Equal(t, "Accept-Encoding", w.Header().Get("Vary"))
+			assert.Empty(t, w.Header().Get("Content-Length"))
+
+			reader, err := gzip.NewReader(w.Body)
+			require.NoError(t, err)
+			body, err := ioutil.ReadAll(reader)
+			require.NoError(t, err)
+			assert.Equal(t, largeBody, string(body))
+		})
+
+		Convey("does not compress when client does not accept gzip", func() {
+			for _, acceptEncoding := range []string{"", "deflate", "gzip;q=0", "gzip; q=0, deflate"} {
+				w := serve(handler("application/json", largeBody), acceptEncoding)
+
+				assert.Equal(t, http.StatusCreated, w.Code, acceptEncoding)
+				assert.Empty(t, w.Header().Get("Content-Encoding"), acceptEncoding)
+				assert.Equal(t, largeBody, w.Body.String(), acceptEncoding)
+			}
+		})
+
+		Convey("does not compress small responses", func() {
+			w := serve(handler("application/json", smallBody), "gzip")
+
+			assert.Equal(t, http.StatusCreated, w.Code)
+			assert.Empty(t, w.Header().Get("Content-Encoding"))
+			assert.Equal(t, "Accept-Encoding", w.Header().Get("Vary"))
+			assert.Equal(t, "1", w.Header().Get("Content-Length"))
+			assert.Equal(t, smallBody, w.Body.String())
+		})
+
+		Convey("does not compress responses other than JSON", func() {
+			w := serve(handler("text/html", largeBody), "gzip")
+
+			assert.Empty(t, w.Header().Get("Content-Encoding"))
+			assert.Equal(t, largeBody, w.Body.String())
+		})
+
+		Convey("does not compress responses already encoded", func() {
+			h := CompressionMiddleware(64)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Content-Type", "application/json")
+				w.Header().Set("Content-Encoding", "br")
+				w.Write([]byte(largeBody))
+			}))
+			w := serve(h, "gzip, br")
+
+			assert.Equal(t, http.StatusOK, w.Code)
+			assert.Equal(t, "br", w.Header().Get("Content-Encoding"))
+			assert.Equal(t, largeBody, w.Body.String())
+		})
+
+		Convey("does not buffer event streams", func() {
+			r := httptest.NewRequest("GET", "/effects", nil)
+			r.Header.Set("Accept-Encoding", "gzip")
+			r.Header.Set("Accept", "text/event-stream")
+			w := httptest.NewRecorder()
+			handler("application/json", largeBody).ServeHTTP(w, r)
+
+			assert.Empty(t, w.Header().Get("Content-Encoding"))
+			assert.Empty(t, w.Header().Get("Vary"))
+			assert.Equal(t, largeBody, w.Body.String())
+		})
+	})
+}
+
+func TestTimeoutMiddleware(t *testing.T) {
+	timeoutResponse := testResponse{http.StatusGatewayTimeout, `{"code": "request_timeout"}`}
+
+	Convey("TimeoutMiddleware", t, func() {
+		Convey("copies the response when handler finishes in time", func() {
+			h := TimeoutMiddleware(time.Second, timeoutResponse)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Content-Type", "application/json")
+				w.WriteHeader(http.StatusCreated)
+				w.Write([]byte(`{"hash": "abc"}`))
+			}))
+			w := httptest.NewRecorder()
+			h.ServeHTTP(w, httptest.NewRequest("POST", "/payment", nil))
+
+			assert.Equal(t, http.StatusCreated, w.Code)
+			assert.Equal(t, "application/json", w.Header().Get("Content-Type"))
+			assert.Equal(t, `{"hash": "abc"}`, w.Body.String())
+		})
+
+		Convey("writes timeout response and cancels handler context when deadline passes", func() {
+			handlerErr := make(chan error, 1)
+			h := TimeoutMiddleware(10*time.Millisecond, timeoutResponse)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				<-r.Context().Done()
+				handlerErr <- r.Context().Err()
+				w.Header().Set("X-Late", "1")
+				w.Write([]byte(`{"hash": "abc"}`))
+			}))
+			w := httptest.NewRecorder()
+			h.ServeHTTP(w, httptest.NewRequest("POST", "/payment", nil))
+
+			assert.Equal(t, context.DeadlineExceeded, <-handlerErr)
+			assert.Equal(t, http.StatusGatewayTimeout, w.Code)
+			assert.Equal(t, `{"code": "request_timeout"}`, w.Body.String())
+			assert.Empty(t, w.Header().Get("X-Late"))
+		})
+
+		Convey("cancels handler context when request is cancelled", func() {
+			handlerErr := make(chan error, 1)
+			h := TimeoutMiddleware(time.Minute, timeoutResponse)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				<-r.Context().Done()
+				handlerErr <- r.Context().Err()
+			}))
+			ctx, cancel := context.WithCancel(context.Background())
+			r := httptest.NewRequest("POST", "/payment", nil).WithContext(ctx)
+			w := httptest.NewRecorder()
+			go func() {
+				time.Sleep(10 * time.Millisecond)
+				cancel()
+			}()
+			h.ServeHTTP(w, r)
+
+			assert.Equal(t, context.Canceled, <-handlerErr)
+		})
+
+		Convey("has no deadline for event streams", func() {
+			h := TimeoutMiddleware(time.Nanosecond, timeoutResponse)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				time.Sleep(10 * time.Millisecond)
+				_, hasDeadline := r.Context().Deadline()
+				assert.False(t, hasDeadline)
+				w.Write([]byte("data: {}\n\n"))
+			}))
+			r := httptest.NewRequest("GET", "/effects", nil)
+			r.Header.Set("Accept", "text/event-stream")
+			w := httptest.NewRecorder()
+			h.ServeHTTP(w, r)
+
+			assert.Equal(t, http.StatusOK, w.Code)
+			assert.Equal(t, "data: {}\n\n", w.Body.String())
+		})
+	})
+}
+
+func TestRetryBudgetMiddleware(t *testing.T) {
+	Convey("RetryBudgetMiddleware", t, func() {
+		Convey("shares the budget between calls made while handling the request", func() {
+			var retries []bool
+			h := RetryBudgetMiddleware(1)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				retries = append(retries, net.TakeRetry(r.Context(), 0), net.TakeRetry(r.Context(), 0))
+			}))
+			h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("POST", "/payment", nil))
+			h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("POST", "/payment", nil))
+
+			assert.Equal(t, []bool{true, false, true, false}, retries)
+		})
+	})
+}
@@ -0,0 +1,63 @@
+package server
+
+import (
+	"fmt"
+	"net/http"
+	"runtime/debug"
+
+	"github.com/sirupsen/logrus"
+)
+
+// RecoveryMiddleware recovers from panics in handlers so a single request cannot crash the server.
+// The panic is logged with the request ID and the stack trace and errorResponse is written, unless
+// the handler has already started writing its response.
+func RecoveryMiddleware(errorResponse Response, log logrus.FieldLogger) func(next http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		fn := func(w http.ResponseWriter, r *http.Request) {
+			rw := &recoveryResponseWriter{ResponseWriter: w}
+
+			defer func() {
+				err := recover()
+				if err == nil {
+					return
+				}
+
+				// Used by net/http to abort a response on purpose
+				if err == http.ErrAbortHandler {
+					panic(err)
+				}
+
+				log.WithFields(logrus.Fields{
+					"request_id": RequestID(r),
+					"method":     r.Method,
+					"path":       r.URL.Path,
+					"panic":      fmt.Sprint(err),
+					"stack":      string(debug.Stack()),
+				}).Error("Panic while handling request")
+
+				if !rw.written {
+					Write(w, errorResponse)
+				}
+			}()
+
+			next.ServeHTTP(rw, r)
+		}
+		return http.HandlerFunc(fn)
+	}
+}
+
+// recoveryResponseWriter remembers if anything has been written to the response
+type recoveryResponseWriter struct {
+	http.ResponseWriter
+	written bool
+}
+
+func (w *recoveryResponseWriter) WriteHeader(status int) {
+	w.written = true
+	w.ResponseWriter.WriteHeader(status)
+}
+
+func (w *recoveryResponseWriter) Write(data []byte) (int, error) {
+	w.written = true
+	return w.ResponseWriter.Write(data)
+}
@@ -0,0 +1,80 @@
+package server
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+	"github.com/sirupsen/logrus/hooks/test"
+	. "github.com/smartystreets/goconvey/convey"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRecoveryMiddleware(t *testing.T) {
+	logger, hook := test.NewNullLogger()
+	errorResponse := testResponse{http.StatusInternalServerError, `{"code": "internal_server_error"}`}
+
+	serve := func(handler http.HandlerFunc, r *http.Request) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		RequestIDMiddleware()(RecoveryMiddleware(errorResponse, logger)(handler)).ServeHTTP(w, r)
+		return w
+	}
+
+	Convey("RecoveryMiddleware", t, func() {
+		hook.Reset()
+
+		Convey("passes responses of handlers that do not panic", func() {
+			w := serve(func(w http.ResponseWriter, r *http.Request) {
+				w.Write([]byte(`{"hash": "abc"}`))
+			}, httptest.NewRequest("GET", "/", nil))
+
+			assert.Equal(t, http.StatusOK, w.Code)
+			assert.Equal(t, `{"hash": "abc"}`, w.Body.String())
+			assert.Nil(t, hook.LastEntry())
+		})
+
+		Convey("writes error response and logs panic", func() {
+			r := httptest.NewRequest("POST", "/payment", nil)
+			r.Header.Set(RequestIDHeader, "req-1")
+
+			w := serve(func(w http.ResponseWriter, r *http.Request) {
+				var response *http.Response
+				w.Write([]byte(response.Status))
+			}, r)
+
+			assert.Equal(t, http.StatusInternalServerError, w.Code)
+			assert.Equal(t, `{"code": "internal_server_error"}`, w.Body.String())
+			assert.Equal(t, "req-1", w.Header().Get(RequestIDHeader))
+
+			entry := hook.LastEntry()
+			require.NotNil(t, entry)
+			assert.Equal(t, logrus.ErrorLevel, entry.Level)
+			assert.Equal(t, "req-1", entry.Data["request_id"])
+			assert.Equal(t, "/payment", entry.Data["path"])
+			assert.Contains(t, entry.Data["panic"], "nil pointer dereference")
+			assert.Contains(t, entry.Data["stack"], "recovery_test.go")
+		})
+
+		Convey("does not overwrite response already written", func() {
+			w := serve(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusAccepted)
+				panic("boom")
+			}, httptest.NewRequest("GET", "/", nil))
+
+			assert.Equal(t, http.StatusAccepted, w.Code)
+			assert.Empty(t, w.Body.String())
+			assert.NotNil(t, hook.LastEntry())
+		})
+
+		Convey("generates request ID", func() {
+			w := serve(func(w http.ResponseWriter, r *http.Request) {
+				w.Write([]byte(RequestID(r)))
+			}, httptest.NewRequest("GET", "/", nil))
+
+			assert.Len(t, w.Body.String(), 32)
+			assert.Equal(t, w.Body.String(), w.Header().Get(RequestIDHeader))
+		})
+	})
+}
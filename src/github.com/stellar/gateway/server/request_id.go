@@ -0,0 +1,48 @@
+package server
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"net/http"
+)
+
+// RequestIDHeader is the header containing ID of the request. It's sent in responses and,
+// when sent by a client, its value is used instead of a generated one.
+const RequestIDHeader = "X-Request-ID"
+
+type requestIDKey struct{}
+
+// RequestIDMiddleware assigns an ID to every request so log entries of the request can be matched
+// with the client request. The ID can be read using RequestID.
+func RequestIDMiddleware() func(next http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		fn := func(w http.ResponseWriter, r *http.Request) {
+			id := r.Header.Get(RequestIDHeader)
+			if id == "" || len(id) > 64 {
+				id = newRequestID()
+			}
+
+			w.Header().Set(RequestIDHeader, id)
+			next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), requestIDKey{}, id)))
+		}
+		return http.HandlerFunc(fn)
+	}
+}
+
+// RequestID returns ID of the request assigned by RequestIDMiddleware or empty string
+func RequestID(r *http.Request) string {
+	return ContextRequestID(r.Context())
+}
+
+// ContextRequestID returns ID of the request with ctx assigned by RequestIDMiddleware or empty string
+func ContextRequestID(ctx context.Context) string {
+	id, _ := ctx.Value(requestIDKey{}).(string)
+	return id
+}
+
+func newRequestID() string {
+	id := make([]byte, 16)
+	rand.Read(id)
+	return hex.EncodeToString(id)
+}
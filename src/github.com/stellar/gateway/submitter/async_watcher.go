@@ -0,0 +1,192 @@
+package submitter
+
+import (
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stellar/gateway/db"
+	"github.com/stellar/gateway/db/entities"
+	"github.com/stellar/gateway/events"
+	"github.com/stellar/gateway/horizon"
+	"github.com/stellar/gateway/protocols/bridge"
+	"github.com/stellar/go/xdr"
+)
+
+// TransactionExpiredCode is the error code of failed events of transactions not found in a ledger
+// after their time bounds expired
+const TransactionExpiredCode = "transaction_expired"
+
+// expiryGrace is waited for after max_time of a transaction before it's considered expired. It covers
+// ledgers closed by validators with clocks behind ours and ingestion of the last ledgers by Horizon.
+const expiryGrace = 30 * time.Second
+
+// AsyncWatcher completes transactions accepted by async submission, which stay in sending status
+// after they're submitted. It polls Horizon until a transaction is found in a ledger or its time
+// bounds expire, marks the sent transaction succeeded or failed and publishes `confirmed` or
+// `failed` payment event. Transactions without time bounds are polled for Timeout at most and
+// left in sending status when they're not found, their outcome is unknown.
+type AsyncWatcher struct {
+	Horizon       horizon.HorizonInterface
+	Repository    db.RepositoryInterface
+	EntityManager db.EntityManagerInterface
+	Events        events.Publisher
+	PollInterval  time.Duration
+	Timeout       time.Duration
+	log           *logrus.Entry
+	now           func() time.Time
+	mutex         sync.Mutex
+	// Hashes of transactions being watched
+	watching map[string]bool
+}
+
+// NewAsyncWatcher creates a new AsyncWatcher
+func NewAsyncWatcher(
+	horizon horizon.HorizonInterface,
+	repository db.RepositoryInterface,
+	entityManager db.EntityManagerInterface,
+	publisher events.Publisher,
+	pollInterval, timeout time.Duration,
+	now func() time.Time,
+) *AsyncWatcher {
+	return &AsyncWatcher{
+		Horizon:       horizon,
+		Repository:    repository,
+		EntityManager: entityManager,
+		Events:        publisher,
+		PollInterval:  pollInterval,
+		Timeout:       timeout,
+		log: logrus.WithFields(logrus.Fields{
+			"service": "AsyncWatcher",
+		}),
+		now:      now,
+		watching: make(map[string]bool),
+	}
+}
+
+// WithHorizon returns a new AsyncWatcher with the same settings polling h
+func (w *AsyncWatcher) WithHorizon(h *horizon.Horizon) *AsyncWatcher {
+	watcher := NewAsyncWatcher(h, w.Repository, w.EntityManager, w.Events, w.PollInterval, w.Timeout, w.now)
+	watcher.log = w.log.WithField("horizon", h.ServerURL)
+	return watcher
+}
+
+// Watch starts watching a transaction accepted by async submission in a background goroutine unless
+// it's already watched. sentTransaction is copied so the caller can keep using it. requestID of the
+// request that submitted the transaction is sent in the event.
+func (w *AsyncWatcher) Watch(sentTransaction *entities.SentTransaction, timeBounds *xdr.TimeBounds, requestID string) {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+
+	hash := sentTransaction.TransactionID
+	if w.watching[hash] {
+		return
+	}
+	w.watching[hash] = true
+
+	deadline := w.now().Add(w.Timeout)
+	expires := timeBounds != nil && timeBounds.MaxTime != 0
+	if expires {
+		deadline = time.Unix(int64(timeBounds.MaxTime), 0).Add(expiryGrace)
+	}
+
+	go func(transaction entities.SentTransaction) {
+		w.watch(&transaction, deadline, expires, requestID)
+
+		w.mutex.Lock()
+		delete(w.watching, hash)
+		w.mutex.Unlock()
+	}(*sentTransaction)
+}
+
+// watch polls Horizon for the transaction until it's found or deadline passes
+func (w *AsyncWatcher) watch(sentTransaction *entities.SentTransaction, deadline time.Time, expires bool, requestID string) {
+	hash := sentTransaction.TransactionID
+	for {
+		time.Sleep(w.PollInterval)
+
+		transaction, err := w.Horizon.LoadTransaction(hash)
+		if err != nil {
+			w.log.WithFields(logrus.Fields{"hash": hash, "err": err}).Warn("Error loading transaction")
+		} else if transaction != nil {
+			w.complete(sentTransaction, transaction, requestID)
+			return
+		}
+
+		if w.now().After(deadline) {
+			break
+		}
+	}
+
+	if !expires {
+		w.log.WithFields(logrus.Fields{"hash": hash}).Warn("Transaction without time bounds not found in a ledger, outcome unknown")
+		return
+	}
+
+	w.log.WithFields(logrus.Fields{"hash": hash}).Print("Transaction expired before it was included in a ledger")
+	sentTransaction.MarkFailed("<expired>")
+	w.save(sentTransaction)
+	w.publish(events.PaymentEvent{Type: events.PaymentFailed, Error: TransactionExpiredCode}, sentTransaction, requestID)
+}
+
+// complete marks the sent transaction succeeded or failed depending on the transaction found in a
+// ledger and publishes its event
+func (w *AsyncWatcher) complete(sentTransaction *entities.SentTransaction, transaction *horizon.TransactionResponse, requestID string) {
+	ledger := transaction.Ledger
+	event := events.PaymentEvent{Type: events.PaymentConfirmed, Ledger: &ledger}
+
+	if transaction.Successful != nil && !*transaction.Successful {
+		event = events.PaymentEvent{Type: events.PaymentFailed}
+		errorResponse := bridge.ErrorFromHorizonResponse(horizon.SubmitTransactionResponse{
+			Extras: &horizon.SubmitTransactionResponseExtras{ResultXdr: transaction.ResultXdr},
+		})
+		if errorResponse != nil {
+			event.Error = errorResponse.Code
+		}
+		sentTransaction.MarkFailed(transaction.ResultXdr)
+	} else {
+		sentTransaction.MarkSucceeded(ledger)
+	}
+
+	w.log.WithFields(logrus.Fields{"hash": transaction.Hash, "ledger": ledger, "status": sentTransaction.Status}).Print("Transaction accepted by async submission found in a ledger")
+	w.save(sentTransaction)
+	w.publish(event, sentTransaction, requestID)
+}
+
+// save persists the outcome of the transaction. Transactions with payment ID are loaded again so
+// changes made after submission (ex. the request hash) are kept.
+func (w *AsyncWatcher) save(sentTransaction *entities.SentTransaction) {
+	if sentTransaction.PaymentID != nil {
+		stored, err := w.Repository.GetSentTransactionByPaymentID(*sentTransaction.PaymentID)
+		if err != nil || stored == nil || stored.TransactionID != sentTransaction.TransactionID {
+			w.log.WithFields(logrus.Fields{"hash": sentTransaction.TransactionID, "err": err}).Warn("Cannot load sent transaction, outcome not saved")
+			return
+		}
+
+		stored.Status = sentTransaction.Status
+		stored.Ledger = sentTransaction.Ledger
+		stored.SucceededAt = sentTransaction.SucceededAt
+		stored.ResultXdr = sentTransaction.ResultXdr
+		sentTransaction = stored
+	}
+
+	err := w.EntityManager.Persist(sentTransaction)
+	if err != nil {
+		w.log.WithFields(logrus.Fields{"hash": sentTransaction.TransactionID, "err": err}).Warn("Error saving sent transaction")
+	}
+}
+
+// publish publishes the follow-up event of the transaction
+func (w *AsyncWatcher) publish(event events.PaymentEvent, sentTransaction *entities.SentTransaction, requestID string) {
+	if w.Events == nil {
+		return
+	}
+
+	event.RequestID = requestID
+	if sentTransaction.PaymentID != nil {
+		event.PaymentID = *sentTransaction.PaymentID
+	}
+	event.Hash = sentTransaction.TransactionID
+	event.Time = w.now().UTC()
+	w.Events.PublishPayment(event)
+}
@@ -0,0 +1,147 @@
+package submitter
+
+import (
+	"testing"
+	"time"
+
+	. "github.com/smartystreets/goconvey/convey"
+	"github.com/stellar/gateway/db/entities"
+	"github.com/stellar/gateway/events"
+	"github.com/stellar/gateway/horizon"
+	"github.com/stellar/gateway/mocks"
+	"github.com/stellar/gateway/protocols/bridge"
+	"github.com/stellar/go/xdr"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAsyncWatcher(t *testing.T) {
+	mockHorizon := new(mocks.MockHorizon)
+	mockRepository := new(mocks.MockRepository)
+	mockEntityManager := new(mocks.MockEntityManager)
+	mockEventsPublisher := new(mocks.MockEventsPublisher)
+
+	Convey("AsyncWatcher", t, func() {
+		watcher := NewAsyncWatcher(mockHorizon, mockRepository, mockEntityManager, mockEventsPublisher, time.Millisecond, 50*time.Millisecond, time.Now)
+
+		published := make(chan events.PaymentEvent, 1)
+		mockEventsPublisher.On("PublishPayment", mock.AnythingOfType("events.PaymentEvent")).Run(func(args mock.Arguments) {
+			published <- args.Get(0).(events.PaymentEvent)
+		}).Once()
+
+		hash := "b3d7b5a1c0f1e2d3c4b5a6978899aabbccddeeff00112233445566778899aabb"
+		sentTransaction := &entities.SentTransaction{TransactionID: hash, Status: entities.SentTransactionStatusSending}
+		sentTransaction.SetExists()
+		var ledger uint64 = 1988728
+
+		Convey("When transaction is found in a ledger", func() {
+			mockHorizon.On("LoadTransaction", hash).Return((*horizon.TransactionResponse)(nil), nil).Once()
+			mockHorizon.On("LoadTransaction", hash).Return(&horizon.TransactionResponse{Hash: hash, Ledger: ledger}, nil).Once()
+			mockEntityManager.On("Persist", mock.AnythingOfType("*entities.SentTransaction")).Run(func(args mock.Arguments) {
+				transaction := args.Get(0).(*entities.SentTransaction)
+				assert.Equal(t, entities.SentTransactionStatusSuccess, transaction.Status)
+				assert.Equal(t, ledger, *transaction.Ledger)
+			}).Return(nil).Once()
+
+			watcher.Watch(sentTransaction, nil, "req-1")
+
+			Convey("it should mark it succeeded and publish confirmed event", func() {
+				event := <-published
+				assert.Equal(t, events.PaymentConfirmed, event.Type)
+				assert.Equal(t, "req-1", event.RequestID)
+				assert.Equal(t, hash, event.Hash)
+				assert.Equal(t, ledger, *event.Ledger)
+				// The caller's copy is not changed
+				assert.Equal(t, entities.SentTransactionStatusSending, sentTransaction.Status)
+				mockHorizon.AssertExpectations(t)
+				mockEntityManager.AssertExpectations(t)
+			})
+		})
+
+		Convey("When transaction with payment ID failed in a ledger", func() {
+			paymentID := "payment-1"
+			requestHash := "request-hash"
+			sentTransaction.PaymentID = &paymentID
+			stored := *sentTransaction
+			stored.RequestHash = &requestHash
+			successful := false
+			mockHorizon.On("LoadTransaction", hash).Return(&horizon.TransactionResponse{
+				Hash:       hash,
+				Ledger:     ledger,
+				ResultXdr:  "AAAAAAAAAGT/////AAAAAQAAAAAAAAAB/////gAAAAA=", // payment_underfunded
+				Successful: &successful,
+			}, nil).Once()
+			mockRepository.On("GetSentTransactionByPaymentID", paymentID).Return(&stored, nil).Once()
+			mockEntityManager.On("Persist", &stored).Run(func(args mock.Arguments) {
+				assert.Equal(t, entities.SentTransactionStatusFailure, stored.Status)
+				assert.Equal(t, requestHash, *stored.RequestHash)
+			}).Return(nil).Once()
+
+			watcher.Watch(sentTransaction, nil, "req-1")
+
+			Convey("it should mark it failed keeping stored changes and publish failed event", func() {
+				event := <-published
+				assert.Equal(t, events.PaymentFailed, event.Type)
+				assert.Equal(t, paymentID, event.PaymentID)
+				assert.Equal(t, bridge.PaymentUnderfunded.Code, event.Error)
+				assert.Nil(t, event.Ledger)
+				mockRepository.AssertExpectations(t)
+				mockEntityManager.AssertExpectations(t)
+			})
+		})
+
+		Convey("When transaction is not found before its time bounds expire", func() {
+			mockHorizon.On("LoadTransaction", hash).Return((*horizon.TransactionResponse)(nil), nil).Once()
+			mockEntityManager.On("Persist", mock.AnythingOfType("*entities.SentTransaction")).Run(func(args mock.Arguments) {
+				assert.Equal(t, entities.SentTransactionStatusFailure, args.Get(0).(*entities.SentTransaction).Status)
+			}).Return(nil).Once()
+
+			maxTime := time.Now().Add(-expiryGrace - time.Minute)
+			watcher.Watch(sentTransaction, &xdr.TimeBounds{MaxTime: xdr.Uint64(maxTime.Unix())}, "req-1")
+
+			Convey("it should mark it failed and publish failed event", func() {
+				event := <-published
+				assert.Equal(t, events.PaymentFailed, event.Type)
+				assert.Equal(t, TransactionExpiredCode, event.Error)
+				assert.Equal(t, hash, event.Hash)
+				mockEntityManager.AssertExpectations(t)
+			})
+		})
+
+		Convey("When transaction without time bounds is not found", func() {
+			calls := len(mockEventsPublisher.Calls)
+			mockHorizon.On("LoadTransaction", hash).Return((*horizon.TransactionResponse)(nil), nil)
+			Reset(func() {
+				mockHorizon.ExpectedCalls = nil
+				mockEventsPublisher.ExpectedCalls = nil
+			})
+
+			watcher.Watch(sentTransaction, nil, "req-1")
+
+			Convey("it should stop watching without an event", func() {
+				watching := func() bool {
+					watcher.mutex.Lock()
+					defer watcher.mutex.Unlock()
+					return watcher.watching[hash]
+				}
+				for start := time.Now(); watching(); time.Sleep(time.Millisecond) {
+					require.True(t, time.Since(start) < time.Second, "still watching")
+				}
+				assert.Len(t, mockEventsPublisher.Calls, calls)
+			})
+		})
+
+		Convey("When transaction is already watched", func() {
+			watcher.watching[hash] = true
+
+			Convey("it should not watch it again", func() {
+				calls := len(mockHorizon.Calls)
+				watcher.Watch(sentTransaction, nil, "req-1")
+				time.Sleep(10 * time.Millisecond)
+				assert.Len(t, mockHorizon.Calls, calls)
+				mockEventsPublisher.ExpectedCalls = nil
+			})
+		})
+	})
+}
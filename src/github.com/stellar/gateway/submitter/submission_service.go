@@ -0,0 +1,89 @@
+package submitter
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stellar/gateway/horizon"
+	"github.com/stellar/gateway/net"
+)
+
+// SubmissionService submits signed transaction envelopes to Stellar network.
+// horizon.HorizonInterface implements it by submitting transactions directly to Horizon.
+type SubmissionService interface {
+	SubmitTransaction(txeBase64 string) (response horizon.SubmitTransactionResponse, err error)
+}
+
+var _ SubmissionService = &horizon.Horizon{}
+
+// SubmissionServiceFunc allows using a function (ex. horizon.Horizon.SubmitTransactionAsync)
+// as a SubmissionService
+type SubmissionServiceFunc func(txeBase64 string) (horizon.SubmitTransactionResponse, error)
+
+// SubmitTransaction calls f(txeBase64)
+func (f SubmissionServiceFunc) SubmitTransaction(txeBase64 string) (horizon.SubmitTransactionResponse, error) {
+	return f(txeBase64)
+}
+
+const relaySubmitTimeout = 60 * time.Second
+
+// RelaySubmissionService submits transactions by posting envelopes (`tx` form param) to a submission
+// relay instead of Horizon. Relay must respond with a body of Horizon `POST /transactions` response.
+type RelaySubmissionService struct {
+	URL    string
+	Client net.HTTPClientInterface
+	log    *logrus.Entry
+}
+
+var _ SubmissionService = &RelaySubmissionService{}
+
+// NewRelaySubmissionService creates a new RelaySubmissionService posting envelopes to relayURL
+func NewRelaySubmissionService(relayURL string) *RelaySubmissionService {
+	return &RelaySubmissionService{
+		URL:    relayURL,
+		Client: &http.Client{Timeout: relaySubmitTimeout},
+		log: logrus.WithFields(logrus.Fields{
+			"service": "RelaySubmissionService",
+		}),
+	}
+}
+
+// SubmitTransaction posts a transaction envelope to the submission relay
+func (s *RelaySubmissionService) SubmitTransaction(txeBase64 string) (response horizon.SubmitTransactionResponse, err error) {
+	resp, err := s.Client.PostForm(s.URL, url.Values{"tx": {txeBase64}})
+	if err != nil {
+		return
+	}
+
+	defer resp.Body.Close()
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return
+	}
+
+	err = json.Unmarshal(body, &response)
+	if err != nil {
+		s.log.WithFields(logrus.Fields{
+			"status": resp.StatusCode,
+			"body":   string(body),
+		}).Error("Cannot unmarshal submission relay response")
+		err = fmt.Errorf("Invalid submission relay response (status %d)", resp.StatusCode)
+		return
+	}
+
+	if response.Ledger == nil && response.Extras == nil {
+		err = fmt.Errorf("Submission relay returned neither ledger nor error result (status %d)", resp.StatusCode)
+		return
+	}
+
+	s.log.WithFields(logrus.Fields{
+		"hash":   response.Hash,
+		"status": resp.StatusCode,
+	}).Info("Transaction submitted to relay")
+	return
+}
@@ -0,0 +1,69 @@
+package submitter
+
+import (
+	"net/url"
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+	"github.com/stellar/gateway/mocks"
+	"github.com/stellar/gateway/net"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRelaySubmissionService(t *testing.T) {
+	mockHTTPClient := new(mocks.MockHTTPClient)
+	service := NewRelaySubmissionService("http://relay/submit")
+	service.Client = mockHTTPClient
+
+	Convey("RelaySubmissionService", t, func() {
+		Convey("SubmitTransaction", func() {
+			Convey("When relay returns success response", func() {
+				mockHTTPClient.On(
+					"PostForm",
+					"http://relay/submit",
+					url.Values{"tx": {"envelope"}},
+				).Return(
+					net.BuildHTTPResponse(200, `{"hash": "abc", "ledger": 123}`),
+					nil,
+				).Once()
+
+				response, err := service.SubmitTransaction("envelope")
+				require.NoError(t, err)
+				assert.Equal(t, "abc", response.Hash)
+				assert.Equal(t, uint64(123), *response.Ledger)
+			})
+
+			Convey("When relay returns transaction error", func() {
+				mockHTTPClient.On(
+					"PostForm",
+					"http://relay/submit",
+					mock.AnythingOfType("url.Values"),
+				).Return(
+					net.BuildHTTPResponse(400, `{"extras": {"envelope_xdr": "envelope", "result_xdr": "AAAAAAAAAGT/////AAAAAQAAAAAAAAAB////+wAAAAA="}}`),
+					nil,
+				).Once()
+
+				response, err := service.SubmitTransaction("envelope")
+				require.NoError(t, err)
+				assert.Nil(t, response.Ledger)
+				assert.Equal(t, "AAAAAAAAAGT/////AAAAAQAAAAAAAAAB////+wAAAAA=", response.Extras.ResultXdr)
+			})
+
+			Convey("When relay returns unexpected response", func() {
+				mockHTTPClient.On(
+					"PostForm",
+					"http://relay/submit",
+					mock.AnythingOfType("url.Values"),
+				).Return(
+					net.BuildHTTPResponse(502, "Bad Gateway"),
+					nil,
+				).Once()
+
+				_, err := service.SubmitTransaction("envelope")
+				assert.EqualError(t, err, "Invalid submission relay response (status 502)")
+			})
+		})
+	})
+}
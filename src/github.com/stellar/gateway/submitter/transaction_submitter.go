@@ -2,9 +2,11 @@ package submitter
 
 import (
 	"bytes"
+	"context"
 	"encoding/hex"
 	"errors"
 	"fmt"
+	"math"
 	"strconv"
 	"sync"
 	"time"
@@ -13,6 +15,8 @@ import (
 	"github.com/stellar/gateway/db"
 	"github.com/stellar/gateway/db/entities"
 	"github.com/stellar/gateway/horizon"
+	"github.com/stellar/gateway/net"
+	"github.com/stellar/gateway/server"
 	"github.com/stellar/go/build"
 	"github.com/stellar/go/hash"
 	"github.com/stellar/go/keypair"
@@ -21,27 +25,59 @@ import (
 
 // TransactionSubmitterInterface helps mocking TransactionSubmitter
 type TransactionSubmitterInterface interface {
-	SubmitTransaction(paymentID *string, seed string, operation, memo interface{}) (response horizon.SubmitTransactionResponse, err error)
+	SubmitTransaction(paymentID *string, source string, operation, memo interface{}, signers ...string) (response horizon.SubmitTransactionResponse, err error)
 	SignAndSubmitRawTransaction(paymentID *string, seed string, tx *xdr.Transaction) (response horizon.SubmitTransactionResponse, err error)
+	ResubmitTransaction(envelopeXdr string) (response horizon.SubmitTransactionResponse, err error)
+	SubmitTransactionAsync(paymentID *string, source string, operation, memo interface{}, signers ...string) (response horizon.SubmitTransactionResponse, err error)
+	ResubmitTransactionAsync(envelopeXdr string) (response horizon.SubmitTransactionResponse, err error)
+	ReserveSequence(source string, ttl time.Duration) (sequence uint64, expiresAt time.Time, err error)
 }
 
 // TransactionSubmitter submits transactions to Stellar Network
 type TransactionSubmitter struct {
 	Horizon       horizon.HorizonInterface
-	Accounts      map[string]*Account // seed => *Account
-	AccountsMutex sync.Mutex
+	Accounts      map[string]*Account // account ID => *Account
+	AccountsMutex *sync.Mutex
 	EntityManager db.EntityManagerInterface
 	Network       build.Network
-	log           *logrus.Entry
-	now           func() time.Time
+	// SubmissionService is used to submit signed transactions. Defaults to Horizon.
+	SubmissionService SubmissionService
+	// AsyncSubmissionService is used by SubmitTransactionAsync and ResubmitTransactionAsync.
+	// SubmissionService is used when it's nil.
+	AsyncSubmissionService SubmissionService
+	// TxTimeout is a lifetime of transactions built by SubmitTransaction.
+	// Transactions are built without timebounds when it's zero.
+	TxTimeout time.Duration
+	// ClockSkew is subtracted from min_time and added to max_time of generated timebounds
+	ClockSkew time.Duration
+	// MaxBaseFee is the maximum fee per operation (in stroops) transactions failing with
+	// tx_insufficient_fee are rebuilt with and resubmitted (within the retry budget of ctx, see
+	// net.TakeRetry). They are not resubmitted when it's zero.
+	MaxBaseFee uint64
+	// AsyncWatcher completes transactions accepted by async submission and publishes their follow-up
+	// events. They stay in sending status when it's nil.
+	AsyncWatcher *AsyncWatcher
+	log          *logrus.Entry
+	now          func() time.Time
+	// Nothing is submitted once ctx is done (see WithContext)
+	ctx context.Context
 }
 
+// ErrSubmissionCancelled is returned when the context of the submitter is done before a transaction
+// is submitted (see WithContext)
+var ErrSubmissionCancelled = errors.New("Context done, transaction not submitted")
+
 // Account represents account used to signing and sending transactions
 type Account struct {
-	Keypair        keypair.KP
+	Keypair keypair.KP
+	// Seed of the account, empty when the account has been loaded by its account ID
 	Seed           string
 	SequenceNumber uint64
-	Mutex          sync.Mutex
+	// Expiration time of the latest sequence number reservation (see ReserveSequence), zero when there are none
+	ReservedUntil time.Time
+	// Highest reserved sequence number, syncing does not go below it until reservations expire
+	ReservedSequence uint64
+	Mutex            sync.Mutex
 }
 
 // NewTransactionSubmitter creates a new TransactionSubmitter
@@ -52,8 +88,10 @@ func NewTransactionSubmitter(
 	now func() time.Time,
 ) (ts TransactionSubmitter) {
 	ts.Horizon = horizon
+	ts.SubmissionService = horizon
 	ts.EntityManager = entityManager
 	ts.Accounts = make(map[string]*Account)
+	ts.AccountsMutex = &sync.Mutex{}
 	ts.Network = build.Network{networkPassphrase}
 	ts.log = logrus.WithFields(logrus.Fields{
 		"service": "TransactionSubmitter",
@@ -62,48 +100,140 @@ func NewTransactionSubmitter(
 	return
 }
 
-// LoadAccount loads current state of Stellar account and creates a map entry if it didn't exist
-func (ts *TransactionSubmitter) LoadAccount(seed string) (*Account, error) {
-	ts.AccountsMutex.Lock()
+// WithHorizon returns a new TransactionSubmitter with the same settings loading accounts from and
+// submitting transactions to h. Sequence numbers of accounts are not shared with ts.
+func (ts *TransactionSubmitter) WithHorizon(h *horizon.Horizon) *TransactionSubmitter {
+	submitter := &TransactionSubmitter{
+		Horizon:                h,
+		Accounts:               make(map[string]*Account),
+		AccountsMutex:          &sync.Mutex{},
+		EntityManager:          ts.EntityManager,
+		Network:                ts.Network,
+		SubmissionService:      h,
+		AsyncSubmissionService: SubmissionServiceFunc(h.SubmitTransactionAsync),
+		TxTimeout:              ts.TxTimeout,
+		ClockSkew:              ts.ClockSkew,
+		MaxBaseFee:             ts.MaxBaseFee,
+		log:                    ts.log.WithField("horizon", h.ServerURL),
+		now:                    ts.now,
+	}
+	if ts.AsyncWatcher != nil {
+		submitter.AsyncWatcher = ts.AsyncWatcher.WithHorizon(h)
+	}
+	return submitter
+}
 
-	account, exist := ts.Accounts[seed]
-	if exist {
-		ts.AccountsMutex.Unlock()
-		return account, nil
+// WithContext returns a copy of ts sharing its accounts (and sequence numbers) that loads accounts
+// and transactions with ctx and submits nothing once ctx is done, ex. when the client request the
+// transaction is sent for times out. Submissions in progress are not cancelled: the transaction
+// could be applied anyway and its outcome would be unknown then.
+func (ts *TransactionSubmitter) WithContext(ctx context.Context) *TransactionSubmitter {
+	submitter := *ts
+	submitter.ctx = ctx
+	if h, ok := ts.Horizon.(*horizon.Horizon); ok {
+		submitter.Horizon = h.WithContext(ctx)
 	}
+	return &submitter
+}
+
+// requestID returns ID of the request the submitter is used for (see WithContext), empty when unknown
+func (ts *TransactionSubmitter) requestID() string {
+	if ts.ctx == nil {
+		return ""
+	}
+	return server.ContextRequestID(ts.ctx)
+}
+
+// cancelled checks if the context of the submitter is done
+func (ts *TransactionSubmitter) cancelled() bool {
+	return ts.ctx != nil && ts.ctx.Err() != nil
+}
 
-	kp, err := keypair.Parse(seed)
+// LoadAccount loads current state of Stellar account and creates a map entry if it didn't exist.
+// source is a seed of the account or its account ID when transactions are signed by other signers.
+// Accounts are identified by account ID so both share the same sequence number.
+func (ts *TransactionSubmitter) LoadAccount(source string) (*Account, error) {
+	account, err := ts.account(source)
 	if err != nil {
-		ts.log.Print("Invalid seed")
-		ts.AccountsMutex.Unlock()
 		return nil, err
 	}
 
-	ts.Accounts[seed] = &Account{
-		Seed:    seed,
-		Keypair: kp,
+	// Load account sequence number
+	account.Mutex.Lock()
+	defer account.Mutex.Unlock()
+
+	// Sequence numbers reserved but not used are reclaimed by syncing with Horizon
+	if !account.ReservedUntil.IsZero() && ts.now().After(account.ReservedUntil) {
+		ts.log.Print("Sequence number reservations expired, syncing sequence number for ", account.Keypair.Address())
+		account.SequenceNumber = 0
+		account.ReservedUntil = time.Time{}
+		account.ReservedSequence = 0
 	}
-	ts.AccountsMutex.Unlock()
 
-	// Load account sequence number
-	ts.Accounts[seed].Mutex.Lock()
-	defer ts.Accounts[seed].Mutex.Unlock()
+	if account.SequenceNumber != 0 {
+		return account, nil
+	}
 
-	if ts.Accounts[seed].SequenceNumber != 0 {
-		return ts.Accounts[seed], nil
+	accountResponse, err := ts.Horizon.LoadAccount(account.Keypair.Address())
+	if err != nil {
+		return nil, err
 	}
 
-	accountResponse, err := ts.Horizon.LoadAccount(ts.Accounts[seed].Keypair.Address())
+	account.SequenceNumber, err = strconv.ParseUint(accountResponse.SequenceNumber, 10, 64)
 	if err != nil {
 		return nil, err
 	}
 
-	ts.Accounts[seed].SequenceNumber, err = strconv.ParseUint(accountResponse.SequenceNumber, 10, 64)
+	return account, nil
+}
+
+// account returns a map entry of the source account, creating it if it didn't exist, without
+// loading its sequence number
+func (ts *TransactionSubmitter) account(source string) (*Account, error) {
+	kp, err := keypair.Parse(source)
 	if err != nil {
+		ts.log.Print("Invalid seed")
 		return nil, err
 	}
 
-	return ts.Accounts[seed], nil
+	ts.AccountsMutex.Lock()
+	defer ts.AccountsMutex.Unlock()
+
+	account, exist := ts.Accounts[kp.Address()]
+	if !exist {
+		account = &Account{Keypair: kp}
+		if _, ok := kp.(*keypair.Full); ok {
+			account.Seed = source
+		}
+		ts.Accounts[kp.Address()] = account
+	}
+	return account, nil
+}
+
+// ReserveSequence increments the current sequence number of the source account (a seed or an account ID)
+// and returns it so a transaction built outside of the bridge can use it without colliding with
+// transactions sent by the bridge. The reservation expires after ttl. When all reservations of the
+// account have expired the sequence number is synced with Horizon, reclaiming the unused ones. Until
+// then transactions sent by the bridge from the account fail with bad sequence error when a reserved
+// sequence number has not been used yet: syncing after bad sequence errors keeps reserved ones.
+func (ts *TransactionSubmitter) ReserveSequence(source string, ttl time.Duration) (sequence uint64, expiresAt time.Time, err error) {
+	account, err := ts.LoadAccount(source)
+	if err != nil {
+		return
+	}
+
+	account.Mutex.Lock()
+	defer account.Mutex.Unlock()
+
+	account.SequenceNumber++
+	account.ReservedSequence = account.SequenceNumber
+	expiresAt = ts.now().Add(ttl)
+	if expiresAt.After(account.ReservedUntil) {
+		account.ReservedUntil = expiresAt
+	}
+
+	ts.log.WithFields(logrus.Fields{"account_id": account.Keypair.Address(), "sequence": account.SequenceNumber, "expires_at": expiresAt}).Info("Sequence number reserved")
+	return account.SequenceNumber, expiresAt, nil
 }
 
 // InitAccount loads an account and returns error if it fails
@@ -117,31 +247,144 @@ func (ts *TransactionSubmitter) InitAccount(seed string) (err error) {
 // - sign it,
 // - submit it to the network.
 func (ts *TransactionSubmitter) SignAndSubmitRawTransaction(paymentID *string, seed string, tx *xdr.Transaction) (response horizon.SubmitTransactionResponse, err error) {
-	account, err := ts.LoadAccount(seed)
+	return ts.signAndSubmit(ts.SubmissionService, paymentID, seed, tx, nil, false)
+}
+
+// signAndSubmit works like SignAndSubmitRawTransaction but additionally signs the transaction
+// with `signers` seeds (ex. seeds of operation source accounts). When keepSequence is true the
+// sequence number of tx is sent as is and the current one is not loaded.
+func (ts *TransactionSubmitter) signAndSubmit(service SubmissionService, paymentID *string, source string, tx *xdr.Transaction, signers []string, keepSequence bool) (response horizon.SubmitTransactionResponse, err error) {
+	var account *Account
+	if keepSequence {
+		account, err = ts.account(source)
+	} else {
+		account, err = ts.LoadAccount(source)
+	}
 	if err != nil {
 		return
 	}
 
-	account.Mutex.Lock()
-	account.SequenceNumber++
-	tx.SeqNum = xdr.SequenceNumber(account.SequenceNumber)
-	account.Mutex.Unlock()
+	// Transactions from accounts given by account ID are signed by signers only
+	sourceKeypair, _ := keypair.Parse(source)
+	sourceFull, sourceIsSeed := sourceKeypair.(*keypair.Full)
+	if !sourceIsSeed && len(signers) == 0 {
+		err = errors.New("Transaction source has no seed and no signers")
+		return
+	}
+
+	if !keepSequence {
+		account.Mutex.Lock()
+		account.SequenceNumber++
+		tx.SeqNum = xdr.SequenceNumber(account.SequenceNumber)
+		account.Mutex.Unlock()
+	}
+
+	for {
+		var sentTransaction *entities.SentTransaction
+		response, sentTransaction, err = ts.signAndSubmitOnce(service, paymentID, account, sourceFull, tx, signers)
+		if err == ErrSubmissionCancelled && !keepSequence {
+			// Not submitted, the sequence number can be used by the next transaction
+			account.Mutex.Lock()
+			if account.SequenceNumber == uint64(tx.SeqNum) {
+				account.SequenceNumber--
+			}
+			account.Mutex.Unlock()
+		}
+		if err != nil {
+			return
+		}
+
+		// Resubmissions count towards the retry budget of the client request (see WithContext)
+		fee, retry := ts.increasedFee(tx, response)
+		if !retry || !net.TakeRetry(ts.ctx, 0) {
+			break
+		}
+
+		// Failed transaction didn't consume the sequence number, the new one uses it again.
+		// Payment ID is moved to the new transaction.
+		if sentTransaction.PaymentID != nil {
+			sentTransaction.PaymentID = nil
+			err = ts.EntityManager.Persist(sentTransaction)
+			if err != nil {
+				return
+			}
+		}
+
+		ts.log.WithFields(logrus.Fields{"hash": response.Hash, "fee": tx.Fee, "new_fee": fee}).Info("Transaction fee too small, resubmitting with higher fee")
+		tx.Fee = fee
+	}
+
+	if response.Ledger != nil || response.Status != "" {
+		response.SourceSequence = strconv.FormatUint(uint64(tx.SeqNum), 10)
+	}
+
+	// Transactions sent with their own sequence number move the current one forward
+	if keepSequence && (response.Ledger != nil || response.Status != "") {
+		account.Mutex.Lock()
+		if account.SequenceNumber != 0 && uint64(tx.SeqNum) > account.SequenceNumber {
+			account.SequenceNumber = uint64(tx.SeqNum)
+		}
+		account.Mutex.Unlock()
+	}
+
+	// Sync sequence number
+	if response.Extras != nil && response.Extras.ResultXdr == "AAAAAAAAAAD////7AAAAAA==" {
+		account.Mutex.Lock()
+		ts.log.Print("Syncing sequence number for ", account.Keypair.Address())
+		accountResponse, err2 := ts.Horizon.LoadAccount(account.Keypair.Address())
+		if err2 != nil {
+			ts.log.Error("Error updating sequence number ", err)
+		} else {
+			account.SequenceNumber, _ = strconv.ParseUint(accountResponse.SequenceNumber, 10, 64)
+			// Reserved sequence numbers are not given to bridge transactions until reservations expire
+			if ts.now().Before(account.ReservedUntil) && account.SequenceNumber < account.ReservedSequence {
+				ts.log.WithFields(logrus.Fields{"account_id": account.Keypair.Address(), "reserved": account.ReservedSequence}).Warn("Reserved sequence number not used yet")
+				account.SequenceNumber = account.ReservedSequence
+			}
+		}
+		account.Mutex.Unlock()
+	}
+	return
+}
 
+// signAndSubmitOnce signs tx with source (when it's a seed) and signers, submits it using service
+// and saves it as a sent transaction
+func (ts *TransactionSubmitter) signAndSubmitOnce(service SubmissionService, paymentID *string, account *Account, sourceFull *keypair.Full, tx *xdr.Transaction, signers []string) (response horizon.SubmitTransactionResponse, sentTransaction *entities.SentTransaction, err error) {
 	hash, err := TransactionHash(tx, ts.Network.Passphrase)
 	if err != nil {
 		ts.log.Print("Error calculating transaction hash")
 		return
 	}
 
-	sig, err := account.Keypair.SignDecorated(hash[:])
-	if err != nil {
-		ts.log.Print("Error signing a transaction")
-		return
+	envelopeXdr := xdr.TransactionEnvelope{
+		Tx: *tx,
 	}
 
-	envelopeXdr := xdr.TransactionEnvelope{
-		Tx:         *tx,
-		Signatures: []xdr.DecoratedSignature{sig},
+	if sourceFull != nil {
+		var sig xdr.DecoratedSignature
+		sig, err = sourceFull.SignDecorated(hash[:])
+		if err != nil {
+			ts.log.Print("Error signing a transaction")
+			return
+		}
+		envelopeXdr.Signatures = append(envelopeXdr.Signatures, sig)
+	}
+
+	for _, signer := range signers {
+		var kp keypair.KP
+		kp, err = keypair.Parse(signer)
+		if err != nil {
+			ts.log.Print("Invalid signer seed")
+			return
+		}
+
+		var sig xdr.DecoratedSignature
+		sig, err = kp.SignDecorated(hash[:])
+		if err != nil {
+			ts.log.Print("Error signing a transaction")
+			return
+		}
+		envelopeXdr.Signatures = append(envelopeXdr.Signatures, sig)
 	}
 
 	txeB64, err := xdr.MarshalBase64(envelopeXdr)
@@ -150,15 +393,15 @@ func (ts *TransactionSubmitter) SignAndSubmitRawTransaction(paymentID *string, s
 		return
 	}
 
-	transactionHashBytes, err := TransactionHash(tx, ts.Network.Passphrase)
-	if err != nil {
-		ts.log.WithFields(logrus.Fields{"err": err}).Warn("Error calculating tx hash")
+	if ts.cancelled() {
+		ts.log.WithFields(logrus.Fields{"hash": hex.EncodeToString(hash[:])}).Warn("Context done, transaction not submitted")
+		err = ErrSubmissionCancelled
 		return
 	}
 
-	sentTransaction := &entities.SentTransaction{
+	sentTransaction = &entities.SentTransaction{
 		PaymentID:     paymentID,
-		TransactionID: hex.EncodeToString(transactionHashBytes[:]),
+		TransactionID: hex.EncodeToString(hash[:]),
 		Status:        entities.SentTransactionStatusSending,
 		Source:        account.Keypair.Address(),
 		SubmittedAt:   ts.now(),
@@ -169,15 +412,26 @@ func (ts *TransactionSubmitter) SignAndSubmitRawTransaction(paymentID *string, s
 		return
 	}
 
-	ts.log.WithFields(logrus.Fields{"tx": txeB64}).Info("Submitting transaction")
-	response, err = ts.Horizon.SubmitTransaction(txeB64)
+	ts.log.WithFields(logrus.Fields{"tx": txeB64, "hash": sentTransaction.TransactionID}).Info("Submitting transaction")
+	response, err = service.SubmitTransaction(txeB64)
 	if err != nil {
 		ts.log.Error("Error submitting transaction ", err)
 		return
 	}
 
+	// Horizon timeout responses don't contain the hash
+	if response.Hash == "" {
+		response.Hash = sentTransaction.TransactionID
+	}
+
 	if response.Ledger != nil {
 		sentTransaction.MarkSucceeded(*response.Ledger)
+	} else if response.Status != "" {
+		// Accepted by async submission, the transaction stays in sending status until the watcher completes it
+		if ts.AsyncWatcher != nil {
+			ts.AsyncWatcher.Watch(sentTransaction, tx.TimeBounds, ts.requestID())
+		}
+		return
 	} else {
 		var result string
 		if response.Extras != nil {
@@ -192,24 +446,140 @@ func (ts *TransactionSubmitter) SignAndSubmitRawTransaction(paymentID *string, s
 		return
 	}
 
-	// Sync sequence number
-	if response.Extras != nil && response.Extras.ResultXdr == "AAAAAAAAAAD////7AAAAAA==" {
-		account.Mutex.Lock()
-		ts.log.Print("Syncing sequence number for ", account.Keypair.Address())
-		accountResponse, err2 := ts.Horizon.LoadAccount(account.Keypair.Address())
-		if err2 != nil {
-			ts.log.Error("Error updating sequence number ", err)
-		} else {
-			account.SequenceNumber, _ = strconv.ParseUint(accountResponse.SequenceNumber, 10, 64)
+	return
+}
+
+// increasedFee returns the fee tx should be resubmitted with when it failed with tx_insufficient_fee:
+// double of the current fee per operation, at least the base fee of the latest ledger and at most
+// MaxBaseFee. It returns false when the fee cannot be increased.
+func (ts *TransactionSubmitter) increasedFee(tx *xdr.Transaction, response horizon.SubmitTransactionResponse) (xdr.Uint32, bool) {
+	if ts.MaxBaseFee == 0 || len(tx.Operations) == 0 || response.Extras == nil {
+		return 0, false
+	}
+
+	var result xdr.TransactionResult
+	err := xdr.SafeUnmarshalBase64(response.Extras.ResultXdr, &result)
+	if err != nil || result.Result.Code != xdr.TransactionResultCodeTxInsufficientFee {
+		return 0, false
+	}
+
+	operations := uint64(len(tx.Operations))
+	currentBaseFee := uint64(tx.Fee) / operations
+	baseFee := 2 * currentBaseFee
+
+	ledger, err := ts.Horizon.LoadLatestLedger()
+	if err != nil {
+		ts.log.WithFields(logrus.Fields{"err": err}).Warn("Cannot load base fee of the latest ledger")
+	} else if uint64(ledger.BaseFeeInStroops) > baseFee {
+		baseFee = uint64(ledger.BaseFeeInStroops)
+	}
+
+	if baseFee > ts.MaxBaseFee {
+		baseFee = ts.MaxBaseFee
+	}
+
+	if baseFee <= currentBaseFee || baseFee*operations > math.MaxUint32 {
+		return 0, false
+	}
+	return xdr.Uint32(baseFee * operations), true
+}
+
+// ResubmitTransaction submits previously signed transaction envelope again. To prevent
+// double-submission it first checks if the transaction has already been included in
+// the ledger and, if so, returns its result without submitting it again.
+func (ts *TransactionSubmitter) ResubmitTransaction(envelopeXdr string) (response horizon.SubmitTransactionResponse, err error) {
+	return ts.resubmitTransaction(ts.SubmissionService, envelopeXdr)
+}
+
+// ResubmitTransactionAsync works like ResubmitTransaction but submits the transaction using
+// AsyncSubmissionService
+func (ts *TransactionSubmitter) ResubmitTransactionAsync(envelopeXdr string) (response horizon.SubmitTransactionResponse, err error) {
+	return ts.resubmitTransaction(ts.asyncSubmissionService(), envelopeXdr)
+}
+
+func (ts *TransactionSubmitter) resubmitTransaction(service SubmissionService, envelopeXdr string) (response horizon.SubmitTransactionResponse, err error) {
+	hash, err := EnvelopeHash(envelopeXdr, ts.Network.Passphrase)
+	if err != nil {
+		ts.log.WithFields(logrus.Fields{"err": err}).Error("Error calculating tx hash")
+		return
+	}
+
+	transaction, err := ts.Horizon.LoadTransaction(hash)
+	if err != nil {
+		ts.log.WithFields(logrus.Fields{"err": err, "hash": hash}).Error("Error loading transaction")
+		return
+	}
+
+	if transaction != nil {
+		ts.log.WithFields(logrus.Fields{"hash": hash}).Info("Transaction already in ledger, skipping submission")
+		ledger := transaction.Ledger
+		response = horizon.SubmitTransactionResponse{
+			Hash:           transaction.Hash,
+			Ledger:         &ledger,
+			ResultXdr:      &transaction.ResultXdr,
+			ResultMetaXdr:  &transaction.ResultMetaXdr,
+			SourceSequence: envelopeSequence(envelopeXdr),
 		}
-		account.Mutex.Unlock()
+		return
+	}
+
+	if ts.cancelled() {
+		ts.log.WithFields(logrus.Fields{"hash": hash}).Warn("Context done, transaction not resubmitted")
+		err = ErrSubmissionCancelled
+		return
+	}
+
+	ts.log.WithFields(logrus.Fields{"tx": envelopeXdr, "hash": hash}).Info("Resubmitting transaction")
+	response, err = service.SubmitTransaction(envelopeXdr)
+	if err != nil {
+		return
+	}
+
+	if response.Hash == "" {
+		response.Hash = hash
+	}
+	if response.Ledger != nil || response.Status != "" {
+		response.SourceSequence = envelopeSequence(envelopeXdr)
 	}
 	return
 }
 
-// SubmitTransaction builds and submits transaction to Stellar network
-func (ts *TransactionSubmitter) SubmitTransaction(paymentID *string, seed string, operation, memo interface{}) (response horizon.SubmitTransactionResponse, err error) {
-	account, err := ts.LoadAccount(seed)
+// envelopeSequence returns the sequence number of the transaction of a base64 encoded envelope,
+// empty when the envelope cannot be decoded
+func envelopeSequence(envelopeXdr string) string {
+	var envelope xdr.TransactionEnvelope
+	err := xdr.SafeUnmarshalBase64(envelopeXdr, &envelope)
+	if err != nil {
+		return ""
+	}
+	return strconv.FormatInt(int64(envelope.Tx.SeqNum), 10)
+}
+
+// SubmitTransaction builds and submits transaction to Stellar network. Transaction is sent from `source`
+// and signed with it, when it's a seed, and all `signers` seeds. The latter are needed when operations
+// have their own source accounts or when `source` is an account ID. Sequence number of the source
+// account is used unless `operation` sets one (build.Sequence), the account is not loaded then.
+func (ts *TransactionSubmitter) SubmitTransaction(paymentID *string, source string, operation, memo interface{}, signers ...string) (response horizon.SubmitTransactionResponse, err error) {
+	return ts.submitTransaction(ts.SubmissionService, paymentID, source, operation, memo, signers)
+}
+
+// SubmitTransactionAsync works like SubmitTransaction but submits the transaction using
+// AsyncSubmissionService. Responses of transactions accepted but not yet included in a ledger
+// have Status set and no Ledger.
+func (ts *TransactionSubmitter) SubmitTransactionAsync(paymentID *string, source string, operation, memo interface{}, signers ...string) (response horizon.SubmitTransactionResponse, err error) {
+	return ts.submitTransaction(ts.asyncSubmissionService(), paymentID, source, operation, memo, signers)
+}
+
+// asyncSubmissionService returns AsyncSubmissionService or SubmissionService when it's not set
+func (ts *TransactionSubmitter) asyncSubmissionService() SubmissionService {
+	if ts.AsyncSubmissionService == nil {
+		return ts.SubmissionService
+	}
+	return ts.AsyncSubmissionService
+}
+
+func (ts *TransactionSubmitter) submitTransaction(service SubmissionService, paymentID *string, source string, operation, memo interface{}, signers []string) (response horizon.SubmitTransactionResponse, err error) {
+	account, err := ts.account(source)
 	if err != nil {
 		return
 	}
@@ -222,7 +592,7 @@ func (ts *TransactionSubmitter) SubmitTransaction(paymentID *string, seed string
 	}
 
 	mutators := []build.TransactionMutator{
-		build.SourceAccount{account.Seed},
+		build.SourceAccount{account.Keypair.Address()},
 		ts.Network,
 		operationMutator,
 	}
@@ -243,7 +613,27 @@ func (ts *TransactionSubmitter) SubmitTransaction(paymentID *string, seed string
 		return
 	}
 
-	return ts.SignAndSubmitRawTransaction(paymentID, seed, txBuilder.TX)
+	if ts.TxTimeout > 0 {
+		txBuilder.TX.TimeBounds = ts.timeBounds()
+	}
+
+	return ts.signAndSubmit(service, paymentID, source, txBuilder.TX, signers, txBuilder.TX.SeqNum != 0)
+}
+
+// timeBounds returns timebounds valid for TxTimeout from now, widened by ClockSkew on both ends
+func (ts *TransactionSubmitter) timeBounds() *xdr.TimeBounds {
+	now := ts.now()
+
+	minTime := now.Add(-ts.ClockSkew).Unix()
+	if minTime < 0 {
+		minTime = 0
+	}
+	maxTime := now.Add(ts.TxTimeout + ts.ClockSkew).Unix()
+
+	return &xdr.TimeBounds{
+		MinTime: xdr.Uint64(minTime),
+		MaxTime: xdr.Uint64(maxTime),
+	}
 }
 
 // BuildTransaction is used in compliance server. The sequence number in built transaction will be equal 0!
@@ -274,6 +664,22 @@ func BuildTransaction(accountID, networkPassphrase string, operation, memo inter
 	return txBuilder.TX, err
 }
 
+// EnvelopeHash returns hex encoded hash of the transaction in a base64 encoded transaction envelope
+func EnvelopeHash(envelopeXdr, networkPassphrase string) (string, error) {
+	var envelope xdr.TransactionEnvelope
+	err := xdr.SafeUnmarshalBase64(envelopeXdr, &envelope)
+	if err != nil {
+		return "", err
+	}
+
+	hash, err := TransactionHash(&envelope.Tx, networkPassphrase)
+	if err != nil {
+		return "", err
+	}
+
+	return hex.EncodeToString(hash[:]), nil
+}
+
 // TransactionHash returns transaction hash for a given Transaction based on the network
 func TransactionHash(tx *xdr.Transaction, networkPassphrase string) ([32]byte, error) {
 	var txBytes bytes.Buffer
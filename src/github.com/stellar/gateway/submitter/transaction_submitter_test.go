@@ -1,6 +1,7 @@
 package submitter
 
 import (
+	"context"
 	"errors"
 	"testing"
 	"time"
@@ -9,9 +10,14 @@ import (
 	"github.com/stellar/gateway/db/entities"
 	"github.com/stellar/gateway/horizon"
 	"github.com/stellar/gateway/mocks"
+	"github.com/stellar/gateway/net"
+	"github.com/stellar/gateway/protocols/bridge"
 	b "github.com/stellar/go/build"
+	"github.com/stellar/go/keypair"
+	"github.com/stellar/go/xdr"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
 )
 
 func TestTransactionSubmitter(t *testing.T) {
@@ -71,6 +77,72 @@ func TestTransactionSubmitter(t *testing.T) {
 			})
 		})
 
+		Convey("ReserveSequence", func() {
+			now := mocks.PredefinedTime
+			transactionSubmitter := NewTransactionSubmitter(
+				mockHorizon,
+				mockEntityManager,
+				"Test SDF Network ; September 2015",
+				func() time.Time { return now },
+			)
+
+			mockHorizon.On("LoadAccount", accountID).Return(
+				horizon.AccountResponse{AccountID: accountID, SequenceNumber: "100"},
+				nil,
+			).Once()
+
+			sequence, expiresAt, err := transactionSubmitter.ReserveSequence(accountID, time.Minute)
+			require.NoError(t, err)
+			assert.Equal(t, uint64(101), sequence)
+			assert.Equal(t, now.Add(time.Minute), expiresAt)
+
+			Convey("it should reserve consecutive sequence numbers", func() {
+				sequence, _, err := transactionSubmitter.ReserveSequence(seed, time.Minute)
+				require.NoError(t, err)
+				assert.Equal(t, uint64(102), sequence)
+				mockHorizon.AssertExpectations(t)
+			})
+
+			Convey("it should sync sequence number when reservations expire", func() {
+				now = now.Add(2 * time.Minute)
+				mockHorizon.On("LoadAccount", accountID).Return(
+					horizon.AccountResponse{AccountID: accountID, SequenceNumber: "100"},
+					nil,
+				).Once()
+
+				account, err := transactionSubmitter.LoadAccount(seed)
+				require.NoError(t, err)
+				assert.Equal(t, uint64(100), account.SequenceNumber)
+				assert.True(t, account.ReservedUntil.IsZero())
+				mockHorizon.AssertExpectations(t)
+			})
+
+			Convey("it should keep reserved sequence numbers when syncing after bad sequence", func() {
+				transactionSubmitter.SubmissionService = SubmissionServiceFunc(func(txeBase64 string) (horizon.SubmitTransactionResponse, error) {
+					return horizon.SubmitTransactionResponse{
+						Extras: &horizon.SubmitTransactionResponseExtras{
+							ResultXdr: "AAAAAAAAAAD////7AAAAAA==", // tx_bad_seq
+						},
+					}, nil
+				})
+				mockEntityManager.On("Persist", mock.AnythingOfType("*entities.SentTransaction")).Return(nil).Twice()
+				mockHorizon.On("LoadAccount", accountID).Return(
+					horizon.AccountResponse{AccountID: accountID, SequenceNumber: "100"},
+					nil,
+				).Once()
+
+				operation := b.Payment(
+					b.Destination{"GB3W7VQ2A2IOQIS4LUFUMRC2DWXONUDH24ROLE6RS4NGUNHVSXKCABOM"},
+					b.NativeAmount{"100"},
+				)
+				_, err := transactionSubmitter.SubmitTransaction((*string)(nil), seed, operation, nil)
+				require.NoError(t, err)
+				assert.Equal(t, uint64(101), transactionSubmitter.Accounts[accountID].SequenceNumber)
+				mockHorizon.AssertExpectations(t)
+				mockEntityManager.AssertExpectations(t)
+			})
+		})
+
 		Convey("SubmitTransaction", func() {
 			Convey("Submits transaction without a memo", func() {
 				operation := b.Payment(
@@ -138,8 +210,9 @@ func TestTransactionSubmitter(t *testing.T) {
 						nil,
 					).Once()
 
-					_, err = transactionSubmitter.SubmitTransaction((*string)(nil), seed, operation, nil)
+					response, err := transactionSubmitter.SubmitTransaction((*string)(nil), seed, operation, nil)
 					assert.Nil(t, err)
+					assert.Empty(t, response.SourceSequence)
 					mockHorizon.AssertExpectations(t)
 				})
 
@@ -217,7 +290,7 @@ func TestTransactionSubmitter(t *testing.T) {
 
 					_, err = transactionSubmitter.SubmitTransaction((*string)(nil), seed, operation, nil)
 					assert.Nil(t, err)
-					assert.Equal(t, uint64(100), transactionSubmitter.Accounts[seed].SequenceNumber)
+					assert.Equal(t, uint64(100), transactionSubmitter.Accounts[accountID].SequenceNumber)
 					mockHorizon.AssertExpectations(t)
 				})
 
@@ -280,9 +353,46 @@ func TestTransactionSubmitter(t *testing.T) {
 					response, err := transactionSubmitter.SubmitTransaction((*string)(nil), seed, operation, nil)
 					assert.Nil(t, err)
 					assert.Equal(t, *response.Ledger, ledger)
-					assert.Equal(t, uint64(10372672437354497), transactionSubmitter.Accounts[seed].SequenceNumber)
+					assert.Equal(t, "10372672437354497", response.SourceSequence)
+					assert.Equal(t, uint64(10372672437354497), transactionSubmitter.Accounts[accountID].SequenceNumber)
 					mockHorizon.AssertExpectations(t)
 				})
+
+				Convey("When context is done", func() {
+					transactionSubmitter := NewTransactionSubmitter(
+						mockHorizon,
+						mockEntityManager,
+						"Test SDF Network ; September 2015",
+						mocks.Now,
+					)
+
+					mockHorizon.On(
+						"LoadAccount",
+						accountID,
+					).Return(
+						horizon.AccountResponse{
+							AccountID:      accountID,
+							SequenceNumber: "10372672437354496",
+						},
+						nil,
+					).Once()
+
+					err := transactionSubmitter.InitAccount(seed)
+					assert.Nil(t, err)
+
+					ctx, cancel := context.WithCancel(context.Background())
+					cancel()
+
+					horizonCalls := len(mockHorizon.Calls)
+					entityManagerCalls := len(mockEntityManager.Calls)
+
+					_, err = transactionSubmitter.WithContext(ctx).SubmitTransaction((*string)(nil), seed, operation, nil)
+					assert.Equal(t, ErrSubmissionCancelled, err)
+					// Nothing persisted or submitted, sequence number is free for the next transaction
+					assert.Equal(t, horizonCalls, len(mockHorizon.Calls))
+					assert.Equal(t, entityManagerCalls, len(mockEntityManager.Calls))
+					assert.Equal(t, uint64(10372672437354496), transactionSubmitter.Accounts[accountID].SequenceNumber)
+				})
 			})
 
 			Convey("Submits transaction with a memo", func() {
@@ -352,10 +462,474 @@ func TestTransactionSubmitter(t *testing.T) {
 					response, err := transactionSubmitter.SubmitTransaction((*string)(nil), seed, operation, memo)
 					assert.Nil(t, err)
 					assert.Equal(t, *response.Ledger, ledger)
-					assert.Equal(t, uint64(10372672437354497), transactionSubmitter.Accounts[seed].SequenceNumber)
+					assert.Equal(t, uint64(10372672437354497), transactionSubmitter.Accounts[accountID].SequenceNumber)
 					mockHorizon.AssertExpectations(t)
 				})
 			})
+
+			Convey("Submits transaction with timebounds", func() {
+				operation := b.Payment(
+					b.Destination{"GB3W7VQ2A2IOQIS4LUFUMRC2DWXONUDH24ROLE6RS4NGUNHVSXKCABOM"},
+					b.NativeAmount{"100"},
+				)
+
+				transactionSubmitter := NewTransactionSubmitter(
+					mockHorizon,
+					mockEntityManager,
+					"Test SDF Network ; September 2015",
+					mocks.Now,
+				)
+				transactionSubmitter.TxTimeout = 60 * time.Second
+				transactionSubmitter.ClockSkew = 5 * time.Second
+
+				mockHorizon.On(
+					"LoadAccount",
+					accountID,
+				).Return(
+					horizon.AccountResponse{
+						AccountID:      accountID,
+						SequenceNumber: "10372672437354496",
+					},
+					nil,
+				).Once()
+
+				err := transactionSubmitter.InitAccount(seed)
+				assert.Nil(t, err)
+
+				mockEntityManager.On(
+					"Persist",
+					mock.AnythingOfType("*entities.SentTransaction"),
+				).Return(nil).Twice()
+
+				ledger := uint64(1486276)
+				mockHorizon.On("SubmitTransaction", mock.AnythingOfType("string")).Return(
+					horizon.SubmitTransactionResponse{Ledger: &ledger},
+					nil,
+				).Once().Run(func(args mock.Arguments) {
+					var envelope xdr.TransactionEnvelope
+					err := xdr.SafeUnmarshalBase64(args.String(0), &envelope)
+					require.NoError(t, err)
+					require.NotNil(t, envelope.Tx.TimeBounds)
+					assert.Equal(t, xdr.Uint64(mocks.PredefinedTime.Unix()-5), envelope.Tx.TimeBounds.MinTime)
+					assert.Equal(t, xdr.Uint64(mocks.PredefinedTime.Unix()+65), envelope.Tx.TimeBounds.MaxTime)
+				})
+
+				_, err = transactionSubmitter.SubmitTransaction((*string)(nil), seed, operation, nil)
+				assert.Nil(t, err)
+				mockHorizon.AssertExpectations(t)
+			})
+
+			Convey("Submits transaction signed by operation sources", func() {
+				// GCF3WVYTHF75PEG6622G5G6KU26GOSDQPDHSCJ3DQD7VONH4EYVDOGKJ
+				signer := "SDWLS4G3XCNIYPKXJWWGGJT6UDY63WV6PEFTWP7JZMQB4RE7EUJQN5XM"
+				operation := b.Payment(
+					b.SourceAccount{"GCF3WVYTHF75PEG6622G5G6KU26GOSDQPDHSCJ3DQD7VONH4EYVDOGKJ"},
+					b.Destination{"GB3W7VQ2A2IOQIS4LUFUMRC2DWXONUDH24ROLE6RS4NGUNHVSXKCABOM"},
+					b.NativeAmount{"100"},
+				)
+
+				transactionSubmitter := NewTransactionSubmitter(
+					mockHorizon,
+					mockEntityManager,
+					"Test SDF Network ; September 2015",
+					mocks.Now,
+				)
+
+				mockHorizon.On(
+					"LoadAccount",
+					accountID,
+				).Return(
+					horizon.AccountResponse{
+						AccountID:      accountID,
+						SequenceNumber: "10372672437354496",
+					},
+					nil,
+				).Once()
+
+				err := transactionSubmitter.InitAccount(seed)
+				assert.Nil(t, err)
+
+				mockEntityManager.On(
+					"Persist",
+					mock.AnythingOfType("*entities.SentTransaction"),
+				).Return(nil).Twice()
+
+				ledger := uint64(1486276)
+				mockHorizon.On("SubmitTransaction", mock.AnythingOfType("string")).Return(
+					horizon.SubmitTransactionResponse{Ledger: &ledger},
+					nil,
+				).Once().Run(func(args mock.Arguments) {
+					var envelope xdr.TransactionEnvelope
+					err := xdr.SafeUnmarshalBase64(args.String(0), &envelope)
+					require.NoError(t, err)
+					require.Len(t, envelope.Signatures, 2)
+
+					hash, err := TransactionHash(&envelope.Tx, "Test SDF Network ; September 2015")
+					require.NoError(t, err)
+					for i, signerSeed := range []string{seed, signer} {
+						kp := keypair.MustParse(signerSeed)
+						assert.NoError(t, kp.Verify(hash[:], envelope.Signatures[i].Signature))
+					}
+				})
+
+				_, err = transactionSubmitter.SubmitTransaction((*string)(nil), seed, operation, nil, signer)
+				assert.Nil(t, err)
+				mockHorizon.AssertExpectations(t)
+			})
+
+			Convey("Submits transaction failing with insufficient fee", func() {
+				operation := b.Payment(
+					b.Destination{"GB3W7VQ2A2IOQIS4LUFUMRC2DWXONUDH24ROLE6RS4NGUNHVSXKCABOM"},
+					b.NativeAmount{"100"},
+				)
+
+				transactionSubmitter := NewTransactionSubmitter(
+					mockHorizon,
+					mockEntityManager,
+					"Test SDF Network ; September 2015",
+					mocks.Now,
+				)
+
+				mockHorizon.On(
+					"LoadAccount",
+					accountID,
+				).Return(
+					horizon.AccountResponse{
+						AccountID:      accountID,
+						SequenceNumber: "10372672437354496",
+					},
+					nil,
+				).Once()
+
+				err := transactionSubmitter.InitAccount(seed)
+				assert.Nil(t, err)
+
+				insufficientFee := horizon.SubmitTransactionResponse{
+					Extras: &horizon.SubmitTransactionResponseExtras{ResultXdr: "AAAAAAAAAAD////3AAAAAA=="},
+				}
+
+				Convey("When resubmitting is disabled", func() {
+					mockEntityManager.On(
+						"Persist",
+						mock.AnythingOfType("*entities.SentTransaction"),
+					).Return(nil).Twice()
+
+					mockHorizon.On("SubmitTransaction", mock.AnythingOfType("string")).Return(insufficientFee, nil).Once()
+
+					response, err := transactionSubmitter.SubmitTransaction((*string)(nil), seed, operation, nil)
+					assert.Nil(t, err)
+					assert.Equal(t, insufficientFee.Extras, response.Extras)
+					mockHorizon.AssertExpectations(t)
+				})
+
+				Convey("When resubmitting is enabled", func() {
+					transactionSubmitter.MaxBaseFee = 300
+
+					mockEntityManager.On(
+						"Persist",
+						mock.AnythingOfType("*entities.SentTransaction"),
+					).Return(nil)
+					Reset(func() {
+						mockEntityManager.ExpectedCalls = nil
+					})
+
+					var fees []xdr.Uint32
+					var sequenceNumbers []xdr.SequenceNumber
+					recordFee := func(args mock.Arguments) {
+						var envelope xdr.TransactionEnvelope
+						err := xdr.SafeUnmarshalBase64(args.String(0), &envelope)
+						require.NoError(t, err)
+						fees = append(fees, envelope.Tx.Fee)
+						sequenceNumbers = append(sequenceNumbers, envelope.Tx.SeqNum)
+					}
+
+					// Network base fee is higher than double of the default fee but lower than the ceiling
+					mockHorizon.On("LoadLatestLedger").Return(horizon.LedgerResponse{BaseFeeInStroops: 250}, nil)
+					Reset(func() {
+						mockHorizon.ExpectedCalls = nil
+					})
+
+					Convey("it should resubmit transaction with higher fee", func() {
+						ledger := uint64(1486276)
+						mockHorizon.On("SubmitTransaction", mock.AnythingOfType("string")).Return(insufficientFee, nil).Once().Run(recordFee)
+						mockHorizon.On("SubmitTransaction", mock.AnythingOfType("string")).Return(
+							horizon.SubmitTransactionResponse{Ledger: &ledger},
+							nil,
+						).Once().Run(recordFee)
+
+						response, err := transactionSubmitter.SubmitTransaction((*string)(nil), seed, operation, nil)
+						assert.Nil(t, err)
+						assert.Equal(t, &ledger, response.Ledger)
+						assert.Equal(t, []xdr.Uint32{100, 250}, fees)
+						assert.Equal(t, sequenceNumbers[0], sequenceNumbers[1])
+					})
+
+					Convey("it should stop at the ceiling", func() {
+						mockHorizon.On("SubmitTransaction", mock.AnythingOfType("string")).Return(insufficientFee, nil).Times(3).Run(recordFee)
+
+						response, err := transactionSubmitter.SubmitTransaction((*string)(nil), seed, operation, nil)
+						assert.Nil(t, err)
+						assert.Equal(t, insufficientFee.Extras, response.Extras)
+						assert.Equal(t, []xdr.Uint32{100, 250, 300}, fees)
+					})
+
+					Convey("it should stop when retry budget is used up", func() {
+						mockHorizon.On("SubmitTransaction", mock.AnythingOfType("string")).Return(insufficientFee, nil).Twice().Run(recordFee)
+
+						ctx := net.WithRetryBudget(context.Background(), 1)
+						response, err := transactionSubmitter.WithContext(ctx).SubmitTransaction((*string)(nil), seed, operation, nil)
+						assert.Nil(t, err)
+						assert.Equal(t, insufficientFee.Extras, response.Extras)
+						assert.Equal(t, []xdr.Uint32{100, 250}, fees)
+					})
+				})
+			})
+
+			Convey("Submits transaction from account ID signed by a signer", func() {
+				// GCF3WVYTHF75PEG6622G5G6KU26GOSDQPDHSCJ3DQD7VONH4EYVDOGKJ
+				signer := "SDWLS4G3XCNIYPKXJWWGGJT6UDY63WV6PEFTWP7JZMQB4RE7EUJQN5XM"
+				operation := b.Payment(
+					b.Destination{"GB3W7VQ2A2IOQIS4LUFUMRC2DWXONUDH24ROLE6RS4NGUNHVSXKCABOM"},
+					b.NativeAmount{"100"},
+				)
+
+				transactionSubmitter := NewTransactionSubmitter(
+					mockHorizon,
+					mockEntityManager,
+					"Test SDF Network ; September 2015",
+					mocks.Now,
+				)
+
+				mockHorizon.On(
+					"LoadAccount",
+					accountID,
+				).Return(
+					horizon.AccountResponse{
+						AccountID:      accountID,
+						SequenceNumber: "10372672437354496",
+					},
+					nil,
+				).Once()
+
+				Convey("When no signers are given", func() {
+					_, err := transactionSubmitter.SubmitTransaction((*string)(nil), accountID, operation, nil)
+					assert.NotNil(t, err)
+				})
+
+				Convey("When signer is given", func() {
+					mockEntityManager.On(
+						"Persist",
+						mock.AnythingOfType("*entities.SentTransaction"),
+					).Return(nil).Twice()
+
+					ledger := uint64(1486276)
+					mockHorizon.On("SubmitTransaction", mock.AnythingOfType("string")).Return(
+						horizon.SubmitTransactionResponse{Ledger: &ledger},
+						nil,
+					).Once().Run(func(args mock.Arguments) {
+						var envelope xdr.TransactionEnvelope
+						err := xdr.SafeUnmarshalBase64(args.String(0), &envelope)
+						require.NoError(t, err)
+						assert.Equal(t, accountID, envelope.Tx.SourceAccount.Address())
+						require.Len(t, envelope.Signatures, 1)
+
+						hash, err := TransactionHash(&envelope.Tx, "Test SDF Network ; September 2015")
+						require.NoError(t, err)
+						assert.NoError(t, keypair.MustParse(signer).Verify(hash[:], envelope.Signatures[0].Signature))
+					})
+
+					_, err := transactionSubmitter.SubmitTransaction((*string)(nil), accountID, operation, nil, signer)
+					assert.Nil(t, err)
+
+					// The same account used with its seed shares the sequence number
+					account, err := transactionSubmitter.LoadAccount(seed)
+					require.NoError(t, err)
+					assert.Equal(t, uint64(10372672437354497), account.SequenceNumber)
+					mockHorizon.AssertExpectations(t)
+				})
+			})
+
+			Convey("Submits transaction with its own sequence number", func() {
+				operation := bridge.Operations{
+					b.Sequence{10372672437354600},
+					b.Payment(
+						b.Destination{"GB3W7VQ2A2IOQIS4LUFUMRC2DWXONUDH24ROLE6RS4NGUNHVSXKCABOM"},
+						b.NativeAmount{"100"},
+					),
+				}
+
+				transactionSubmitter := NewTransactionSubmitter(
+					mockHorizon,
+					mockEntityManager,
+					"Test SDF Network ; September 2015",
+					mocks.Now,
+				)
+
+				mockEntityManager.On(
+					"Persist",
+					mock.AnythingOfType("*entities.SentTransaction"),
+				).Return(nil).Twice()
+
+				ledger := uint64(1486276)
+				mockHorizon.On("SubmitTransaction", mock.AnythingOfType("string")).Return(
+					horizon.SubmitTransactionResponse{Ledger: &ledger},
+					nil,
+				).Once().Run(func(args mock.Arguments) {
+					var envelope xdr.TransactionEnvelope
+					err := xdr.SafeUnmarshalBase64(args.String(0), &envelope)
+					require.NoError(t, err)
+					assert.Equal(t, xdr.SequenceNumber(10372672437354600), envelope.Tx.SeqNum)
+				})
+
+				Convey("it should not load the account", func() {
+					_, err := transactionSubmitter.SubmitTransaction((*string)(nil), seed, operation, nil)
+					assert.Nil(t, err)
+					mockHorizon.AssertExpectations(t)
+				})
+
+				Convey("it should move the current sequence number forward", func() {
+					transactionSubmitter.Accounts[accountID] = &Account{
+						Keypair:        keypair.MustParse(seed),
+						Seed:           seed,
+						SequenceNumber: 10372672437354496,
+					}
+
+					_, err := transactionSubmitter.SubmitTransaction((*string)(nil), seed, operation, nil)
+					assert.Nil(t, err)
+					assert.Equal(t, uint64(10372672437354600), transactionSubmitter.Accounts[accountID].SequenceNumber)
+				})
+			})
+		})
+
+		Convey("SubmitTransactionAsync", func() {
+			transactionSubmitter := NewTransactionSubmitter(
+				mockHorizon,
+				mockEntityManager,
+				"Test SDF Network ; September 2015",
+				mocks.Now,
+			)
+
+			var submitted string
+			transactionSubmitter.AsyncSubmissionService = SubmissionServiceFunc(func(txeBase64 string) (horizon.SubmitTransactionResponse, error) {
+				submitted = txeBase64
+				return horizon.SubmitTransactionResponse{Hash: "abc", Status: horizon.AsyncStatusPending}, nil
+			})
+			// Polls only after the test ends
+			transactionSubmitter.AsyncWatcher = NewAsyncWatcher(mockHorizon, nil, mockEntityManager, nil, time.Hour, time.Hour, mocks.Now)
+
+			mockHorizon.On("LoadAccount", accountID).Return(
+				horizon.AccountResponse{
+					AccountID:      accountID,
+					SequenceNumber: "10372672437354496",
+				},
+				nil,
+			).Once()
+
+			// Pending transaction is persisted only once, in sending status
+			mockEntityManager.On(
+				"Persist",
+				mock.AnythingOfType("*entities.SentTransaction"),
+			).Return(nil).Once().Run(func(args mock.Arguments) {
+				transaction := args.Get(0).(*entities.SentTransaction)
+				assert.Equal(t, "sending", string(transaction.Status))
+			})
+
+			operation := b.Payment(
+				b.Destination{"GB3W7VQ2A2IOQIS4LUFUMRC2DWXONUDH24ROLE6RS4NGUNHVSXKCABOM"},
+				b.NativeAmount{"100"},
+			)
+
+			response, err := transactionSubmitter.SubmitTransactionAsync((*string)(nil), seed, operation, nil)
+			require.NoError(t, err)
+			assert.NotEmpty(t, submitted)
+			assert.Equal(t, "abc", response.Hash)
+			assert.Equal(t, horizon.AsyncStatusPending, response.Status)
+			assert.Nil(t, response.Ledger)
+			assert.Len(t, transactionSubmitter.AsyncWatcher.watching, 1, "transaction should be watched")
+			mockHorizon.AssertExpectations(t)
+			mockEntityManager.AssertExpectations(t)
+		})
+
+		Convey("ResubmitTransaction", func() {
+			transactionSubmitter := NewTransactionSubmitter(
+				mockHorizon,
+				mockEntityManager,
+				"Test SDF Network ; September 2015",
+				mocks.Now,
+			)
+
+			tx, err := b.Transaction(
+				b.SourceAccount{seed},
+				b.Sequence{123},
+				b.TestNetwork,
+				b.Payment(
+					b.Destination{"GB3W7VQ2A2IOQIS4LUFUMRC2DWXONUDH24ROLE6RS4NGUNHVSXKCABOM"},
+					b.NativeAmount{"100"},
+				),
+			)
+			require.NoError(t, err)
+			txe, err := tx.Sign(seed)
+			require.NoError(t, err)
+			txeB64, err := txe.Base64()
+			require.NoError(t, err)
+			hash, err := tx.HashHex()
+			require.NoError(t, err)
+
+			Convey("EnvelopeHash returns transaction hash", func() {
+				envelopeHash, err := EnvelopeHash(txeB64, "Test SDF Network ; September 2015")
+				assert.Nil(t, err)
+				assert.Equal(t, hash, envelopeHash)
+			})
+
+			Convey("When transaction is already in the ledger", func() {
+				mockHorizon.On("LoadTransaction", hash).Return(
+					&horizon.TransactionResponse{Hash: hash, Ledger: 100, ResultXdr: "AAAAAAAAAGQAAAAAAAAAAQAAAAAAAAABAAAAAAAAAAA="},
+					nil,
+				).Once()
+
+				response, err := transactionSubmitter.ResubmitTransaction(txeB64)
+				assert.Nil(t, err)
+				assert.Equal(t, hash, response.Hash)
+				assert.Equal(t, uint64(100), *response.Ledger)
+				assert.Equal(t, "123", response.SourceSequence)
+				mockHorizon.AssertExpectations(t)
+			})
+
+			Convey("When transaction is not in the ledger", func() {
+				mockHorizon.On("LoadTransaction", hash).Return(
+					(*horizon.TransactionResponse)(nil),
+					nil,
+				).Once()
+
+				ledger := uint64(100)
+				mockHorizon.On("SubmitTransaction", txeB64).Return(
+					horizon.SubmitTransactionResponse{Ledger: &ledger},
+					nil,
+				).Once()
+
+				response, err := transactionSubmitter.ResubmitTransaction(txeB64)
+				assert.Nil(t, err)
+				assert.Equal(t, "123", response.SourceSequence)
+				mockHorizon.AssertExpectations(t)
+			})
+
+			Convey("When transaction is resubmitted asynchronously", func() {
+				mockHorizon.On("LoadTransaction", hash).Return(
+					(*horizon.TransactionResponse)(nil),
+					nil,
+				).Once()
+
+				transactionSubmitter.AsyncSubmissionService = SubmissionServiceFunc(func(txeBase64 string) (horizon.SubmitTransactionResponse, error) {
+					assert.Equal(t, txeB64, txeBase64)
+					return horizon.SubmitTransactionResponse{Status: horizon.AsyncStatusPending}, nil
+				})
+
+				response, err := transactionSubmitter.ResubmitTransactionAsync(txeB64)
+				assert.Nil(t, err)
+				assert.Equal(t, hash, response.Hash)
+				assert.Equal(t, horizon.AsyncStatusPending, response.Status)
+				mockHorizon.AssertExpectations(t)
+			})
 		})
 	})
 }